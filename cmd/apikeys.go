@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// apiKeyEnvPrefix prefixes the environment variables holding API keys, e.g.
+// KUMOCTL_API_KEY_PETSTORE_AUTH for the petstore_auth scheme
+const apiKeyEnvPrefix = "KUMOCTL_API_KEY_"
+
+// apiKeyEnvVar returns the environment variable read for a security scheme
+func apiKeyEnvVar(scheme string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, scheme)
+	return apiKeyEnvPrefix + strings.ToUpper(name)
+}
+
+// apiKeyQueryParams resolves the values of the spec's query string API keys,
+// from the --api-key flag first and the environment second
+func apiKeyQueryParams(cmd *cobra.Command, spec openapi.APISpec) (url.Values, error) {
+	apiKeys, err := cmd.Flags().GetStringArray("api-key")
+	if err != nil {
+		return nil, err
+	}
+
+	schemes := make(map[string]openapi.SecurityScheme)
+	for name, scheme := range spec.GetSecuritySchemes() {
+		if scheme.Type == "apiKey" && scheme.In == "query" && scheme.Name != "" {
+			schemes[name] = scheme
+		}
+	}
+
+	params := make(url.Values)
+	for _, k := range apiKeys {
+		name, value, found := strings.Cut(k, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid API key format: %s (expected 'scheme=value')", k)
+		}
+		scheme, ok := schemes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown API key scheme: %s (the spec declares no apiKey scheme of that name sent in the query)", name)
+		}
+		params.Set(scheme.Name, value)
+	}
+
+	for name, scheme := range schemes {
+		if params.Has(scheme.Name) {
+			continue
+		}
+		if value, ok := os.LookupEnv(apiKeyEnvVar(name)); ok {
+			params.Set(scheme.Name, value)
+		}
+	}
+
+	return params, nil
+}
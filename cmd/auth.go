@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// oauth2ClientSecretEnv holds the client secret when the flag isn't given
+	oauth2ClientSecretEnv = "KUMOCTL_OAUTH2_CLIENT_SECRET"
+	// oauth2RefreshTokenEnv holds the refresh token when the flag isn't given
+	oauth2RefreshTokenEnv = "KUMOCTL_OAUTH2_REFRESH_TOKEN"
+	// oauth2AccessTokenEnv holds a pre-minted access token when the flag isn't given
+	oauth2AccessTokenEnv = "KUMOCTL_OAUTH2_ACCESS_TOKEN"
+	// sessionCredentialsEnv holds the login input as JSON when no file is given
+	sessionCredentialsEnv = "KUMOCTL_SESSION_CREDENTIALS"
+	// subjectTokenEnv holds the token exchanged at the STS when neither flag is given
+	subjectTokenEnv = "KUMOCTL_SUBJECT_TOKEN"
+	// tokenExchangeClientSecretEnv holds the STS client secret when the flag isn't given
+	tokenExchangeClientSecretEnv = "KUMOCTL_TOKEN_EXCHANGE_CLIENT_SECRET"
+)
+
+// addAuthFlags registers the flags configuring credentials that are renewed
+// during a session
+func addAuthFlags(cmd *cobra.Command) {
+	cmd.Flags().String("oauth2-token-url", "", "OAuth2 token endpoint (default the tokenUrl of the spec's oauth2 security scheme)")
+	cmd.Flags().String("oauth2-client-id", "", "OAuth2 client ID, enables the client credentials grant")
+	cmd.Flags().String("oauth2-client-secret", "", "OAuth2 client secret (default $"+oauth2ClientSecretEnv+")")
+	cmd.Flags().String("oauth2-refresh-token", "", "OAuth2 refresh token, enables the refresh token grant (default $"+oauth2RefreshTokenEnv+")")
+	cmd.Flags().String("oauth2-access-token", "", "access token to use until it expires (default $"+oauth2AccessTokenEnv+")")
+	cmd.Flags().StringSlice("oauth2-scopes", []string{}, "OAuth2 scopes to request")
+	cmd.Flags().String("session-login", "", "tool name of the login operation establishing a cookie session")
+	cmd.Flags().String("session-credentials-file", "", "JSON file with the login operation's input (default $"+sessionCredentialsEnv+")")
+	cmd.Flags().String("session-cookie", "", "name of the session cookie (default the spec's cookie security scheme)")
+	cmd.Flags().String("token-exchange-url", "", "token endpoint of the STS exchanging the subject token for audience-scoped tokens (RFC 8693)")
+	cmd.Flags().String("subject-token", "", "token to exchange (default $"+subjectTokenEnv+")")
+	cmd.Flags().String("subject-token-file", "", "file holding the token to exchange, read for every exchange")
+	cmd.Flags().String("subject-token-type", kumo_mcp.AccessTokenType, "token type URI of the subject token")
+	cmd.Flags().StringArray("token-audience", []string{}, "audience requested for an upstream host in the form of host=audience (default the host's origin)")
+	cmd.Flags().String("token-exchange-client-id", "", "client ID authenticating with the STS")
+	cmd.Flags().String("token-exchange-client-secret", "", "client secret authenticating with the STS (default $"+tokenExchangeClientSecretEnv+")")
+}
+
+// flagOrEnv returns the value of a string flag, falling back to an environment
+// variable so secrets can be kept out of command lines
+func flagOrEnv(cmd *cobra.Command, name, env string) (string, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil || value != "" {
+		return value, err
+	}
+	return os.Getenv(env), nil
+}
+
+// authFromFlags builds the authenticator renewing credentials during a
+// session, returning nil when none is configured
+func authFromFlags(cmd *cobra.Command, spec openapi.APISpec, opts *kumo_mcp.ToolOptions) (kumo_mcp.Authenticator, error) {
+	session, err := sessionFromFlags(cmd, spec, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	oauth2, err := oauth2FromFlags(cmd, spec, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange, err := tokenExchangeFromFlags(cmd, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case session != nil && oauth2 != nil:
+		return nil, fmt.Errorf("--session-login conflicts with OAuth2")
+	case exchange != nil && (session != nil || oauth2 != nil):
+		return nil, fmt.Errorf("--token-exchange-url conflicts with --session-login and OAuth2")
+	case session != nil:
+		return session, nil
+	case oauth2 != nil:
+		if opts.Headers.Get("Authorization") != "" {
+			return nil, fmt.Errorf("OAuth2 conflicts with the Authorization header given in --headers or --basic-auth")
+		}
+		return oauth2, nil
+	case exchange != nil:
+		if opts.Headers.Get("Authorization") != "" {
+			return nil, fmt.Errorf("token exchange conflicts with the Authorization header given in --headers or --basic-auth")
+		}
+		return exchange, nil
+	default:
+		return nil, nil
+	}
+}
+
+// tokenExchangeFromFlags builds the authenticator exchanging a subject token
+// for audience-scoped tokens, returning nil when no STS is configured
+func tokenExchangeFromFlags(cmd *cobra.Command, opts *kumo_mcp.ToolOptions) (kumo_mcp.Authenticator, error) {
+	var cfg kumo_mcp.TokenExchangeConfig
+	var err error
+
+	if cfg.TokenURL, err = cmd.Flags().GetString("token-exchange-url"); err != nil || cfg.TokenURL == "" {
+		return nil, err
+	}
+
+	if cfg.SubjectTokenFile, err = cmd.Flags().GetString("subject-token-file"); err != nil {
+		return nil, err
+	}
+	if cfg.SubjectTokenFile == "" {
+		if cfg.SubjectToken, err = flagOrEnv(cmd, "subject-token", subjectTokenEnv); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.SubjectTokenType, err = cmd.Flags().GetString("subject-token-type"); err != nil {
+		return nil, err
+	}
+	if cfg.ClientID, err = cmd.Flags().GetString("token-exchange-client-id"); err != nil {
+		return nil, err
+	}
+	if cfg.ClientSecret, err = flagOrEnv(cmd, "token-exchange-client-secret", tokenExchangeClientSecretEnv); err != nil {
+		return nil, err
+	}
+
+	audiences, err := cmd.Flags().GetStringArray("token-audience")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Audiences = make(map[string]string, len(audiences))
+	for _, a := range audiences {
+		host, audience, found := strings.Cut(a, "=")
+		host, audience = strings.TrimSpace(host), strings.TrimSpace(audience)
+		if !found || host == "" || audience == "" {
+			return nil, fmt.Errorf("invalid token audience format: %s (expected 'host=audience')", a)
+		}
+		cfg.Audiences[host] = audience
+	}
+
+	return kumo_mcp.NewTokenExchangeAuthenticator(cfg, opts.HTTPClient)
+}
+
+// oauth2FromFlags builds the authenticator managing an OAuth2 access token,
+// returning nil when no OAuth2 grant is configured
+func oauth2FromFlags(cmd *cobra.Command, spec openapi.APISpec, opts *kumo_mcp.ToolOptions) (kumo_mcp.Authenticator, error) {
+	var cfg kumo_mcp.OAuth2Config
+	var err error
+
+	if cfg.ClientID, err = cmd.Flags().GetString("oauth2-client-id"); err != nil {
+		return nil, err
+	}
+	if cfg.RefreshToken, err = flagOrEnv(cmd, "oauth2-refresh-token", oauth2RefreshTokenEnv); err != nil {
+		return nil, err
+	}
+
+	if cfg.ClientID == "" && cfg.RefreshToken == "" {
+		return nil, nil
+	}
+
+	if cfg.ClientSecret, err = flagOrEnv(cmd, "oauth2-client-secret", oauth2ClientSecretEnv); err != nil {
+		return nil, err
+	}
+	if cfg.AccessToken, err = flagOrEnv(cmd, "oauth2-access-token", oauth2AccessTokenEnv); err != nil {
+		return nil, err
+	}
+	if cfg.Scopes, err = cmd.Flags().GetStringSlice("oauth2-scopes"); err != nil {
+		return nil, err
+	}
+	if cfg.TokenURL, err = cmd.Flags().GetString("oauth2-token-url"); err != nil {
+		return nil, err
+	}
+
+	if cfg.TokenURL == "" {
+		if cfg.TokenURL, err = specTokenURL(spec); err != nil {
+			return nil, err
+		}
+	}
+
+	source, err := kumo_mcp.NewOAuth2TokenSource(cfg, opts.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kumo_mcp.BearerAuthenticator{Source: source}, nil
+}
+
+// specTokenURL returns the token endpoint declared by the spec's oauth2
+// security scheme
+func specTokenURL(spec openapi.APISpec) (string, error) {
+	var tokenURLs []string
+	for _, scheme := range spec.GetSecuritySchemes() {
+		if scheme.Type == "oauth2" && scheme.TokenURL != "" {
+			tokenURLs = append(tokenURLs, scheme.TokenURL)
+		}
+	}
+	sort.Strings(tokenURLs)
+
+	switch {
+	case len(tokenURLs) == 0:
+		return "", fmt.Errorf("the spec declares no OAuth2 token URL, set --oauth2-token-url")
+	case len(tokenURLs) > 1 && tokenURLs[0] != tokenURLs[len(tokenURLs)-1]:
+		return "", fmt.Errorf("the spec declares several OAuth2 token URLs, choose one with --oauth2-token-url")
+	default:
+		return tokenURLs[0], nil
+	}
+}
+
+// sessionFromFlags builds the authenticator logging in through an operation
+// of the spec, returning nil when no login operation is configured
+func sessionFromFlags(cmd *cobra.Command, spec openapi.APISpec, opts *kumo_mcp.ToolOptions) (*kumo_mcp.SessionAuthenticator, error) {
+	login, err := cmd.Flags().GetString("session-login")
+	if err != nil || login == "" {
+		return nil, err
+	}
+
+	var cfg kumo_mcp.SessionConfig
+	if cfg.Login, err = kumo_mcp.FindTool(spec, login, opts); err != nil {
+		return nil, err
+	}
+
+	if cfg.Credentials, err = sessionCredentials(cmd); err != nil {
+		return nil, err
+	}
+
+	if cfg.CookieName, err = cmd.Flags().GetString("session-cookie"); err != nil {
+		return nil, err
+	}
+
+	if cfg.CookieName == "" {
+		cfg.CookieName = specSessionCookie(spec)
+	}
+
+	return kumo_mcp.NewSessionAuthenticator(cfg, opts)
+}
+
+// sessionCredentials reads the login operation's input from the credentials
+// file or the environment
+func sessionCredentials(cmd *cobra.Command) (kumo_mcp.APIToolInput, error) {
+	path, err := cmd.Flags().GetString("session-credentials-file")
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if path != "" {
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read session credentials: %w", err)
+		}
+	} else if value := os.Getenv(sessionCredentialsEnv); value != "" {
+		data = []byte(value)
+	} else {
+		return kumo_mcp.APIToolInput{}, nil
+	}
+
+	var credentials kumo_mcp.APIToolInput
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("session credentials must be a JSON object: %w", err)
+	}
+	return credentials, nil
+}
+
+// specSessionCookie returns the cookie named by the spec's apiKey security
+// scheme in a cookie, or an empty string when there is no single one
+func specSessionCookie(spec openapi.APISpec) string {
+	var name string
+	for _, scheme := range spec.GetSecuritySchemes() {
+		if scheme.Type != "apiKey" || scheme.In != "cookie" {
+			continue
+		}
+		if name != "" && name != scheme.Name {
+			return ""
+		}
+		name = scheme.Name
+	}
+	return name
+}
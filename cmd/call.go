@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var callCmd = &cobra.Command{
+	Use:   "call [spec-path-or-url] [tool-name]",
+	Short: "Call a tool and print the API response",
+	Long: `Build and send the request a tool call would, then print the response status,
+headers and body. The command fails when the call does or the API answers with
+an error status.`,
+	Example: "  kumoctl call ./spec.json getUser --input '{\"userId\": \"42\"}'",
+	Args:    cobra.ExactArgs(2),
+	RunE:    runCall,
+}
+
+func runCall(cmd *cobra.Command, args []string) error {
+	source, toolName := args[0], args[1]
+
+	if !isURL(source) {
+		if _, err := os.Stat(source); os.IsNotExist(err) {
+			return fmt.Errorf("file does not exist: %s", source)
+		}
+	}
+
+	rawInput, err := cmd.Flags().GetString("input")
+	if err != nil {
+		return err
+	}
+
+	var input kumo_mcp.APIToolInput
+	if err := json.Unmarshal([]byte(rawInput), &input); err != nil {
+		return fmt.Errorf("invalid --input, expected a JSON object: %w", err)
+	}
+
+	openapiSpec, err := loadSpec(cmd, source)
+	if err != nil {
+		return err
+	}
+
+	toolOptions, err := callOptionsFromFlags(cmd, openapiSpec)
+	if err != nil {
+		return err
+	}
+
+	tool, err := kumo_mcp.FindTool(openapiSpec, toolName, toolOptions)
+	if err != nil {
+		return err
+	}
+
+	output, err := kumo_mcp.CallTool(cmd.Context(), tool, input, toolOptions)
+	if err != nil {
+		return err
+	}
+
+	if err := writeToolOutput(cmd.OutOrStdout(), output); err != nil {
+		return err
+	}
+
+	switch {
+	case output.Error != "":
+		return fmt.Errorf("%s", output.Error)
+	case output.StatusCode >= 400:
+		return fmt.Errorf("API answered with status %d", output.StatusCode)
+	}
+	return nil
+}
+
+// callOptionsFromFlags builds the options of commands calling tools directly
+// from the flags registered by addCallFlags
+func callOptionsFromFlags(cmd *cobra.Command, spec openapi.APISpec) (*kumo_mcp.ToolOptions, error) {
+	toolOptions, err := requestOptionsFromFlags(cmd, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if toolOptions.Timeout, err = cmd.Flags().GetDuration("timeout"); err != nil {
+		return nil, err
+	}
+
+	if toolOptions.HTTPClient, err = httpClientFromFlags(cmd); err != nil {
+		return nil, err
+	}
+
+	if toolOptions.Auth, err = authFromFlags(cmd, spec, toolOptions); err != nil {
+		return nil, err
+	}
+
+	if err := applyCassetteFlags(cmd, toolOptions); err != nil {
+		return nil, err
+	}
+
+	if err := applyHostGuard(cmd, []openapi.APISpec{spec}, []string{toolOptions.BaseURL}, toolOptions); err != nil {
+		return nil, err
+	}
+
+	return toolOptions, nil
+}
+
+// addCallFlags registers the flags of commands calling tools directly
+func addCallFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("timeout", 0, "timeout for each call, 0 disables it")
+	addRequestFlags(cmd)
+	addHTTPClientFlags(cmd)
+	addSpecLoadingFlags(cmd)
+	addCassetteFlags(cmd)
+	addHostGuardFlags(cmd)
+	addAuthFlags(cmd)
+}
+
+// writeToolOutput prints the status line, sorted headers and indented body of
+// a tool call
+func writeToolOutput(w io.Writer, output kumo_mcp.APIToolOutput) error {
+	if output.StatusCode == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w, "%d %s\n", output.StatusCode, http.StatusText(output.StatusCode))
+
+	names := make([]string, 0, len(output.Headers))
+	for name := range output.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: %s\n", name, output.Headers[name])
+	}
+
+	switch body := output.Body.(type) {
+	case nil:
+		if output.Snippet != "" {
+			fmt.Fprintf(w, "\n%s\n", output.Snippet)
+		}
+	case string:
+		fmt.Fprintf(w, "\n%s\n", strings.TrimRight(body, "\n"))
+	default:
+		data, err := json.MarshalIndent(body, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n%s\n", data)
+	}
+	return nil
+}
+
+func init() {
+	callCmd.Flags().String("input", "{}", "tool input as a JSON object")
+	addCallFlags(callCmd)
+	rootCmd.AddCommand(callCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCall(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/users/42" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": "42", "name": "Alice"})
+	}))
+	defer upstream.Close()
+
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Users API", "version": "1.0.0"},
+  "servers": [{"url": "` + upstream.URL + `"}],
+  "paths": {
+    "/users/{userId}": {
+      "get": {
+        "operationId": "getUser",
+        "parameters": [{"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(spec), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		expectErr string
+		expectOut []string
+	}{
+		{
+			name:      "successful call",
+			input:     `{"userId": "42"}`,
+			expectOut: []string{"200 OK", "Content-Type: application/json", `"name": "Alice"`},
+		},
+		{
+			name:      "error status",
+			input:     `{"userId": "7"}`,
+			expectErr: "status 404",
+			expectOut: []string{"404 Not Found", `"error": "not found"`},
+		},
+		{
+			name:      "invalid input",
+			input:     `{}`,
+			expectErr: "userId: is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			callCmd.SetOut(&stdout)
+			callCmd.SetContext(context.Background())
+			defer callCmd.SetOut(nil)
+
+			if err := callCmd.Flags().Set("input", tt.input); err != nil {
+				t.Fatalf("Failed to set input: %v", err)
+			}
+
+			err := runCall(callCmd, []string{specPath, "getUser"})
+			switch {
+			case tt.expectErr == "" && err != nil:
+				t.Fatalf("Unexpected error: %v", err)
+			case tt.expectErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expectErr)):
+				t.Fatalf("Expected error containing %q, got %v", tt.expectErr, err)
+			}
+
+			for _, expected := range tt.expectOut {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+				}
+			}
+		})
+	}
+}
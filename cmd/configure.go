@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -13,13 +18,59 @@ import (
 
 // MCPServerConfig represents a single MCP server configuration
 type MCPServerConfig struct {
-	Command string   `json:"command"`
-	Args    []string `json:"args"`
+	// Type is the transport of the server, for clients expecting one
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Source tells Zed the server isn't one of its extensions
+	Source  string   `json:"source,omitempty" yaml:"source,omitempty"`
+	Command string   `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string `json:"args,omitempty" yaml:"args,omitempty"`
+	// Env holds the headers as KUMOCTL_HEADER_* variables, out of the
+	// arguments anyone can read in the process list
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// URL locates remote servers, under the key of the client
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// ServerURL is the URL of remote servers for Windsurf
+	ServerURL string `json:"serverUrl,omitempty" yaml:"serverUrl,omitempty"`
+	// HTTPURL is the URL of remote servers for Gemini CLI
+	HTTPURL string `json:"httpUrl,omitempty" yaml:"httpUrl,omitempty"`
+	// Headers are sent to remote servers
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
 }
 
-// ClaudeDesktopConfig represents the full Claude Desktop configuration
-type MCPClientConfig struct {
-	MCPServers map[string]MCPServerConfig `json:"mcpServers"`
+// remoteURL returns the URL of a remote server, whatever its key
+func (s MCPServerConfig) remoteURL() string {
+	for _, u := range []string{s.URL, s.ServerURL, s.HTTPURL} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// mcpClient describes where an LLM client reads its MCP servers from
+type mcpClient struct {
+	// Name is shown in messages, e.g. VS Code
+	Name string
+	// ConfigFile holds the client's configuration
+	ConfigFile string
+	// ServersKey is the key of the object holding the servers by name
+	ServersKey string
+	// ServerType is the type of server entries, for clients expecting one
+	ServerType string
+	// ServerSource is the source of server entries, for clients expecting one
+	ServerSource string
+	// URLKey is the key of the URL of remote servers, empty for clients
+	// that only start servers as commands
+	URLKey string
+	// RemoteType is the type of remote server entries, for clients expecting
+	// one
+	RemoteType string
+	// JSONC is set for config files with comments, or holding more than
+	// settings, which are edited in place
+	JSONC bool
+	// Note is printed once the server is configured
+	Note string
 }
 
 var configureCmd = &cobra.Command{
@@ -31,6 +82,16 @@ This command helps you automatically configure kumoctl as an MCP server in your
 Supported clients:
 - Claude Desktop (default)
 - Cursor
+- VS Code, for the user or the workspace in the current directory (--scope)
+- Claude Code, for the user or the project in the current directory (--scope)
+- Gemini CLI
+- Windsurf
+- Zed, keeping the comments of its settings
+- Any other client with --client generic, which prints the server entry as
+  JSON, YAML or TOML (--format) without touching any file
+
+With --transport http the entry points clients supporting remote servers to
+the MCP server at --url instead of starting kumoctl, and the spec is left out.
 
 Examples:
   # Generate configuration for Claude Desktop
@@ -45,28 +106,58 @@ Examples:
   # Generate configuration without installing
   kumoctl configure --dry-run examples/openapi3-example.yaml weather-api
 
+  # Review the change and confirm before installing
+  kumoctl configure --explain examples/openapi3-example.yaml weather-api
+
   # Specify custom client
-  kumoctl configure --client=cursor examples/openapi2-example.json my-tools`,
-	Args: cobra.ExactArgs(2),
+  kumoctl configure --client=cursor examples/openapi2-example.json my-tools
+
+  # Configure the VS Code workspace in the current directory
+  kumoctl configure --client=vscode --scope=workspace examples/openapi2-example.json my-tools
+
+  # Print the server entry for another client
+  kumoctl configure --client=generic --format=toml examples/openapi2-example.json my-tools
+
+  # Share the server with the project in the current directory through its .mcp.json
+  kumoctl configure --client=claude-code --scope=project examples/openapi2-example.json my-tools
+
+  # Connect Cursor to a remote MCP server
+  kumoctl configure --client=cursor --transport=http --url=https://mcp.example.com/mcp my-api --headers 'Authorization=Bearer ${API_TOKEN}'`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		// Remote servers don't need the spec
+		if transport, _ := cmd.Flags().GetString("transport"); transport == "http" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	RunE: runConfigure,
 }
 
 var (
-	dryRun bool
-	client string
+	dryRun  bool
+	explain bool
+	client  string
+	scope   string
 )
 
+// errConfigureAborted is returned when the user declines the explained change
+var errConfigureAborted = errors.New("aborted, nothing was written")
+
 func init() {
 	rootCmd.AddCommand(configureCmd)
 
 	configureCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print configuration without installing")
-	configureCmd.Flags().StringVar(&client, "client", "claude-desktop", "Target LLM client (claude-desktop, cursor)")
-	configureCmd.Flags().StringArray("headers", []string{}, "Headers to inject on requests in the form of key=value")
+	configureCmd.Flags().BoolVar(&explain, "explain", false, "Explain the change and ask for confirmation before installing")
+	configureCmd.PersistentFlags().StringVar(&client, "client", "claude-desktop", "Target LLM client (claude-desktop, claude-code, cursor, gemini, vscode, windsurf, zed), or generic to print the server entry")
+	configureCmd.PersistentFlags().StringVar(&scope, "scope", "user", "Where the server is installed: user, or project (workspace) for the current directory (vscode, claude-code)")
+	configureCmd.Flags().StringArray("headers", []string{}, "Headers to inject on requests in the form of key=value, passed to the server as KUMOCTL_HEADER_* variables")
+	configureCmd.Flags().String("format", "json", "Format of the server entry printed for --client generic: json, yaml or toml")
+	configureCmd.Flags().String("transport", "stdio", "How the client reaches the server: stdio, starting kumoctl, or http, connecting to --url")
+	configureCmd.Flags().String("url", "", "URL of the remote MCP server for --transport http")
 }
 
 func runConfigure(cmd *cobra.Command, args []string) error {
-	specSource := args[0]
-	serverName := args[1]
+	serverName := args[len(args)-1]
 
 	// Get headers flag
 	headers, err := cmd.Flags().GetStringArray("headers")
@@ -74,41 +165,221 @@ func runConfigure(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Check if source is a URL or file path
-	isURL := strings.HasPrefix(specSource, "http://") || strings.HasPrefix(specSource, "https://")
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+
+	transport, err := cmd.Flags().GetString("transport")
+	if err != nil {
+		return err
+	}
+
+	serverURL, err := cmd.Flags().GetString("url")
+	if err != nil {
+		return err
+	}
 
-	var specPath string
-	if isURL {
-		// For URLs, we'll pass the URL directly
-		specPath = specSource
+	// Unknown clients get the entry to paste into their config
+	generic := strings.ToLower(client) == "generic"
+	var target *mcpClient
+	if generic {
+		target = &mcpClient{URLKey: "url", RemoteType: "http"}
 	} else {
-		// Validate spec file exists
-		if _, err := os.Stat(specSource); os.IsNotExist(err) {
-			return fmt.Errorf("spec file does not exist: %s", specSource)
+		if cmd.Flags().Changed("format") {
+			return fmt.Errorf("--format is only supported by --client generic")
+		}
+		if target, err = clientFor(client, scope); err != nil {
+			return err
+		}
+	}
+
+	var server MCPServerConfig
+	switch transport {
+	case "stdio":
+		if cmd.Flags().Changed("url") {
+			return fmt.Errorf("--url is only supported by --transport http")
 		}
 
-		// Get absolute path to spec file
-		absSpecFile, err := filepath.Abs(specSource)
+		specSource := args[0]
+
+		// Check if source is a URL or file path
+		isURL := strings.HasPrefix(specSource, "http://") || strings.HasPrefix(specSource, "https://")
+
+		var specPath string
+		if isURL {
+			// For URLs, we'll pass the URL directly
+			specPath = specSource
+		} else {
+			// Validate spec file exists
+			if _, err := os.Stat(specSource); os.IsNotExist(err) {
+				return fmt.Errorf("spec file does not exist: %s", specSource)
+			}
+
+			// Get absolute path to spec file
+			absSpecFile, err := filepath.Abs(specSource)
+			if err != nil {
+				return fmt.Errorf("failed to get absolute path for spec file: %w", err)
+			}
+			specPath = absSpecFile
+		}
+
+		// Get kumoctl executable path
+		executable, err := getKumoctlPath()
 		if err != nil {
-			return fmt.Errorf("failed to get absolute path for spec file: %w", err)
+			return fmt.Errorf("failed to locate kumoctl executable: %w", err)
+		}
+
+		if server, err = serverConfig(target, executable, specPath, headers); err != nil {
+			return err
+		}
+	case "http":
+		if server, err = remoteServerConfig(target, serverURL, headers); err != nil {
+			return err
 		}
-		specPath = absSpecFile
+	default:
+		return fmt.Errorf("unsupported transport %s, expected stdio or http", transport)
 	}
 
-	// Get kumoctl executable path
-	executable, err := getKumoctlPath()
+	if generic {
+		return writeServerConfig(os.Stdout, format, serverName, server)
+	}
+
+	err = configureMCPClient(target, server, serverName, headers)
+	if errors.Is(err, errConfigureAborted) {
+		fmt.Println("Aborted, nothing was written")
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to locate kumoctl executable: %w", err)
+		return err
 	}
 
-	// Generate configuration based on client
-	switch strings.ToLower(client) {
+	fmt.Printf("Successfully configured MCP server '%s' for %s\n", serverName, target.Name)
+	fmt.Println(target.Note)
+	return nil
+}
+
+// clientFor returns where the named client reads its servers from, in the
+// given scope
+func clientFor(name, scope string) (*mcpClient, error) {
+	name = strings.ToLower(name)
+
+	// Projects are called workspaces by VS Code
+	project := false
+	switch scope {
+	case "user":
+	case "project", "workspace":
+		if name != "vscode" && name != "claude-code" {
+			return nil, fmt.Errorf("--scope %s is only supported by vscode and claude-code", scope)
+		}
+		project = true
+	default:
+		return nil, fmt.Errorf("unsupported scope %s, expected user, project or workspace", scope)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
 	case "claude-desktop":
-		return configureClaudeDesktop(executable, specPath, serverName, headers)
+		return &mcpClient{
+			Name:       "Claude Desktop",
+			ConfigFile: filepath.Join(getClaudeDesktopConfigDir(), "claude_desktop_config.json"),
+			ServersKey: "mcpServers",
+			Note:       "Please restart Claude Desktop for changes to take effect.",
+		}, nil
 	case "cursor":
-		return configureCursor(executable, specPath, serverName, headers)
+		// Cursor uses a similar configuration format to Claude Desktop
+		// but in a different location
+		return &mcpClient{
+			Name:       "Cursor",
+			ConfigFile: filepath.Join(getCursorConfigDir(), "mcp_config.json"),
+			ServersKey: "mcpServers",
+			URLKey:     "url",
+			Note:       "Note: Cursor MCP integration is experimental. Please refer to Cursor documentation for the latest setup instructions.",
+		}, nil
+	case "windsurf":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		return &mcpClient{
+			Name:       "Windsurf",
+			ConfigFile: filepath.Join(home, ".codeium", "windsurf", "mcp_config.json"),
+			ServersKey: "mcpServers",
+			URLKey:     "serverUrl",
+			Note:       "Press the refresh button of Windsurf's MCP servers panel to load the server.",
+		}, nil
+	case "gemini":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		// Its other settings, comments included, are kept as they are
+		return &mcpClient{
+			Name:       "Gemini CLI",
+			ConfigFile: filepath.Join(home, ".gemini", "settings.json"),
+			ServersKey: "mcpServers",
+			URLKey:     "httpUrl",
+			JSONC:      true,
+			Note:       "Restart Gemini CLI and run /mcp to check the server.",
+		}, nil
+	case "zed":
+		return &mcpClient{
+			Name:         "Zed",
+			ConfigFile:   filepath.Join(getZedConfigDir(), "settings.json"),
+			ServersKey:   "context_servers",
+			ServerSource: "custom",
+			URLKey:       "url",
+			JSONC:        true,
+			Note:         "Zed starts the server once the settings are saved, check it in the Agent Panel settings.",
+		}, nil
+	case "claude-code":
+		if project {
+			return &mcpClient{
+				Name:       "Claude Code",
+				ConfigFile: filepath.Join(wd, ".mcp.json"),
+				ServersKey: "mcpServers",
+				ServerType: "stdio",
+				URLKey:     "url",
+				RemoteType: "http",
+				Note:       "Claude Code asks to approve the servers of the project's .mcp.json when it starts, run /mcp to check the server.",
+			}, nil
+		}
+
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		// The file also holds the state of Claude Code, which is kept as it is
+		return &mcpClient{
+			Name:       "Claude Code",
+			ConfigFile: filepath.Join(home, ".claude.json"),
+			ServersKey: "mcpServers",
+			ServerType: "stdio",
+			URLKey:     "url",
+			RemoteType: "http",
+			JSONC:      true,
+			Note:       "Restart Claude Code and run /mcp to check the server.",
+		}, nil
+	case "vscode":
+		configFile := filepath.Join(getVSCodeConfigDir(), "mcp.json")
+		if project {
+			configFile = filepath.Join(wd, ".vscode", "mcp.json")
+		}
+		return &mcpClient{
+			Name:       "VS Code",
+			ConfigFile: configFile,
+			ServersKey: "servers",
+			ServerType: "stdio",
+			URLKey:     "url",
+			RemoteType: "http",
+			Note:       "Start the server from the MCP: List Servers command of VS Code.",
+		}, nil
 	default:
-		return fmt.Errorf("unsupported client: %s", client)
+		return nil, fmt.Errorf("unsupported client: %s", name)
 	}
 }
 
@@ -132,36 +403,6 @@ func getKumoctlPath() (string, error) {
 	return executable, nil
 }
 
-func configureClaudeDesktop(executable, specFile, serverName string, headers []string) error {
-	configDir := getClaudeDesktopConfigDir()
-	configFile := filepath.Join(configDir, "claude_desktop_config.json")
-
-	if err := configureMCPClient(configDir, configFile, executable, specFile, serverName, headers); err != nil {
-		return err
-	}
-
-	fmt.Printf("Successfully configured MCP server '%s' for Claude Desktop\n", serverName)
-	fmt.Printf("Please restart Claude Desktop for changes to take effect.\n")
-
-	return nil
-}
-
-func configureCursor(executable, specFile, serverName string, headers []string) error {
-	// Cursor uses a similar configuration format to Claude Desktop
-	// but in a different location
-	configDir := getCursorConfigDir()
-	configFile := filepath.Join(configDir, "mcp_config.json")
-
-	if err := configureMCPClient(configDir, configFile, executable, specFile, serverName, headers); err != nil {
-		return nil
-	}
-
-	fmt.Printf("Successfully configured MCP server '%s' for Cursor\n", serverName)
-	fmt.Printf("Note: Cursor MCP integration is experimental. Please refer to Cursor documentation for the latest setup instructions.\n")
-
-	return nil
-}
-
 func getClaudeDesktopConfigDir() string {
 	switch runtime.GOOS {
 	case "darwin": // macOS
@@ -198,70 +439,318 @@ func getCursorConfigDir() string {
 	}
 }
 
-func getMCPClientConfig(configFile string, executable string, specFile string, serverName string, headers []string) (*MCPClientConfig, error) {
-	// Read existing configuration
-	var config MCPClientConfig
-	if data, err := os.ReadFile(configFile); err == nil {
-		if err := json.Unmarshal(data, &config); err != nil {
-			return nil, fmt.Errorf("failed to parse existing config: %w", err)
+// getZedConfigDir returns the directory of Zed's settings
+func getZedConfigDir() string {
+	if runtime.GOOS == "windows" {
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			home, _ := os.UserHomeDir()
+			appData = filepath.Join(home, "AppData", "Roaming")
 		}
+		return filepath.Join(appData, "Zed")
 	}
 
-	// Initialize mcpServers if it doesn't exist
-	if config.MCPServers == nil {
-		config.MCPServers = make(map[string]MCPServerConfig)
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "zed")
+}
+
+// getVSCodeConfigDir returns the directory of VS Code's user settings
+func getVSCodeConfigDir() string {
+	switch runtime.GOOS {
+	case "darwin": // macOS
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, "Library", "Application Support", "Code", "User")
+	case "windows":
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			home, _ := os.UserHomeDir()
+			appData = filepath.Join(home, "AppData", "Roaming")
+		}
+		return filepath.Join(appData, "Code", "User")
+	default: // Linux and others
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, ".config", "Code", "User")
 	}
+}
 
-	// Create the server configuration
-	args := []string{"serve", specFile}
+// readClientConfig reads the config file of a client and the servers it
+// configures. Every other key and server is kept as is. A missing file is an
+// empty config.
+func readClientConfig(target *mcpClient) (config, servers map[string]json.RawMessage, err error) {
+	config = make(map[string]json.RawMessage)
+	servers = make(map[string]json.RawMessage)
 
-	// Add headers if provided
-	for _, header := range headers {
-		args = append(args, "--headers", header)
+	data, err := os.ReadFile(target.ConfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, servers, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read existing config: %w", err)
+	}
+	if target.JSONC {
+		data = standardizeJSONC(data)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return config, servers, nil
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse existing config: %w", err)
+	}
+	if config == nil {
+		config = make(map[string]json.RawMessage)
 	}
 
-	serverConfig := MCPServerConfig{
+	if raw, ok := config[target.ServersKey]; ok {
+		if err := json.Unmarshal(raw, &servers); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s of existing config: %w", target.ServersKey, err)
+		}
+		if servers == nil {
+			servers = make(map[string]json.RawMessage)
+		}
+	}
+	return config, servers, nil
+}
+
+// serverConfig creates the entry serving the spec, passing the headers
+// through its environment
+func serverConfig(target *mcpClient, executable, specFile string, headers []string) (MCPServerConfig, error) {
+	server := MCPServerConfig{
+		Type:    target.ServerType,
+		Source:  target.ServerSource,
 		Command: executable,
-		Args:    args,
+		Args:    []string{"serve", specFile},
 	}
 
-	// Add or update the server
-	config.MCPServers[serverName] = serverConfig
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return MCPServerConfig{}, fmt.Errorf("invalid header format: %s (expected 'key=value')", header)
+		}
+		if server.Env == nil {
+			server.Env = make(map[string]string)
+		}
+		server.Env[headerEnvName(key)] = strings.TrimSpace(value)
+	}
+	return server, nil
+}
 
-	return &config, nil
+// hasLiteralHeaderValues reports whether a server entry holds header values
+// written out rather than referenced as env:NAME or ${NAME}
+func hasLiteralHeaderValues(server MCPServerConfig) bool {
+	for _, values := range []map[string]string{server.Env, server.Headers} {
+		for _, value := range values {
+			if !strings.HasPrefix(value, "env:") && !headerEnvRegex.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-func configureMCPClient(configDir string, configFile string, executable string, specFile string, serverName string, headers []string) error {
-	// Create config directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// remoteServerConfig creates the entry connecting to the MCP server at the
+// URL, sending it the headers
+func remoteServerConfig(target *mcpClient, serverURL string, headers []string) (MCPServerConfig, error) {
+	if target.URLKey == "" {
+		return MCPServerConfig{}, fmt.Errorf("%s can't connect to remote servers from its config, use --transport stdio", target.Name)
+	}
+
+	u, err := url.Parse(serverURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return MCPServerConfig{}, fmt.Errorf("--transport http requires an http or https --url, got %q", serverURL)
 	}
 
-	config, err := getMCPClientConfig(configFile, executable, specFile, serverName, headers)
+	server := MCPServerConfig{Type: target.RemoteType}
+	switch target.URLKey {
+	case "serverUrl":
+		server.ServerURL = serverURL
+	case "httpUrl":
+		server.HTTPURL = serverURL
+	default:
+		server.URL = serverURL
+	}
+
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return MCPServerConfig{}, fmt.Errorf("invalid header format: %s (expected 'key=value')", header)
+		}
+		if server.Headers == nil {
+			server.Headers = make(map[string]string)
+		}
+		server.Headers[key] = strings.TrimSpace(value)
+	}
+	return server, nil
+}
+
+// mergeServerConfig returns the client's config with the server added or
+// replaced. JSONC configs are edited in place, keeping their comments.
+func mergeServerConfig(target *mcpClient, serverName string, server MCPServerConfig) ([]byte, error) {
+	if target.JSONC {
+		data, err := os.ReadFile(target.ConfigFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read existing config: %w", err)
+		}
+		return setJSONCValue(data, []string{target.ServersKey, serverName}, server)
+	}
+
+	config, servers, err := readClientConfig(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if servers[serverName], err = json.Marshal(server); err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	if config[target.ServersKey], err = json.Marshal(servers); err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+
+	configJSON, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return configJSON, nil
+}
+
+func configureMCPClient(target *mcpClient, server MCPServerConfig, serverName string, headers []string) error {
+	configJSON, err := mergeServerConfig(target, serverName, server)
 	if err != nil {
 		return err
 	}
 
+	if explain {
+		if err := explainConfigure(os.Stdout, target, server.Command, serverName, server, headers); err != nil {
+			return err
+		}
+
+		if !dryRun {
+			ok, err := confirm(os.Stdin, os.Stdout, "Write this configuration?")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errConfigureAborted
+			}
+		}
+	}
+
 	if dryRun {
 		// Print the configuration
-		configJSON, err := json.MarshalIndent(config, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal configuration: %w", err)
+		fmt.Printf("%s\n", bytes.TrimRight(configJSON, "\n"))
+		return nil
+	}
+
+	// Create config directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(target.ConfigFile), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// Header values written out may be secrets, only the owner may read them
+	perm := os.FileMode(0644)
+	if hasLiteralHeaderValues(server) {
+		perm = 0600
+	}
+
+	if err := os.WriteFile(target.ConfigFile, configJSON, perm); err != nil {
+		return fmt.Errorf("failed to write configuration file: %w", err)
+	}
+
+	// The mode of an existing file is kept by WriteFile
+	if perm == 0600 {
+		if err := os.Chmod(target.ConfigFile, perm); err != nil {
+			return fmt.Errorf("failed to restrict configuration file: %w", err)
 		}
+	}
 
-		fmt.Printf("%s\n", configJSON)
-		return nil
+	return nil
+}
+
+// explainConfigure describes the change configure is about to make: the config
+// file, the server entry merged into it, the executable and the environment
+// variables the server reads
+func explainConfigure(w io.Writer, target *mcpClient, executable, serverName string, server MCPServerConfig, headers []string) error {
+	status := "will be created"
+	if _, err := os.Stat(target.ConfigFile); err == nil {
+		status = "exists"
+		if _, servers, err := readClientConfig(target); err == nil {
+			if _, ok := servers[serverName]; ok {
+				status = fmt.Sprintf("exists, its server %s is replaced", serverName)
+			}
+		}
 	}
 
-	// Write the configuration
-	configJSON, err := json.MarshalIndent(config, "", "  ")
+	fragment, err := json.MarshalIndent(map[string]map[string]MCPServerConfig{target.ServersKey: {serverName: server}}, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal configuration: %w", err)
 	}
 
-	if err := os.WriteFile(configFile, configJSON, 0644); err != nil {
-		return fmt.Errorf("failed to write configuration file: %w", err)
+	fmt.Fprintf(w, "Config file: %s (%s)\n", target.ConfigFile, status)
+	if executable != "" {
+		fmt.Fprintf(w, "Executable:  %s\n", executable)
+	} else {
+		fmt.Fprintf(w, "Remote URL:  %s\n", server.remoteURL())
+	}
+	fmt.Fprintf(w, "Merged into the config file:\n%s\n", fragment)
+
+	envVars := headerEnvVars(headers)
+	if len(envVars) == 0 {
+		fmt.Fprintln(w, "Environment variables: none required")
+		return nil
 	}
 
+	fmt.Fprintln(w, "Environment variables the client must provide:")
+	for _, name := range envVars {
+		state := "set in this shell"
+		if _, ok := os.LookupEnv(name); !ok {
+			state = "not set in this shell"
+		}
+		fmt.Fprintf(w, "  %s (%s)\n", name, state)
+	}
 	return nil
 }
+
+// headerEnvVars returns the environment variables referenced by header values
+// as env:NAME or ${NAME}, in order of appearance
+func headerEnvVars(headers []string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, header := range headers {
+		_, value, _ := strings.Cut(header, "=")
+		value = strings.TrimSpace(value)
+		if name, ok := strings.CutPrefix(value, "env:"); ok {
+			add(name)
+			continue
+		}
+		for _, match := range headerEnvRegex.FindAllStringSubmatch(value, -1) {
+			add(match[1])
+		}
+	}
+	return names
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(r io.Reader, w io.Writer, prompt string) (bool, error) {
+	fmt.Fprintf(w, "%s [y/N] ", prompt)
+
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
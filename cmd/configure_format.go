@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tomlBareKeyRegex matches the keys TOML accepts without quotes
+var tomlBareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// writeServerConfig prints the server entry keyed by its name as JSON, YAML
+// or TOML, for clients configure doesn't know
+func writeServerConfig(w io.Writer, format, serverName string, server MCPServerConfig) error {
+	keyed := map[string]MCPServerConfig{serverName: server}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(keyed)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(keyed); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "toml":
+		// The JSON encoding gives the field names and order
+		data, err := json.Marshal(server)
+		if err != nil {
+			return fmt.Errorf("failed to marshal configuration: %w", err)
+		}
+		return writeTOMLTable(w, []string{serverName}, data)
+	default:
+		return fmt.Errorf("unsupported format %s, expected json, yaml or toml", format)
+	}
+}
+
+// writeTOMLTable prints a JSON object of strings, lists and nested objects as
+// a TOML table under the header of its path, keeping the order of its keys
+func writeTOMLTable(w io.Writer, path []string, data json.RawMessage) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	header := make([]string, len(path))
+	for i, key := range path {
+		header[i] = tomlKey(key)
+	}
+	fmt.Fprintf(w, "[%s]\n", strings.Join(header, "."))
+
+	// Keys of the table come before its nested tables
+	var nestedKeys []string
+	nested := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+		if bytes.HasPrefix(raw, []byte("{")) {
+			nestedKeys = append(nestedKeys, key)
+			nested[key] = raw
+			continue
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		encoded, err := tomlValue(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		fmt.Fprintf(w, "%s = %s\n", tomlKey(key), encoded)
+	}
+
+	for _, key := range nestedKeys {
+		fmt.Fprintln(w)
+		if err := writeTOMLTable(w, append(path, key), nested[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tomlValue encodes a string, number, boolean or list of them
+func tomlValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return tomlString(v), nil
+	case bool, float64:
+		return fmt.Sprint(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			encoded, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = encoded
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	default:
+		return "", fmt.Errorf("unsupported TOML value %v", value)
+	}
+}
+
+// tomlKey quotes keys TOML doesn't accept bare
+func tomlKey(key string) string {
+	if tomlBareKeyRegex.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlString encodes a basic string, escaping quotes, backslashes and control
+// characters
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
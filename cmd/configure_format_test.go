@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteServerConfig(t *testing.T) {
+	server := MCPServerConfig{
+		Command: "/usr/local/bin/kumoctl",
+		Args:    []string{"serve", "/specs/api.yaml", "--headers", `X-Note=say "hi"\now`},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "json",
+			expected: `{
+  "my-api": {
+    "command": "/usr/local/bin/kumoctl",
+    "args": [
+      "serve",
+      "/specs/api.yaml",
+      "--headers",
+      "X-Note=say \"hi\"\\now"
+    ]
+  }
+}
+`,
+		},
+		{
+			format: "yaml",
+			expected: `my-api:
+  command: /usr/local/bin/kumoctl
+  args:
+    - serve
+    - /specs/api.yaml
+    - --headers
+    - X-Note=say "hi"\now
+`,
+		},
+		{
+			format: "toml",
+			expected: `[my-api]
+command = "/usr/local/bin/kumoctl"
+args = ["serve", "/specs/api.yaml", "--headers", "X-Note=say \"hi\"\\now"]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeServerConfig(&out, tt.format, "my-api", server); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestWriteServerConfigInvalidFormat(t *testing.T) {
+	err := writeServerConfig(&bytes.Buffer{}, "ini", "my-api", MCPServerConfig{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported format ini") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
+
+func TestWriteTOMLTable(t *testing.T) {
+	data := []byte(`{"command":"kumoctl","env":{"API_TOKEN":"x\ty","b.c":"d"},"disabled":false}`)
+
+	var out bytes.Buffer
+	if err := writeTOMLTable(&out, []string{"mcp_servers", "my api"}, data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `[mcp_servers."my api"]
+command = "kumoctl"
+disabled = false
+
+[mcp_servers."my api".env]
+API_TOKEN = "x\ty"
+"b.c" = "d"
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
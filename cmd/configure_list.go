@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// maskedHeaderValue replaces the values of headers listed by configure list
+const maskedHeaderValue = "[REDACTED]"
+
+// installedServer is a kumoctl server found in the config of a client
+type installedServer struct {
+	Name string
+	// Spec is the spec source served, empty when it comes from kumoctl.yaml
+	Spec string
+	// Headers are the headers sent, with their values masked
+	Headers []string
+}
+
+var configureListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the kumoctl servers configured in an LLM client",
+	Long: `List the MCP servers of the client (--client, --scope) that kumoctl serves,
+with their spec source and the headers they send. Header values are masked,
+so the output can be shared when auditing a setup.`,
+	Example: `  # Servers of Claude Desktop
+  kumoctl configure list
+
+  # Servers of the VS Code workspace in the current directory
+  kumoctl configure list --client=vscode --scope=workspace`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, err := clientFor(client, scope)
+		if err != nil {
+			return err
+		}
+
+		_, servers, err := readClientConfig(target)
+		if err != nil {
+			return err
+		}
+
+		executable, err := getKumoctlPath()
+		if err != nil {
+			return fmt.Errorf("failed to locate kumoctl executable: %w", err)
+		}
+
+		return writeInstalledServers(cmd.OutOrStdout(), target, installedServers(servers, executable))
+	},
+}
+
+func init() {
+	configureCmd.AddCommand(configureListCmd)
+}
+
+// installedServers returns the servers started with kumoctl serve, by the
+// executable or a command named kumoctl, sorted by name
+func installedServers(servers map[string]json.RawMessage, executable string) []installedServer {
+	var installed []installedServer
+	for name, raw := range servers {
+		var server MCPServerConfig
+		if err := json.Unmarshal(raw, &server); err != nil || len(server.Args) == 0 || server.Args[0] != "serve" {
+			continue
+		}
+		command := strings.TrimSuffix(filepath.Base(server.Command), ".exe")
+		if server.Command != executable && command != "kumoctl" {
+			continue
+		}
+
+		installed = append(installed, installedServerOf(name, server))
+	}
+
+	sort.Slice(installed, func(i, j int) bool { return installed[i].Name < installed[j].Name })
+	return installed
+}
+
+// installedServerOf reads the spec source and the headers from the arguments
+// of kumoctl serve and its KUMOCTL_HEADER_* variables
+func installedServerOf(name string, config MCPServerConfig) installedServer {
+	server := installedServer{Name: name}
+	args := config.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		server.Spec = args[0]
+		args = args[1:]
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if header, ok := strings.CutPrefix(arg, "--headers="); ok {
+			server.Headers = append(server.Headers, maskHeader(header))
+			continue
+		}
+		if arg == "--headers" && i+1 < len(args) {
+			i++
+			server.Headers = append(server.Headers, maskHeader(args[i]))
+		}
+	}
+
+	env := make([]string, 0, len(config.Env))
+	for variable, value := range config.Env {
+		env = append(env, variable+"="+value)
+	}
+	for _, header := range envHeaders(env, nil) {
+		server.Headers = append(server.Headers, maskHeader(header))
+	}
+	return server
+}
+
+// maskHeader keeps the name of a key=value header
+func maskHeader(header string) string {
+	key, _, _ := strings.Cut(header, "=")
+	return strings.TrimSpace(key) + "=" + maskedHeaderValue
+}
+
+// writeInstalledServers prints the kumoctl servers of a client's config file
+func writeInstalledServers(w io.Writer, target *mcpClient, servers []installedServer) error {
+	if len(servers) == 0 {
+		fmt.Fprintf(w, "No kumoctl servers configured for %s in %s\n", target.Name, target.ConfigFile)
+		return nil
+	}
+
+	fmt.Fprintf(w, "kumoctl servers configured for %s in %s\n", target.Name, target.ConfigFile)
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"Name", "Spec", "Headers"})
+	for _, server := range servers {
+		spec := server.Spec
+		if spec == "" {
+			spec = "(kumoctl.yaml)"
+		}
+		t.AppendRow(table.Row{server.Name, spec, strings.Join(server.Headers, "\n")})
+	}
+	t.Render()
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstalledServers(t *testing.T) {
+	servers := map[string]json.RawMessage{
+		"weather": json.RawMessage(`{"command":"/usr/local/bin/kumoctl","args":["serve","/specs/weather.yaml","--headers","Authorization=Bearer ${TOKEN}","--headers=X-Team=ops"],"env":{"KUMOCTL_HEADER_X_API_KEY":"abc","PATH":"/usr/bin"}}`),
+		"billing": json.RawMessage(`{"type":"stdio","command":"/opt/kumoctl/build","args":["serve","https://example.com/billing.json"]}`),
+		"profile": json.RawMessage(`{"command":"kumoctl.exe","args":["serve","--profile","prod"]}`),
+		"files":   json.RawMessage(`{"command":"npx","args":["-y","@modelcontextprotocol/server-filesystem"]}`),
+		"version": json.RawMessage(`{"command":"kumoctl","args":["version"]}`),
+		"broken":  json.RawMessage(`"not a server"`),
+	}
+
+	expected := []installedServer{
+		{Name: "billing", Spec: "https://example.com/billing.json"},
+		{Name: "profile"},
+		{Name: "weather", Spec: "/specs/weather.yaml", Headers: []string{"Authorization=[REDACTED]", "X-Team=[REDACTED]", "X-Api-Key=[REDACTED]"}},
+	}
+
+	installed := installedServers(servers, "/opt/kumoctl/build")
+	if !reflect.DeepEqual(installed, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, installed)
+	}
+}
+
+func TestWriteInstalledServers(t *testing.T) {
+	target := &mcpClient{Name: "Claude Desktop", ConfigFile: "/home/me/claude_desktop_config.json"}
+
+	var out bytes.Buffer
+	if err := writeInstalledServers(&out, target, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "No kumoctl servers configured for Claude Desktop in /home/me/claude_desktop_config.json\n"; out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+
+	out.Reset()
+	servers := []installedServer{{Name: "weather", Headers: []string{"Authorization=[REDACTED]"}}}
+	if err := writeInstalledServers(&out, target, servers); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, s := range []string{"weather", "(kumoctl.yaml)", "Authorization=[REDACTED]"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("Expected output to contain %q, got:\n%s", s, out.String())
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var configureRemoveCmd = &cobra.Command{
+	Use:   "remove [server-name]",
+	Short: "Remove an MCP server from the configuration of an LLM client",
+	Long: `Remove an MCP server from the configuration of the client (--client, --scope),
+leaving its other servers and settings as they are. Config files with comments
+are edited in place.`,
+	Example: `  # Uninstall a server from Claude Desktop
+  kumoctl configure remove my-api
+
+  # Preview the config file without the server
+  kumoctl configure remove --client=zed --dry-run my-api`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverName := args[0]
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		target, err := clientFor(client, scope)
+		if err != nil {
+			return err
+		}
+
+		configJSON, err := removeServerConfig(target, serverName)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\n", bytes.TrimRight(configJSON, "\n"))
+			return nil
+		}
+
+		info, err := os.Stat(target.ConfigFile)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(target.ConfigFile, configJSON, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write configuration file: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed MCP server '%s' from %s\n", serverName, target.Name)
+		return nil
+	},
+}
+
+func init() {
+	configureRemoveCmd.Flags().Bool("dry-run", false, "Print the configuration without the server instead of writing it")
+	configureCmd.AddCommand(configureRemoveCmd)
+}
+
+// removeServerConfig returns the client's config without the server. JSONC
+// configs are edited in place, keeping their comments. Removing a server the
+// config doesn't have is an error, so nothing is written.
+func removeServerConfig(target *mcpClient, serverName string) ([]byte, error) {
+	notFound := fmt.Errorf("server %s is not configured in %s", serverName, target.ConfigFile)
+
+	if target.JSONC {
+		data, err := os.ReadFile(target.ConfigFile)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, notFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read existing config: %w", err)
+		}
+
+		configJSON, found, err := deleteJSONCValue(data, []string{target.ServersKey, serverName})
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, notFound
+		}
+		return configJSON, nil
+	}
+
+	config, servers, err := readClientConfig(target)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := servers[serverName]; !ok {
+		return nil, notFound
+	}
+
+	delete(servers, serverName)
+	if config[target.ServersKey], err = json.Marshal(servers); err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+
+	configJSON, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return configJSON, nil
+}
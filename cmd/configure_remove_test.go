@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemoveServerConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	existing := `{
+  "globalShortcut": "Ctrl+Space",
+  "mcpServers": {
+    "files": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem"]},
+    "my-api": {"command": "/usr/local/bin/kumoctl", "args": ["serve", "/specs/api.json"]}
+  }
+}`
+	if err := os.WriteFile(configFile, []byte(existing), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	target := &mcpClient{Name: "Claude Desktop", ConfigFile: configFile, ServersKey: "mcpServers"}
+
+	data, err := removeServerConfig(target, "my-api")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var config struct {
+		GlobalShortcut string                     `json:"globalShortcut"`
+		MCPServers     map[string]json.RawMessage `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	if config.GlobalShortcut != "Ctrl+Space" {
+		t.Errorf("Expected the other settings to be kept, got %q", config.GlobalShortcut)
+	}
+	if _, ok := config.MCPServers["my-api"]; ok {
+		t.Error("Expected my-api to be removed")
+	}
+	if _, ok := config.MCPServers["files"]; !ok {
+		t.Error("Expected the other servers to be kept")
+	}
+
+	if _, err := removeServerConfig(target, "unknown"); err == nil || !strings.Contains(err.Error(), "server unknown is not configured") {
+		t.Errorf("Expected an error for an unknown server, got %v", err)
+	}
+
+	target.ConfigFile = filepath.Join(t.TempDir(), "missing.json")
+	target.JSONC = true
+	if _, err := removeServerConfig(target, "my-api"); err == nil || !strings.Contains(err.Error(), "is not configured") {
+		t.Errorf("Expected an error for a missing config file, got %v", err)
+	}
+}
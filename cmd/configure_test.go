@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeaderEnvVars(t *testing.T) {
+	headers := []string{
+		"Authorization=Bearer ${API_TOKEN}",
+		"X-Api-Key=env:API_KEY",
+		"X-Team=ops",
+		"X-Token=${API_TOKEN}",
+	}
+
+	expected := []string{"API_TOKEN", "API_KEY"}
+	if got := headerEnvVars(headers); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestExplainConfigure(t *testing.T) {
+	t.Setenv("KUMOCTL_TEST_TOKEN", "secret")
+
+	configFile := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	if err := os.WriteFile(configFile, []byte(`{"mcpServers": {"my-api": {"command": "old"}}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	server := MCPServerConfig{Command: "/usr/local/bin/kumoctl", Args: []string{"serve", "/specs/api.json"}}
+	headers := []string{"Authorization=Bearer ${KUMOCTL_TEST_TOKEN}", "X-Api-Key=env:KUMOCTL_TEST_UNSET"}
+
+	var out bytes.Buffer
+	target := &mcpClient{ConfigFile: configFile, ServersKey: "mcpServers"}
+	if err := explainConfigure(&out, target, server.Command, "my-api", server, headers); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		configFile + " (exists, its server my-api is replaced)",
+		"Executable:  /usr/local/bin/kumoctl",
+		`"/specs/api.json"`,
+		"KUMOCTL_TEST_TOKEN (set in this shell)",
+		"KUMOCTL_TEST_UNSET (not set in this shell)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected explanation to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Error("Expected the explanation not to resolve secrets")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "y\n", expected: true},
+		{input: "Yes\n", expected: true},
+		{input: "n\n", expected: false},
+		{input: "\n", expected: false},
+		{input: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		got, err := confirm(strings.NewReader(tt.input), &out, "Write?")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != tt.expected {
+			t.Errorf("Expected %v for %q, got %v", tt.expected, tt.input, got)
+		}
+	}
+}
+
+func TestConfigureMCPClientKeepsConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".vscode", "mcp.json")
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	existing := `{
+		"inputs": [{"type": "promptString", "id": "token"}],
+		"servers": {"github": {"type": "http", "url": "https://api.githubcopilot.com/mcp/"}, "my-api": {"command": "old"}}
+	}`
+	if err := os.WriteFile(configFile, []byte(existing), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	target, err := clientFor("vscode", "user")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	target.ConfigFile = configFile
+
+	entry, err := serverConfig(target, "/usr/local/bin/kumoctl", "/specs/api.json", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := configureMCPClient(target, entry, "my-api", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	var config struct {
+		Inputs  []map[string]string        `json:"inputs"`
+		Servers map[string]json.RawMessage `json:"servers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	if len(config.Inputs) != 1 || config.Inputs[0]["id"] != "token" {
+		t.Errorf("Expected the inputs to be kept, got %v", config.Inputs)
+	}
+	if !strings.Contains(string(config.Servers["github"]), "https://api.githubcopilot.com/mcp/") {
+		t.Errorf("Expected the other servers to be kept, got %s", config.Servers["github"])
+	}
+
+	var server MCPServerConfig
+	if err := json.Unmarshal(config.Servers["my-api"], &server); err != nil {
+		t.Fatalf("Failed to parse server: %v", err)
+	}
+	expected := MCPServerConfig{Type: "stdio", Command: "/usr/local/bin/kumoctl", Args: []string{"serve", "/specs/api.json"}}
+	if !reflect.DeepEqual(server, expected) {
+		t.Errorf("Expected server %+v, got %+v", expected, server)
+	}
+}
+
+func TestClientForScope(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	target, err := clientFor("vscode", "workspace")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := filepath.Join(wd, ".vscode", "mcp.json"); target.ConfigFile != expected {
+		t.Errorf("Expected config file %s, got %s", expected, target.ConfigFile)
+	}
+
+	if _, err := clientFor("claude-desktop", "workspace"); err == nil {
+		t.Error("Expected an error for a scope the client doesn't support")
+	}
+}
+
+func TestClientForConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		client     string
+		scope      string
+		configFile string
+		serversKey string
+	}{
+		{client: "windsurf", scope: "user", configFile: filepath.Join(home, ".codeium", "windsurf", "mcp_config.json"), serversKey: "mcpServers"},
+		{client: "gemini", scope: "user", configFile: filepath.Join(home, ".gemini", "settings.json"), serversKey: "mcpServers"},
+		{client: "zed", scope: "user", configFile: filepath.Join(home, ".config", "zed", "settings.json"), serversKey: "context_servers"},
+		{client: "claude-code", scope: "user", configFile: filepath.Join(home, ".claude.json"), serversKey: "mcpServers"},
+		{client: "claude-code", scope: "project", configFile: filepath.Join(wd, ".mcp.json"), serversKey: "mcpServers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.client+" "+tt.scope, func(t *testing.T) {
+			target, err := clientFor(tt.client, tt.scope)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if target.ConfigFile != tt.configFile || target.ServersKey != tt.serversKey {
+				t.Errorf("Expected %s under %s, got %s under %s", tt.configFile, tt.serversKey, target.ConfigFile, target.ServersKey)
+			}
+		})
+	}
+}
+
+func TestServerConfigHeadersInEnv(t *testing.T) {
+	headers := []string{"Authorization=Bearer s3cr3t", "X-Api-Key = env:API_KEY"}
+	server, err := serverConfig(&mcpClient{ServerType: "stdio"}, "/usr/local/bin/kumoctl", "/specs/api.json", headers)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := MCPServerConfig{
+		Type:    "stdio",
+		Command: "/usr/local/bin/kumoctl",
+		Args:    []string{"serve", "/specs/api.json"},
+		Env: map[string]string{
+			"KUMOCTL_HEADER_AUTHORIZATION": "Bearer s3cr3t",
+			"KUMOCTL_HEADER_X_API_KEY":     "env:API_KEY",
+		},
+	}
+	if !reflect.DeepEqual(server, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, server)
+	}
+}
+
+func TestServerConfigInvalidHeader(t *testing.T) {
+	if _, err := serverConfig(&mcpClient{ServerType: "stdio"}, "/usr/local/bin/kumoctl", "/specs/api.json", []string{"Authorization"}); err == nil {
+		t.Error("Expected a header without = to be rejected")
+	}
+}
+
+func TestConfigureMCPClientFileMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  []string
+		expected os.FileMode
+	}{
+		{name: "referenced secrets", headers: []string{"Authorization=Bearer ${API_TOKEN}", "X-Api-Key=env:API_KEY"}, expected: 0644},
+		{name: "literal secret", headers: []string{"Authorization=Bearer s3cr3t"}, expected: 0600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "mcp.json")
+			if err := os.WriteFile(configFile, []byte(`{}`), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			target := &mcpClient{Name: "test", ConfigFile: configFile, ServersKey: "mcpServers"}
+
+			server, err := serverConfig(target, "/usr/local/bin/kumoctl", "/specs/api.json", tt.headers)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := configureMCPClient(target, server, "my-api", tt.headers); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			info, err := os.Stat(configFile)
+			if err != nil {
+				t.Fatalf("Failed to stat config: %v", err)
+			}
+			if mode := info.Mode().Perm(); mode != tt.expected {
+				t.Errorf("Expected mode %o, got %o", tt.expected, mode)
+			}
+		})
+	}
+}
+
+func TestRemoteServerConfig(t *testing.T) {
+	headers := []string{"Authorization=Bearer ${API_TOKEN}"}
+
+	tests := []struct {
+		client   string
+		expected MCPServerConfig
+	}{
+		{client: "cursor", expected: MCPServerConfig{URL: "https://mcp.example.com/mcp"}},
+		{client: "vscode", expected: MCPServerConfig{Type: "http", URL: "https://mcp.example.com/mcp"}},
+		{client: "claude-code", expected: MCPServerConfig{Type: "http", URL: "https://mcp.example.com/mcp"}},
+		{client: "windsurf", expected: MCPServerConfig{ServerURL: "https://mcp.example.com/mcp"}},
+		{client: "gemini", expected: MCPServerConfig{HTTPURL: "https://mcp.example.com/mcp"}},
+		{client: "zed", expected: MCPServerConfig{URL: "https://mcp.example.com/mcp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.client, func(t *testing.T) {
+			target, err := clientFor(tt.client, "user")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			server, err := remoteServerConfig(target, "https://mcp.example.com/mcp", headers)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tt.expected.Headers = map[string]string{"Authorization": "Bearer ${API_TOKEN}"}
+			if !reflect.DeepEqual(server, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, server)
+			}
+		})
+	}
+}
+
+func TestRemoteServerConfigErrors(t *testing.T) {
+	claudeDesktop, err := clientFor("claude-desktop", "user")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cursor, err := clientFor("cursor", "user")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		target        *mcpClient
+		url           string
+		headers       []string
+		expectedError string
+	}{
+		{name: "client without remote servers", target: claudeDesktop, url: "https://mcp.example.com/mcp", expectedError: "can't connect to remote servers"},
+		{name: "missing url", target: cursor, expectedError: "requires an http or https --url"},
+		{name: "other scheme", target: cursor, url: "ws://mcp.example.com/mcp", expectedError: "requires an http or https --url"},
+		{name: "invalid header", target: cursor, url: "https://mcp.example.com/mcp", headers: []string{"Authorization"}, expectedError: "invalid header format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := remoteServerConfig(tt.target, tt.url, tt.headers)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
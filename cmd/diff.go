@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [old-spec] [new-spec]",
+	Short: "Show how the tools differ between two versions of a spec",
+	Long: `Compare the tools generated from two versions of a spec and list the ones that
+were added, removed or changed, with their changed names, inputs and schemas.
+Changes that can break calls made against the old tools are marked.
+
+Operations are matched by operationId, or by method and path without one.`,
+	Example: "  kumoctl diff ./openapi-v1.json ./openapi-v2.json\n  kumoctl diff https://api.example.com/openapi.json ./openapi.json --exit-code",
+	Args:    cobra.ExactArgs(2),
+	RunE:    runDiff,
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	exitCode, err := cmd.Flags().GetBool("exit-code")
+	if err != nil {
+		return err
+	}
+
+	oldSpec, err := loadSpec(cmd, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", args[0], err)
+	}
+	newSpec, err := loadSpec(cmd, args[1])
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", args[1], err)
+	}
+
+	diff, err := kumo_mcp.DiffTools(oldSpec, newSpec)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if diff.Empty() {
+		fmt.Fprintln(out, "no tool changes")
+		return nil
+	}
+
+	for _, name := range diff.Added {
+		fmt.Fprintf(out, "+ %s\n", name)
+	}
+	for _, name := range diff.Removed {
+		fmt.Fprintf(out, "- %s (breaking)\n", name)
+	}
+	for _, change := range diff.Changed {
+		fmt.Fprintf(out, "~ %s\n", change.Name)
+		for _, c := range change.Changes {
+			if c.Breaking {
+				fmt.Fprintf(out, "    %s (breaking)\n", c.Message)
+			} else {
+				fmt.Fprintf(out, "    %s\n", c.Message)
+			}
+		}
+	}
+	fmt.Fprintf(out, "\n%d added, %d removed, %d changed", len(diff.Added), len(diff.Removed), len(diff.Changed))
+	if diff.Breaking() {
+		fmt.Fprint(out, ", with breaking changes")
+	}
+	fmt.Fprintln(out)
+
+	if exitCode {
+		// The differences were reported above, only the exit status is left
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return fmt.Errorf("tools differ")
+	}
+	return nil
+}
+
+func init() {
+	diffCmd.Flags().Bool("exit-code", false, "exit with status 1 when the tools differ")
+	addHTTPClientFlags(diffCmd)
+	addSpecLoadingFlags(diffCmd)
+	rootCmd.AddCommand(diffCmd)
+}
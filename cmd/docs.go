@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs [spec-path-or-url]",
+	Short: "Generate documentation of the tools",
+	Long: `Generate Markdown or HTML documentation of the tools generated from the spec,
+with one section per tool holding its description, a table of its inputs and
+example arguments, to share with the people using the MCP server.`,
+	Example: "  kumoctl docs ./spec.json > TOOLS.md\n  kumoctl docs ./spec.json --format html --out tools.html --tag users",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runDocs,
+}
+
+func runDocs(cmd *cobra.Command, args []string) error {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+
+	var write func(io.Writer, openapi.APISpec, *kumo_mcp.ToolOptions) error
+	switch format {
+	case "markdown", "md":
+		write = kumo_mcp.WriteMarkdownDocs
+	case "html":
+		write = kumo_mcp.WriteHTMLDocs
+	default:
+		return fmt.Errorf("unsupported format %s, expected markdown or html", format)
+	}
+
+	openapiSpec, err := loadSpec(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	filter, err := toolFilterFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	opts := &kumo_mcp.ToolOptions{Filter: filter}
+
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		return write(cmd.OutOrStdout(), openapiSpec, opts)
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", out, err)
+	}
+	if err := write(file, openapiSpec, opts); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func init() {
+	docsCmd.Flags().String("format", "markdown", "documentation format: markdown or html")
+	docsCmd.Flags().String("out", "", "write the documentation to this file instead of stdout")
+	addFilterFlags(docsCmd)
+	addHTTPClientFlags(docsCmd)
+	addSpecLoadingFlags(docsCmd)
+	rootCmd.AddCommand(docsCmd)
+}
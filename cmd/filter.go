@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags registers the flags selecting which operations become tools
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("tag", []string{}, "only expose operations with one of these tags")
+	cmd.Flags().StringSlice("method", []string{}, "only expose operations with one of these HTTP methods")
+	cmd.Flags().StringSlice("path", []string{}, "only expose operations whose path matches one of these globs, e.g. /users/*")
+	cmd.Flags().StringSlice("toolsets", []string{}, "only expose the tools of these toolsets, one per tag, see kumoctl list toolsets")
+	cmd.Flags().Bool("include-deprecated", false, "also expose operations marked deprecated, with a warning in their description")
+}
+
+// toolFilterFromFlags builds the tool filter. Deprecated operations are left
+// out unless --include-deprecated is set, the filter is nil when nothing is
+// left out.
+func toolFilterFromFlags(cmd *cobra.Command) (*kumo_mcp.ToolFilter, error) {
+	tags, err := cmd.Flags().GetStringSlice("tag")
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := cmd.Flags().GetStringSlice("method")
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := cmd.Flags().GetStringSlice("path")
+	if err != nil {
+		return nil, err
+	}
+
+	toolsets, err := cmd.Flags().GetStringSlice("toolsets")
+	if err != nil {
+		return nil, err
+	}
+
+	includeDeprecated, err := cmd.Flags().GetBool("include-deprecated")
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := kumo_mcp.NewToolFilter(tags, methods, paths)
+	if err != nil || (len(toolsets) == 0 && includeDeprecated) {
+		return filter, err
+	}
+
+	if filter == nil {
+		filter = &kumo_mcp.ToolFilter{}
+	}
+	filter.Toolsets = toolsets
+	filter.ExcludeDeprecated = !includeDeprecated
+	return filter, nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/httpclient"
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// placeholderRegex matches the variables of server URLs, e.g. {region}
+var placeholderRegex = regexp.MustCompile(`\{[^}]*\}`)
+
+// addHostGuardFlags registers the flags restricting the hosts tool calls
+// reach
+func addHostGuardFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("allow-host", nil, "additional hosts tool calls may reach, e.g. api.example.com or *.example.com, * allows every host")
+}
+
+// applyHostGuard restricts the tool calls of toolOptions to the hosts declared
+// by the specs and --allow-host, and to the hosts of overridden base URLs
+// unless they are internal. Replayed calls never leave the process and aren't
+// restricted.
+func applyHostGuard(cmd *cobra.Command, specs []openapi.APISpec, baseURLs []string, toolOptions *kumo_mcp.ToolOptions) error {
+	replay, err := cmd.Flags().GetString("replay")
+	if err != nil {
+		return err
+	}
+	if replay != "" {
+		return nil
+	}
+
+	allowHosts, err := cmd.Flags().GetStringSlice("allow-host")
+	if err != nil {
+		return err
+	}
+
+	guard := httpclient.NewHostGuard()
+	guard.Trust(allowHosts...)
+	for _, spec := range specs {
+		trusted, checked := specHosts(spec)
+		guard.Trust(trusted...)
+		guard.Allow(checked...)
+	}
+
+	for _, baseURL := range baseURLs {
+		if baseURL == "" {
+			continue
+		}
+
+		u, err := url.Parse(placeholderRegex.ReplaceAllString(baseURL, "*"))
+		if err != nil {
+			return fmt.Errorf("invalid base URL %s: %w", baseURL, err)
+		}
+		guard.Allow(u.Hostname())
+
+		// Hosts filled from tool input can only be checked when called
+		if strings.Contains(u.Hostname(), "*") {
+			continue
+		}
+		if err := guard.Check(cmd.Context(), u); err != nil {
+			return fmt.Errorf("base URL %s: %w, allow it with --allow-host", baseURL, err)
+		}
+	}
+
+	toolOptions.HTTPClient = guard.Client(toolOptions.HTTPClient)
+	return nil
+}
+
+// specHosts returns the hosts of the servers a spec declares, for the whole
+// API and for single operations. Hosts with variables are returned as
+// patterns apart, as their values aren't vetted by the spec's author.
+func specHosts(spec openapi.APISpec) (trusted, checked []string) {
+	urls := []string{spec.GetBaseURL()}
+	for _, server := range spec.GetServers() {
+		urls = append(urls, server.URL)
+	}
+	for _, pathItem := range spec.GetPaths() {
+		for _, operation := range pathItem.GetOperations() {
+			if operation == nil {
+				continue
+			}
+			for _, server := range operation.GetServers() {
+				urls = append(urls, server.URL)
+			}
+		}
+	}
+
+	for _, raw := range urls {
+		pattern := placeholderRegex.ReplaceAllString(raw, "*")
+		u, err := url.Parse(pattern)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+
+		if strings.Contains(u.Hostname(), "*") {
+			checked = append(checked, u.Hostname())
+		} else {
+			trusted = append(trusted, u.Hostname())
+		}
+	}
+	return trusted, checked
+}
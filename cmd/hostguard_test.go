@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestSpecHosts(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [
+			{"url": "https://api.example.com/v1"},
+			{"url": "https://{tenant}.example.com/v1", "variables": {"tenant": {"default": "acme"}}}
+		],
+		"paths": {
+			"/uploads": {
+				"post": {
+					"operationId": "upload",
+					"servers": [{"url": "https://uploads.example.com"}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	trusted, checked := specHosts(spec)
+	sort.Strings(trusted)
+
+	expectedTrusted := []string{"api.example.com", "api.example.com", "uploads.example.com"}
+	if !reflect.DeepEqual(trusted, expectedTrusted) {
+		t.Errorf("Expected trusted hosts %v, got %v", expectedTrusted, trusted)
+	}
+	if expectedChecked := []string{"*.example.com"}; !reflect.DeepEqual(checked, expectedChecked) {
+		t.Errorf("Expected checked hosts %v, got %v", expectedChecked, checked)
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/kumolabai/kumoctl/pkg/httpclient"
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+// addHTTPClientFlags registers the flags configuring the shared HTTP client
+func addHTTPClientFlags(cmd *cobra.Command) {
+	defaults := httpclient.DefaultConfig()
+	cmd.Flags().Int("max-idle-conns", defaults.MaxIdleConns, "maximum idle keep-alive connections across all hosts")
+	cmd.Flags().Int("max-idle-conns-per-host", defaults.MaxIdleConnsPerHost, "maximum idle keep-alive connections per host")
+	cmd.Flags().Duration("idle-conn-timeout", defaults.IdleConnTimeout, "how long idle connections are kept open")
+	cmd.Flags().Bool("disable-keep-alives", defaults.DisableKeepAlives, "open a new connection for every request")
+	cmd.Flags().String("proxy", "", "proxy URL for all outgoing requests, overrides HTTP_PROXY/HTTPS_PROXY")
+	cmd.Flags().String("cacert", "", "PEM file with additional certificate authorities to trust")
+	cmd.Flags().String("client-cert", "", "PEM client certificate for APIs requiring mutual TLS")
+	cmd.Flags().String("client-key", "", "PEM private key of the client certificate")
+	cmd.Flags().Bool("insecure", false, "skip TLS certificate verification (development only)")
+	cmd.Flags().StringSlice("deny-host", nil, "hosts, IP addresses and CIDR ranges no request may reach, e.g. *.corp.example.com or 10.0.0.0/8, in addition to the cloud metadata endpoints")
+}
+
+// httpClientFromFlags builds the shared HTTP client from the flags registered
+// by addHTTPClientFlags
+func httpClientFromFlags(cmd *cobra.Command) (*http.Client, error) {
+	var cfg httpclient.Config
+	var err error
+
+	if cfg.MaxIdleConns, err = cmd.Flags().GetInt("max-idle-conns"); err != nil {
+		return nil, err
+	}
+	if cfg.MaxIdleConnsPerHost, err = cmd.Flags().GetInt("max-idle-conns-per-host"); err != nil {
+		return nil, err
+	}
+	if cfg.IdleConnTimeout, err = cmd.Flags().GetDuration("idle-conn-timeout"); err != nil {
+		return nil, err
+	}
+	if cfg.DisableKeepAlives, err = cmd.Flags().GetBool("disable-keep-alives"); err != nil {
+		return nil, err
+	}
+	if cfg.Proxy, err = cmd.Flags().GetString("proxy"); err != nil {
+		return nil, err
+	}
+	if cfg.CACertFile, err = cmd.Flags().GetString("cacert"); err != nil {
+		return nil, err
+	}
+	if cfg.ClientCertFile, err = cmd.Flags().GetString("client-cert"); err != nil {
+		return nil, err
+	}
+	if cfg.ClientKeyFile, err = cmd.Flags().GetString("client-key"); err != nil {
+		return nil, err
+	}
+	if cfg.InsecureSkipVerify, err = cmd.Flags().GetBool("insecure"); err != nil {
+		return nil, err
+	}
+	if cfg.DenyHosts, err = cmd.Flags().GetStringSlice("deny-host"); err != nil {
+		return nil, err
+	}
+
+	return httpclient.New(cfg)
+}
+
+// warnInsecure prints a warning to stderr when TLS verification is disabled.
+// It is written even in quiet mode, so the setting can't go unnoticed.
+func warnInsecure(cmd *cobra.Command) error {
+	insecure, err := cmd.Flags().GetBool("insecure")
+	if err != nil {
+		return err
+	}
+
+	if insecure {
+		fmt.Fprintln(cmd.ErrOrStderr(), "WARNING: --insecure disables TLS certificate verification, connections can be intercepted. Never use it in production.")
+	}
+	return nil
+}
+
+// addCassetteFlags registers the flags recording and replaying the HTTP
+// traffic of tool calls
+func addCassetteFlags(cmd *cobra.Command) {
+	cmd.Flags().String("record", "", "record the HTTP traffic of tool calls to this YAML cassette file")
+	cmd.Flags().String("replay", "", "answer tool calls from this YAML cassette file instead of calling the API")
+}
+
+// applyCassetteFlags routes the tool calls of toolOptions, which share one
+// client, through the cassette of --record or --replay, redacting the secret
+// query parameters of all of them. The authenticators keep the plain client,
+// so token requests are neither recorded nor replayed.
+func applyCassetteFlags(cmd *cobra.Command, toolOptions ...*kumo_mcp.ToolOptions) error {
+	record, err := cmd.Flags().GetString("record")
+	if err != nil {
+		return err
+	}
+	replay, err := cmd.Flags().GetString("replay")
+	if err != nil {
+		return err
+	}
+
+	if record == "" && replay == "" {
+		return nil
+	}
+	if record != "" && replay != "" {
+		return fmt.Errorf("--record and --replay can't be used together")
+	}
+
+	var secretParams []string
+	for _, opts := range toolOptions {
+		// Login requests carry the credentials, which must not end up in cassettes
+		if _, ok := opts.Auth.(*kumo_mcp.SessionAuthenticator); ok {
+			return fmt.Errorf("--record and --replay can't be used with --session-login")
+		}
+
+		for name := range opts.QueryParams {
+			if !slices.Contains(secretParams, name) {
+				secretParams = append(secretParams, name)
+			}
+		}
+	}
+
+	var transport http.RoundTripper
+	if record != "" {
+		transport, err = httpclient.NewRecorder(record, toolOptions[0].HTTPClient.Transport, secretParams)
+	} else {
+		transport, err = httpclient.LoadReplayer(replay, secretParams)
+	}
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Transport: transport}
+	for _, opts := range toolOptions {
+		opts.HTTPClient = client
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+func TestApplyCassetteFlagsRedactsEverySpec(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer mockServer.Close()
+
+	cassettePath := filepath.Join(t.TempDir(), "cassette.yaml")
+	cmd := &cobra.Command{Use: "serve"}
+	addCassetteFlags(cmd)
+	cmd.Flags().Set("record", cassettePath)
+
+	plain := &http.Client{}
+	users := &kumo_mcp.ToolOptions{HTTPClient: plain, QueryParams: url.Values{"api_key": {"users-secret"}}}
+	orders := &kumo_mcp.ToolOptions{HTTPClient: plain, QueryParams: url.Values{"token": {"orders-secret"}}}
+	if err := applyCassetteFlags(cmd, users, orders); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if users.HTTPClient != orders.HTTPClient || users.HTTPClient == plain {
+		t.Fatal("Expected every spec to share the recording client")
+	}
+
+	for _, query := range []string{"api_key=users-secret", "token=orders-secret"} {
+		resp, err := orders.HTTPClient.Get(mockServer.URL + "/items?" + query)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	data, err := os.ReadFile(cassettePath)
+	if err != nil {
+		t.Fatalf("Failed to read cassette: %v", err)
+	}
+	if strings.Contains(string(data), "users-secret") || strings.Contains(string(data), "orders-secret") {
+		t.Errorf("Expected the query keys of every spec to be redacted, got %s", data)
+	}
+}
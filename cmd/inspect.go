@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [spec-path-or-url] [tool-name]",
+	Short: "Show the details of a tool or preview the HTTP request it would send",
+	Long: `Print the method, URL template, parameters, request body schema and response
+schema of a tool.
+
+With --input, print the exact method, URL, headers and JSON body a call with
+that input would send instead, without making any request to the API.
+Credentials are masked.`,
+	Example: "  kumoctl inspect ./spec.json createUser\n  kumoctl inspect ./spec.json createUser --input '{\"name\": \"Alice\"}'",
+	Args:    cobra.ExactArgs(2),
+	RunE:    runInspect,
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	source, toolName := args[0], args[1]
+
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		if _, err := os.Stat(source); os.IsNotExist(err) {
+			return fmt.Errorf("file does not exist: %s", source)
+		}
+	}
+
+	rawInput, err := cmd.Flags().GetString("input")
+	if err != nil {
+		return err
+	}
+
+	var input kumo_mcp.APIToolInput
+	preview := cmd.Flags().Changed("input")
+	if preview {
+		if err := json.Unmarshal([]byte(rawInput), &input); err != nil {
+			return fmt.Errorf("invalid --input, expected a JSON object: %w", err)
+		}
+	}
+
+	openapiSpec, err := loadSpec(cmd, source)
+	if err != nil {
+		return err
+	}
+
+	toolOptions, err := requestOptionsFromFlags(cmd, openapiSpec)
+	if err != nil {
+		return err
+	}
+
+	tool, err := kumo_mcp.FindTool(openapiSpec, toolName, toolOptions)
+	if err != nil {
+		return err
+	}
+
+	if !preview {
+		return writeToolDetails(cmd.OutOrStdout(), tool)
+	}
+
+	req, err := kumo_mcp.BuildRequest(cmd.Context(), tool, input, toolOptions)
+	if err != nil {
+		return err
+	}
+
+	requestPreview, err := kumo_mcp.PreviewRequest(req, toolOptions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), requestPreview)
+	return nil
+}
+
+// writeToolDetails prints the route, parameters, request body schema and
+// response schema of a tool
+func writeToolDetails(w io.Writer, tool *kumo_mcp.EnrichedTool) error {
+	fmt.Fprintf(w, "%s\n%s %s\n", tool.Name, strings.ToUpper(tool.Method), strings.TrimRight(tool.BaseUrl, "/")+tool.Path)
+	if tool.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", strings.TrimSpace(tool.Description))
+	}
+	if tool.Operation == nil {
+		return nil
+	}
+
+	// Body parameters of OpenAPI 2.0 specs are shown as the request body
+	var params []openapi.Parameter
+	for _, param := range tool.Operation.GetParameters() {
+		if param.GetIn() != "body" {
+			params = append(params, param)
+		}
+	}
+	if len(params) > 0 {
+		fmt.Fprint(w, "\nParameters:\n")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  NAME\tIN\tREQUIRED\tTYPE\tDESCRIPTION")
+		for _, param := range params {
+			required := "no"
+			if param.IsRequired() {
+				required = "yes"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", param.GetName(), param.GetIn(), required, parameterType(param), param.GetDescription())
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	bodySchema, err := openapi.GenerateRequestBodySchema(tool.Operation)
+	if err != nil {
+		return fmt.Errorf("failed to convert request body: %w", err)
+	}
+	if err := writeSchema(w, "Request body", bodySchema); err != nil {
+		return err
+	}
+
+	responseSchema, err := openapi.GenerateOutputSchema(tool.Operation)
+	if err != nil {
+		return fmt.Errorf("failed to convert response: %w", err)
+	}
+	return writeSchema(w, "Response", responseSchema)
+}
+
+// parameterType names the type of a parameter, with its format and the type
+// of its items where it has them, e.g. string (date) or array of integer
+func parameterType(param openapi.Parameter) string {
+	typ, format, items := param.GetType(), param.GetFormat(), param.GetItems()
+	if schema := param.GetSchema(); schema != nil {
+		typ, format, items = schema.GetType(), schema.GetFormat(), schema.GetItems()
+	}
+
+	switch {
+	case typ == "":
+		typ = "string"
+	case typ == "array" && items != nil && items.GetType() != "":
+		typ += " of " + items.GetType()
+	}
+	if format != "" {
+		typ += " (" + format + ")"
+	}
+	return typ
+}
+
+// writeSchema prints a titled, indented JSON schema, or that there is none
+func writeSchema(w io.Writer, title string, schema *jsonschema.Schema) error {
+	if schema == nil {
+		fmt.Fprintf(w, "\n%s: none\n", title)
+		return nil
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%s:\n%s\n", title, data)
+	return nil
+}
+
+func init() {
+	inspectCmd.Flags().String("input", "{}", "preview the request of a call with this JSON object input")
+	addRequestFlags(inspectCmd)
+	addHTTPClientFlags(inspectCmd)
+	addSpecLoadingFlags(inspectCmd)
+	rootCmd.AddCommand(inspectCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunInspect(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Users API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com/v1"}],
+  "paths": {
+    "/users/{userId}": {
+      "put": {
+        "operationId": "updateUser",
+        "summary": "Update a user",
+        "parameters": [
+          {"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "fields", "in": "query", "description": "Fields to return", "schema": {"type": "array", "items": {"type": "string"}}}
+        ],
+        "requestBody": {
+          "content": {"application/json": {"schema": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}}}
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "string"}}}}}
+          }
+        }
+      }
+    }
+  }
+}`
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(spec), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		expectOut []string
+	}{
+		{
+			name: "tool details",
+			expectOut: []string{
+				"PUT https://api.example.com/v1/users/{userId}",
+				"Update a user",
+				"userId  path   yes",
+				"fields  query  no        array of string  Fields to return",
+				"Request body:",
+				`"required": [` + "\n    \"name\"",
+				"Response:",
+				`"id": {`,
+			},
+		},
+		{
+			name:      "request preview",
+			input:     `{"userId": "42", "name": "Alice"}`,
+			expectOut: []string{"PUT https://api.example.com/v1/users/42", `"name": "Alice"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			inspectCmd.SetOut(&stdout)
+			inspectCmd.SetContext(context.Background())
+			defer inspectCmd.SetOut(nil)
+
+			input := inspectCmd.Flags().Lookup("input")
+			input.Value.Set("{}")
+			input.Changed = false
+			if tt.input != "" {
+				if err := inspectCmd.Flags().Set("input", tt.input); err != nil {
+					t.Fatalf("Failed to set input: %v", err)
+				}
+			}
+
+			if err := runInspect(inspectCmd, []string{specPath, "updateUser"}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expectOut {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// standardizeJSONC turns JSON with comments and trailing commas, as in the
+// settings of editors, into JSON by blanking them. Offsets are unchanged, so
+// positions found in the result hold in the original.
+func standardizeJSONC(data []byte) []byte {
+	out := bytes.Clone(data)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	lastComma := -1
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			i = skipJSONString(out, i) - 1
+			lastComma = -1
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			end := bytes.IndexByte(out[i:], '\n')
+			if end < 0 {
+				end = len(out) - i
+			}
+			blank(i, i+end)
+			i += end - 1
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := len(out)
+			if j := bytes.Index(out[i+2:], []byte("*/")); j >= 0 {
+				end = i + 2 + j + 2
+			}
+			blank(i, end)
+			i = end - 1
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
+}
+
+// skipJSONString returns the offset after the string starting at i
+func skipJSONString(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// jsonMember is a member of a JSON object with the offsets of its key and
+// value
+type jsonMember struct {
+	key        string
+	keyStart   int
+	valueStart int
+	valueEnd   int
+}
+
+// jsonObject is an object of a JSON document, found by offsets
+type jsonObject struct {
+	// start and end are the offsets of the braces
+	start, end int
+	members    []jsonMember
+}
+
+// jsonScanner finds the objects of standardized JSON by offsets
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) && strings.IndexByte(" \t\r\n", s.data[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+func (s *jsonScanner) expect(c byte) error {
+	s.skipSpace()
+	if s.pos >= len(s.data) || s.data[s.pos] != c {
+		return fmt.Errorf("expected %q at offset %d", c, s.pos)
+	}
+	s.pos++
+	return nil
+}
+
+// object scans the object at the current offset
+func (s *jsonScanner) object() (*jsonObject, error) {
+	s.skipSpace()
+	obj := &jsonObject{start: s.pos}
+	if err := s.expect('{'); err != nil {
+		return nil, err
+	}
+
+	for {
+		s.skipSpace()
+		if s.pos < len(s.data) && s.data[s.pos] == '}' {
+			obj.end = s.pos
+			s.pos++
+			return obj, nil
+		}
+		if len(obj.members) > 0 {
+			if err := s.expect(','); err != nil {
+				return nil, err
+			}
+			s.skipSpace()
+		}
+
+		if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+			return nil, fmt.Errorf("expected a key at offset %d", s.pos)
+		}
+		keyStart := s.pos
+		keyEnd := skipJSONString(s.data, s.pos)
+		var key string
+		if err := json.Unmarshal(s.data[s.pos:keyEnd], &key); err != nil {
+			return nil, fmt.Errorf("invalid key at offset %d: %w", s.pos, err)
+		}
+		s.pos = keyEnd
+
+		if err := s.expect(':'); err != nil {
+			return nil, err
+		}
+		s.skipSpace()
+		member := jsonMember{key: key, keyStart: keyStart, valueStart: s.pos}
+		if err := s.skipValue(); err != nil {
+			return nil, err
+		}
+		member.valueEnd = s.pos
+		obj.members = append(obj.members, member)
+	}
+}
+
+// skipValue moves past the value at the current offset
+func (s *jsonScanner) skipValue() error {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return fmt.Errorf("unexpected end of document")
+	}
+
+	switch s.data[s.pos] {
+	case '"':
+		s.pos = skipJSONString(s.data, s.pos)
+	case '{':
+		if _, err := s.object(); err != nil {
+			return err
+		}
+	case '[':
+		s.pos++
+		for first := true; ; first = false {
+			s.skipSpace()
+			if s.pos < len(s.data) && s.data[s.pos] == ']' {
+				s.pos++
+				return nil
+			}
+			if !first {
+				if err := s.expect(','); err != nil {
+					return err
+				}
+			}
+			if err := s.skipValue(); err != nil {
+				return err
+			}
+		}
+	default:
+		start := s.pos
+		for s.pos < len(s.data) && strings.IndexByte(",}] \t\r\n", s.data[s.pos]) < 0 {
+			s.pos++
+		}
+		if s.pos == start {
+			return fmt.Errorf("unexpected %q at offset %d", s.data[s.pos], s.pos)
+		}
+	}
+	return nil
+}
+
+// setJSONCValue sets the value at the path of nested object keys in a JSON
+// document with comments, creating the missing objects. The rest of the
+// document, comments included, is kept as it is.
+func setJSONCValue(data []byte, path []string, value interface{}) ([]byte, error) {
+	if len(bytes.TrimSpace(standardizeJSONC(data))) == 0 {
+		data = []byte("{}\n")
+	}
+
+	scanner := &jsonScanner{data: standardizeJSONC(data)}
+	obj, err := scanner.object()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse existing config: %w", err)
+	}
+
+	for depth, key := range path {
+		var member *jsonMember
+		for i := range obj.members {
+			if obj.members[i].key == key {
+				member = &obj.members[i]
+			}
+		}
+
+		last := depth == len(path)-1
+		if member != nil && !last && scanner.data[member.valueStart] == '{' {
+			scanner.pos = member.valueStart
+			if obj, err = scanner.object(); err != nil {
+				return nil, fmt.Errorf("failed to parse existing config: %w", err)
+			}
+			continue
+		}
+
+		// Build what is missing from here on, nested objects ending with the
+		// value
+		var nested interface{} = value
+		for i := len(path) - 1; i > depth; i-- {
+			nested = map[string]interface{}{path[i]: nested}
+		}
+
+		indent := memberIndent(data, obj, depth)
+		unit := "  "
+		if len(indent)%(depth+1) == 0 {
+			unit = indent[:len(indent)/(depth+1)]
+		}
+		encoded, err := json.MarshalIndent(nested, indent, unit)
+		if err != nil {
+			return nil, err
+		}
+
+		var out bytes.Buffer
+		if member != nil {
+			out.Write(data[:member.valueStart])
+			out.Write(encoded)
+			out.Write(data[member.valueEnd:])
+			return out.Bytes(), nil
+		}
+
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		entry := string(keyJSON) + ": " + string(encoded)
+
+		if n := len(obj.members); n > 0 {
+			end := obj.members[n-1].valueEnd
+			out.Write(data[:end])
+			out.WriteString(",\n" + indent + entry)
+			out.Write(data[end:])
+		} else {
+			// Comments of the empty object are kept before the member
+			out.Write(bytes.TrimRight(data[:obj.end], " \t\r\n"))
+			out.WriteString("\n" + indent + entry + "\n" + strings.Repeat(unit, depth))
+			out.Write(data[obj.end:])
+		}
+		return out.Bytes(), nil
+	}
+	return data, nil
+}
+
+// memberIndent returns the indentation of the members of an object, taken
+// from its first member or its depth
+func memberIndent(data []byte, obj *jsonObject, depth int) string {
+	if len(obj.members) > 0 {
+		keyStart := obj.members[0].keyStart
+		lineStart := bytes.LastIndexByte(data[:keyStart], '\n') + 1
+		if prefix := data[lineStart:keyStart]; len(bytes.TrimLeft(prefix, " \t")) == 0 {
+			return string(prefix)
+		}
+	}
+	return strings.Repeat("  ", depth+1)
+}
+
+// deleteJSONCValue removes the member at the path of nested object keys from
+// a JSON document with comments, reporting whether it was found. The lines of
+// the member go with it, the rest of the document is kept as it is.
+func deleteJSONCValue(data []byte, path []string) ([]byte, bool, error) {
+	std := standardizeJSONC(data)
+	if len(bytes.TrimSpace(std)) == 0 || len(path) == 0 {
+		return data, false, nil
+	}
+
+	scanner := &jsonScanner{data: std}
+	obj, err := scanner.object()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse existing config: %w", err)
+	}
+
+	for depth, key := range path {
+		index := -1
+		for i := range obj.members {
+			if obj.members[i].key == key {
+				index = i
+			}
+		}
+		if index < 0 {
+			return data, false, nil
+		}
+		member := obj.members[index]
+
+		if depth < len(path)-1 {
+			if std[member.valueStart] != '{' {
+				return data, false, nil
+			}
+			scanner.pos = member.valueStart
+			if obj, err = scanner.object(); err != nil {
+				return nil, false, fmt.Errorf("failed to parse existing config: %w", err)
+			}
+			continue
+		}
+
+		// comma is the separator left behind by removing the last member
+		start, end, comma := member.keyStart, member.valueEnd, -1
+		switch {
+		case len(obj.members) == 1:
+			start, end = obj.start+1, obj.end
+		case index < len(obj.members)-1:
+			// Up to the next member, on its own line when both are
+			end = obj.members[index+1].keyStart
+			if ownLine(std, start) && ownLine(std, end) {
+				start, end = lineStart(std, start), lineStart(std, end)
+			}
+		default:
+			// The member's line goes with it when only a trailing comma or
+			// comments follow, the comments of the previous member stay
+			prevEnd := obj.members[index-1].valueEnd
+			comma = prevEnd + bytes.IndexByte(std[prevEnd:], ',')
+			if eol := bytes.IndexByte(std[end:], '\n'); eol >= 0 && len(bytes.Trim(std[end:end+eol], " \t\r,")) == 0 {
+				end += eol
+				if ownLine(std, start) {
+					start = lineStart(std, start) - 1
+				}
+			}
+		}
+
+		out := make([]byte, 0, len(data))
+		if comma >= 0 {
+			out = append(out, data[:comma]...)
+			out = append(out, data[comma+1:start]...)
+		} else {
+			out = append(out, data[:start]...)
+		}
+		out = append(out, data[end:]...)
+		return out, true, nil
+	}
+	return data, false, nil
+}
+
+// lineStart returns the offset of the line holding offset i
+func lineStart(data []byte, i int) int {
+	return bytes.LastIndexByte(data[:i], '\n') + 1
+}
+
+// ownLine reports whether only spaces come before offset i on its line
+func ownLine(data []byte, i int) bool {
+	return len(bytes.Trim(data[lineStart(data, i):i], " \t")) == 0
+}
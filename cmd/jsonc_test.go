@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStandardizeJSONC(t *testing.T) {
+	input := `{
+  // theme
+  "theme": "One Dark", /* inline */
+  "url": "https://example.com/a//b",
+  "list": [1, 2,],
+}`
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(standardizeJSONC([]byte(input)), &parsed); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, standardizeJSONC([]byte(input)))
+	}
+	if parsed["url"] != "https://example.com/a//b" {
+		t.Errorf("Expected strings to be kept, got %v", parsed["url"])
+	}
+	if len(standardizeJSONC([]byte(input))) != len(input) {
+		t.Error("Expected offsets to be unchanged")
+	}
+}
+
+func TestSetJSONCValue(t *testing.T) {
+	server := map[string]string{"command": "kumoctl"}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:  "empty file",
+			input: "",
+			expected: `{
+  "context_servers": {
+    "api": {
+      "command": "kumoctl"
+    }
+  }
+}
+`,
+		},
+		{
+			name: "new key after comments and trailing comma",
+			input: `// Zed settings
+{
+    // Font size
+    "buffer_font_size": 15,
+}
+`,
+			expected: `// Zed settings
+{
+    // Font size
+    "buffer_font_size": 15,
+    "context_servers": {
+        "api": {
+            "command": "kumoctl"
+        }
+    },
+}
+`,
+		},
+		{
+			name: "added next to other servers",
+			input: `{
+  "context_servers": {
+    "other": {"command": "other"} // keep
+  }
+}`,
+			expected: `{
+  "context_servers": {
+    "other": {"command": "other"},
+    "api": {
+      "command": "kumoctl"
+    } // keep
+  }
+}`,
+		},
+		{
+			name: "replaced",
+			input: `{
+  "context_servers": {
+    "api": {"command": "old", /* old */}
+  }
+}`,
+			expected: `{
+  "context_servers": {
+    "api": {
+      "command": "kumoctl"
+    }
+  }
+}`,
+		},
+		{
+			name: "empty object",
+			input: `{
+  "context_servers": {}
+}`,
+			expected: `{
+  "context_servers": {
+    "api": {
+      "command": "kumoctl"
+    }
+  }
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setJSONCValue([]byte(tt.input), []string{"context_servers", "api"}, server)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetJSONCValueInvalid(t *testing.T) {
+	if _, err := setJSONCValue([]byte(`{"a": }`), []string{"b"}, 1); err == nil {
+		t.Error("Expected an error for an invalid document")
+	}
+}
+
+func TestDeleteJSONCValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		found    bool
+	}{
+		{
+			name: "first of several",
+			input: `{
+  "context_servers": {
+    // the API
+    "api": {
+      "command": "kumoctl"
+    },
+    "other": {"command": "other"} // keep
+  }
+}`,
+			expected: `{
+  "context_servers": {
+    // the API
+    "other": {"command": "other"} // keep
+  }
+}`,
+			found: true,
+		},
+		{
+			name: "last with a trailing comma",
+			input: `{
+  "context_servers": {
+    "other": {"command": "other"}, // keep
+    "api": {"command": "kumoctl"},
+  },
+}`,
+			expected: `{
+  "context_servers": {
+    "other": {"command": "other"} // keep
+  },
+}`,
+			found: true,
+		},
+		{
+			name: "only server",
+			input: `{
+  "theme": "One Dark",
+  "context_servers": {
+    "api": {"command": "kumoctl"}
+  }
+}`,
+			expected: `{
+  "theme": "One Dark",
+  "context_servers": {}
+}`,
+			found: true,
+		},
+		{
+			name:     "last on the same line",
+			input:    `{"context_servers": {"other": {}, "api": {"command": "kumoctl"}}}`,
+			expected: `{"context_servers": {"other": {} }}`,
+			found:    true,
+		},
+		{
+			name:     "missing server",
+			input:    `{"context_servers": {"other": {}}}`,
+			expected: `{"context_servers": {"other": {}}}`,
+		},
+		{
+			name:     "missing servers",
+			input:    `{"theme": "One Dark"}`,
+			expected: `{"theme": "One Dark"}`,
+		},
+		{
+			name:     "empty file",
+			input:    "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := deleteJSONCValue([]byte(tt.input), []string{"context_servers", "api"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if found != tt.found {
+				t.Errorf("Expected found %v, got %v", tt.found, found)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kumolabai/kumoctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var listProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List profiles from the config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if len(file.Profiles) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No profiles configured")
+			return nil
+		}
+
+		names := make([]string, 0, len(file.Profiles))
+		for name := range file.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		t := table.NewWriter()
+		t.SetOutputMirror(cmd.OutOrStdout())
+		t.AppendHeader(table.Row{"Profile", "Spec", "Base URL", "Environments"})
+		for _, name := range names {
+			settings, err := file.Resolve(name, "")
+			if err != nil {
+				return err
+			}
+
+			var envs []string
+			if profile := file.Profiles[name]; profile != nil {
+				for env := range profile.Environments {
+					envs = append(envs, env)
+				}
+			}
+			sort.Strings(envs)
+
+			t.AppendRow(table.Row{name, settingString(settings, config.SpecKey), settingString(settings, "base-url"), strings.Join(envs, ", ")})
+		}
+		t.Render()
+
+		return nil
+	},
+}
+
+// settingString returns a scalar setting, or an empty string when unset
+func settingString(settings config.Settings, key string) string {
+	if value, ok := settings[key]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+func init() {
+	listProfilesCmd.Flags().String("config", "", "path to the config file (default ./kumoctl.yaml, then ~/.config/kumoctl/kumoctl.yaml)")
+	listCmd.AddCommand(listProfilesCmd)
+}
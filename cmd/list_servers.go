@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var listServersCmd = &cobra.Command{
+	Use:   "servers [spec-path-or-url]",
+	Short: "List the servers declared in the spec",
+	Long: `List the servers declared in the spec. Select one with --server-index or
+--server-url, otherwise tools call the first server.`,
+	Args: verifySpecSource,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := specSource(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		if err := warnInsecure(cmd); err != nil {
+			return err
+		}
+
+		openapiSpec, err := loadSpec(cmd, source)
+		if err != nil {
+			return err
+		}
+
+		servers := openapiSpec.GetServers()
+		if len(servers) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No servers declared, tools call %s\n", openapiSpec.GetBaseURL())
+			return nil
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(cmd.OutOrStdout())
+		t.AppendHeader(table.Row{"Index", "URL", "Variables", "Description"})
+		for i, server := range servers {
+			t.AppendRow(table.Row{i, server.URL, serverVariablesString(server), server.Description})
+		}
+		t.Render()
+
+		return nil
+	},
+}
+
+// serverVariablesString lists the variables of a server with their default
+// and allowed values
+func serverVariablesString(server openapi.Server) string {
+	names := make([]string, 0, len(server.Variables))
+	for name := range server.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vars := make([]string, 0, len(names))
+	for _, name := range names {
+		variable := server.Variables[name]
+		v := name + "=" + variable.Default
+		if len(variable.Enum) > 0 {
+			v += " (" + strings.Join(variable.Enum, "|") + ")"
+		}
+		vars = append(vars, v)
+	}
+	return strings.Join(vars, ", ")
+}
+
+func init() {
+	addHTTPClientFlags(listServersCmd)
+	addSpecLoadingFlags(listServersCmd)
+	addProfileFlags(listServersCmd)
+	listCmd.AddCommand(listServersCmd)
+}
@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"sort"
+	"strings"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/jedib0t/go-pretty/v6/table"
 	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
-	"github.com/kumolabai/kumoctl/pkg/openapi"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var listToolsCmd = &cobra.Command{
@@ -15,25 +19,69 @@ var listToolsCmd = &cobra.Command{
 	Short: "List generated tools from spec",
 	Args:  verifySpecSource,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		source := args[0]
-		openapiSpec, err := openapi.LoadSpecFromSource(source)
+		source, err := specSource(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		if output != "table" && output != "json" && output != "yaml" {
+			return fmt.Errorf("unsupported output %s, expected table, json or yaml", output)
+		}
+
+		showSchema, err := cmd.Flags().GetBool("show-schema")
+		if err != nil {
+			return err
+		}
+
+		filter, err := toolFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := warnInsecure(cmd); err != nil {
+			return err
+		}
+
+		openapiSpec, err := loadSpec(cmd, source)
 		if err != nil {
 			return err
 		}
 
 		// Dynamically generate tools from OpenAPI paths
-		tools, err := kumo_mcp.GetToolsFromSpec(openapiSpec)
+		generated, err := kumo_mcp.GetToolsFromSpec(openapiSpec)
 		if err != nil {
 			return fmt.Errorf("failed to generate tools from OpenAPI spec: %w", err)
 		}
 
+		var tools []*kumo_mcp.EnrichedTool
+		for _, tool := range generated {
+			if filter.Match(tool) {
+				tools = append(tools, tool)
+			}
+		}
+		sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+		if output != "table" {
+			return writeToolListings(cmd.OutOrStdout(), output, tools)
+		}
+
 		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"#", "Name", "Description"})
+		t.SetOutputMirror(cmd.OutOrStdout())
+		if showSchema {
+			t.AppendHeader(table.Row{"#", "Name", "Description", "Inputs"})
+		} else {
+			t.AppendHeader(table.Row{"#", "Name", "Description"})
+		}
 		for i, tool := range tools {
-			t.AppendRow(table.Row{
-				i + 1, tool.Name, tool.Description,
-			})
+			row := table.Row{i + 1, tool.Name, tool.Description}
+			if showSchema {
+				row = append(row, inputSummary(tool.InputSchema))
+			}
+			t.AppendRow(row)
 			t.AppendSeparator()
 		}
 		t.Render()
@@ -42,6 +90,104 @@ var listToolsCmd = &cobra.Command{
 	},
 }
 
+// inputSummary lists the inputs of a tool one per line with their type,
+// marking required ones with *
+func inputSummary(schema *jsonschema.Schema) string {
+	if schema == nil || len(schema.Properties) == 0 {
+		return ""
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		property := schema.Properties[name]
+		line := name
+		if required[name] {
+			line += "*"
+		}
+
+		types := property.Types
+		if property.Type != "" {
+			types = []string{property.Type}
+		}
+		if len(types) > 0 {
+			line += " (" + strings.Join(types, "|") + ")"
+		}
+		if len(property.Enum) > 0 {
+			values := make([]string, len(property.Enum))
+			for j, value := range property.Enum {
+				values[j] = fmt.Sprint(value)
+			}
+			line += ": " + strings.Join(values, ", ")
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// toolListing is a tool as printed by list tools --output json or yaml
+type toolListing struct {
+	Name        string      `json:"name" yaml:"name"`
+	Description string      `json:"description" yaml:"description"`
+	Method      string      `json:"method" yaml:"method"`
+	Path        string      `json:"path" yaml:"path"`
+	InputSchema interface{} `json:"input_schema" yaml:"input_schema"`
+}
+
+// writeToolListings prints the tools with their full input schema as JSON or
+// YAML
+func writeToolListings(w io.Writer, format string, tools []*kumo_mcp.EnrichedTool) error {
+	listings := make([]toolListing, 0, len(tools))
+	for _, tool := range tools {
+		// Round-trip the schema so YAML follows its JSON field names
+		data, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			return fmt.Errorf("failed to encode input schema of %s: %w", tool.Name, err)
+		}
+		var schema interface{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return err
+		}
+
+		listings = append(listings, toolListing{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Method:      strings.ToUpper(tool.Method),
+			Path:        tool.Path,
+			InputSchema: schema,
+		})
+	}
+
+	if format == "yaml" {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(listings); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(listings)
+}
+
 func init() {
+	listToolsCmd.Flags().Bool("show-schema", false, "add the inputs of each tool to the table, required ones marked with *")
+	addFilterFlags(listToolsCmd)
+	listToolsCmd.Flags().StringP("output", "o", "table", "output format: table, json or yaml")
+	addHTTPClientFlags(listToolsCmd)
+	addSpecLoadingFlags(listToolsCmd)
+	addProfileFlags(listToolsCmd)
 	listCmd.AddCommand(listToolsCmd)
 }
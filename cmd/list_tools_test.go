@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteToolListings(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Users", "version": "1"},
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "summary": "Get a user",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tools, err := kumo_mcp.GetToolsFromSpec(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeToolListings(&out, format, tools); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var listings []map[string]interface{}
+			if format == "json" {
+				err = json.Unmarshal(out.Bytes(), &listings)
+			} else {
+				err = yaml.Unmarshal(out.Bytes(), &listings)
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse output: %v\n%s", err, out.String())
+			}
+
+			if len(listings) != 1 {
+				t.Fatalf("Expected 1 tool, got %d", len(listings))
+			}
+			listing := listings[0]
+			if listing["name"] != "getUser" || listing["method"] != "GET" || listing["path"] != "/users/{id}" || listing["description"] != "Get a user" {
+				t.Errorf("Unexpected listing: %v", listing)
+			}
+
+			schema, _ := listing["input_schema"].(map[string]interface{})
+			properties, _ := schema["properties"].(map[string]interface{})
+			if _, ok := properties["id"]; !ok {
+				t.Errorf("Expected the id input in the schema, got %v", schema)
+			}
+		})
+	}
+}
+
+func TestInputSummary(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"id":    {Type: "string"},
+			"view":  {Type: "string", Enum: []interface{}{"full", "brief"}},
+			"limit": {Types: []string{"integer", "null"}},
+		},
+		Required: []string{"id"},
+	}
+
+	expected := "id* (string)\nlimit (integer|null)\nview (string): full, brief"
+	if got := inputSummary(schema); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if got := inputSummary(&jsonschema.Schema{Type: "object"}); got != "" {
+		t.Errorf("Expected no inputs, got %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var listToolsetsCmd = &cobra.Command{
+	Use:   "toolsets [spec-path-or-url]",
+	Short: "List the toolsets of the spec, one per tag",
+	Long: `List the toolsets of the spec, one per tag, with their tools. Operations
+without tags are in the default toolset. Enable some of them with serve
+--toolsets, or run one server per toolset.`,
+	Args: verifySpecSource,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := specSource(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		filter, err := toolFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := warnInsecure(cmd); err != nil {
+			return err
+		}
+
+		openapiSpec, err := loadSpec(cmd, source)
+		if err != nil {
+			return err
+		}
+
+		toolsets, err := kumo_mcp.GetToolsets(openapiSpec, &kumo_mcp.ToolOptions{Filter: filter})
+		if err != nil {
+			return fmt.Errorf("failed to generate tools from OpenAPI spec: %w", err)
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(cmd.OutOrStdout())
+		t.AppendHeader(table.Row{"Toolset", "Tag", "Description", "Tools"})
+		for _, toolset := range toolsets {
+			t.AppendRow(table.Row{toolset.Name, toolset.Tag, toolset.Description, strings.Join(toolset.Tools, "\n")})
+			t.AppendSeparator()
+		}
+		t.Render()
+
+		return nil
+	},
+}
+
+func init() {
+	addFilterFlags(listToolsetsCmd)
+	addHTTPClientFlags(listToolsetsCmd)
+	addSpecLoadingFlags(listToolsetsCmd)
+	addProfileFlags(listToolsetsCmd)
+	listCmd.AddCommand(listToolsetsCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// loggerKey is the context key of the logger set up by a command
+type loggerKey struct{}
+
+// addLogFlags registers the flags configuring diagnostics
+func addLogFlags(cmd *cobra.Command) {
+	cmd.Flags().String("log-level", "info", "minimum level of logged messages: debug, info, warn or error")
+	cmd.Flags().String("log-file", "", "also write logs to this file, appending to it")
+}
+
+// loggerFromFlags builds a logger writing to stderr, only warnings and errors
+// with --quiet, and to --log-file. The returned function closes the log file.
+func loggerFromFlags(cmd *cobra.Command) (*slog.Logger, func() error, error) {
+	rawLevel, err := cmd.Flags().GetString("log-level")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(rawLevel)); err != nil {
+		return nil, nil, fmt.Errorf("invalid --log-level %s, expected debug, info, warn or error", rawLevel)
+	}
+
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logFile, err := cmd.Flags().GetString("log-file")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stderrLevel := level
+	if quiet && stderrLevel < slog.LevelWarn {
+		stderrLevel = slog.LevelWarn
+	}
+	handlers := []slog.Handler{slog.NewTextHandler(cmd.ErrOrStderr(), &slog.HandlerOptions{Level: stderrLevel})}
+
+	closeLog := func() error { return nil }
+	if logFile != "" {
+		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		handlers = append(handlers, slog.NewTextHandler(file, &slog.HandlerOptions{Level: level}))
+		closeLog = file.Close
+	}
+
+	return slog.New(multiHandler(handlers)), closeLog, nil
+}
+
+// withLogger sets up the logger used by the command's helpers, such as
+// loadSpec
+func withLogger(cmd *cobra.Command, logger *slog.Logger) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.SetContext(context.WithValue(ctx, loggerKey{}, logger))
+}
+
+// commandLogger returns the logger set up by the command, or one writing
+// warnings and errors to stderr
+func commandLogger(cmd *cobra.Command) *slog.Logger {
+	if ctx := cmd.Context(); ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.New(slog.NewTextHandler(cmd.ErrOrStderr(), &slog.HandlerOptions{Level: slog.LevelWarn}))
+}
+
+// multiHandler sends every record to all of its handlers that accept its level
+type multiHandler []slog.Handler
+
+func (h multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// serverManifest describes what a running kumoctl instance exposes, for
+// orchestration and debugging tools. It never contains credentials.
+type serverManifest struct {
+	Kumoctl   string           `json:"kumoctl"`
+	Name      string           `json:"name"`
+	Title     string           `json:"title"`
+	Version   string           `json:"version"`
+	Transport string           `json:"transport"`
+	StartedAt time.Time        `json:"started_at"`
+	Specs     []manifestSpec   `json:"specs"`
+	Tools     int              `json:"tools"`
+	Filters   *manifestFilters `json:"filters,omitempty"`
+	Auth      []string         `json:"auth"`
+}
+
+type manifestSpec struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	SHA256  string `json:"sha256"`
+	BaseURL string `json:"base_url"`
+	Tools   int    `json:"tools"`
+}
+
+type manifestFilters struct {
+	Tags              []string `json:"tags,omitempty"`
+	Methods           []string `json:"methods,omitempty"`
+	Paths             []string `json:"paths,omitempty"`
+	Toolsets          []string `json:"toolsets,omitempty"`
+	IncludeDeprecated bool     `json:"include_deprecated,omitempty"`
+}
+
+// addManifestSpec records a served spec and the number of tools it registered
+func (m *serverManifest) addManifestSpec(l *loadedSpec, tools int) error {
+	hash, err := openapi.Hash(l.spec)
+	if err != nil {
+		return err
+	}
+
+	baseURL := l.spec.GetBaseURL()
+	if l.opts.BaseURL != "" {
+		baseURL = l.opts.BaseURL
+	}
+
+	m.Specs = append(m.Specs, manifestSpec{Name: l.Name, Source: l.Source, SHA256: hash, BaseURL: baseURL, Tools: tools})
+	m.Tools += tools
+	return nil
+}
+
+// writeManifest writes the manifest as indented JSON to path
+func writeManifest(path string, manifest *serverManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// manifestFiltersFor describes the tool filter, nil when every tool but the
+// deprecated ones is served, as by default
+func manifestFiltersFor(filter *kumo_mcp.ToolFilter) *manifestFilters {
+	if filter == nil {
+		return &manifestFilters{IncludeDeprecated: true}
+	}
+	if len(filter.Tags) == 0 && len(filter.Methods) == 0 && len(filter.Paths) == 0 && len(filter.Toolsets) == 0 && filter.ExcludeDeprecated {
+		return nil
+	}
+	return &manifestFilters{Tags: filter.Tags, Methods: filter.Methods, Paths: filter.Paths, Toolsets: filter.Toolsets, IncludeDeprecated: !filter.ExcludeDeprecated}
+}
+
+// authModes names the ways credentials are sent upstream
+func authModes(opts *kumo_mcp.ToolOptions) []string {
+	modes := []string{}
+
+	switch auth := opts.Auth.(type) {
+	case *kumo_mcp.BearerAuthenticator:
+		modes = append(modes, "oauth2")
+	case *kumo_mcp.SessionAuthenticator:
+		modes = append(modes, "session")
+	case *kumo_mcp.TokenExchangeAuthenticator:
+		modes = append(modes, "token-exchange")
+	case nil:
+	default:
+		modes = append(modes, fmt.Sprintf("%T", auth))
+	}
+
+	if authorization := opts.Headers.Get("Authorization"); authorization != "" {
+		// Only name the scheme, a bare value would be the secret itself
+		if scheme, _, found := strings.Cut(authorization, " "); found {
+			modes = append(modes, "header:"+strings.ToLower(scheme))
+		} else {
+			modes = append(modes, "header")
+		}
+	}
+
+	if len(opts.QueryParams) > 0 {
+		modes = append(modes, "api-key")
+	}
+
+	if opts.Signer != nil {
+		modes = append(modes, "hmac")
+	}
+
+	if len(opts.TagHeaders) > 0 {
+		tags := make([]string, 0, len(opts.TagHeaders))
+		for tag := range opts.TagHeaders {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		modes = append(modes, "tag-headers:"+strings.Join(tags, ","))
+	}
+
+	if len(modes) == 0 {
+		modes = append(modes, "none")
+	}
+	return modes
+}
+
+// transportName names the MCP transport a server runs on
+func transportName(transport mcp.Transport) string {
+	switch transport.(type) {
+	case *mcp.StdioTransport:
+		return "stdio"
+	case *mcp.InMemoryTransport:
+		return "in-memory"
+	default:
+		return fmt.Sprintf("%T", transport)
+	}
+}
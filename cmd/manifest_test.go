@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+)
+
+func TestAuthModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     *kumo_mcp.ToolOptions
+		expected []string
+	}{
+		{name: "no credentials", opts: &kumo_mcp.ToolOptions{}, expected: []string{"none"}},
+		{
+			name:     "authorization scheme",
+			opts:     &kumo_mcp.ToolOptions{Headers: http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}}},
+			expected: []string{"header:basic"},
+		},
+		{
+			name:     "bare authorization value is not echoed",
+			opts:     &kumo_mcp.ToolOptions{Headers: http.Header{"Authorization": {"s3cr3t"}}},
+			expected: []string{"header"},
+		},
+		{
+			name: "combined",
+			opts: &kumo_mcp.ToolOptions{
+				Auth:        &kumo_mcp.BearerAuthenticator{},
+				QueryParams: url.Values{"api_key": {"s3cr3t"}},
+				TagHeaders:  map[string]http.Header{"reports": nil, "admin": nil},
+			},
+			expected: []string{"oauth2", "api-key", "tag-headers:admin,reports"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if modes := authModes(tt.opts); !reflect.DeepEqual(modes, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, modes)
+			}
+		})
+	}
+}
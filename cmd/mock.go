@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/kumolabai/kumoctl/pkg/mock"
+	"github.com/spf13/cobra"
+)
+
+var mockCmd = &cobra.Command{
+	Use:   "mock [spec-path-or-url]",
+	Short: "Serve fake responses for the operations of a spec",
+	Long: `Start an HTTP server answering every operation of a spec with the example of
+its successful response, or a value synthesized from the response schema when
+it has no example. Point serve at it with --base-url to demo and test the
+generated tools without access to the real API.
+
+Operations are served under the path of the spec's base URL.`,
+	Example: "  kumoctl mock ./spec.json --addr localhost:8081\n  kumoctl serve ./spec.json --base-url http://localhost:8081/v1",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runMock,
+}
+
+func runMock(cmd *cobra.Command, args []string) error {
+	source := args[0]
+
+	if !isURL(source) {
+		if _, err := os.Stat(source); os.IsNotExist(err) {
+			return fmt.Errorf("file does not exist: %s", source)
+		}
+	}
+
+	addr, err := cmd.Flags().GetString("addr")
+	if err != nil {
+		return err
+	}
+
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return err
+	}
+
+	openapiSpec, err := loadSpec(cmd, source)
+	if err != nil {
+		return err
+	}
+
+	handler, err := mock.NewHandler(openapiSpec)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	stderr := cmd.ErrOrStderr()
+	if !quiet {
+		for _, route := range handler.Routes() {
+			fmt.Fprintf(stderr, "%-7s %s -> %d\n", route.Method, route.Path, route.Status)
+		}
+	}
+
+	baseURL := "http://" + listener.Addr().String() + handler.BasePath()
+	fmt.Fprintf(stderr, "Mock of %s listening on %s\n", source, baseURL)
+	fmt.Fprintf(stderr, "Serve its tools with: kumoctl serve %s --base-url %s\n", source, baseURL)
+
+	var h http.Handler = handler
+	if !quiet {
+		h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(stderr, "%s %s\n", r.Method, r.URL.RequestURI())
+			handler.ServeHTTP(w, r)
+		})
+	}
+	return http.Serve(listener, h)
+}
+
+func init() {
+	mockCmd.Flags().String("addr", "localhost:8081", "address to listen on")
+	mockCmd.Flags().BoolP("quiet", "q", false, "don't log the routes and requests on stderr")
+	addHTTPClientFlags(mockCmd)
+	addSpecLoadingFlags(mockCmd)
+	rootCmd.AddCommand(mockCmd)
+}
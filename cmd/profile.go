@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kumolabai/kumoctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// profileEnv selects a profile when --profile isn't given
+const profileEnv = "KUMOCTL_PROFILE"
+
+// addProfileFlags registers the flags selecting settings from the config file
+func addProfileFlags(cmd *cobra.Command) {
+	cmd.Flags().String("config", "", "path to the config file (default ./kumoctl.yaml, then ~/.config/kumoctl/kumoctl.yaml)")
+	cmd.Flags().String("profile", "", "profile from the config file providing the spec and flag values (default $"+profileEnv+")")
+	cmd.Flags().String("env", "", "environment of the selected profile, e.g. staging or prod")
+}
+
+// loadConfig reads the config file given by --config, or the one found in the
+// working directory or ~/.config/kumoctl
+func loadConfig(cmd *cobra.Command) (*config.File, error) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		if path, err = config.Find(); err != nil {
+			return nil, err
+		}
+	}
+
+	return config.Load(path)
+}
+
+// profileSettings returns the settings of the config file with the profile
+// selected by --profile or $KUMOCTL_PROFILE and --env applied, or nil when the command takes no
+// settings from the config file
+func profileSettings(cmd *cobra.Command) (config.Settings, error) {
+	_, settings, err := resolveConfig(cmd)
+	return settings, err
+}
+
+// resolveConfig loads the config file and resolves the selected profile
+func resolveConfig(cmd *cobra.Command) (*config.File, config.Settings, error) {
+	if cmd.Flags().Lookup("profile") == nil {
+		return nil, nil, nil
+	}
+
+	profile, err := flagOrEnv(cmd, "profile", profileEnv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env, err := cmd.Flags().GetString("env")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if profile == "" && env != "" {
+		return nil, nil, fmt.Errorf("--env requires --profile")
+	}
+
+	file, err := loadConfig(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings, err := file.Resolve(profile, env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, settings, nil
+}
+
+// applyProfile sets every flag that wasn't given on the command line from the
+// config file and the selected profile, so explicit flags always win
+func applyProfile(cmd *cobra.Command) error {
+	file, settings, err := resolveConfig(cmd)
+	if err != nil || file == nil {
+		return err
+	}
+
+	return applySettings(cmd, settings, file.Settings)
+}
+
+// applySettings sets unchanged flags from settings keyed by flag name. Shared
+// settings come from the top level of the config file and are skipped by
+// commands without the flag, e.g. timeouts when listing tools.
+func applySettings(cmd *cobra.Command, settings, shared config.Settings) error {
+	for key, value := range settings {
+		if key == config.SpecKey {
+			continue
+		}
+
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil {
+			if _, ok := shared[key]; ok {
+				continue
+			}
+			return fmt.Errorf("unknown setting %s, settings are named after the flags of %s", key, cmd.CommandPath())
+		}
+		if flag.Changed {
+			continue
+		}
+
+		for _, v := range settingValues(value) {
+			if err := flag.Value.Set(v); err != nil {
+				return fmt.Errorf("invalid value for setting %s: %w", key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// settingValues flattens a setting into flag values. Lists repeat the flag and
+// maps become sorted key=value pairs.
+func settingValues(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case map[string]interface{}:
+		return mapSettingValues(v)
+	case config.Settings:
+		return mapSettingValues(v)
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func mapSettingValues(m map[string]interface{}) []string {
+	values := make([]string, 0, len(m))
+	for key, item := range m {
+		values = append(values, fmt.Sprintf("%s=%v", key, item))
+	}
+	sort.Strings(values)
+	return values
+}
+
+// specSource returns the spec path or URL given as argument, falling back to
+// the spec of the config file or the selected profile
+func specSource(cmd *cobra.Command, args []string) (string, error) {
+	entries, err := specEntries(cmd, args)
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) > 1 {
+		return "", fmt.Errorf("%s takes a single spec, the config file lists %d", cmd.CommandPath(), len(entries))
+	}
+	return entries[0].Source, nil
+}
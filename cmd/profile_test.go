@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newProfileTestCommand(t *testing.T) *cobra.Command {
+	t.Helper()
+
+	configPath := filepath.Join(t.TempDir(), "kumoctl.yaml")
+	content := `
+profiles:
+  billing:
+    spec: ./billing.json
+    timeout: 10s
+    headers:
+      Authorization: Bearer dev-token
+    environments:
+      staging:
+        base-url: https://staging.billing.example.com
+        spec: ./billing-staging.json
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cmd := &cobra.Command{Use: "serve"}
+	cmd.Flags().StringArray("headers", []string{}, "")
+	cmd.Flags().Duration("timeout", 30*time.Second, "")
+	cmd.Flags().String("base-url", "", "")
+	addProfileFlags(cmd)
+	if err := cmd.Flags().Set("config", configPath); err != nil {
+		t.Fatalf("Failed to set config flag: %v", err)
+	}
+	return cmd
+}
+
+func TestApplyProfile(t *testing.T) {
+	cmd := newProfileTestCommand(t)
+	cmd.Flags().Set("profile", "billing")
+	cmd.Flags().Set("env", "staging")
+	cmd.Flags().Set("timeout", "5s")
+
+	if err := applyProfile(cmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	headers, _ := cmd.Flags().GetStringArray("headers")
+	if !reflect.DeepEqual(headers, []string{"Authorization=Bearer dev-token"}) {
+		t.Errorf("Expected headers from profile, got %v", headers)
+	}
+
+	baseURL, _ := cmd.Flags().GetString("base-url")
+	if baseURL != "https://staging.billing.example.com" {
+		t.Errorf("Expected base URL from environment, got %s", baseURL)
+	}
+
+	// Flags given on the command line win over the profile
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout != 5*time.Second {
+		t.Errorf("Expected command line timeout, got %s", timeout)
+	}
+
+	source, err := specSource(cmd, nil)
+	if err != nil || source != "./billing-staging.json" {
+		t.Errorf("Expected spec from environment, got %q (%v)", source, err)
+	}
+
+	if source, _ := specSource(cmd, []string{"./other.json"}); source != "./other.json" {
+		t.Errorf("Expected spec argument to win, got %q", source)
+	}
+}
+
+func TestApplyProfileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		env     string
+	}{
+		{name: "env without profile", env: "staging"},
+		{name: "unknown profile", profile: "payments"},
+		{name: "unknown environment", profile: "billing", env: "qa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newProfileTestCommand(t)
+			cmd.Flags().Set("profile", tt.profile)
+			cmd.Flags().Set("env", tt.env)
+
+			if err := applyProfile(cmd); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestApplyTopLevelSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "kumoctl.yaml")
+	content := `
+spec: ./openapi.json
+timeout: 10s
+rate-limit: 5/s
+profiles:
+  billing:
+    transcript: ./calls.jsonl
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cmd := &cobra.Command{Use: "list"}
+	cmd.Flags().Duration("timeout", 30*time.Second, "")
+	addProfileFlags(cmd)
+	cmd.Flags().Set("config", configPath)
+
+	// Top-level settings for flags the command lacks are skipped
+	if err := applyProfile(cmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout != 10*time.Second {
+		t.Errorf("Expected timeout from the config file, got %s", timeout)
+	}
+
+	if source, err := specSource(cmd, nil); err != nil || source != "./openapi.json" {
+		t.Errorf("Expected spec from the config file, got %q (%v)", source, err)
+	}
+
+	// Profile settings must name a flag of the command
+	cmd.Flags().Set("profile", "billing")
+	if err := applyProfile(cmd); err == nil {
+		t.Error("Expected error for a profile setting the command doesn't know")
+	}
+}
+
+func TestProfileFromEnvironment(t *testing.T) {
+	t.Setenv(profileEnv, "billing")
+
+	cmd := newProfileTestCommand(t)
+	if err := applyProfile(cmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if source, err := specSource(cmd, nil); err != nil || source != "./billing.json" {
+		t.Errorf("Expected spec of the profile from $%s, got %q (%v)", profileEnv, source, err)
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "kumoctl.yaml")
+	content := `
+spec: ./openapi.json
+profiles:
+  staging:
+    base-url: https://staging.example.com
+  prod:
+    base-url: https://api.example.com
+    environments:
+      eu: {base-url: https://eu.api.example.com}
+      us: {base-url: https://us.api.example.com}
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	listProfilesCmd.SetOut(&out)
+	listProfilesCmd.Flags().Set("config", configPath)
+	defer listProfilesCmd.Flags().Set("config", "")
+
+	if err := listProfilesCmd.RunE(listProfilesCmd, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	var rows []string
+	for _, line := range lines {
+		if strings.Contains(line, "example.com") {
+			rows = append(rows, strings.Join(strings.Fields(strings.ReplaceAll(line, "|", " ")), " "))
+		}
+	}
+
+	expected := []string{
+		"prod ./openapi.json https://api.example.com eu, us",
+		"staging ./openapi.json https://staging.example.com",
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected rows %q, got %q", expected, rows)
+	}
+}
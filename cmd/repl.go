@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var replCmd = &cobra.Command{
+	Use:   "repl [spec-path-or-url]",
+	Short: "Browse and call the tools of a spec interactively",
+	Long: `Start an interactive session to browse the tools generated from a spec, fill in
+their inputs when prompted and send calls, printing the API responses.
+
+Tool names can be abbreviated to any unambiguous prefix. Type "help" in the
+session for the available commands.`,
+	Example: "  kumoctl repl ./spec.json\n  kumoctl repl https://api.example.com/openapi.json --auth-token $TOKEN",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runREPLCommand,
+}
+
+const replHelp = `Commands:
+  tools [text]          list the tools, optionally those matching text
+  describe <tool>       show the route, description and inputs of a tool
+  call <tool> [json]    call a tool with a JSON object, or prompt for its inputs
+  help                  show this help
+  exit                  end the session
+`
+
+func runREPLCommand(cmd *cobra.Command, args []string) error {
+	source := args[0]
+
+	if !isURL(source) {
+		if _, err := os.Stat(source); os.IsNotExist(err) {
+			return fmt.Errorf("file does not exist: %s", source)
+		}
+	}
+
+	openapiSpec, err := loadSpec(cmd, source)
+	if err != nil {
+		return err
+	}
+
+	toolOptions, err := callOptionsFromFlags(cmd, openapiSpec)
+	if err != nil {
+		return err
+	}
+	if toolOptions.Filter, err = toolFilterFromFlags(cmd); err != nil {
+		return err
+	}
+
+	tools, err := kumo_mcp.PrepareTools(openapiSpec, toolOptions)
+	if err != nil {
+		return err
+	}
+
+	session := &replSession{
+		ctx:   cmd.Context(),
+		in:    bufio.NewScanner(cmd.InOrStdin()),
+		out:   cmd.OutOrStdout(),
+		tools: tools,
+		opts:  toolOptions,
+	}
+	fmt.Fprintf(session.out, "%d tools loaded from %s, type \"help\" for commands\n", len(tools), source)
+	return session.run()
+}
+
+// replSession reads commands and tool inputs line by line until exit or the
+// end of the input
+type replSession struct {
+	ctx   context.Context
+	in    *bufio.Scanner
+	out   io.Writer
+	tools []*kumo_mcp.EnrichedTool
+	opts  *kumo_mcp.ToolOptions
+}
+
+func (s *replSession) run() error {
+	for {
+		line, ok := s.prompt("kumoctl> ")
+		if !ok {
+			return s.in.Err()
+		}
+
+		command, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+		rest = strings.TrimSpace(rest)
+
+		switch command {
+		case "":
+		case "exit", "quit":
+			return nil
+		case "help", "?":
+			fmt.Fprint(s.out, replHelp)
+		case "tools", "ls":
+			s.listTools(rest)
+		case "describe", "desc":
+			if tool := s.resolveTool(rest); tool != nil {
+				s.describeTool(tool)
+			}
+		case "call":
+			name, input, _ := strings.Cut(rest, " ")
+			if tool := s.resolveTool(name); tool != nil {
+				s.callTool(tool, strings.TrimSpace(input))
+			}
+		default:
+			fmt.Fprintf(s.out, "unknown command %q, type \"help\" for commands\n", command)
+		}
+	}
+}
+
+// prompt writes the prompt and reads the next line, reporting false at the
+// end of the input
+func (s *replSession) prompt(prompt string) (string, bool) {
+	fmt.Fprint(s.out, prompt)
+	if !s.in.Scan() {
+		fmt.Fprintln(s.out)
+		return "", false
+	}
+	return s.in.Text(), true
+}
+
+// resolveTool finds a tool by its name or an unambiguous prefix of it,
+// listing the candidates otherwise
+func (s *replSession) resolveTool(name string) *kumo_mcp.EnrichedTool {
+	if name == "" {
+		fmt.Fprintln(s.out, "missing tool name")
+		return nil
+	}
+
+	var candidates []*kumo_mcp.EnrichedTool
+	for _, tool := range s.tools {
+		if tool.Name == name {
+			return tool
+		}
+		if strings.HasPrefix(tool.Name, name) {
+			candidates = append(candidates, tool)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		fmt.Fprintf(s.out, "unknown tool: %s\n", name)
+		return nil
+	case 1:
+		return candidates[0]
+	}
+
+	names := make([]string, len(candidates))
+	for i, tool := range candidates {
+		names[i] = tool.Name
+	}
+	fmt.Fprintf(s.out, "ambiguous tool %s: %s\n", name, strings.Join(names, ", "))
+	return nil
+}
+
+func (s *replSession) listTools(text string) {
+	text = strings.ToLower(text)
+	for _, tool := range s.tools {
+		if text != "" && !strings.Contains(strings.ToLower(tool.Name+" "+tool.Description), text) {
+			continue
+		}
+		summary, _, _ := strings.Cut(tool.Description, "\n")
+		fmt.Fprintf(s.out, "%-30s %s\n", tool.Name, summary)
+	}
+}
+
+func (s *replSession) describeTool(tool *kumo_mcp.EnrichedTool) {
+	fmt.Fprintf(s.out, "%s\n%s %s\n", tool.Name, strings.ToUpper(tool.Method), tool.Path)
+	if tool.Description != "" {
+		fmt.Fprintf(s.out, "\n%s\n", strings.TrimSpace(tool.Description))
+	}
+	if inputs := inputSummary(tool.InputSchema); inputs != "" {
+		fmt.Fprintf(s.out, "\nInputs (* required):\n  %s\n", strings.ReplaceAll(inputs, "\n", "\n  "))
+	}
+}
+
+// callTool sends a call with the given JSON input, prompting for the inputs
+// when there is none, and prints the response
+func (s *replSession) callTool(tool *kumo_mcp.EnrichedTool, rawInput string) {
+	var input kumo_mcp.APIToolInput
+	if rawInput != "" {
+		if err := json.Unmarshal([]byte(rawInput), &input); err != nil {
+			fmt.Fprintf(s.out, "invalid input, expected a JSON object: %v\n", err)
+			return
+		}
+	} else {
+		var ok bool
+		if input, ok = s.promptInputs(tool.InputSchema); !ok {
+			return
+		}
+	}
+
+	output, err := kumo_mcp.CallTool(s.ctx, tool, input, s.opts)
+	if err != nil {
+		fmt.Fprintf(s.out, "error: %v\n", err)
+		return
+	}
+	if err := writeToolOutput(s.out, output); err != nil {
+		fmt.Fprintf(s.out, "error: %v\n", err)
+		return
+	}
+	if output.Error != "" {
+		fmt.Fprintf(s.out, "error: %s\n", output.Error)
+	}
+}
+
+// promptInputs asks for every input of a schema, required ones first. Empty
+// answers skip optional inputs and are asked again for required ones.
+func (s *replSession) promptInputs(schema *jsonschema.Schema) (kumo_mcp.APIToolInput, bool) {
+	input := make(kumo_mcp.APIToolInput)
+	for _, field := range promptFields(schema) {
+		label := field.name
+		if field.required {
+			label += "*"
+		}
+		label += " (" + field.typeName + ")"
+		if field.allowed != "" {
+			label += " [" + field.allowed + "]"
+		}
+
+		for {
+			line, ok := s.prompt("  " + label + ": ")
+			if !ok {
+				return nil, false
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				if field.required {
+					continue
+				}
+				break
+			}
+			input[field.name] = parseREPLValue(line, field.schema)
+			break
+		}
+	}
+	return input, true
+}
+
+// promptField is an input of a tool as asked for by promptInputs
+type promptField struct {
+	name     string
+	typeName string
+	required bool
+	allowed  string
+	schema   *jsonschema.Schema
+}
+
+// promptFields lists the inputs of a schema, required ones first, each
+// group sorted by name
+func promptFields(schema *jsonschema.Schema) []promptField {
+	if schema == nil {
+		return nil
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	fields := make([]promptField, 0, len(schema.Properties))
+	for name, property := range schema.Properties {
+		field := promptField{name: name, typeName: "any", required: required[name], schema: property}
+		if property.Type != "" {
+			field.typeName = property.Type
+		} else if len(property.Types) > 0 {
+			field.typeName = strings.Join(property.Types, "|")
+		}
+		if len(property.Enum) > 0 {
+			values := make([]string, len(property.Enum))
+			for i, value := range property.Enum {
+				values[i] = fmt.Sprint(value)
+			}
+			field.allowed = strings.Join(values, ", ")
+		}
+		fields = append(fields, field)
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].required != fields[j].required {
+			return fields[i].required
+		}
+		return fields[i].name < fields[j].name
+	})
+	return fields
+}
+
+// parseREPLValue reads an answer as JSON, so numbers, booleans, arrays and
+// objects can be typed as such, keeping it as text for string inputs or when
+// it is not valid JSON
+func parseREPLValue(line string, schema *jsonschema.Schema) interface{} {
+	if schema != nil && schema.Type == "string" {
+		return line
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(line), &value); err != nil {
+		return line
+	}
+	return value
+}
+
+func init() {
+	addCallFlags(replCmd)
+	addFilterFlags(replCmd)
+	rootCmd.AddCommand(replCmd)
+}
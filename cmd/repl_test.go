@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestREPLSession(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path, "query": r.URL.RawQuery})
+	}))
+	defer upstream.Close()
+
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Users API", "version": "1.0.0"},
+  "servers": [{"url": "` + upstream.URL + `"}],
+  "paths": {
+    "/users": {
+      "get": {
+        "operationId": "listUsers",
+        "summary": "List users",
+        "parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer"}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/users/{userId}": {
+      "get": {
+        "operationId": "getUser",
+        "summary": "Get a user",
+        "parameters": [{"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	openapiSpec, err := openapi.LoadSpec([]byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	opts := &kumo_mcp.ToolOptions{}
+	tools, err := kumo_mcp.PrepareTools(openapiSpec, opts)
+	if err != nil {
+		t.Fatalf("Failed to prepare tools: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		expectOut []string
+	}{
+		{
+			name:      "list tools",
+			input:     "tools\n",
+			expectOut: []string{"getUser", "listUsers"},
+		},
+		{
+			name:      "filter tools",
+			input:     "tools list\n",
+			expectOut: []string{"listUsers"},
+		},
+		{
+			name:      "describe by prefix",
+			input:     "describe getU\n",
+			expectOut: []string{"GET /users/{userId}", "userId* (string)"},
+		},
+		{
+			name:      "unknown tool",
+			input:     "describe users\n",
+			expectOut: []string{"unknown tool: users"},
+		},
+		{
+			name:      "call with JSON",
+			input:     `call getUser {"userId": "42"}` + "\n",
+			expectOut: []string{"200 OK", `"path": "/users/42"`},
+		},
+		{
+			name:      "call with prompts",
+			input:     "call listUsers\n5\n",
+			expectOut: []string{"limit (integer): ", `"query": "limit=5"`},
+		},
+		{
+			name:      "required input asked again",
+			input:     "call getUser\n\n7\n",
+			expectOut: []string{"userId* (string): ", `"path": "/users/7"`},
+		},
+		{
+			name:      "failed call keeps the session",
+			input:     "call getUser {}\nhelp\n",
+			expectOut: []string{"userId: is required", "Commands:"},
+		},
+		{
+			name:      "unknown command",
+			input:     "fetch\nexit\ntools\n",
+			expectOut: []string{`unknown command "fetch"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			session := &replSession{
+				ctx:   context.Background(),
+				in:    bufio.NewScanner(strings.NewReader(tt.input)),
+				out:   &out,
+				tools: tools,
+				opts:  opts,
+			}
+			if err := session.run(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expectOut {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,467 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// basicAuthEnv holds user:pass credentials when --basic-auth isn't given
+const basicAuthEnv = "KUMOCTL_BASIC_AUTH"
+
+// headerEnvPrefix starts the environment variables holding headers, e.g.
+// KUMOCTL_HEADER_X_API_KEY for X-Api-Key, so MCP client configs can pass
+// secrets through their env field instead of the arguments
+const headerEnvPrefix = "KUMOCTL_HEADER_"
+
+// addRequestFlags registers the flags shaping the requests tools send, shared
+// by every command that builds tool requests
+func addRequestFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("headers", []string{}, "headers to inject on requests in the form of key=value")
+	cmd.Flags().StringArray("api-key", []string{}, "value of an apiKey security scheme sent in the query string in the form of scheme=value")
+	cmd.Flags().String("basic-auth", "", "credentials in the form of user:pass sent as HTTP basic auth (default $KUMOCTL_BASIC_AUTH)")
+	cmd.Flags().String("base-url", "", "call the API at this base URL instead of the one declared in the spec")
+	cmd.Flags().Int("server-index", -1, "call the API at the server with this index in the spec's servers list (see list servers)")
+	cmd.Flags().String("server-url", "", "call the API at this server, which must be one of the spec's servers")
+	cmd.Flags().StringArray("server-var", []string{}, "value of a server URL variable in the form of name=value (default from the spec)")
+	cmd.Flags().StringArray("host-var", []string{}, "base URL host variable filled from tool input in the form of name or name=pattern")
+	cmd.Flags().StringArray("tag-headers", []string{}, "headers to inject on the operations of a tag in the form of tag:key=value")
+	cmd.Flags().StringArray("elevated-headers", []string{}, "headers for the operations of a tag that only apply with --elevate, in the form of tag:key=value")
+	cmd.Flags().StringSlice("elevate", []string{}, "tags whose --elevated-headers are sent")
+	cmd.Flags().Bool("flatten-body", false, "give body fields named like a parameter the parameter's input instead of body.<name>, the one value filling both")
+	addSigningFlags(cmd)
+}
+
+// requestOptionsFromFlags builds the tool options from the flags registered by
+// addRequestFlags
+func requestOptionsFromFlags(cmd *cobra.Command, spec openapi.APISpec) (*kumo_mcp.ToolOptions, error) {
+	headers, err := cmd.Flags().GetStringArray("headers")
+	if err != nil {
+		return nil, err
+	}
+
+	parsedHeaders, err := parseHeaders(append(headers, envHeaders(os.Environ(), headers)...))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyBasicAuth(cmd, parsedHeaders); err != nil {
+		return nil, err
+	}
+
+	staticHeaders, headerTemplates, err := kumo_mcp.SplitHeaderTemplates(parsedHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, err := serverFromFlags(cmd, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	toolOptions := &kumo_mcp.ToolOptions{
+		BaseURL:         baseURL,
+		Headers:         staticHeaders,
+		HeaderTemplates: headerTemplates,
+	}
+
+	toolOptions.Signer, err = signerFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	toolOptions.QueryParams, err = apiKeyQueryParams(cmd, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	hostVars, err := cmd.Flags().GetStringArray("host-var")
+	if err != nil {
+		return nil, err
+	}
+
+	toolOptions.HostVariables, err = parseHostVariables(hostVars)
+	if err != nil {
+		return nil, err
+	}
+
+	toolOptions.TagHeaders, err = tagHeadersFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if toolOptions.FlattenBody, err = cmd.Flags().GetBool("flatten-body"); err != nil {
+		return nil, err
+	}
+
+	return toolOptions, nil
+}
+
+// serverFromFlags returns the base URL chosen by --base-url, --server-index or
+// --server-url with the --server-var values substituted, or an empty string to
+// use the spec's base URL
+func serverFromFlags(cmd *cobra.Command, spec openapi.APISpec) (string, error) {
+	baseURL, err := cmd.Flags().GetString("base-url")
+	if err != nil {
+		return "", err
+	}
+
+	index, err := cmd.Flags().GetInt("server-index")
+	if err != nil {
+		return "", err
+	}
+
+	serverURL, err := cmd.Flags().GetString("server-url")
+	if err != nil {
+		return "", err
+	}
+
+	selected := 0
+	for _, set := range []bool{baseURL != "", index >= 0, serverURL != ""} {
+		if set {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return "", fmt.Errorf("only one of --base-url, --server-index and --server-url can be used")
+	}
+
+	if baseURL != "" {
+		return baseURL, nil
+	}
+
+	serverVars, err := cmd.Flags().GetStringArray("server-var")
+	if err != nil {
+		return "", err
+	}
+
+	values, err := parseServerVariables(serverVars)
+	if err != nil {
+		return "", err
+	}
+
+	hostVars, err := cmd.Flags().GetStringArray("host-var")
+	if err != nil {
+		return "", err
+	}
+
+	servers := spec.GetServers()
+	server, err := selectServer(servers, index, serverURL)
+	if err != nil {
+		return "", err
+	}
+
+	explicit := server != nil
+	if server == nil {
+		if len(servers) == 0 {
+			if len(values) > 0 {
+				return "", fmt.Errorf("--server-var is set but the spec declares no servers")
+			}
+			return "", nil
+		}
+		server = &servers[0]
+	}
+
+	// Host variables are filled from tool input, so keep their placeholders
+	for _, v := range hostVars {
+		name, _, _ := strings.Cut(v, "=")
+		delete(server.Variables, strings.TrimSpace(name))
+	}
+
+	resolved, err := server.ResolveURL(values)
+	if err != nil {
+		return "", err
+	}
+
+	if !explicit && resolved == spec.GetBaseURL() {
+		return "", nil
+	}
+	return resolved, nil
+}
+
+// selectServer returns the server at index, or the server matching url. A
+// negative index and an empty url select nothing.
+func selectServer(servers []openapi.Server, index int, url string) (*openapi.Server, error) {
+	if index >= 0 {
+		if index >= len(servers) {
+			return nil, fmt.Errorf("invalid --server-index %d, the spec declares %d servers", index, len(servers))
+		}
+		return &servers[index], nil
+	}
+
+	if url == "" {
+		return nil, nil
+	}
+
+	for i, server := range servers {
+		if strings.TrimSuffix(server.URL, "/") == strings.TrimSuffix(url, "/") {
+			return &servers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("server %s is not declared in the spec (use --base-url for other servers)", url)
+}
+
+// parseServerVariables parses --server-var values in the form of name=value
+func parseServerVariables(serverVarStrings []string) (map[string]string, error) {
+	values := make(map[string]string, len(serverVarStrings))
+	for _, v := range serverVarStrings {
+		name, value, found := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid server variable format: %s (expected 'name=value')", v)
+		}
+		values[name] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
+
+// tagHeadersFromFlags returns the headers of --tag-headers with the
+// --elevated-headers of the tags given to --elevate applied on top. Elevated
+// headers of other tags are never resolved, so their secrets need not be set.
+func tagHeadersFromFlags(cmd *cobra.Command) (map[string]http.Header, error) {
+	defaults, err := cmd.Flags().GetStringArray("tag-headers")
+	if err != nil {
+		return nil, err
+	}
+
+	elevated, err := cmd.Flags().GetStringArray("elevated-headers")
+	if err != nil {
+		return nil, err
+	}
+
+	elevate, err := cmd.Flags().GetStringSlice("elevate")
+	if err != nil {
+		return nil, err
+	}
+
+	tagHeaders, err := parseTagHeaders(defaults, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[string]bool, len(elevate))
+	for _, tag := range elevate {
+		enabled[tag] = true
+	}
+
+	elevatedHeaders, err := parseTagHeaders(elevated, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range elevate {
+		headers, ok := elevatedHeaders[tag]
+		if !ok {
+			return nil, fmt.Errorf("--elevate %s: no --elevated-headers are configured for this tag", tag)
+		}
+
+		if tagHeaders[tag] == nil {
+			tagHeaders[tag] = make(http.Header)
+		}
+		for key, values := range headers {
+			tagHeaders[tag][key] = values
+		}
+	}
+
+	return tagHeaders, nil
+}
+
+// parseTagHeaders parses tag:key=value entries, skipping tags missing from
+// only unless it is nil
+func parseTagHeaders(entries []string, only map[string]bool) (map[string]http.Header, error) {
+	grouped := make(map[string][]string)
+	for _, entry := range entries {
+		tag, header, found := strings.Cut(entry, ":")
+		tag = strings.TrimSpace(tag)
+		if !found || tag == "" {
+			return nil, fmt.Errorf("invalid tag header format: %s (expected 'tag:key=value')", entry)
+		}
+		if only != nil && !only[tag] {
+			continue
+		}
+		grouped[tag] = append(grouped[tag], header)
+	}
+
+	tagHeaders := make(map[string]http.Header, len(grouped))
+	for tag, headers := range grouped {
+		parsed, err := parseHeaders(headers)
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", tag, err)
+		}
+		tagHeaders[tag] = parsed
+	}
+	return tagHeaders, nil
+}
+
+func parseHeaders(headerStrings []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, h := range headerStrings {
+		parts := strings.SplitN(h, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid header format: %s (expected 'key=value')", h)
+		}
+		key := strings.TrimSpace(parts[0])
+		value, err := resolveHeaderValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for header %s: %w", key, err)
+		}
+		headers.Add(key, value)
+	}
+	return headers, nil
+}
+
+// headerEnvName returns the environment variable holding a header. A _ in
+// the name is written as __ so it is read back as _, any other character
+// that can't appear in a variable name as _, read back as -.
+func headerEnvName(key string) string {
+	var name strings.Builder
+	for _, r := range strings.TrimSpace(key) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9':
+			name.WriteRune(r)
+		case r == '_':
+			name.WriteString("__")
+		default:
+			name.WriteByte('_')
+		}
+	}
+	return headerEnvPrefix + strings.ToUpper(name.String())
+}
+
+// headerFromEnvName returns the header held by the variable name suffix, with
+// __ read as _ and _ as -
+func headerFromEnvName(suffix string) string {
+	var key strings.Builder
+	for i := 0; i < len(suffix); i++ {
+		switch {
+		case strings.HasPrefix(suffix[i:], "__"):
+			key.WriteByte('_')
+			i++
+		case suffix[i] == '_':
+			key.WriteByte('-')
+		default:
+			key.WriteByte(suffix[i])
+		}
+	}
+	return http.CanonicalHeaderKey(key.String())
+}
+
+// envHeaders returns the headers held by KUMOCTL_HEADER_* variables of the
+// environment as key=value, with __ read as _ and _ as -. Headers given as
+// flags win.
+func envHeaders(environ []string, flagHeaders []string) []string {
+	given := make(map[string]bool, len(flagHeaders))
+	for _, header := range flagHeaders {
+		key, _, _ := strings.Cut(header, "=")
+		given[http.CanonicalHeaderKey(strings.TrimSpace(key))] = true
+	}
+
+	var headers []string
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		suffix, ok := strings.CutPrefix(name, headerEnvPrefix)
+		if !ok || suffix == "" {
+			continue
+		}
+		key := headerFromEnvName(suffix)
+		if !given[key] {
+			headers = append(headers, key+"="+value)
+		}
+	}
+	sort.Strings(headers)
+	return headers
+}
+
+// headerEnvRegex matches ${NAME} references in header values
+var headerEnvRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveHeaderValue reads secrets referenced by a header value from the
+// environment, either the whole value as env:NAME or ${NAME} expansions, so
+// they never have to be written into MCP client configs
+func resolveHeaderValue(value string) (string, error) {
+	if name, ok := strings.CutPrefix(value, "env:"); ok {
+		resolved, found := os.LookupEnv(name)
+		if !found {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return resolved, nil
+	}
+
+	var missing []string
+	resolved := headerEnvRegex.ReplaceAllStringFunc(value, func(match string) string {
+		name := match[2 : len(match)-1]
+		resolved, found := os.LookupEnv(name)
+		if !found {
+			missing = append(missing, name)
+		}
+		return resolved
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variables not set: %s", strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// applyBasicAuth sets the Authorization header from --basic-auth, or from
+// KUMOCTL_BASIC_AUTH when the flag isn't given
+func applyBasicAuth(cmd *cobra.Command, headers http.Header) error {
+	credentials, err := cmd.Flags().GetString("basic-auth")
+	if err != nil {
+		return err
+	}
+
+	if credentials == "" {
+		credentials = os.Getenv(basicAuthEnv)
+	}
+
+	if credentials == "" {
+		return nil
+	}
+
+	authorization, err := basicAuthorization(credentials)
+	if err != nil {
+		return err
+	}
+
+	if headers.Get("Authorization") != "" {
+		return fmt.Errorf("basic auth conflicts with the Authorization header given in --headers")
+	}
+
+	headers.Set("Authorization", authorization)
+	return nil
+}
+
+// basicAuthorization encodes user:pass credentials as an Authorization value
+func basicAuthorization(credentials string) (string, error) {
+	user, _, found := strings.Cut(credentials, ":")
+	if !found || user == "" {
+		return "", fmt.Errorf("invalid basic auth format (expected 'user:pass')")
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)), nil
+}
+
+func parseHostVariables(hostVarStrings []string) (map[string]*kumo_mcp.HostVariable, error) {
+	hostVars := make(map[string]*kumo_mcp.HostVariable)
+	for _, v := range hostVarStrings {
+		name, pattern, _ := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid host variable format: %s (expected 'name' or 'name=pattern')", v)
+		}
+		hostVar, err := kumo_mcp.NewHostVariable(name, strings.TrimSpace(pattern))
+		if err != nil {
+			return nil, err
+		}
+		hostVars[name] = hostVar
+	}
+	return hostVars, nil
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
 	"github.com/kumolabai/kumoctl/pkg/openapi"
@@ -13,92 +18,690 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// preflightTimeout bounds the connectivity check performed before serving
+const preflightTimeout = 10 * time.Second
+
 var serveCmd = &cobra.Command{
-	Use:     "serve [spec-path-or-url]",
+	Use:     "serve [spec-path-or-url...]",
 	Short:   "Start MCP Server from OpenAPI Spec",
-	Example: "  kumoctl serve ./spec.json --headers \"Authorization=Basic <creds>\"\n  kumoctl serve https://api.example.com/openapi.json --headers \"Authorization=Bearer token\"",
-	Args:    verifySpecSource,
+	Example: "  kumoctl serve ./spec.json --headers \"Authorization=Basic <creds>\"\n  kumoctl serve https://api.example.com/openapi.json --headers \"Authorization=Bearer token\"\n  kumoctl serve ./users.json ./orders.yaml\n  kumoctl serve --profile billing --env staging",
+	Args:    verifySpecSources,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		source := args[0]
-		openapiSpec, err := openapi.LoadSpecFromSource(source)
+		specs, err := specEntries(cmd, args)
 		if err != nil {
 			return err
 		}
 
-		headers, err := cmd.Flags().GetStringArray("headers")
+		// Run the server over stdin/stdout, until the client disconnects
+		return runServer(cmd, specs, &mcp.StdioTransport{})
+	},
+}
+
+// loadedSpec is a spec to serve with the options of its tools
+type loadedSpec struct {
+	specEntry
+	spec openapi.APISpec
+	opts *kumo_mcp.ToolOptions
+	// registry keeps the spec's tools in sync when it is reloaded
+	registry *kumo_mcp.ToolRegistry
+	// prompts are the names of the spec's tag prompts
+	prompts []string
+}
+
+// runServer serves the tools generated from the specs over the given
+// transport. Stdout belongs to the MCP stream, so every human-readable message
+// goes to stderr.
+func runServer(cmd *cobra.Command, specs []specEntry, transport mcp.Transport) error {
+	cmd.SetOut(cmd.ErrOrStderr())
+
+	logger, closeLog, err := loggerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	withLogger(cmd, logger)
+
+	if err := warnInsecure(cmd); err != nil {
+		return err
+	}
+
+	loaded := make([]*loadedSpec, 0, len(specs))
+	for _, entry := range specs {
+		openapiSpec, err := loadSpec(cmd, entry.Source)
 		if err != nil {
 			return err
 		}
+		loaded = append(loaded, &loadedSpec{specEntry: entry, spec: openapiSpec})
+	}
 
-		parsedHeaders, err := parseHeaders(headers)
+	if len(loaded) > 1 {
+		index, err := cmd.Flags().GetInt("server-index")
 		if err != nil {
 			return err
 		}
+		serverURL, err := cmd.Flags().GetString("server-url")
+		if err != nil {
+			return err
+		}
+		if index >= 0 || serverURL != "" {
+			return fmt.Errorf("--server-index and --server-url select a server of a single spec, set base-url per spec in the config file instead")
+		}
+	}
+
+	// Limits are shared, the credentials of every spec are resolved against
+	// it in specToolOptions
+	toolOptions, err := toolOptionsFromFlags(cmd, loaded[0].spec)
+	if err != nil && len(loaded) > 1 {
+		return fmt.Errorf("spec %s: %w", loaded[0].Name, err)
+	}
+	if err != nil {
+		return err
+	}
+	toolOptions.Logger = logger
+
+	tracerProvider, err := tracerProviderFromEnv(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if tracerProvider != nil {
+		defer shutdownTracing(tracerProvider, logger)
+		toolOptions.TracerProvider = tracerProvider
+		logger.Info("exporting traces over OTLP")
+	}
+
+	transcriptPath, err := cmd.Flags().GetString("transcript")
+	if err != nil {
+		return err
+	}
 
-		serverName := "kumolab-mcp-server"
-		serverTitle := "KumoLab.ai MCP Server"
-		version := "v0.0.1"
+	sources := make([]string, 0, len(loaded))
+	for _, l := range loaded {
+		sources = append(sources, l.Source)
+	}
+	source := strings.Join(sources, ", ")
 
-		if openapiSpec.GetInfo().Title != "" {
-			serverTitle = openapiSpec.GetInfo().Title
+	if transcriptPath != "" {
+		toolOptions.Transcript, err = kumo_mcp.NewTranscript(transcriptPath, source)
+		if err != nil {
+			return err
 		}
+		defer toolOptions.Transcript.Close()
+	}
+
+	// The authenticators of every spec are built on the plain client, so
+	// token requests skip the cassette and the host guard
+	if err := specToolOptions(cmd, loaded, toolOptions); err != nil {
+		return err
+	}
+
+	specOptions := []*kumo_mcp.ToolOptions{toolOptions}
+	for _, l := range loaded {
+		specOptions = append(specOptions, l.opts)
+	}
+	if err := applyCassetteFlags(cmd, specOptions...); err != nil {
+		return err
+	}
+
+	// Tool calls of every spec share the client, so it allows all their hosts
+	apiSpecs := make([]openapi.APISpec, 0, len(loaded))
+	baseURLs := []string{toolOptions.BaseURL}
+	for _, l := range loaded {
+		apiSpecs = append(apiSpecs, l.spec)
+		baseURLs = append(baseURLs, l.BaseURL)
+	}
+	if err := applyHostGuard(cmd, apiSpecs, baseURLs, toolOptions); err != nil {
+		return err
+	}
+	for _, l := range loaded {
+		l.opts.HTTPClient = toolOptions.HTTPClient
+	}
+
+	skipPreflight, err := cmd.Flags().GetBool("skip-preflight")
+	if err != nil {
+		return err
+	}
+
+	replay, err := cmd.Flags().GetString("replay")
+	if err != nil {
+		return err
+	}
 
-		if openapiSpec.GetVersion() != "" {
-			version = openapiSpec.GetVersion()
+	// Replayed and dry run calls never reach the API
+	if replay != "" || toolOptions.DryRun {
+		skipPreflight = true
+	}
+
+	if toolOptions.DryRun {
+		logger.Info("dry run: tool calls return the request they would send instead of sending it")
+	}
+
+	// Establish the sessions up front, so bad credentials fail the startup
+	for _, l := range loaded {
+		if session, ok := l.opts.Auth.(*kumo_mcp.SessionAuthenticator); ok && !toolOptions.DryRun {
+			ctx, cancel := context.WithTimeout(cmd.Context(), preflightTimeout)
+			err := session.Login(ctx)
+			cancel()
+			if err != nil {
+				return err
+			}
 		}
+	}
 
-		server := mcp.NewServer(&mcp.Implementation{Name: serverName, Title: serverTitle, Version: version}, nil)
+	if !skipPreflight {
+		for _, l := range loaded {
+			baseURL := l.spec.GetBaseURL()
+			if l.opts.BaseURL != "" {
+				baseURL = l.opts.BaseURL
+			}
 
-		// Dynamically generate tools from OpenAPI paths
-		if err := kumo_mcp.GenerateToolsFromSpec(server, openapiSpec, parsedHeaders); err != nil {
-			return fmt.Errorf("failed to generate tools from OpenAPI spec: %w", err)
+			ctx, cancel := context.WithTimeout(cmd.Context(), preflightTimeout)
+			err := kumo_mcp.Preflight(ctx, baseURL, l.opts)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("%w (use --skip-preflight to start anyway)", err)
+			}
 		}
+	}
 
-		// Run the server over stdin/stdout, until the client disconnects
-		if err := server.Run(cmd.Context(), &mcp.StdioTransport{}); err != nil {
-			log.Fatal(err)
+	serverName := "kumolab-mcp-server"
+	serverTitle := "KumoLab.ai MCP Server"
+	serverVersion := "v0.0.1"
+
+	if len(loaded) == 1 {
+		if loaded[0].spec.GetInfo().Title != "" {
+			serverTitle = loaded[0].spec.GetInfo().Title
+		}
+
+		if loaded[0].spec.GetVersion() != "" {
+			serverVersion = loaded[0].spec.GetVersion()
+		}
+	} else {
+		titles := make([]string, 0, len(loaded))
+		for _, l := range loaded {
+			title := l.spec.GetInfo().Title
+			if title == "" {
+				title = l.Name
+			}
+			titles = append(titles, title)
+		}
+		serverTitle = strings.Join(titles, " + ")
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: serverName, Title: serverTitle, Version: serverVersion}, nil)
+	kumo_mcp.AddCapabilityNegotiation(server, toolOptions)
+
+	manifest := &serverManifest{
+		Kumoctl:   version,
+		Name:      serverName,
+		Title:     serverTitle,
+		Version:   serverVersion,
+		Transport: transportName(transport),
+		StartedAt: time.Now().UTC(),
+		Filters:   manifestFiltersFor(toolOptions.Filter),
+		Auth:      authModes(toolOptions),
+	}
+
+	// Dynamically generate tools from OpenAPI paths
+	var toolNames []string
+	for _, l := range loaded {
+		l.registry = kumo_mcp.NewToolRegistry(server, l.opts)
+		summary, err := l.registry.Sync(l.spec)
+		if err != nil {
+			return fmt.Errorf("failed to generate tools from OpenAPI spec %s: %w", l.Source, err)
+		}
+		toolNames = append(toolNames, summary.Added...)
+
+		if err := manifest.addManifestSpec(l, len(summary.Added)); err != nil {
+			return err
+		}
+
+		if err := exposeSpec(server, l, len(loaded) > 1); err != nil {
+			return err
+		}
+	}
+
+	if err := verifyOperationTimeouts(toolOptions.OperationTimeouts, toolNames); err != nil {
+		return err
+	}
+
+	manifestPath, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return err
+	}
+
+	if manifestPath != "" {
+		if err := writeManifest(manifestPath, manifest); err != nil {
+			return err
+		}
+	} else if data, err := json.Marshal(manifest); err == nil {
+		logger.Info("manifest", "manifest", string(data))
+	}
+
+	watch, err := cmd.Flags().GetBool("watch")
+	if err != nil {
+		return err
+	}
+
+	if watch {
+		interval, err := cmd.Flags().GetDuration("watch-interval")
+		if err != nil {
+			return err
+		}
+		if interval <= 0 {
+			return fmt.Errorf("--watch-interval must be positive")
+		}
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+		go watchSpecs(ctx, cmd, server, loaded, interval, logger)
+	}
+
+	logger.Info("serving", "title", serverTitle, "source", source)
+
+	if err := server.Run(cmd.Context(), transport); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("MCP server stopped: %w", err)
+	}
+
+	return nil
+}
+
+// exposeSpec registers the resources and tag prompts of a spec, replacing
+// those of its previous version
+func exposeSpec(server *mcp.Server, l *loadedSpec, multiple bool) error {
+	if err := kumo_mcp.AddSpecResources(server, l.Name, l.spec, l.opts); err != nil {
+		return fmt.Errorf("failed to expose OpenAPI spec %s as resources: %w", l.Source, err)
+	}
+
+	// Tags of different specs may share a name
+	promptPrefix := ""
+	if multiple {
+		promptPrefix = l.Name + "_"
+	}
+
+	prompts, err := kumo_mcp.AddTagPrompts(server, promptPrefix, l.spec, l.opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate prompts from OpenAPI spec %s: %w", l.Source, err)
+	}
+
+	var stale []string
+	for _, name := range l.prompts {
+		if !slices.Contains(prompts, name) {
+			stale = append(stale, name)
 		}
+	}
+	if len(stale) > 0 {
+		server.RemovePrompts(stale...)
+	}
+	l.prompts = prompts
+
+	return nil
+}
 
+// specToolOptions derives the tool options of every spec from the shared
+// ones, applying the spec's base URL and headers, resolving the credentials
+// against the spec's security schemes and prefixing the names of tools that
+// several specs generate
+func specToolOptions(cmd *cobra.Command, loaded []*loadedSpec, shared *kumo_mcp.ToolOptions) error {
+	if len(loaded) == 1 && loaded[0].BaseURL == "" && len(loaded[0].Headers) == 0 {
+		loaded[0].opts = shared
 		return nil
-	},
+	}
+
+	toolNames := make(map[string][]string, len(loaded))
+	for _, l := range loaded {
+		tools, err := kumo_mcp.GetToolsFromSpec(l.spec)
+		if err != nil {
+			return fmt.Errorf("failed to generate tools from OpenAPI spec %s: %w", l.Source, err)
+		}
+		for _, tool := range tools {
+			toolNames[l.Name] = append(toolNames[l.Name], tool.Name)
+		}
+	}
+	renames := kumo_mcp.DisambiguateToolNames(toolNames)
+
+	for _, l := range loaded {
+		opts := *shared
+		opts.ToolNames = renames[l.Name]
+
+		if l.BaseURL != "" {
+			opts.BaseURL = l.BaseURL
+		} else if len(loaded) > 1 {
+			// The shared base URL was resolved from the first spec's servers
+			baseURL, err := serverFromFlags(cmd, l.spec)
+			if err != nil {
+				return fmt.Errorf("spec %s: %w", l.Name, err)
+			}
+			opts.BaseURL = baseURL
+		}
+
+		if len(l.Headers) > 0 {
+			parsed, err := parseHeaders(l.Headers)
+			if err != nil {
+				return fmt.Errorf("spec %s: %w", l.Name, err)
+			}
+
+			static, templates, err := kumo_mcp.SplitHeaderTemplates(parsed)
+			if err != nil {
+				return fmt.Errorf("spec %s: %w", l.Name, err)
+			}
+
+			opts.Headers = shared.Headers.Clone()
+			if opts.Headers == nil {
+				opts.Headers = make(http.Header)
+			}
+			for key, values := range static {
+				opts.Headers[key] = values
+			}
+			opts.HeaderTemplates = append(append([]*kumo_mcp.HeaderTemplate{}, shared.HeaderTemplates...), templates...)
+		}
+
+		// The shared credentials were resolved against the first spec
+		if len(loaded) > 1 {
+			var err error
+			if opts.QueryParams, err = apiKeyQueryParams(cmd, l.spec); err != nil {
+				return fmt.Errorf("spec %s: %w", l.Name, err)
+			}
+			if opts.Auth, err = authFromFlags(cmd, l.spec, &opts); err != nil {
+				return fmt.Errorf("spec %s: %w", l.Name, err)
+			}
+		}
+
+		l.opts = &opts
+	}
+
+	return nil
 }
 
-func parseHeaders(headerStrings []string) (http.Header, error) {
-	headers := make(http.Header)
-	for _, h := range headerStrings {
-		parts := strings.SplitN(h, "=", 2)
+// schemaPruningFromFlags returns how to trim input schemas, or nil to keep them whole
+func schemaPruningFromFlags(cmd *cobra.Command) (*kumo_mcp.SchemaPruning, error) {
+	stripDescriptions, err := cmd.Flags().GetBool("strip-descriptions")
+	if err != nil {
+		return nil, err
+	}
+
+	stripExamples, err := cmd.Flags().GetBool("strip-examples")
+	if err != nil {
+		return nil, err
+	}
+
+	maxDepth, err := cmd.Flags().GetInt("max-schema-depth")
+	if err != nil {
+		return nil, err
+	}
+
+	if maxDepth < 0 {
+		return nil, fmt.Errorf("--max-schema-depth must not be negative")
+	}
+
+	if !stripDescriptions && !stripExamples && maxDepth == 0 {
+		return nil, nil
+	}
+
+	return &kumo_mcp.SchemaPruning{StripDescriptions: stripDescriptions, StripExamples: stripExamples, MaxDepth: maxDepth}, nil
+}
+
+// toolOptionsFromFlags builds the options shared by all generated tools
+func toolOptionsFromFlags(cmd *cobra.Command, spec openapi.APISpec) (*kumo_mcp.ToolOptions, error) {
+	toolOptions, err := requestOptionsFromFlags(cmd, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if toolOptions.Timeout, err = cmd.Flags().GetDuration("timeout"); err != nil {
+		return nil, err
+	}
+
+	if toolOptions.ToolPrefix, err = cmd.Flags().GetString("tool-prefix"); err != nil {
+		return nil, err
+	}
+
+	if toolOptions.PrefixToolsets, err = cmd.Flags().GetBool("prefix-toolsets"); err != nil {
+		return nil, err
+	}
+
+	if toolOptions.NameCase, err = cmd.Flags().GetString("tool-name-case"); err != nil {
+		return nil, err
+	}
+	switch toolOptions.NameCase {
+	case "", kumo_mcp.NameCaseSnake, kumo_mcp.NameCaseCamel:
+	default:
+		return nil, fmt.Errorf("invalid --tool-name-case %s, expected snake or camel", toolOptions.NameCase)
+	}
+
+	operationTimeouts, err := cmd.Flags().GetStringArray("operation-timeout")
+	if err != nil {
+		return nil, err
+	}
+
+	toolOptions.OperationTimeouts, err = parseOperationTimeouts(operationTimeouts)
+	if err != nil {
+		return nil, err
+	}
+
+	toolOptions.Budget, err = parseBudget(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	toolOptions.HTTPClient, err = httpClientFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	toolOptions.Auth, err = authFromFlags(cmd, spec, toolOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if toolOptions.DryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+		return nil, err
+	}
+
+	confirmMethods, err := cmd.Flags().GetStringSlice("confirm-destructive")
+	if err != nil {
+		return nil, err
+	}
+	for _, method := range confirmMethods {
+		toolOptions.ConfirmMethods = append(toolOptions.ConfirmMethods, strings.ToUpper(strings.TrimSpace(method)))
+	}
+
+	if toolOptions.Filter, err = toolFilterFromFlags(cmd); err != nil {
+		return nil, err
+	}
+
+	disableNextPage, err := cmd.Flags().GetBool("disable-next-page")
+	if err != nil {
+		return nil, err
+	}
+
+	if !disableNextPage {
+		toolOptions.PageCursors = kumo_mcp.NewPageCursors()
+	}
+
+	if toolOptions.FollowPages, err = cmd.Flags().GetInt("follow-pages"); err != nil {
+		return nil, err
+	}
+	if toolOptions.FollowPages < 0 {
+		return nil, fmt.Errorf("--follow-pages must not be negative")
+	}
+
+	toolOptions.Pruning, err = schemaPruningFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyRateLimits(cmd, toolOptions); err != nil {
+		return nil, err
+	}
+
+	cacheTTL, err := cmd.Flags().GetDuration("cache-ttl")
+	if err != nil {
+		return nil, err
+	}
+
+	cacheEntries, err := cmd.Flags().GetInt("cache-max-entries")
+	if err != nil {
+		return nil, err
+	}
+	if cacheEntries < 0 {
+		return nil, fmt.Errorf("--cache-max-entries must not be negative")
+	}
+
+	if cacheTTL > 0 {
+		toolOptions.Cache = kumo_mcp.NewResponseCache(cacheTTL, cacheEntries)
+	}
+
+	return toolOptions, nil
+}
+
+func parseOperationTimeouts(timeoutStrings []string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, t := range timeoutStrings {
+		parts := strings.SplitN(t, "=", 2)
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid header format: %s (expected 'key=value')", h)
+			return nil, fmt.Errorf("invalid operation timeout format: %s (expected 'tool=duration')", t)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation timeout for %s: %w", parts[0], err)
 		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		headers.Add(key, value)
+		timeouts[strings.TrimSpace(parts[0])] = timeout
 	}
-	return headers, nil
+	return timeouts, nil
 }
 
-func verifySpecSource(cmd *cobra.Command, args []string) error {
-	if err := cobra.ExactArgs(1)(cmd, args); err != nil {
-		return err
+// verifyOperationTimeouts rejects timeouts of tools that aren't served, which
+// would otherwise be ignored
+func verifyOperationTimeouts(timeouts map[string]time.Duration, toolNames []string) error {
+	var unknown []string
+	for name := range timeouts {
+		if !slices.Contains(toolNames, name) {
+			unknown = append(unknown, name)
+		}
 	}
+	sort.Strings(unknown)
 
-	source := args[0]
+	if len(unknown) > 0 {
+		return fmt.Errorf("--operation-timeout names unknown tools: %s (use the served tool names)", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// parseBudget builds the daily request budget from the budget flags, returning
+// nil when no budget is configured
+func parseBudget(cmd *cobra.Command) (*kumo_mcp.RequestBudget, error) {
+	dailyBudget, err := cmd.Flags().GetInt("daily-budget")
+	if err != nil {
+		return nil, err
+	}
 
-	// Only validate file existence if it's not a URL
-	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
-		if _, err := os.Stat(source); os.IsNotExist(err) {
-			return fmt.Errorf("file does not exist: %s", source)
+	classBudgets, err := cmd.Flags().GetStringArray("class-budget")
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]int)
+	if dailyBudget > 0 {
+		limits[kumo_mcp.BudgetClassAll] = dailyBudget
+	}
+	for _, b := range classBudgets {
+		parts := strings.SplitN(b, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid class budget format: %s (expected 'class=count')", b)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid class budget for %s: %w", parts[0], err)
+		}
+		limits[strings.TrimSpace(parts[0])] = limit
+	}
+
+	if len(limits) == 0 {
+		return nil, nil
+	}
+
+	return kumo_mcp.NewRequestBudget(limits)
+}
+
+// applyRateLimits configures the global and per-host rate limiters from flags
+func applyRateLimits(cmd *cobra.Command, toolOptions *kumo_mcp.ToolOptions) error {
+	rateLimit, err := cmd.Flags().GetString("rate-limit")
+	if err != nil {
+		return err
+	}
+
+	if rateLimit != "" {
+		rate, err := kumo_mcp.ParseRate(rateLimit)
+		if err != nil {
+			return err
 		}
+		toolOptions.RateLimit = kumo_mcp.NewRateLimiter(rate)
 	}
 
-	if _, err := openapi.LoadSpecFromSource(source); err != nil {
+	hostRateLimit, err := cmd.Flags().GetString("host-rate-limit")
+	if err != nil {
 		return err
 	}
 
+	if hostRateLimit != "" {
+		rate, err := kumo_mcp.ParseRate(hostRateLimit)
+		if err != nil {
+			return err
+		}
+		toolOptions.HostRateLimit = kumo_mcp.NewHostRateLimiter(rate)
+	}
+
 	return nil
 }
 
+// verifySpecSource checks that the single spec of a command exists and loads
+func verifySpecSource(cmd *cobra.Command, args []string) error {
+	if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+		return err
+	}
+
+	if err := verifySpecSources(cmd, args); err != nil {
+		return err
+	}
+
+	_, err := specSource(cmd, args)
+	return err
+}
+
 func init() {
-	serveCmd.Flags().StringArray("headers", []string{}, "headers to inject on requests in the form of key=value")
+	addRequestFlags(serveCmd)
+	serveCmd.Flags().Duration("timeout", 30*time.Second, "timeout for each tool call, 0 disables it")
+	serveCmd.Flags().StringArray("operation-timeout", []string{}, "per-tool timeout override in the form of tool=duration, keyed by the served tool name after prefixing and renaming")
+	serveCmd.Flags().String("tool-name-case", "", "normalize tool names to snake (get_user_by_id) or camel (getUserById) case")
+	serveCmd.Flags().String("tool-prefix", "", "prepend this to every tool name, e.g. github_, so tools of several servers in one client don't collide")
+	serveCmd.Flags().Bool("prefix-toolsets", false, "prepend the toolset of every tool to its name, e.g. users_listUsers, to group the tools of a large API")
+	serveCmd.Flags().Duration("cache-ttl", 0, "cache successful GET responses for this long, 0 disables caching")
+	serveCmd.Flags().Int("cache-max-entries", 1000, "maximum number of cached responses, evicting the least recently used, 0 means unbounded")
+	serveCmd.Flags().Int("daily-budget", 0, "maximum requests per day, after which mutating tools are disabled (0 means unlimited)")
+	serveCmd.Flags().StringArray("class-budget", []string{}, "daily request budget per tool class in the form of class=count (read, write)")
+	addHTTPClientFlags(serveCmd)
+	addSpecLoadingFlags(serveCmd)
+	addCassetteFlags(serveCmd)
+	addHostGuardFlags(serveCmd)
+	addAuthFlags(serveCmd)
+	addFilterFlags(serveCmd)
+	addProfileFlags(serveCmd)
+	serveCmd.Flags().String("manifest", "", "write a JSON manifest of the served specs, tools, filters and auth modes to this file instead of stderr")
+	serveCmd.Flags().String("transcript", "", "record every tool call with inputs, outputs and timings to this JSON Lines file")
+	serveCmd.Flags().BoolP("quiet", "q", false, "only log warnings and errors on stderr")
+	addLogFlags(serveCmd)
+	serveCmd.Flags().Bool("disable-next-page", false, "don't offer the _next_page input continuing paginated listings")
+	serveCmd.Flags().Int("follow-pages", 0, "follow paginated GET listings and merge up to this many pages into one result, 0 disables it")
+	serveCmd.Flags().Bool("strip-descriptions", false, "remove descriptions from tool input schemas")
+	serveCmd.Flags().Bool("strip-examples", false, "remove examples from tool input schemas")
+	serveCmd.Flags().Int("max-schema-depth", 0, "drop the nested fields of tool inputs deeper than this, 0 keeps every level")
+	serveCmd.Flags().Bool("watch", false, "reload the specs when they change and update the tools, notifying clients")
+	serveCmd.Flags().Duration("watch-interval", 2*time.Second, "how often to check the specs for changes with --watch, URLs are downloaded again every time")
+	serveCmd.Flags().Bool("dry-run", false, "return the request every tool call would send, with credentials masked, instead of sending it")
+	serveCmd.Flags().StringSlice("confirm-destructive", nil, "ask the user to approve calls with these HTTP methods through the MCP client before sending them, DELETE when given without a value")
+	serveCmd.Flags().Lookup("confirm-destructive").NoOptDefVal = http.MethodDelete
+	serveCmd.Flags().Bool("skip-preflight", false, "skip the connectivity check against the API on startup")
+	serveCmd.Flags().String("rate-limit", "", "maximum request rate across all tools, e.g. 10/s or 100/m")
+	serveCmd.Flags().String("host-rate-limit", "", "maximum request rate to each upstream host, e.g. 5/s")
 	rootCmd.AddCommand(serveCmd)
 }
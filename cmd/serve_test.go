@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// TestServeStdoutPurity runs a full MCP session through the serve code path
+// and asserts nothing but the MCP stream could ever reach stdout
+func TestServeStdoutPurity(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "1", "name": "Alice"})
+	}))
+	defer upstream.Close()
+
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Users API", "version": "1.0.0"},
+  "servers": [{"url": "` + upstream.URL + `"}],
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(spec), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	// Capture everything written to the process stdout during the session
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	originalStdout := os.Stdout
+	os.Stdout = stdoutWriter
+	defer func() { os.Stdout = originalStdout }()
+
+	captured := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(stdoutReader)
+		captured <- string(data)
+	}()
+
+	var stderr bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	serveCmd.SetContext(ctx)
+	serveCmd.SetErr(&stderr)
+	defer serveCmd.SetErr(nil)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- runServer(serveCmd, []specEntry{{Source: specPath, Name: "spec"}}, serverTransport)
+	}()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+	if len(tools.Tools) != 1 {
+		t.Fatalf("Expected 1 tool, got %d", len(tools.Tools))
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "getUser",
+		Arguments: map[string]interface{}{"id": "1"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Tool call returned an error: %+v", result.Content)
+	}
+
+	session.Close()
+	if err := <-serveErr; err != nil {
+		t.Fatalf("Server failed: %v", err)
+	}
+
+	stdoutWriter.Close()
+	if out := <-captured; out != "" {
+		t.Errorf("Expected no output on stdout, got %q", out)
+	}
+
+	if !strings.Contains(stderr.String(), "serving") {
+		t.Errorf("Expected diagnostics on stderr, got %q", stderr.String())
+	}
+}
+
+func TestBasicAuthorization(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials string
+		expected    string
+		expectError bool
+	}{
+		{name: "user and password", credentials: "alice:s3cr3t", expected: "Basic YWxpY2U6czNjcjN0"},
+		{name: "password with colon", credentials: "alice:a:b", expected: "Basic YWxpY2U6YTpi"},
+		{name: "missing password separator", credentials: "alice", expectError: true},
+		{name: "missing user", credentials: ":s3cr3t", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorization, err := basicAuthorization(tt.credentials)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil || authorization != tt.expected {
+				t.Errorf("Expected %q, got %q (%v)", tt.expected, authorization, err)
+			}
+		})
+	}
+}
+
+func TestParseHeadersFromEnv(t *testing.T) {
+	t.Setenv("KUMOCTL_TEST_TOKEN", "s3cr3t")
+
+	tests := []struct {
+		name        string
+		header      string
+		expected    string
+		expectError bool
+	}{
+		{name: "literal value", header: "X-Team=billing", expected: "billing"},
+		{name: "env prefix", header: "Authorization=env:KUMOCTL_TEST_TOKEN", expected: "s3cr3t"},
+		{name: "expansion", header: "Authorization=Bearer ${KUMOCTL_TEST_TOKEN}", expected: "Bearer s3cr3t"},
+		{name: "dollar without braces is literal", header: "X-Price=$5", expected: "$5"},
+		{name: "unset env prefix", header: "Authorization=env:KUMOCTL_TEST_UNSET", expectError: true},
+		{name: "unset expansion", header: "Authorization=Bearer ${KUMOCTL_TEST_UNSET}", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, err := parseHeaders([]string{tt.header})
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			key, _, _ := strings.Cut(tt.header, "=")
+			if value := headers.Get(key); value != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestEnvHeaders(t *testing.T) {
+	environ := []string{
+		"KUMOCTL_HEADER_AUTHORIZATION=Bearer ${API_TOKEN}",
+		"KUMOCTL_HEADER_X_API_KEY=abc=def",
+		"KUMOCTL_HEADER_X_TEAM=ops",
+		"KUMOCTL_HEADER_API__KEY=ghi",
+		"KUMOCTL_HEADER_=ignored",
+		"KUMOCTL_BASIC_AUTH=user:pass",
+		"HOME=/home/me",
+	}
+
+	expected := []string{"Api_key=ghi", "Authorization=Bearer ${API_TOKEN}", "X-Api-Key=abc=def"}
+	if got := envHeaders(environ, []string{"x-team=billing"}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	for key, expected := range map[string]string{
+		"Authorization": "KUMOCTL_HEADER_AUTHORIZATION",
+		"X-Api-Key":     "KUMOCTL_HEADER_X_API_KEY",
+		" x.trace ":     "KUMOCTL_HEADER_X_TRACE",
+		"api_key":       "KUMOCTL_HEADER_API__KEY",
+	} {
+		if got := headerEnvName(key); got != expected {
+			t.Errorf("Expected %s for %q, got %s", expected, key, got)
+		}
+	}
+
+	for _, key := range []string{"X-Api-Key", "api_key", "X-Tenant_Id"} {
+		suffix := strings.TrimPrefix(headerEnvName(key), headerEnvPrefix)
+		if got := headerFromEnvName(suffix); !strings.EqualFold(got, key) {
+			t.Errorf("Expected %s to be read back, got %s", key, got)
+		}
+	}
+}
+
+func TestTagHeadersFromFlags(t *testing.T) {
+	t.Setenv("KUMOCTL_TEST_ADMIN_TOKEN", "admin-token")
+
+	newCommand := func(elevate string) *cobra.Command {
+		cmd := &cobra.Command{Use: "serve"}
+		addRequestFlags(cmd)
+		cmd.Flags().Set("tag-headers", "admin:X-Team=ops")
+		cmd.Flags().Set("tag-headers", "admin:Authorization=Bearer read-only")
+		cmd.Flags().Set("elevated-headers", "admin:Authorization=Bearer ${KUMOCTL_TEST_ADMIN_TOKEN}")
+		// Secrets of tags that aren't elevated are never resolved
+		cmd.Flags().Set("elevated-headers", "billing:Authorization=Bearer ${KUMOCTL_TEST_UNSET}")
+		if elevate != "" {
+			cmd.Flags().Set("elevate", elevate)
+		}
+		return cmd
+	}
+
+	tagHeaders, err := tagHeadersFromFlags(newCommand(""))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := tagHeaders["admin"].Get("Authorization"); got != "Bearer read-only" {
+		t.Errorf("Expected default tag header without --elevate, got %q", got)
+	}
+
+	tagHeaders, err = tagHeadersFromFlags(newCommand("admin"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := tagHeaders["admin"].Get("Authorization"); got != "Bearer admin-token" {
+		t.Errorf("Expected elevated header, got %q", got)
+	}
+	if got := tagHeaders["admin"].Get("X-Team"); got != "ops" {
+		t.Errorf("Expected default tag headers to be kept, got %q", got)
+	}
+
+	if _, err := tagHeadersFromFlags(newCommand("billing")); err == nil {
+		t.Error("Expected error for an elevated header with an unset variable")
+	}
+	if _, err := tagHeadersFromFlags(newCommand("reports")); err == nil {
+		t.Error("Expected error for --elevate without elevated headers")
+	}
+}
+
+func TestSelectServer(t *testing.T) {
+	servers := []openapi.Server{
+		{URL: "https://api.example.com", Description: "Production"},
+		{URL: "https://sandbox.example.com/", Description: "Sandbox"},
+	}
+
+	tests := []struct {
+		name        string
+		index       int
+		url         string
+		expected    string
+		expectError bool
+	}{
+		{name: "default", index: -1, expected: ""},
+		{name: "by index", index: 1, expected: "https://sandbox.example.com/"},
+		{name: "index out of range", index: 2, expectError: true},
+		{name: "by URL ignoring trailing slash", index: -1, url: "https://sandbox.example.com", expected: "https://sandbox.example.com/"},
+		{name: "undeclared URL", index: -1, url: "https://other.example.com", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := selectServer(servers, tt.index, tt.url)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %v", server)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got := ""
+			if server != nil {
+				got = server.URL
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestServerFromFlags(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [
+			{"url": "https://api.example.com"},
+			{
+				"url": "https://{tenant}.{region}.example.com",
+				"variables": {
+					"tenant": {"default": "demo"},
+					"region": {"default": "us", "enum": ["us", "eu"]}
+				}
+			}
+		],
+		"paths": {}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		flags       map[string][]string
+		expected    string
+		expectError bool
+	}{
+		{name: "first server as declared", expected: ""},
+		{name: "server defaults", flags: map[string][]string{"server-index": {"1"}}, expected: "https://demo.us.example.com"},
+		{name: "server variable override", flags: map[string][]string{"server-index": {"1"}, "server-var": {"region=eu"}}, expected: "https://demo.eu.example.com"},
+		{name: "host variables keep their placeholder", flags: map[string][]string{"server-index": {"1"}, "host-var": {"tenant"}}, expected: "https://{tenant}.us.example.com"},
+		{name: "base URL wins", flags: map[string][]string{"base-url": {"https://{region}.local"}}, expected: "https://{region}.local"},
+		{name: "conflicting selection", flags: map[string][]string{"base-url": {"https://local"}, "server-index": {"0"}}, expectError: true},
+		{name: "undeclared server variable", flags: map[string][]string{"server-var": {"region=eu"}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "serve"}
+			addRequestFlags(cmd)
+			for name, values := range tt.flags {
+				for _, value := range values {
+					if err := cmd.Flags().Set(name, value); err != nil {
+						t.Fatalf("Failed to set --%s: %v", name, err)
+					}
+				}
+			}
+
+			got, err := serverFromFlags(cmd, spec)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestServerFromFlagsFirstServerVariables(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{
+			"url": "https://{tenant}.example.com",
+			"variables": {"tenant": {"default": "demo"}}
+		}],
+		"paths": {}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	cmd := &cobra.Command{Use: "serve"}
+	addRequestFlags(cmd)
+	if got, err := serverFromFlags(cmd, spec); err != nil || got != "" {
+		t.Errorf("Expected the spec's base URL, got %q (%v)", got, err)
+	}
+
+	// The spec's base URL fills the tenant with its default
+	if err := cmd.Flags().Set("host-var", "tenant"); err != nil {
+		t.Fatalf("Failed to set --host-var: %v", err)
+	}
+	if got, err := serverFromFlags(cmd, spec); err != nil || got != "https://{tenant}.example.com" {
+		t.Errorf("Expected the tenant placeholder to be kept, got %q (%v)", got, err)
+	}
+}
+
+func TestVerifyOperationTimeouts(t *testing.T) {
+	toolNames := []string{"github_listRepos", "github_getRepo"}
+
+	if err := verifyOperationTimeouts(map[string]time.Duration{"github_listRepos": time.Minute}, toolNames); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	err := verifyOperationTimeouts(map[string]time.Duration{"listRepos": time.Minute, "github_getRepo": time.Second}, toolNames)
+	if err == nil || !strings.Contains(err.Error(), "listRepos") || strings.Contains(err.Error(), "github_getRepo") {
+		t.Errorf("Expected the unprefixed name to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+// addSigningFlags registers the flags configuring HMAC request signing
+func addSigningFlags(cmd *cobra.Command) {
+	cmd.Flags().String("hmac-key-env", "", "environment variable holding the secret used to HMAC sign every request")
+	cmd.Flags().String("hmac-key-file", "", "file holding the secret used to HMAC sign every request")
+	cmd.Flags().String("hmac-algorithm", "sha256", "HMAC hash algorithm (sha1, sha256, sha512)")
+	cmd.Flags().String("hmac-header", "X-Signature", "header carrying the HMAC signature")
+	cmd.Flags().String("hmac-timestamp-header", "X-Timestamp", "header carrying the signed Unix timestamp")
+	cmd.Flags().String("hmac-canonical", kumo_mcp.CanonicalTimestampBody, "what is signed: timestamp-body, or request for method, path, query, timestamp and body")
+	cmd.Flags().String("hmac-encoding", "hex", "HMAC signature encoding (hex, base64)")
+}
+
+// signerFromFlags builds the request signer, returning nil when signing isn't
+// configured
+func signerFromFlags(cmd *cobra.Command) (kumo_mcp.RequestSigner, error) {
+	secret, err := hmacSecretFromFlags(cmd)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+
+	cfg := kumo_mcp.HMACSignerConfig{Secret: secret}
+
+	if cfg.Algorithm, err = cmd.Flags().GetString("hmac-algorithm"); err != nil {
+		return nil, err
+	}
+	if cfg.SignatureHeader, err = cmd.Flags().GetString("hmac-header"); err != nil {
+		return nil, err
+	}
+	if cfg.TimestampHeader, err = cmd.Flags().GetString("hmac-timestamp-header"); err != nil {
+		return nil, err
+	}
+	if cfg.Canonicalization, err = cmd.Flags().GetString("hmac-canonical"); err != nil {
+		return nil, err
+	}
+	if cfg.Encoding, err = cmd.Flags().GetString("hmac-encoding"); err != nil {
+		return nil, err
+	}
+
+	return kumo_mcp.NewHMACSigner(cfg)
+}
+
+// hmacSecretFromFlags reads the signing secret from the configured key source,
+// returning nil when signing isn't configured
+func hmacSecretFromFlags(cmd *cobra.Command) ([]byte, error) {
+	keyEnv, err := cmd.Flags().GetString("hmac-key-env")
+	if err != nil {
+		return nil, err
+	}
+
+	keyFile, err := cmd.Flags().GetString("hmac-key-file")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case keyEnv != "" && keyFile != "":
+		return nil, fmt.Errorf("--hmac-key-env and --hmac-key-file are mutually exclusive")
+	case keyEnv != "":
+		secret := os.Getenv(keyEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("HMAC signing secret environment variable %s is not set", keyEnv)
+		}
+		return []byte(secret), nil
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read HMAC signing secret: %w", err)
+		}
+		// Editors usually leave a trailing newline behind
+		return []byte(strings.TrimRight(string(data), "\r\n")), nil
+	default:
+		return nil, nil
+	}
+}
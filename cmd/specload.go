@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// addSpecLoadingFlags registers the flags configuring how specs are
+// downloaded and cached, and how imported tool definitions are read
+func addSpecLoadingFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("spec-headers", []string{}, "headers to send when downloading the spec in the form of key=value, not sent to the API")
+	cmd.Flags().Bool("no-spec-cache", false, "don't cache downloaded specs under ~/.cache/kumoctl or fall back to the cached copy")
+	addToolDefinitionFlags(cmd)
+}
+
+// addToolDefinitionFlags registers the flags mapping imported tool
+// definitions to HTTP operations
+func addToolDefinitionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("tool-route", []string{}, "HTTP operation of an imported tool definition in the form of 'name=METHOD /path'")
+}
+
+// loadSpec loads the spec at source using the shared HTTP client configured
+// by the command's flags, sending --spec-headers when it is downloaded. Remote
+// specs are cached on disk unless --no-spec-cache is set.
+func loadSpec(cmd *cobra.Command, source string) (openapi.APISpec, error) {
+	opts, err := loadOptionsFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return openapi.LoadSpecFromSourceWithOptions(source, opts)
+}
+
+// loadOptionsFromFlags configures how specs are read from the flags
+// registered by addHTTPClientFlags and addSpecLoadingFlags
+func loadOptionsFromFlags(cmd *cobra.Command) (*openapi.LoadOptions, error) {
+	client, err := httpClientFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	specHeaderStrings, err := cmd.Flags().GetStringArray("spec-headers")
+	if err != nil {
+		return nil, err
+	}
+	specHeaders, err := parseHeaders(specHeaderStrings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --spec-headers: %w", err)
+	}
+
+	noCache, err := cmd.Flags().GetBool("no-spec-cache")
+	if err != nil {
+		return nil, err
+	}
+	var cacheDir string
+	if !noCache {
+		// Without a home directory specs are simply not cached
+		cacheDir, _ = openapi.DefaultCacheDir()
+	}
+
+	toolDefinitions, err := toolDefinitionOptionsFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapi.LoadOptions{
+		HTTPClient:      client,
+		Headers:         specHeaders,
+		CacheDir:        cacheDir,
+		ToolDefinitions: toolDefinitions,
+		Logger:          commandLogger(cmd),
+	}, nil
+}
+
+// toolDefinitionOptionsFromFlags maps imported tool definitions to HTTP
+// operations from the flags registered by addToolDefinitionFlags, sending
+// them to --base-url
+func toolDefinitionOptionsFromFlags(cmd *cobra.Command) (openapi.ToolDefinitionOptions, error) {
+	var opts openapi.ToolDefinitionOptions
+
+	if cmd.Flags().Lookup("base-url") != nil {
+		baseURL, err := cmd.Flags().GetString("base-url")
+		if err != nil {
+			return opts, err
+		}
+		opts.BaseURL = baseURL
+	}
+
+	routes, err := cmd.Flags().GetStringArray("tool-route")
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Routes = make(map[string]openapi.ToolRoute, len(routes))
+	for _, r := range routes {
+		name, value, found := strings.Cut(r, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return opts, fmt.Errorf("invalid tool route format: %s (expected 'name=METHOD /path')", r)
+		}
+
+		route, err := openapi.ParseToolRoute(value)
+		if err != nil {
+			return opts, err
+		}
+		opts.Routes[strings.TrimSpace(name)] = route
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// specNameRegex matches the characters replaced in spec names
+var specNameRegex = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// specEntry is one of the specs served together, with the base URL and
+// headers applying to its tools only
+type specEntry struct {
+	Source  string
+	Name    string
+	BaseURL string
+	Headers []string
+}
+
+// specEntries returns the specs given as arguments, falling back to the spec
+// or list of specs of the config file or the selected profile. Listed specs
+// are either a path or URL, or a map with spec, name, base-url and headers.
+func specEntries(cmd *cobra.Command, args []string) ([]specEntry, error) {
+	var entries []specEntry
+	if len(args) > 0 {
+		for _, arg := range args {
+			entries = append(entries, specEntry{Source: arg})
+		}
+		return nameSpecEntries(entries), nil
+	}
+
+	settings, err := profileSettings(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	switch spec := settings[config.SpecKey].(type) {
+	case string:
+		if spec != "" {
+			entries = append(entries, specEntry{Source: spec})
+		}
+	case []interface{}:
+		for _, item := range spec {
+			entry, err := parseSpecEntry(item)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("requires a spec path or URL, or a spec in the config file")
+	}
+
+	return nameSpecEntries(entries), nil
+}
+
+// parseSpecEntry parses an item of a spec list in the config file
+func parseSpecEntry(item interface{}) (specEntry, error) {
+	if source, ok := item.(string); ok {
+		return specEntry{Source: source}, nil
+	}
+
+	fields, ok := asSettingsMap(item)
+	if !ok {
+		return specEntry{}, fmt.Errorf("invalid spec entry %v (expected a path, URL or map)", item)
+	}
+
+	var entry specEntry
+	for key, value := range fields {
+		switch key {
+		case config.SpecKey:
+			entry.Source = fmt.Sprint(value)
+		case "name":
+			entry.Name = fmt.Sprint(value)
+		case "base-url":
+			entry.BaseURL = fmt.Sprint(value)
+		case "headers":
+			entry.Headers = settingValues(value)
+		default:
+			return specEntry{}, fmt.Errorf("unknown key %s in spec entry (expected spec, name, base-url or headers)", key)
+		}
+	}
+
+	if entry.Source == "" {
+		return specEntry{}, fmt.Errorf("spec entry without spec: %v", item)
+	}
+	return entry, nil
+}
+
+// asSettingsMap returns a map nested in the settings
+func asSettingsMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case config.Settings:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// nameSpecEntries names unnamed specs after their file, keeping names unique
+func nameSpecEntries(entries []specEntry) []specEntry {
+	seen := make(map[string]int)
+	for i := range entries {
+		if entries[i].Name == "" {
+			entries[i].Name = specName(entries[i].Source)
+		}
+
+		seen[entries[i].Name]++
+		if n := seen[entries[i].Name]; n > 1 {
+			entries[i].Name = fmt.Sprintf("%s%d", entries[i].Name, n)
+		}
+	}
+	return entries
+}
+
+// specName derives a name from the file name of a spec path or URL, e.g.
+// billing for ./specs/billing.openapi.yaml
+func specName(source string) string {
+	name := source
+	if u, err := url.Parse(source); err == nil && u.Host != "" {
+		name = u.Path
+	}
+
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name, _, _ = strings.Cut(name, ".")
+	name = strings.Trim(specNameRegex.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		return "spec"
+	}
+	return name
+}
+
+// verifySpecSources checks that every spec to serve exists and loads
+func verifySpecSources(cmd *cobra.Command, args []string) error {
+	if err := applyProfile(cmd); err != nil {
+		return err
+	}
+
+	entries, err := specEntries(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		// Only validate file existence if it's not a URL
+		if !strings.HasPrefix(entry.Source, "http://") && !strings.HasPrefix(entry.Source, "https://") {
+			if _, err := os.Stat(entry.Source); os.IsNotExist(err) {
+				return fmt.Errorf("file does not exist: %s", entry.Source)
+			}
+		}
+
+		if _, err := loadSpec(cmd, entry.Source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
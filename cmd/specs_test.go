@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+func TestSpecName(t *testing.T) {
+	tests := map[string]string{
+		"./specs/billing.openapi.yaml":               "billing",
+		"https://api.example.com/v2/openapi.json":    "openapi",
+		"https://api.example.com/users-api.json?x=1": "users_api",
+		"../.hidden.json":                            "spec",
+	}
+
+	for source, expected := range tests {
+		if got := specName(source); got != expected {
+			t.Errorf("specName(%q) = %q, expected %q", source, got, expected)
+		}
+	}
+}
+
+func TestSpecEntriesFromConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "kumoctl.yaml")
+	content := `
+spec:
+  - ./users.json
+  - spec: ./billing/openapi.json
+    base-url: https://billing.example.com
+    headers:
+      X-Team: billing
+  - ./orders/openapi.json
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cmd := &cobra.Command{Use: "serve"}
+	addProfileFlags(cmd)
+	cmd.Flags().Set("config", configPath)
+
+	entries, err := specEntries(cmd, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []specEntry{
+		{Source: "./users.json", Name: "users"},
+		{Source: "./billing/openapi.json", Name: "openapi", BaseURL: "https://billing.example.com", Headers: []string{"X-Team=billing"}},
+		{Source: "./orders/openapi.json", Name: "openapi2"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+
+	if _, err := specSource(cmd, nil); err == nil {
+		t.Error("Expected commands taking a single spec to reject a spec list")
+	}
+}
+
+func TestSpecToolOptions(t *testing.T) {
+	loadTestSpec := func(paths string) openapi.APISpec {
+		spec, err := openapi.LoadSpec([]byte(`{
+			"openapi": "3.0.0",
+			"info": {"title": "Test", "version": "1.0.0"},
+			"servers": [{"url": "https://api.example.com"}],
+			"paths": {` + paths + `}
+		}`))
+		if err != nil {
+			t.Fatalf("Failed to load spec: %v", err)
+		}
+		return spec
+	}
+
+	listItems := `"/items": {"get": {"operationId": "listItems", "responses": {"200": {"description": "OK"}}}}`
+	getUser := `"/user": {"get": {"operationId": "getUser", "responses": {"200": {"description": "OK"}}}}`
+
+	loaded := []*loadedSpec{
+		{specEntry: specEntry{Name: "users", Headers: []string{"X-Team=users"}}, spec: loadTestSpec(listItems + "," + getUser)},
+		{specEntry: specEntry{Name: "orders", BaseURL: "https://orders.example.com"}, spec: loadTestSpec(listItems)},
+	}
+	shared := &kumo_mcp.ToolOptions{Headers: map[string][]string{"Authorization": {"Bearer token"}}}
+
+	cmd := &cobra.Command{Use: "serve"}
+	addRequestFlags(cmd)
+	addAuthFlags(cmd)
+
+	if err := specToolOptions(cmd, loaded, shared); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	users, orders := loaded[0].opts, loaded[1].opts
+	if !reflect.DeepEqual(users.ToolNames, map[string]string{"listItems": "users_listItems"}) {
+		t.Errorf("Expected the colliding tool to be prefixed, got %v", users.ToolNames)
+	}
+	if !reflect.DeepEqual(orders.ToolNames, map[string]string{"listItems": "orders_listItems"}) {
+		t.Errorf("Expected the colliding tool to be prefixed, got %v", orders.ToolNames)
+	}
+	if users.Headers.Get("X-Team") != "users" || users.Headers.Get("Authorization") != "Bearer token" {
+		t.Errorf("Expected spec headers on top of the shared ones, got %v", users.Headers)
+	}
+	if shared.Headers.Get("X-Team") != "" || orders.Headers.Get("X-Team") != "" {
+		t.Error("Expected spec headers not to leak into other specs")
+	}
+	if orders.BaseURL != "https://orders.example.com" || users.BaseURL != "" {
+		t.Errorf("Expected per-spec base URLs, got %q and %q", users.BaseURL, orders.BaseURL)
+	}
+}
+
+func TestSpecToolOptionsCredentials(t *testing.T) {
+	loadTestSpec := func(param string) openapi.APISpec {
+		spec, err := openapi.LoadSpec([]byte(`{
+			"openapi": "3.0.0",
+			"info": {"title": "Test", "version": "1.0.0"},
+			"servers": [{"url": "https://api.example.com"}],
+			"paths": {},
+			"components": {"securitySchemes": {"key": {"type": "apiKey", "in": "query", "name": "` + param + `"}}}
+		}`))
+		if err != nil {
+			t.Fatalf("Failed to load spec: %v", err)
+		}
+		return spec
+	}
+
+	loaded := []*loadedSpec{
+		{specEntry: specEntry{Name: "users"}, spec: loadTestSpec("api_key")},
+		{specEntry: specEntry{Name: "orders"}, spec: loadTestSpec("token")},
+	}
+
+	cmd := &cobra.Command{Use: "serve"}
+	addRequestFlags(cmd)
+	addAuthFlags(cmd)
+	cmd.Flags().Set("api-key", "key=secret")
+
+	if err := specToolOptions(cmd, loaded, &kumo_mcp.ToolOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := loaded[0].opts.QueryParams.Get("api_key"); got != "secret" {
+		t.Errorf("Expected the users key in api_key, got %v", loaded[0].opts.QueryParams)
+	}
+	if got := loaded[1].opts.QueryParams.Get("token"); got != "secret" || loaded[1].opts.QueryParams.Has("api_key") {
+		t.Errorf("Expected the orders key in token, got %v", loaded[1].opts.QueryParams)
+	}
+
+	cmd.Flags().Set("api-key", "other=secret")
+	if err := specToolOptions(cmd, loaded, &kumo_mcp.ToolOptions{}); err == nil {
+		t.Error("Expected a scheme unknown to a spec to be rejected")
+	}
+}
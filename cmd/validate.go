@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [spec-path-or-url]",
+	Short: "Check a spec for problems affecting tool generation",
+	Long: `Validate the spec strictly and report every problem that affects the generated
+tools, such as unresolved references, request bodies that aren't JSON, missing
+operationIds and clashing tool names.
+
+The command exits with status 1 when errors are found, or warnings with --strict,
+so it can gate CI pipelines.`,
+	Example: "  kumoctl validate ./spec.json\n  kumoctl validate https://api.example.com/openapi.json --strict",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runValidate,
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	strict, err := cmd.Flags().GetBool("strict")
+	if err != nil {
+		return err
+	}
+
+	opts, err := loadOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	data, err := openapi.ReadSource(args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	spec, problems := openapi.ValidateDocument(data)
+	if spec != nil {
+		problems = append(problems, kumo_mcp.ValidateTools(spec)...)
+	}
+
+	var errorCount, warningCount int
+	for _, problem := range problems {
+		fmt.Fprintln(cmd.OutOrStdout(), problem)
+		if problem.Severity == openapi.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no problems found")
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "\n%d errors, %d warnings\n", errorCount, warningCount)
+
+	if errorCount > 0 || (strict && warningCount > 0) {
+		// The problems were reported above, only the exit status is left
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return fmt.Errorf("validation failed")
+	}
+	return nil
+}
+
+func init() {
+	validateCmd.Flags().Bool("strict", false, "fail on warnings too")
+	addHTTPClientFlags(validateCmd)
+	addSpecLoadingFlags(validateCmd)
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// specVersion identifies the version of a spec that is being served
+type specVersion struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// watchSpecs checks the specs for changes every interval until ctx is done,
+// reloading those that changed
+func watchSpecs(ctx context.Context, cmd *cobra.Command, server *mcp.Server, loaded []*loadedSpec, interval time.Duration, logger *slog.Logger) {
+	versions := make([]specVersion, len(loaded))
+	for i, l := range loaded {
+		version, err := currentSpecVersion(l)
+		if err != nil {
+			logger.Warn("watching spec failed", "source", l.Source, "error", err)
+		}
+		versions[i] = version
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for i, l := range loaded {
+				if err := reloadSpec(cmd, server, l, &versions[i], len(loaded) > 1, logger); err != nil {
+					logger.Warn("reloading spec failed, still serving the previous version", "source", l.Source, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// currentSpecVersion identifies the spec being served, with the modification
+// time of its file
+func currentSpecVersion(l *loadedSpec) (specVersion, error) {
+	var version specVersion
+	if !isURL(l.Source) {
+		info, err := os.Stat(l.Source)
+		if err != nil {
+			return version, err
+		}
+		version.modTime, version.size = info.ModTime(), info.Size()
+	}
+
+	hash, err := openapi.Hash(l.spec)
+	if err != nil {
+		return version, err
+	}
+	version.hash = hash
+	return version, nil
+}
+
+// reloadSpec loads the spec again when its file changed, or every time for
+// URLs, and replaces its tools, resources and prompts when the document
+// differs. The server notifies clients of the changed tools.
+func reloadSpec(cmd *cobra.Command, server *mcp.Server, l *loadedSpec, version *specVersion, multiple bool, logger *slog.Logger) error {
+	next := *version
+	if !isURL(l.Source) {
+		info, err := os.Stat(l.Source)
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Equal(version.modTime) && info.Size() == version.size {
+			return nil
+		}
+		next.modTime, next.size = info.ModTime(), info.Size()
+	}
+
+	spec, err := loadSpec(cmd, l.Source)
+	if err != nil {
+		return err
+	}
+
+	hash, err := openapi.Hash(spec)
+	if err != nil {
+		return err
+	}
+	if hash == version.hash {
+		*version = next
+		return nil
+	}
+
+	summary, err := l.registry.Sync(spec)
+	if err != nil {
+		return fmt.Errorf("failed to generate tools: %w", err)
+	}
+
+	l.spec = spec
+	next.hash = hash
+	*version = next
+
+	if err := exposeSpec(server, l, multiple); err != nil {
+		return err
+	}
+
+	logger.Info("reloaded spec", "source", l.Source, "added", len(summary.Added), "changed", len(summary.Changed), "removed", len(summary.Removed))
+	return nil
+}
+
+// isURL reports whether a spec source is downloaded rather than read from disk
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	kumo_mcp "github.com/kumolabai/kumoctl/pkg/mcp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReloadSpec(t *testing.T) {
+	specWithPaths := func(operationIDs ...string) string {
+		paths := make([]string, len(operationIDs))
+		for i, id := range operationIDs {
+			paths[i] = `"/` + id + `": {"get": {"operationId": "` + id + `", "tags": ["items"], "responses": {"200": {"description": "OK"}}}}`
+		}
+		return `{
+  "openapi": "3.0.0",
+  "info": {"title": "Items API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {` + strings.Join(paths, ",") + `}
+}`
+	}
+
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(specWithPaths("listItems", "getItem")), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	spec, err := loadSpec(serveCmd, specPath)
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	l := &loadedSpec{specEntry: specEntry{Source: specPath, Name: "items"}, spec: spec, opts: &kumo_mcp.ToolOptions{}}
+	l.registry = kumo_mcp.NewToolRegistry(server, l.opts)
+	if _, err := l.registry.Sync(l.spec); err != nil {
+		t.Fatalf("Failed to sync tools: %v", err)
+	}
+	if err := exposeSpec(server, l, false); err != nil {
+		t.Fatalf("Failed to expose spec: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	listChanged := make(chan struct{}, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) { listChanged <- struct{}{} },
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	version, err := currentSpecVersion(l)
+	if err != nil {
+		t.Fatalf("Failed to read spec version: %v", err)
+	}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	// An unchanged file is not loaded again
+	if err := reloadSpec(serveCmd, server, l, &version, false, logger); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no reload, got %q", logs.String())
+	}
+
+	if err := os.WriteFile(specPath, []byte(specWithPaths("listItems", "createItem", "deleteItem")), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	// File systems with coarse timestamps may not see the rewrite otherwise
+	version.modTime = time.Time{}
+
+	if err := reloadSpec(serveCmd, server, l, &version, false, logger); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(logs.String(), "added=2 changed=0 removed=1") {
+		t.Errorf("Expected the reload to be logged, got %q", logs.String())
+	}
+
+	select {
+	case <-listChanged:
+	case <-ctx.Done():
+		t.Fatal("Expected a tools/list_changed notification")
+	}
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "createItem,deleteItem,listItems" {
+		t.Errorf("Expected the tools of the new spec, got %v", names)
+	}
+
+	// A broken spec keeps the previous version
+	if err := os.WriteFile(specPath, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	version.modTime = time.Time{}
+	if err := reloadSpec(serveCmd, server, l, &version, false, logger); err == nil {
+		t.Error("Expected an error for a broken spec")
+	}
+	if len(l.spec.GetPaths()) != 3 {
+		t.Errorf("Expected the previous spec to be kept, got %d paths", len(l.spec.GetPaths()))
+	}
+}
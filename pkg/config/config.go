@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the kumoctl config file
+const FileName = "kumoctl.yaml"
+
+// SpecKey is the setting holding the spec path or URL, every other setting is
+// named after the command line flag it provides a value for
+const SpecKey = "spec"
+
+// Settings maps flag names to values. Lists and maps expand to repeated flag
+// values, maps in the form of key=value.
+type Settings map[string]interface{}
+
+// Profile is a named set of settings, with optional environments overriding
+// them, e.g. the staging and prod deployments of the same API
+type Profile struct {
+	Settings     `yaml:",inline"`
+	Environments map[string]Settings `yaml:"environments"`
+}
+
+// File is the parsed kumoctl config file. Its top-level settings apply to
+// every invocation, profiles are applied on top of them.
+type File struct {
+	Settings `yaml:",inline"`
+	Profiles map[string]*Profile `yaml:"profiles"`
+}
+
+// DefaultPath returns ~/.config/kumoctl/kumoctl.yaml
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "kumoctl", FileName), nil
+}
+
+// Find returns the config file to use: kumoctl.yaml in the working directory
+// if there is one, otherwise ~/.config/kumoctl/kumoctl.yaml
+func Find() (string, error) {
+	if _, err := os.Stat(FileName); err == nil {
+		return FileName, nil
+	}
+	return DefaultPath()
+}
+
+// Load reads the config file at path. A missing file yields an empty config.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// Resolve returns the top-level settings with a profile and its named
+// environment applied on top. Maps such as headers are merged, any other value
+// replaces the one it overrides. An empty profile resolves the top-level
+// settings alone.
+func (f *File) Resolve(profileName, envName string) (Settings, error) {
+	settings := make(Settings, len(f.Settings))
+	merge(settings, f.Settings)
+
+	if profileName == "" {
+		return settings, nil
+	}
+
+	profile, ok := f.Profiles[profileName]
+	if !ok || profile == nil {
+		return nil, fmt.Errorf("unknown profile: %s", profileName)
+	}
+	merge(settings, profile.Settings)
+
+	if envName == "" {
+		return settings, nil
+	}
+
+	env, ok := profile.Environments[envName]
+	if !ok {
+		return nil, fmt.Errorf("unknown environment %s in profile %s (available: %v)", envName, profileName, sortedKeys(profile.Environments))
+	}
+	merge(settings, env)
+
+	return settings, nil
+}
+
+// merge applies overrides to settings, merging maps
+func merge(settings, overrides Settings) {
+	for key, value := range overrides {
+		base, baseIsMap := asMap(settings[key])
+		override, overrideIsMap := asMap(value)
+		if baseIsMap && overrideIsMap {
+			merged := make(map[string]interface{}, len(base)+len(override))
+			for k, v := range base {
+				merged[k] = v
+			}
+			for k, v := range override {
+				merged[k] = v
+			}
+			value = merged
+		}
+		settings[key] = value
+	}
+}
+
+// asMap returns a nested map setting. Maps nested inside Settings decode as
+// Settings themselves.
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case Settings:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+func sortedKeys(m map[string]Settings) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
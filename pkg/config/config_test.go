@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConfig = `
+profiles:
+  billing:
+    spec: https://billing.example.com/openapi.json
+    timeout: 10s
+    headers:
+      Authorization: Bearer dev-token
+      X-Team: billing
+    environments:
+      staging:
+        base-url: https://staging.billing.example.com
+        headers:
+          Authorization: Bearer staging-token
+      prod:
+        base-url: https://billing.example.com
+        cacert: /etc/ssl/billing-ca.pem
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestResolve(t *testing.T) {
+	file, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		profile  string
+		env      string
+		expected Settings
+	}{
+		{
+			name:    "profile without environment",
+			profile: "billing",
+			expected: Settings{
+				"spec":    "https://billing.example.com/openapi.json",
+				"timeout": "10s",
+				"headers": map[string]interface{}{"Authorization": "Bearer dev-token", "X-Team": "billing"},
+			},
+		},
+		{
+			name:    "environment merges headers and adds base URL",
+			profile: "billing",
+			env:     "staging",
+			expected: Settings{
+				"spec":     "https://billing.example.com/openapi.json",
+				"timeout":  "10s",
+				"base-url": "https://staging.billing.example.com",
+				"headers":  map[string]interface{}{"Authorization": "Bearer staging-token", "X-Team": "billing"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := file.Resolve(tt.profile, tt.env)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(settings, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, settings)
+			}
+		})
+	}
+}
+
+func TestResolveUnknown(t *testing.T) {
+	file, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if _, err := file.Resolve("missing", ""); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+	if _, err := file.Resolve("billing", "qa"); err == nil {
+		t.Error("Expected error for unknown environment")
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	file, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(file.Profiles) != 0 {
+		t.Errorf("Expected empty config, got %v", file.Profiles)
+	}
+}
+
+func TestResolveTopLevel(t *testing.T) {
+	file, err := Load(writeConfig(t, `
+spec: ./openapi.yaml
+timeout: 5s
+headers:
+  X-Client: kumoctl
+`+testConfig))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	settings, err := file.Resolve("", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings["spec"] != "./openapi.yaml" || settings["timeout"] != "5s" {
+		t.Errorf("Expected top-level settings, got %v", settings)
+	}
+
+	settings, err = file.Resolve("billing", "staging")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"Authorization": "Bearer staging-token",
+		"X-Client":      "kumoctl",
+		"X-Team":        "billing",
+	}
+	if !reflect.DeepEqual(settings["headers"], expected) || settings["timeout"] != "10s" {
+		t.Errorf("Expected profile applied on top of the top-level settings, got %v", settings)
+	}
+}
+
+func TestFind(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	path, err := Find()
+	if err != nil || path == FileName {
+		t.Errorf("Expected the default path without a local config, got %q (%v)", path, err)
+	}
+
+	if err := os.WriteFile(FileName, []byte("spec: ./openapi.yaml\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if path, err := Find(); err != nil || path != FileName {
+		t.Errorf("Expected the config in the working directory, got %q (%v)", path, err)
+	}
+}
@@ -0,0 +1,247 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces the values of secret query parameters in cassettes
+const redactedValue = "REDACTED"
+
+// Cassette holds recorded HTTP interactions, in the order they happened
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is a recorded request and the response it got
+type Interaction struct {
+	Request  RecordedRequest  `yaml:"request"`
+	Response RecordedResponse `yaml:"response"`
+}
+
+// RecordedRequest identifies a request. Headers aren't recorded, as they
+// carry credentials.
+type RecordedRequest struct {
+	Method string `yaml:"method"`
+	URL    string `yaml:"url"`
+	Body   string `yaml:"body,omitempty"`
+}
+
+// RecordedResponse is the response replayed for a matching request
+type RecordedResponse struct {
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+// Recorder is a transport saving every request and its response to a
+// cassette file. The file is rewritten after each interaction, so it is
+// complete even if the process ends abruptly.
+type Recorder struct {
+	path   string
+	next   http.RoundTripper
+	secret map[string]bool
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder records the traffic of next to the cassette at path, writing
+// an empty cassette right away so unwritable paths fail at startup. The values
+// of the secret query parameters are redacted.
+func NewRecorder(path string, next http.RoundTripper, secretParams []string) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	r := &Recorder{path: path, next: next, secret: secretSet(secretParams), cassette: Cassette{Interactions: []Interaction{}}}
+	if err := r.write(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := recordRequest(req, r.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Cookies may hold sessions, so they are left out like request headers
+	headers := make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		if name != "Set-Cookie" {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request:  request,
+		Response: RecordedResponse{Status: resp.StatusCode, Headers: headers, Body: string(body)},
+	})
+	if err := r.write(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) write() error {
+	data, err := yaml.Marshal(r.cassette)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), ".cassette-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Replayer is a transport answering requests from a cassette without any
+// network access. Requests match an interaction by method, URL and body; the
+// recorded interactions of a request are replayed in order, repeating the
+// last one once all were used.
+type Replayer struct {
+	secret map[string]bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// LoadReplayer reads the cassette at path. The values of the secret query
+// parameters are ignored when matching requests, as they were redacted.
+func LoadReplayer(path string, secretParams []string) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var cassette Cassette
+	if err := yaml.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	return &Replayer{
+		secret:       secretSet(secretParams),
+		interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := recordRequest(req, r.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, interaction := range r.interactions {
+		if interaction.Request != request {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("no recorded response for %s %s", request.Method, request.URL)
+	}
+	r.used[match] = true
+
+	recorded := r.interactions[match].Response
+	header := make(http.Header, len(recorded.Headers))
+	for name, value := range recorded.Headers {
+		header.Set(name, value)
+	}
+	// The body is replayed whole, whatever length was recorded
+	header.Del("Content-Length")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// recordRequest identifies a request, with its query sorted and the values
+// of secret parameters redacted. The body is read and restored.
+func recordRequest(req *http.Request, secret map[string]bool) (RecordedRequest, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return RecordedRequest{}, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	u := *req.URL
+	query := u.Query()
+	for name := range query {
+		if secret[name] {
+			query[name] = []string{redactedValue}
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.User = nil
+
+	return RecordedRequest{Method: req.Method, URL: u.String(), Body: string(body)}, nil
+}
+
+func secretSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"call": %d, "got": "%s"}`, n, body)
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	recorder, err := NewRecorder(path, http.DefaultTransport, []string{"api_key"})
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+
+	send := func(client *http.Client, url, body string) (*http.Response, string, error) {
+		req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		return resp, string(data), err
+	}
+
+	recording := &http.Client{Transport: recorder}
+	for _, body := range []string{"a", "a", "b"} {
+		if _, _, err := send(recording, upstream.URL+"/items?b=2&a=1&api_key=k1", body); err != nil {
+			t.Fatalf("Failed to record: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read cassette: %v", err)
+	}
+	for _, secret := range []string{"k1", "Bearer token", "session=secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected cassette not to contain %q, got:\n%s", secret, data)
+		}
+	}
+
+	upstream.Close()
+
+	replayer, err := LoadReplayer(path, []string{"api_key"})
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	replaying := &http.Client{Transport: replayer}
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		expectBody string
+		expectErr  string
+	}{
+		{name: "first recording", url: "/items?a=1&b=2&api_key=k2", body: "a", expectBody: `{"call": 1, "got": "a"}`},
+		{name: "recordings in order", url: "/items?a=1&b=2&api_key=k2", body: "a", expectBody: `{"call": 2, "got": "a"}`},
+		{name: "last recording repeated", url: "/items?a=1&b=2&api_key=k2", body: "a", expectBody: `{"call": 2, "got": "a"}`},
+		{name: "matched by body", url: "/items?b=2&a=1&api_key=k3", body: "b", expectBody: `{"call": 3, "got": "b"}`},
+		{name: "not recorded", url: "/items?a=1", body: "a", expectErr: "no recorded response for POST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body, err := send(replaying, upstream.URL+tt.url, tt.body)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if resp.StatusCode != http.StatusCreated {
+				t.Errorf("Expected status 201, got %d", resp.StatusCode)
+			}
+			if resp.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("Expected recorded Content-Type, got %q", resp.Header.Get("Content-Type"))
+			}
+			if body != tt.expectBody {
+				t.Errorf("Expected body %s, got %s", tt.expectBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// DefaultDenyHosts are the cloud metadata endpoints, which hand out
+// credentials to anything on the machine asking for them. Requests to them
+// are always denied.
+var DefaultDenyHosts = []string{
+	"169.254.169.254",
+	"fd00:ec2::254",
+	"100.100.100.200",
+	"metadata.google.internal",
+	"metadata.goog",
+}
+
+// denyList holds the hosts and networks no request may reach
+type denyList struct {
+	hosts []string
+	nets  []*net.IPNet
+}
+
+// newDenyList parses host patterns, where * matches one label, IP addresses
+// and CIDR ranges
+func newDenyList(patterns []string) (*denyList, error) {
+	d := &denyList{}
+	for _, pattern := range normalizePatterns(patterns) {
+		if strings.Contains(pattern, "/") {
+			_, network, err := net.ParseCIDR(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid denied range %s: %w", pattern, err)
+			}
+			d.nets = append(d.nets, network)
+			continue
+		}
+
+		if ip := net.ParseIP(pattern); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			d.nets = append(d.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		d.hosts = append(d.hosts, pattern)
+	}
+	return d, nil
+}
+
+// checkHost returns an error when the host, a name or an address, is denied
+func (d *denyList) checkHost(host string) error {
+	host = strings.ToLower(host)
+	if ip := net.ParseIP(host); ip != nil {
+		return d.checkIP(ip)
+	}
+	if matchesAny(d.hosts, host) {
+		return fmt.Errorf("host %s is denied", host)
+	}
+	return nil
+}
+
+func (d *denyList) checkIP(ip net.IP) error {
+	for _, network := range d.nets {
+		if network.Contains(ip) {
+			return fmt.Errorf("address %s is denied", ip)
+		}
+	}
+	return nil
+}
+
+// proxy wraps the proxy selection of a transport, which runs before every
+// request, to reject requests to denied hosts before anything is sent
+func (d *denyList) proxy(next func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if err := d.checkHost(req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		if next == nil {
+			return nil, nil
+		}
+		return next(req)
+	}
+}
+
+// control rejects connections to denied addresses once names are resolved,
+// so hosts aliasing a denied address can't reach it either
+func (d *denyList) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return d.checkIP(ip)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDenyListCheckHost(t *testing.T) {
+	deny, err := newDenyList(append(append([]string{}, DefaultDenyHosts...), "*.corp.example.com", "10.0.0.0/8", "192.0.2.7"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		host   string
+		denied bool
+	}{
+		{host: "169.254.169.254", denied: true},
+		{host: "fd00:ec2::254", denied: true},
+		{host: "Metadata.Google.Internal", denied: true},
+		{host: "vault.corp.example.com", denied: true},
+		{host: "10.20.30.40", denied: true},
+		{host: "192.0.2.7", denied: true},
+		{host: "192.0.2.8", denied: false},
+		{host: "api.example.com", denied: false},
+		{host: "corp.example.com", denied: false},
+		{host: "169.254.169.253", denied: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := deny.checkHost(tt.host)
+			if denied := err != nil; denied != tt.denied {
+				t.Errorf("Expected denied %v, got %v", tt.denied, err)
+			}
+		})
+	}
+}
+
+func TestNewInvalidDenyHosts(t *testing.T) {
+	if _, err := New(Config{DenyHosts: []string{"10.0.0.0/33"}}); err == nil {
+		t.Error("Expected an error for an invalid range")
+	}
+}
+
+func TestNewDeniesHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request to %s", r.URL)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	tests := []struct {
+		name          string
+		deny          string
+		url           string
+		expectedError string
+	}{
+		{name: "denied address", deny: "127.0.0.1", url: server.URL, expectedError: "address 127.0.0.1 is denied"},
+		{name: "name resolving to a denied range", deny: "127.0.0.0/8", url: "http://localhost:" + serverURL.Port(), expectedError: "is denied"},
+		{name: "denied name", deny: "localhost", url: "http://localhost:" + serverURL.Port(), expectedError: "host localhost is denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(Config{DenyHosts: []string{tt.deny}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, err := client.Get(tt.url)
+			if err == nil {
+				resp.Body.Close()
+				t.Fatalf("Expected the request to be denied")
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
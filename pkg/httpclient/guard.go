@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// HostGuard keeps requests on allowed hosts, so neither a spec, a base URL
+// override nor an upstream redirect can turn kumoctl into a proxy to internal
+// services such as cloud metadata endpoints
+type HostGuard struct {
+	// trusted hosts are reachable whatever their address
+	trusted []string
+	// checked hosts are reachable unless they resolve to an internal address
+	checked []string
+	// lookup resolves the addresses of checked hosts
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewHostGuard creates a guard allowing no host at all
+func NewHostGuard() *HostGuard {
+	return &HostGuard{lookup: net.DefaultResolver.LookupIPAddr}
+}
+
+// Trust allows requests to the hosts matching the patterns whatever their
+// address. In patterns, * matches one label, e.g. *.example.com, and a lone *
+// matches every host.
+func (g *HostGuard) Trust(patterns ...string) {
+	g.trusted = append(g.trusted, normalizePatterns(patterns)...)
+}
+
+// Allow allows requests to the hosts matching the patterns as long as they
+// don't resolve to a private, link-local or unspecified address. Loopback
+// addresses stay reachable, for local mocks and development servers.
+func (g *HostGuard) Allow(patterns ...string) {
+	g.checked = append(g.checked, normalizePatterns(patterns)...)
+}
+
+// Check returns an error when requests to the URL aren't allowed
+func (g *HostGuard) Check(ctx context.Context, u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	if matchesAny(g.trusted, host) {
+		return nil
+	}
+	if !matchesAny(g.checked, host) {
+		return fmt.Errorf("host %s is not allowed", host)
+	}
+
+	var addrs []net.IPAddr
+	if ip := net.ParseIP(host); ip != nil {
+		addrs = []net.IPAddr{{IP: ip}}
+	} else {
+		var err error
+		if addrs, err = g.lookup(ctx, host); err != nil {
+			return err
+		}
+	}
+
+	for _, addr := range addrs {
+		if isInternal(addr.IP) {
+			return fmt.Errorf("host %s is not allowed, it resolves to the internal address %s", host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// Client returns a copy of client checking every request it sends, redirects
+// included, before any connection is made
+func (g *HostGuard) Client(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	guarded := *client
+	guarded.Transport = &guardTransport{guard: g, next: next}
+	return &guarded
+}
+
+type guardTransport struct {
+	guard *HostGuard
+	next  http.RoundTripper
+}
+
+func (t *guardTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.guard.Check(req.Context(), req.URL); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// isInternal reports whether an address belongs to a network that outside
+// callers must not reach through kumoctl
+func isInternal(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+func normalizePatterns(patterns []string) []string {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			normalized = append(normalized, pattern)
+		}
+	}
+	return normalized
+}
+
+func matchesAny(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if matchHost(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHost matches a host against a pattern label by label, so wildcards
+// never span dots
+func matchHost(pattern, host string) bool {
+	if pattern == "*" || pattern == host {
+		return true
+	}
+
+	patternLabels := strings.Split(pattern, ".")
+	hostLabels := strings.Split(host, ".")
+	if len(patternLabels) != len(hostLabels) {
+		return false
+	}
+	for i, label := range patternLabels {
+		if ok, err := path.Match(label, hostLabels[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHostGuardCheck(t *testing.T) {
+	guard := NewHostGuard()
+	guard.Trust("api.example.com", "10.0.0.5", "*.internal.example.com")
+	guard.Allow("*.tenants.example.com", "169.254.169.254", "127.0.0.1", "10.1.2.3", "rebind.example.net")
+	guard.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "rebind.example.net":
+			return []net.IPAddr{{IP: net.ParseIP("192.168.1.10")}}, nil
+		default:
+			return []net.IPAddr{{IP: net.ParseIP("203.0.113.7")}}, nil
+		}
+	}
+
+	tests := []struct {
+		url           string
+		expectedError string
+	}{
+		{url: "https://api.example.com/v1/users"},
+		{url: "https://API.example.com:8443/v1"},
+		{url: "http://10.0.0.5/admin"},
+		{url: "https://billing.internal.example.com"},
+		{url: "https://acme.tenants.example.com/v1"},
+		{url: "http://127.0.0.1:8081/v1"},
+		{url: "https://evil.example.org", expectedError: "host evil.example.org is not allowed"},
+		{url: "https://a.b.tenants.example.com", expectedError: "is not allowed"},
+		{url: "http://169.254.169.254/latest/meta-data", expectedError: "internal address 169.254.169.254"},
+		{url: "http://10.1.2.3/", expectedError: "internal address 10.1.2.3"},
+		{url: "http://rebind.example.net/", expectedError: "internal address 192.168.1.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("Invalid URL: %v", err)
+			}
+
+			err = guard.Check(context.Background(), u)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestHostGuardTrustAll(t *testing.T) {
+	guard := NewHostGuard()
+	guard.Trust("*")
+
+	u, _ := url.Parse("http://169.254.169.254/")
+	if err := guard.Check(context.Background(), u); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHostGuardClientRejectsRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	guard := NewHostGuard()
+	guard.Trust(serverURL.Hostname())
+
+	resp, err := guard.Client(nil).Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("Expected the redirect to be rejected")
+	}
+	if !strings.Contains(err.Error(), "host 169.254.169.254 is not allowed") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
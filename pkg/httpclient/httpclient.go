@@ -0,0 +1,136 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Config configures the HTTP client shared by all generated tools and remote
+// spec loading
+type Config struct {
+	// MaxIdleConns limits idle keep-alive connections across all hosts
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits idle keep-alive connections to each host
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes idle connections after this duration
+	IdleConnTimeout time.Duration
+	// DisableKeepAlives opens a new connection for every request
+	DisableKeepAlives bool
+	// Proxy is used for every request when set, otherwise HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY from the environment are honored
+	Proxy string
+	// CACertFile is a PEM bundle of additional certificate authorities trusted
+	// alongside the system roots
+	CACertFile string
+	// ClientCertFile and ClientKeyFile are a PEM certificate and private key
+	// presented to servers requiring mutual TLS
+	ClientCertFile string
+	ClientKeyFile  string
+	// InsecureSkipVerify disables TLS certificate verification. Only meant for
+	// development environments with self-signed certificates.
+	InsecureSkipVerify bool
+	// DenyHosts are host patterns, IP addresses and CIDR ranges no request may
+	// reach, in addition to DefaultDenyHosts
+	DenyHosts []string
+}
+
+// DefaultConfig returns the connection pooling defaults
+func DefaultConfig() Config {
+	return Config{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// New creates an HTTP client with a pooled transport. The client has no
+// overall timeout, callers bound requests through their context instead.
+func New(cfg Config) (*http.Client, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default transport type %T", http.DefaultTransport)
+	}
+
+	transport = transport.Clone()
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	transport.DisableKeepAlives = cfg.DisableKeepAlives
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL: %s", cfg.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	deny, err := newDenyList(append(append([]string{}, DefaultDenyHosts...), cfg.DenyHosts...))
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = deny.proxy(transport.Proxy)
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: deny.control}
+	transport.DialContext = dialer.DialContext
+
+	if cfg.CACertFile != "" {
+		pool, err := loadCertPool(cfg.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig(transport)
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		if cfg.ClientCertFile == "" || cfg.ClientKeyFile == "" {
+			return nil, fmt.Errorf("client certificate and key must be provided together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig(transport)
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.InsecureSkipVerify {
+		transport.TLSClientConfig = tlsConfig(transport)
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// tlsConfig returns a copy of the transport's TLS config that can be modified
+func tlsConfig(transport *http.Transport) *tls.Config {
+	if transport.TLSClientConfig == nil {
+		return &tls.Config{}
+	}
+	return transport.TLSClientConfig.Clone()
+}
+
+// loadCertPool returns the system roots extended with the certificates in the
+// PEM file at path
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// redactedValue replaces secrets in anything returned to the client
+const redactedValue = "[REDACTED]"
+
+// addSecretQueryParams sets the configured secret query parameters on a URL,
+// overriding any value of the same name taken from tool input
+func addSecretQueryParams(u *url.URL, params url.Values) {
+	if len(params) == 0 {
+		return
+	}
+
+	query := u.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	u.RawQuery = query.Encode()
+}
+
+// removeSecretInputs hides inputs that are filled from secret query parameters
+// so clients are never asked for them
+func removeSecretInputs(schema *jsonschema.Schema, params url.Values) {
+	if schema == nil || len(params) == 0 {
+		return
+	}
+
+	for name := range params {
+		delete(schema.Properties, name)
+		schema.Required = slices.DeleteFunc(schema.Required, func(required string) bool {
+			return required == name
+		})
+	}
+}
+
+// minRedactLength is the length from which secret values are redacted
+// anywhere. Shorter ones, such as 1 or abc, would also match unrelated data,
+// so they are only redacted as the value of their query parameter.
+const minRedactLength = 8
+
+// redact replaces every secret query parameter value in s, both raw and URL
+// encoded, since transport errors embed the full request URL
+func (o *ToolOptions) redact(s string) string {
+	for name, values := range o.QueryParams {
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if len(value) >= minRedactLength {
+				s = strings.ReplaceAll(s, value, redactedValue)
+				s = strings.ReplaceAll(s, url.QueryEscape(value), redactedValue)
+				continue
+			}
+
+			param := regexp.MustCompile(`(^|[?&])` + regexp.QuoteMeta(url.QueryEscape(name)+"=") +
+				`(?:` + regexp.QuoteMeta(value) + `|` + regexp.QuoteMeta(url.QueryEscape(value)) + `)([&#\s"']|$)`)
+			s = param.ReplaceAllString(s, "${1}"+url.QueryEscape(name)+"="+redactedValue+"${2}")
+		}
+	}
+	return s
+}
+
+// redactOutput removes secret query parameter values from a tool result
+func (o *ToolOptions) redactOutput(output APIToolOutput) APIToolOutput {
+	if len(o.QueryParams) == 0 {
+		return output
+	}
+
+	output.Error = o.redact(output.Error)
+	output.Snippet = o.redact(output.Snippet)
+	for key, value := range output.Headers {
+		output.Headers[key] = o.redact(value)
+	}
+	output.Body = o.redactValue(output.Body)
+	return output
+}
+
+// redactValue redacts the strings nested anywhere in a parsed response body
+func (o *ToolOptions) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return o.redact(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = o.redactValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = o.redactValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCreateAPIHandlerForTool_QueryAPIKey(t *testing.T) {
+	var received url.Values
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", r.URL.String())
+		json.NewEncoder(w).Encode(map[string]interface{}{"echo": r.URL.RawQuery})
+	}))
+	defer mockServer.Close()
+
+	tool := &EnrichedTool{
+		Tool:    &mcp.Tool{Name: "listUsers"},
+		BaseUrl: mockServer.URL,
+		Method:  "get",
+		Path:    "/users",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{
+			Parameters: openapi3.Parameters{
+				{Value: &openapi3.Parameter{Name: "api_key", In: "query"}},
+				{Value: &openapi3.Parameter{Name: "limit", In: "query"}},
+			},
+		}},
+	}
+
+	opts := &ToolOptions{QueryParams: url.Values{"api_key": {"s3cr3t+key"}}}
+	handler := createAPIHandlerForTool(tool, opts)
+
+	_, output, err := handler(context.Background(), nil, APIToolInput{"limit": 10, "api_key": "from-input"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if received.Get("api_key") != "s3cr3t+key" || received.Get("limit") != "10" {
+		t.Errorf("Expected configured API key and input params upstream, got %v", received)
+	}
+
+	encoded, _ := json.Marshal(output)
+	if strings.Contains(string(encoded), "s3cr3t") {
+		t.Errorf("API key leaked into tool output: %s", encoded)
+	}
+}
+
+func TestCreateAPIHandlerForTool_QueryAPIKeyRedactsErrors(t *testing.T) {
+	tool := &EnrichedTool{
+		Tool:      &mcp.Tool{Name: "listUsers"},
+		BaseUrl:   "http://127.0.0.1:1",
+		Method:    "get",
+		Path:      "/users",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{}},
+	}
+
+	opts := &ToolOptions{QueryParams: url.Values{"api_key": {"s3cr3t"}}}
+	_, output, _ := createAPIHandlerForTool(tool, opts)(context.Background(), nil, APIToolInput{})
+
+	if output.Error == "" {
+		t.Fatal("Expected connection error")
+	}
+	if strings.Contains(output.Error, "s3cr3t") || !strings.Contains(output.Error, redactedValue) {
+		t.Errorf("Expected API key to be redacted, got %q", output.Error)
+	}
+}
+
+func TestRemoveSecretInputs(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"api_key": {Type: "string"},
+			"limit":   {Type: "integer"},
+		},
+		Required: []string{"api_key", "limit"},
+	}
+
+	removeSecretInputs(schema, url.Values{"api_key": {"s3cr3t"}})
+
+	if _, ok := schema.Properties["api_key"]; ok {
+		t.Error("Expected api_key to be removed from properties")
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"limit"}) {
+		t.Errorf("Expected only limit to be required, got %v", schema.Required)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	opts := &ToolOptions{QueryParams: url.Values{"api_key": {"abc"}, "token": {"s3cr3t-token"}}}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "GET https://api.example.com/pets?api_key=abc&limit=1", expected: "GET https://api.example.com/pets?api_key=[REDACTED]&limit=1"},
+		{input: `Get "https://api.example.com/pets?limit=1&api_key=abc": connection refused`, expected: `Get "https://api.example.com/pets?limit=1&api_key=[REDACTED]": connection refused`},
+		{input: "the abc of pets, api_key=abcd", expected: "the abc of pets, api_key=abcd"},
+		{input: "token s3cr3t-token in the body", expected: "token [REDACTED] in the body"},
+	}
+
+	for _, tt := range tests {
+		if got := opts.redact(tt.input); got != tt.expected {
+			t.Errorf("redact(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryLeeway renews tokens slightly before they expire, so a request
+// never leaves with a token that expires in flight
+const tokenExpiryLeeway = 30 * time.Second
+
+// Authenticator adds credentials to upstream requests. When the API rejects a
+// request with 401 the credentials are invalidated, and the request is retried
+// once with renewed ones.
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *http.Request) error
+	Invalidate()
+}
+
+// requestInvalidator is implemented by authenticators holding credentials per
+// upstream, so a 401 only invalidates the ones the rejected request carried
+type requestInvalidator interface {
+	InvalidateRequest(req *http.Request)
+}
+
+// TokenSource returns a bearer token, renewing it as needed
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Invalidate()
+}
+
+// BearerAuthenticator sends the tokens of a TokenSource as bearer tokens
+type BearerAuthenticator struct {
+	Source TokenSource
+}
+
+// Authenticate sets the Authorization header
+func (a *BearerAuthenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.Source.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate discards the current token
+func (a *BearerAuthenticator) Invalidate() {
+	a.Source.Invalidate()
+}
+
+// OAuth2Config configures an OAuth2TokenSource
+type OAuth2Config struct {
+	// TokenURL is the token endpoint of the authorization server
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// RefreshToken selects the refresh_token grant, otherwise client
+	// credentials are used
+	RefreshToken string
+	// AccessToken is a token minted before startup, used until it is rejected
+	AccessToken string
+}
+
+// OAuth2TokenSource manages the lifecycle of an OAuth2 access token, renewing
+// it with the refresh token or client credentials when it expires or is
+// rejected
+type OAuth2TokenSource struct {
+	cfg    OAuth2Config
+	client *http.Client
+	now    func() time.Time
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	expiry       time.Time
+}
+
+// NewOAuth2TokenSource creates a token source requesting tokens with client,
+// nil uses http.DefaultClient
+func NewOAuth2TokenSource(cfg OAuth2Config, client *http.Client) (*OAuth2TokenSource, error) {
+	if cfg.TokenURL == "" {
+		return nil, fmt.Errorf("OAuth2 token URL must not be empty")
+	}
+	if cfg.RefreshToken == "" && cfg.ClientID == "" {
+		return nil, fmt.Errorf("OAuth2 requires a refresh token or client credentials")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &OAuth2TokenSource{
+		cfg:          cfg,
+		client:       client,
+		now:          time.Now,
+		accessToken:  cfg.AccessToken,
+		refreshToken: cfg.RefreshToken,
+	}, nil
+}
+
+// Token returns the current access token, requesting a new one when there is
+// none or it is about to expire
+func (s *OAuth2TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && (s.expiry.IsZero() || s.now().Add(tokenExpiryLeeway).Before(s.expiry)) {
+		return s.accessToken, nil
+	}
+
+	if err := s.fetch(ctx); err != nil {
+		return "", err
+	}
+	return s.accessToken, nil
+}
+
+// Invalidate discards the current access token
+func (s *OAuth2TokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessToken = ""
+}
+
+// oauth2TokenResponse is the token endpoint response of RFC 6749 section 5.1
+type oauth2TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetch requests a new access token, the caller must hold s.mu
+func (s *OAuth2TokenSource) fetch(ctx context.Context) error {
+	form := url.Values{}
+	if s.refreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", s.refreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if len(s.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	}
+
+	token, err := requestToken(ctx, s.client, s.cfg.TokenURL, s.cfg.ClientID, s.cfg.ClientSecret, form)
+	if err != nil {
+		return err
+	}
+
+	s.accessToken = token.AccessToken
+	s.expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		s.expiry = s.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	// Servers may rotate the refresh token with every use
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+
+	return nil
+}
+
+// requestToken posts a grant to an OAuth2 token endpoint, authenticating the
+// client with HTTP basic auth when credentials are given
+func requestToken(ctx context.Context, client *http.Client, tokenURL, clientID, clientSecret string, form url.Values) (*oauth2TokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var token oauth2TokenResponse
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("invalid token response (status %d)", resp.StatusCode)
+	}
+
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		if token.Error != "" {
+			return nil, fmt.Errorf("token request rejected (status %d): %s %s", resp.StatusCode, token.Error, token.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token request rejected (status %d)", resp.StatusCode)
+	}
+
+	return &token, nil
+}
+
+// sendAuthenticated sends a request with the credentials of auth. When the API
+// answers 401 the credentials are renewed and the request is sent once more.
+func sendAuthenticated(ctx context.Context, req *http.Request, auth Authenticator, opts *ToolOptions) (*http.Response, error) {
+	if auth == nil {
+		return opts.httpClient().Do(req)
+	}
+
+	// Keep a pristine copy, the retry needs a fresh body
+	retry := req.Clone(ctx)
+
+	if err := auth.Authenticate(ctx, req); err != nil {
+		return nil, fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	resp, err := opts.httpClient().Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	if invalidator, ok := auth.(requestInvalidator); ok {
+		invalidator.InvalidateRequest(req)
+	} else {
+		auth.Invalidate()
+	}
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := auth.Authenticate(ctx, retry); err != nil {
+		return nil, fmt.Errorf("failed to renew credentials: %w", err)
+	}
+
+	opts.logger().Info("renewed credentials after 401, retrying", "method", retry.Method, "path", retry.URL.Path)
+	return opts.httpClient().Do(retry)
+}
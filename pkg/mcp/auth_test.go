@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newTokenServer issues token-1, token-2, ... and records the grants it saw
+func newTokenServer(t *testing.T, grants *[]string) *httptest.Server {
+	t.Helper()
+
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse token request: %v", err)
+		}
+		*grants = append(*grants, r.PostForm.Get("grant_type")+":"+r.PostForm.Get("refresh_token"))
+
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("token-%d", n),
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOAuth2TokenSourceLifecycle(t *testing.T) {
+	var grants []string
+	tokenServer := newTokenServer(t, &grants)
+
+	source, err := NewOAuth2TokenSource(OAuth2Config{TokenURL: tokenServer.URL, RefreshToken: "refresh-0"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now := time.Unix(0, 0)
+	source.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if token, err := source.Token(ctx); err != nil || token != "token-1" {
+			t.Fatalf("Expected cached token-1, got %q (%v)", token, err)
+		}
+	}
+
+	// Tokens are renewed shortly before they expire, with the rotated refresh token
+	now = now.Add(time.Hour - tokenExpiryLeeway)
+	if token, err := source.Token(ctx); err != nil || token != "token-2" {
+		t.Fatalf("Expected renewed token-2, got %q (%v)", token, err)
+	}
+
+	source.Invalidate()
+	if token, err := source.Token(ctx); err != nil || token != "token-3" {
+		t.Fatalf("Expected token-3 after invalidation, got %q (%v)", token, err)
+	}
+
+	expected := []string{"refresh_token:refresh-0", "refresh_token:refresh-1", "refresh_token:refresh-2"}
+	if fmt.Sprint(grants) != fmt.Sprint(expected) {
+		t.Errorf("Expected grants %v, got %v", expected, grants)
+	}
+}
+
+func TestCreateAPIHandlerForTool_RetriesAfterUnauthorized(t *testing.T) {
+	var grants []string
+	tokenServer := newTokenServer(t, &grants)
+
+	var calls atomic.Int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// The startup token has expired, only freshly minted ones are accepted
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer apiServer.Close()
+
+	source, err := NewOAuth2TokenSource(OAuth2Config{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		AccessToken:  "expired",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tool := &EnrichedTool{
+		Tool:      &mcp.Tool{Name: "listUsers"},
+		BaseUrl:   apiServer.URL,
+		Method:    "get",
+		Path:      "/users",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{}},
+	}
+
+	handler := createAPIHandlerForTool(tool, &ToolOptions{Auth: &BearerAuthenticator{Source: source}})
+	_, output, err := handler(context.Background(), nil, APIToolInput{})
+	if err != nil || output.StatusCode != http.StatusOK {
+		t.Fatalf("Expected retry to succeed, got %v %+v", err, output)
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("Expected the request to be retried once, got %d calls", calls.Load())
+	}
+	if len(grants) != 1 || grants[0] != "client_credentials:" {
+		t.Errorf("Expected a single client credentials grant, got %v", grants)
+	}
+}
+
+func TestNewOAuth2TokenSourceErrors(t *testing.T) {
+	if _, err := NewOAuth2TokenSource(OAuth2Config{ClientID: "client"}, nil); err == nil {
+		t.Error("Expected error without token URL")
+	}
+	if _, err := NewOAuth2TokenSource(OAuth2Config{TokenURL: "https://auth.example.com/token"}, nil); err == nil {
+		t.Error("Expected error without grant")
+	}
+}
+
+func TestTokenExchangeAuthenticator(t *testing.T) {
+	var audiences []string
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse token request: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != tokenExchangeGrantType {
+			t.Errorf("Expected token exchange grant, got %q", got)
+		}
+		if got := r.PostForm.Get("subject_token"); got != "subject" {
+			t.Errorf("Expected subject token, got %q", got)
+		}
+		if got := r.PostForm.Get("subject_token_type"); got != AccessTokenType {
+			t.Errorf("Expected access token type, got %q", got)
+		}
+
+		audience := r.PostForm.Get("audience")
+		audiences = append(audiences, audience)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":      "token-for-" + audience,
+			"issued_token_type": AccessTokenType,
+			"token_type":        "Bearer",
+			"expires_in":        3600,
+		})
+	}))
+	t.Cleanup(sts.Close)
+
+	auth, err := NewTokenExchangeAuthenticator(TokenExchangeConfig{
+		TokenURL:     sts.URL,
+		SubjectToken: "subject",
+		Audiences:    map[string]string{"billing.example.com": "billing-api"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://billing.example.com/invoices", expected: "Bearer token-for-billing-api"},
+		{url: "https://billing.example.com/customers", expected: "Bearer token-for-billing-api"},
+		{url: "https://users.example.com:8443/users", expected: "Bearer token-for-https://users.example.com:8443"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		if err := auth.Authenticate(context.Background(), req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := req.Header.Get("Authorization"); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.url, tt.expected, got)
+		}
+	}
+
+	// Tokens are cached per audience
+	if len(audiences) != 2 {
+		t.Errorf("Expected one exchange per audience, got %v", audiences)
+	}
+
+	// A rejected request only invalidates the token of its audience
+	auth.InvalidateRequest(httptest.NewRequest(http.MethodGet, "https://billing.example.com/invoices", nil))
+	for _, rawURL := range []string{"https://users.example.com:8443/users", "https://billing.example.com/invoices"} {
+		if err := auth.Authenticate(context.Background(), httptest.NewRequest(http.MethodGet, rawURL, nil)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(audiences) != 3 || audiences[2] != "billing-api" {
+		t.Errorf("Expected a new exchange for the invalidated audience only, got %v", audiences)
+	}
+
+	auth.Invalidate()
+	req := httptest.NewRequest(http.MethodGet, "https://billing.example.com/invoices", nil)
+	if err := auth.Authenticate(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(audiences) != 4 {
+		t.Errorf("Expected a new exchange after invalidation, got %v", audiences)
+	}
+}
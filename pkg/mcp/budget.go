@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// BudgetClassAll counts every tool call
+	BudgetClassAll = "all"
+	// BudgetClassRead counts calls to GET, HEAD and OPTIONS operations
+	BudgetClassRead = "read"
+	// BudgetClassWrite counts calls to every other, mutating operation
+	BudgetClassWrite = "write"
+)
+
+// RequestBudget caps the number of upstream requests made per UTC day for each
+// API key. Once the overall budget is spent mutating tools are refused, while a
+// class-specific budget refuses every tool of its class.
+type RequestBudget struct {
+	limits map[string]int
+	now    func() time.Time
+
+	mu     sync.Mutex
+	day    string
+	counts map[string]map[string]int
+}
+
+// NewRequestBudget creates a budget from daily limits keyed by tool class
+func NewRequestBudget(limits map[string]int) (*RequestBudget, error) {
+	for class, limit := range limits {
+		switch class {
+		case BudgetClassAll, BudgetClassRead, BudgetClassWrite:
+		default:
+			return nil, fmt.Errorf("unknown budget class: %s (expected all, read or write)", class)
+		}
+		if limit < 0 {
+			return nil, fmt.Errorf("budget for %s must not be negative", class)
+		}
+	}
+
+	return &RequestBudget{
+		limits: limits,
+		now:    time.Now,
+		counts: make(map[string]map[string]int),
+	}, nil
+}
+
+// Spend records a call of the given class against the key, or returns an error
+// telling the agent to stop when the budget doesn't allow it
+func (b *RequestBudget) Spend(key string, class string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Reset all counters when the UTC day rolls over
+	today := b.now().UTC().Format(time.DateOnly)
+	if b.day != today {
+		b.day = today
+		b.counts = make(map[string]map[string]int)
+	}
+
+	counts, ok := b.counts[key]
+	if !ok {
+		counts = make(map[string]int)
+		b.counts[key] = counts
+	}
+
+	if limit, ok := b.limits[class]; ok && counts[class] >= limit {
+		return fmt.Errorf("daily %s request budget of %d is exhausted; stop calling these tools until the budget resets at midnight UTC", class, limit)
+	}
+
+	if limit, ok := b.limits[BudgetClassAll]; ok && class == BudgetClassWrite && counts[BudgetClassAll] >= limit {
+		return fmt.Errorf("daily request budget of %d is exhausted, mutating tools are disabled; stop making changes until the budget resets at midnight UTC", limit)
+	}
+
+	counts[class]++
+	counts[BudgetClassAll]++
+	return nil
+}
+
+// budgetClass returns the budget class for an HTTP method
+func budgetClass(method string) string {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return BudgetClassRead
+	default:
+		return BudgetClassWrite
+	}
+}
+
+// budgetKey fingerprints the configured credentials a request is sent with:
+// the static headers, the headers of the tool's tags, the ones the
+// authenticator adds and the secret query parameters. Header parameters and
+// templated headers are left out, as they may change with every call. Budgets
+// are thereby tracked per API key without keeping the secret itself in memory.
+func budgetKey(ctx context.Context, req *http.Request, tool *EnrichedTool, auth Authenticator, opts *ToolOptions) (string, error) {
+	headers := opts.Headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	for key, values := range tagHeadersFor(tool, opts.TagHeaders) {
+		headers[http.CanonicalHeaderKey(key)] = values
+	}
+
+	if auth != nil {
+		// The URL is kept, the credentials may depend on the host
+		probe := req.Clone(ctx)
+		probe.Header = make(http.Header)
+		if err := auth.Authenticate(ctx, probe); err != nil {
+			return "", err
+		}
+		for key, values := range probe.Header {
+			headers[key] = values
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s=%s\n", name, strings.Join(headers[name], ","))
+	}
+
+	if !tool.anonymous() {
+		params := make([]string, 0, len(opts.QueryParams))
+		for name := range opts.QueryParams {
+			params = append(params, name)
+		}
+		sort.Strings(params)
+		for _, name := range params {
+			fmt.Fprintf(hash, "?%s=%s\n", name, strings.Join(opts.QueryParams[name], ","))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
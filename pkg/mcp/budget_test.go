@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRequestBudget(t *testing.T) {
+	t.Run("overall budget disables mutating tools only", func(t *testing.T) {
+		budget, err := NewRequestBudget(map[string]int{BudgetClassAll: 2})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := budget.Spend("key", BudgetClassRead); err != nil {
+				t.Fatalf("Unexpected error on call %d: %v", i, err)
+			}
+		}
+
+		if err := budget.Spend("key", BudgetClassWrite); err == nil || !strings.Contains(err.Error(), "mutating tools are disabled") {
+			t.Errorf("Expected mutating tools to be disabled, got %v", err)
+		}
+
+		if err := budget.Spend("key", BudgetClassRead); err != nil {
+			t.Errorf("Read tools should keep working, got %v", err)
+		}
+	})
+
+	t.Run("class budget refuses its class", func(t *testing.T) {
+		budget, err := NewRequestBudget(map[string]int{BudgetClassWrite: 1})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := budget.Spend("key", BudgetClassWrite); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := budget.Spend("key", BudgetClassWrite); err == nil {
+			t.Error("Expected write budget to be exhausted")
+		}
+		if err := budget.Spend("other-key", BudgetClassWrite); err != nil {
+			t.Errorf("Budgets should be tracked per key, got %v", err)
+		}
+	})
+
+	t.Run("budget resets on a new day", func(t *testing.T) {
+		budget, err := NewRequestBudget(map[string]int{BudgetClassRead: 1})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		now := time.Date(2025, 1, 1, 23, 59, 0, 0, time.UTC)
+		budget.now = func() time.Time { return now }
+
+		if err := budget.Spend("key", BudgetClassRead); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := budget.Spend("key", BudgetClassRead); err == nil {
+			t.Fatal("Expected read budget to be exhausted")
+		}
+
+		now = now.Add(2 * time.Minute)
+		if err := budget.Spend("key", BudgetClassRead); err != nil {
+			t.Errorf("Expected budget to reset, got %v", err)
+		}
+	})
+
+	t.Run("unknown class is rejected", func(t *testing.T) {
+		if _, err := NewRequestBudget(map[string]int{"delete": 1}); err == nil {
+			t.Error("Expected error for unknown class")
+		}
+	})
+}
+
+// staticAuthenticator sets a fixed Authorization header
+type staticAuthenticator string
+
+func (a staticAuthenticator) Authenticate(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", string(a))
+	return nil
+}
+
+func (a staticAuthenticator) Invalidate() {}
+
+func TestBudgetKey(t *testing.T) {
+	tool := &EnrichedTool{Tool: &mcp.Tool{Name: "listPets"}, Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{Tags: []string{"admin"}}}}
+	key := func(rawURL string, headers http.Header, auth Authenticator, opts *ToolOptions) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, rawURL, nil)
+		for name, values := range headers {
+			req.Header[name] = values
+		}
+		key, err := budgetKey(context.Background(), req, tool, auth, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return key
+	}
+	withHeaders := func(value string) *ToolOptions {
+		return &ToolOptions{Headers: http.Header{"Authorization": {value}}}
+	}
+
+	a := key("https://api.example.com/pets", nil, nil, withHeaders("Bearer a"))
+	if a == key("https://api.example.com/pets", nil, nil, withHeaders("Bearer b")) {
+		t.Error("Different headers should produce different keys")
+	}
+	if strings.Contains(a, "Bearer") {
+		t.Error("Key should not contain the credential")
+	}
+	if a != key("https://api.example.com/pets", nil, nil, withHeaders("Bearer a")) {
+		t.Error("Key should be stable")
+	}
+
+	perCall := http.Header{"Idempotency-Key": {"1"}, "X-Session-Id": {"2"}, "X-Auth-Date": {"3"}, "X-Signature": {"4"}}
+	if a != key("https://api.example.com/pets?limit=1", perCall, nil, withHeaders("Bearer a")) {
+		t.Error("Header parameters, templated headers, signatures and parameters should not change the key")
+	}
+
+	secret := func(value string) *ToolOptions { return &ToolOptions{QueryParams: url.Values{"api_key": {value}}} }
+	if key("https://api.example.com/pets", nil, nil, secret("a")) == key("https://api.example.com/pets", nil, nil, secret("b")) {
+		t.Error("Different query API keys should produce different keys")
+	}
+
+	tagged := &ToolOptions{TagHeaders: map[string]http.Header{"admin": {"X-Admin-Token": {"a"}}}}
+	if key("https://api.example.com/pets", nil, nil, tagged) == key("https://api.example.com/pets", nil, nil, &ToolOptions{}) {
+		t.Error("Tag headers should produce different keys")
+	}
+
+	if key("https://api.example.com/pets", nil, staticAuthenticator("Bearer a"), &ToolOptions{}) == key("https://api.example.com/pets", nil, staticAuthenticator("Bearer b"), &ToolOptions{}) {
+		t.Error("Different authenticator credentials should produce different keys")
+	}
+	if a != key("https://api.example.com/pets", nil, staticAuthenticator("Bearer a"), &ToolOptions{}) {
+		t.Error("Authenticator credentials should be keyed like static ones")
+	}
+}
+
+func TestCreateAPIHandlerForTool_BudgetIgnoresHeaderParameters(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer mockServer.Close()
+
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "` + mockServer.URL + `"}],
+		"paths": {
+			"/orders": {
+				"post": {
+					"operationId": "createOrder",
+					"parameters": [{"name": "Idempotency-Key", "in": "header", "schema": {"type": "string"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	budget, err := NewRequestBudget(map[string]int{BudgetClassWrite: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	handler := createAPIHandlerForTool(tools[0], &ToolOptions{Budget: budget, Headers: http.Header{"Authorization": {"Bearer a"}}})
+
+	for i := 0; i < 3; i++ {
+		_, output, _ := handler(context.Background(), nil, APIToolInput{"Idempotency-Key": strconv.Itoa(i)})
+		if i < 2 && output.Error != "" {
+			t.Fatalf("Unexpected error on call %d: %s", i, output.Error)
+		}
+		if i == 2 && !strings.Contains(output.Error, "budget") {
+			t.Errorf("Expected the budget to be exhausted despite a new Idempotency-Key, got %+v", output)
+		}
+	}
+}
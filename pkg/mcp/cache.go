@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleRetention is how long expired entries with an ETag or Last-Modified
+// validator are kept for conditional requests
+const staleRetention = time.Hour
+
+// ResponseCache keeps successful GET tool outputs in memory for a fixed TTL,
+// since models frequently re-fetch the same resource within one conversation.
+// Expired entries carrying a validator are revalidated with a conditional
+// request, and replayed when the upstream answers 304 Not Modified. Beyond
+// maxEntries the least recently used entry is evicted.
+type ResponseCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// recent orders the entries from most to least recently used
+	recent *list.List
+}
+
+type cacheEntry struct {
+	key     string
+	output  APIToolOutput
+	expires time.Time
+}
+
+// NewResponseCache creates a cache whose entries expire after ttl, holding at
+// most maxEntries of them, 0 means unbounded
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	return &ResponseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+// lookup returns the entry for key, marking it as recently used
+func (c *ResponseCache) lookup(key string) (*cacheEntry, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.recent.MoveToFront(element)
+	return element.Value.(*cacheEntry), true
+}
+
+// Get returns the cached output for key if it hasn't expired
+func (c *ResponseCache) Get(key string) (APIToolOutput, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(key)
+	if !ok || !c.now().Before(entry.expires) {
+		return APIToolOutput{}, false
+	}
+
+	return entry.output, true
+}
+
+// Stale returns the output for key even when it has expired, as long as it is
+// retained for revalidation
+func (c *ResponseCache) Stale(key string) (APIToolOutput, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(key)
+	if !ok || !c.retained(entry, c.now()) {
+		return APIToolOutput{}, false
+	}
+
+	return entry.output, true
+}
+
+// Refresh renews the TTL of an entry the upstream confirmed as not modified
+func (c *ResponseCache) Refresh(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.lookup(key); ok {
+		entry.expires = c.now().Add(c.ttl)
+	}
+}
+
+// retained reports whether an entry is fresh or can still be revalidated
+func (c *ResponseCache) retained(entry *cacheEntry, now time.Time) bool {
+	if now.Before(entry.expires) {
+		return true
+	}
+	return hasValidator(entry.output) && now.Before(entry.expires.Add(staleRetention))
+}
+
+// Set stores output under key, evicting entries that can't be used anymore
+// and the least recently used ones beyond maxEntries
+func (c *ResponseCache) Set(key string, output APIToolOutput) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, element := range c.entries {
+		if !c.retained(element.Value.(*cacheEntry), now) {
+			c.recent.Remove(element)
+			delete(c.entries, k)
+		}
+	}
+
+	entry := &cacheEntry{key: key, output: output, expires: now.Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recent.MoveToFront(element)
+	} else {
+		c.entries[key] = c.recent.PushFront(entry)
+	}
+
+	for c.maxEntries > 0 && c.recent.Len() > c.maxEntries {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cacheKey identifies a request by method, URL and the headers set from the
+// input and the options, taken before the per-call templated headers and
+// signature. Header values are hashed so credentials are not kept in memory as
+// plain map keys.
+func cacheKey(req *http.Request) string {
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", req.Method, req.URL.String())
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, strings.Join(req.Header[key], ","))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// isCacheable reports whether a request's response may be served from cache
+func isCacheable(req *http.Request) bool {
+	return req.Method == http.MethodGet
+}
+
+// hasValidator reports whether an output can be revalidated with a
+// conditional request
+func hasValidator(output APIToolOutput) bool {
+	return output.Headers["Etag"] != "" || output.Headers["Last-Modified"] != ""
+}
+
+// setConditionalHeaders asks the upstream to answer 304 Not Modified when the
+// cached output is still current, unless the caller set its own conditions
+func setConditionalHeaders(req *http.Request, cached APIToolOutput) {
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return
+	}
+
+	if etag := cached.Headers["Etag"]; etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := cached.Headers["Last-Modified"]; lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+}
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestResponseCacheExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewResponseCache(time.Minute, 0)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("key", APIToolOutput{StatusCode: 200, Body: "cached"})
+
+	if output, ok := cache.Get("key"); !ok || output.Body != "cached" {
+		t.Fatalf("Expected cache hit, got %v %v", output, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("key"); ok {
+		t.Error("Expected entry to expire after TTL")
+	}
+}
+
+func TestResponseCacheMaxEntries(t *testing.T) {
+	cache := NewResponseCache(time.Minute, 2)
+
+	cache.Set("a", APIToolOutput{Body: "a"})
+	cache.Set("b", APIToolOutput{Body: "b"})
+	cache.Get("a")
+	cache.Set("c", APIToolOutput{Body: "c"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected %s to be kept", key)
+		}
+	}
+	if len(cache.entries) != 2 || cache.recent.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(cache.entries))
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	newRequest := func(url, auth string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Authorization", auth)
+		return req
+	}
+
+	base := cacheKey(newRequest("https://api.example.com/users?id=1", "a"))
+	if base != cacheKey(newRequest("https://api.example.com/users?id=1", "a")) {
+		t.Error("Identical requests should share a key")
+	}
+	if base == cacheKey(newRequest("https://api.example.com/users?id=2", "a")) {
+		t.Error("Different URLs should not share a key")
+	}
+	if base == cacheKey(newRequest("https://api.example.com/users?id=1", "b")) {
+		t.Error("Different headers should not share a key")
+	}
+}
+
+func TestCreateAPIHandlerForTool_Cache(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer mockServer.Close()
+
+	newTool := func(method string) *EnrichedTool {
+		return &EnrichedTool{
+			Tool:      &mcp.Tool{Name: method + "Tool"},
+			BaseUrl:   mockServer.URL,
+			Method:    method,
+			Path:      "/users",
+			Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{}},
+		}
+	}
+
+	opts := &ToolOptions{Cache: NewResponseCache(time.Minute, 0)}
+
+	getHandler := createAPIHandlerForTool(newTool("get"), opts)
+	for i := 0; i < 3; i++ {
+		_, output, err := getHandler(context.Background(), nil, APIToolInput{})
+		if err != nil || output.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected result: %v %+v", err, output)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected GET to be served from cache, got %d upstream requests", requests)
+	}
+
+	postHandler := createAPIHandlerForTool(newTool("post"), opts)
+	for i := 0; i < 2; i++ {
+		postHandler(context.Background(), nil, APIToolInput{})
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected POST to bypass the cache, got %d upstream requests", requests)
+	}
+}
+
+// sequenceSigner signs every request with a new nonce
+type sequenceSigner struct{ calls int }
+
+func (s *sequenceSigner) Sign(req *http.Request, body []byte) error {
+	s.calls++
+	req.Header.Set("X-Signature", strconv.Itoa(s.calls))
+	return nil
+}
+
+func TestCreateAPIHandlerForTool_CacheSignedRequests(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer mockServer.Close()
+
+	tool := &EnrichedTool{
+		Tool:      &mcp.Tool{Name: "getUser"},
+		BaseUrl:   mockServer.URL,
+		Method:    "get",
+		Path:      "/users/1",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{}},
+	}
+
+	requestTime, err := NewHeaderTemplate("X-Request-Time", "{{now.UnixNano}}")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	handler := createAPIHandlerForTool(tool, &ToolOptions{
+		Cache:           NewResponseCache(time.Minute, 0),
+		Signer:          &sequenceSigner{},
+		HeaderTemplates: []*HeaderTemplate{requestTime},
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, output, err := handler(context.Background(), nil, APIToolInput{}); err != nil || output.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected result: %v %+v", err, output)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected signed and templated GETs to be served from cache, got %d upstream requests", requests)
+	}
+}
+
+func TestCreateAPIHandlerForTool_NotModified(t *testing.T) {
+	var conditions []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditions = append(conditions, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer mockServer.Close()
+
+	tool := &EnrichedTool{
+		Tool:      &mcp.Tool{Name: "getUser"},
+		BaseUrl:   mockServer.URL,
+		Method:    "get",
+		Path:      "/users/1",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{}},
+	}
+
+	now := time.Unix(0, 0)
+	cache := NewResponseCache(time.Minute, 0)
+	cache.now = func() time.Time { return now }
+	handler := createAPIHandlerForTool(tool, &ToolOptions{Cache: cache})
+
+	call := func() APIToolOutput {
+		t.Helper()
+		_, output, err := handler(context.Background(), nil, APIToolInput{})
+		if err != nil || output.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected result: %v %+v", err, output)
+		}
+		if body, ok := output.Body.(map[string]interface{}); !ok || body["id"] != float64(1) {
+			t.Fatalf("Expected the cached body, got %+v", output)
+		}
+		return output
+	}
+
+	if output := call(); output.FromCache {
+		t.Error("Expected the first response not to come from the cache")
+	}
+
+	// Once expired the entry is revalidated and the 304 replays the body
+	now = now.Add(2 * time.Minute)
+	if output := call(); !output.FromCache {
+		t.Error("Expected the 304 to be answered from the cache")
+	}
+
+	// The 304 renewed the entry
+	if output := call(); !output.FromCache {
+		t.Error("Expected a cache hit after revalidation")
+	}
+
+	expected := []string{"", `"v1"`}
+	if len(conditions) != len(expected) || conditions[0] != expected[0] || conditions[1] != expected[1] {
+		t.Errorf("Expected conditions %q, got %q", expected, conditions)
+	}
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// structuredOutputProtocolVersion is the first MCP revision with structured tool output
+const structuredOutputProtocolVersion = "2025-06-18"
+
+// ClientFeatures describes the optional MCP features a connected client
+// declared support for during initialization. Tool list change notifications
+// and logging need no negotiation: every protocol revision defines the former
+// and log messages are only sent once the client sets a level.
+type ClientFeatures struct {
+	// StructuredOutput is set when tool results may carry structuredContent
+	StructuredOutput bool
+	// Elicitation is set when the server may ask the user for input
+	Elicitation bool
+	// Sampling is set when the server may request LLM completions
+	Sampling bool
+}
+
+// ClientFeaturesFor derives the features a client supports from its
+// initialize request. A nil request means the client isn't initialized yet,
+// so no optional feature is assumed.
+func ClientFeaturesFor(params *mcp.InitializeParams) ClientFeatures {
+	if params == nil {
+		return ClientFeatures{}
+	}
+
+	features := ClientFeatures{
+		StructuredOutput: params.ProtocolVersion >= structuredOutputProtocolVersion,
+	}
+
+	if params.Capabilities != nil {
+		features.Elicitation = params.Capabilities.Elicitation != nil && features.StructuredOutput
+		features.Sampling = params.Capabilities.Sampling != nil
+	}
+
+	return features
+}
+
+// Downgraded lists the optional features the client can't handle
+func (f ClientFeatures) Downgraded() []string {
+	var downgraded []string
+	if !f.StructuredOutput {
+		downgraded = append(downgraded, "structured output")
+	}
+	if !f.Elicitation {
+		downgraded = append(downgraded, "elicitation")
+	}
+	if !f.Sampling {
+		downgraded = append(downgraded, "sampling")
+	}
+	return downgraded
+}
+
+// sessionFeatures returns the features of the client behind a request
+func sessionFeatures(req mcp.Request) ClientFeatures {
+	if req == nil {
+		return ClientFeatures{}
+	}
+
+	session, ok := req.GetSession().(*mcp.ServerSession)
+	if !ok || session == nil {
+		return ClientFeatures{}
+	}
+
+	return ClientFeaturesFor(session.InitializeParams())
+}
+
+// AddCapabilityNegotiation makes the server adapt to each client's declared
+// capabilities: downgraded features are logged when the client initializes,
+// and structured tool output is withheld from clients predating it.
+func AddCapabilityNegotiation(server *mcp.Server, opts *ToolOptions) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return result, err
+			}
+
+			switch method {
+			case "initialize":
+				params, ok := req.GetParams().(*mcp.InitializeParams)
+				if !ok {
+					break
+				}
+				if downgraded := ClientFeaturesFor(params).Downgraded(); len(downgraded) > 0 {
+					clientName := "client"
+					if params.ClientInfo != nil && params.ClientInfo.Name != "" {
+						clientName = params.ClientInfo.Name
+					}
+					opts.logger().Warn("client does not support some features, they are disabled",
+						"client", clientName, "protocol", params.ProtocolVersion, "features", strings.Join(downgraded, ", "))
+				}
+			case "tools/call":
+				if res, ok := result.(*mcp.CallToolResult); ok && !sessionFeatures(req).StructuredOutput {
+					res.StructuredContent = nil
+				}
+			}
+
+			return result, nil
+		}
+	})
+}
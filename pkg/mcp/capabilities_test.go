@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestClientFeaturesFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     *mcp.InitializeParams
+		expected   ClientFeatures
+		downgraded []string
+	}{
+		{
+			name:       "not initialized",
+			params:     nil,
+			expected:   ClientFeatures{},
+			downgraded: []string{"structured output", "elicitation", "sampling"},
+		},
+		{
+			name: "current client with every capability",
+			params: &mcp.InitializeParams{
+				ProtocolVersion: "2025-06-18",
+				Capabilities: &mcp.ClientCapabilities{
+					Elicitation: &mcp.ElicitationCapabilities{},
+					Sampling:    &mcp.SamplingCapabilities{},
+				},
+			},
+			expected: ClientFeatures{StructuredOutput: true, Elicitation: true, Sampling: true},
+		},
+		{
+			name: "older protocol revision",
+			params: &mcp.InitializeParams{
+				ProtocolVersion: "2025-03-26",
+				Capabilities: &mcp.ClientCapabilities{
+					Elicitation: &mcp.ElicitationCapabilities{},
+				},
+			},
+			expected:   ClientFeatures{},
+			downgraded: []string{"structured output", "elicitation", "sampling"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			features := ClientFeaturesFor(tt.params)
+			if features != tt.expected {
+				t.Errorf("ClientFeaturesFor() = %+v, expected %+v", features, tt.expected)
+			}
+			if !reflect.DeepEqual(features.Downgraded(), tt.downgraded) {
+				t.Errorf("Downgraded() = %v, expected %v", features.Downgraded(), tt.downgraded)
+			}
+		})
+	}
+}
+
+func TestAddCapabilityNegotiationLogsDowngrades(t *testing.T) {
+	var logs bytes.Buffer
+	opts := &ToolOptions{Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	AddCapabilityNegotiation(server, opts)
+	mcp.AddTool(server, &mcp.Tool{Name: "ping", InputSchema: &jsonschema.Schema{Type: "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+			return nil, APIToolOutput{StatusCode: 200}, nil
+		})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "basic-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "ping"})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	if result.StructuredContent == nil {
+		t.Error("Expected structured output for a current client")
+	}
+
+	if !strings.Contains(logs.String(), "basic-client") || !strings.Contains(logs.String(), "elicitation") {
+		t.Errorf("Expected downgrade to be logged, got %q", logs.String())
+	}
+}
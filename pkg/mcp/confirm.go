@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// requiresConfirmation reports whether calls of the tool wait for the user's
+// approval
+func (o *ToolOptions) requiresConfirmation(tool *EnrichedTool) bool {
+	for _, method := range o.ConfirmMethods {
+		if strings.EqualFold(method, tool.Method) {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmCall wraps a handler to ask the user, through MCP elicitation, to
+// approve every call of the tool before anything is sent. Declined calls and
+// calls from clients unable to ask the user fail without reaching the API.
+func confirmCall(tool *EnrichedTool, opts *ToolOptions, handler apiToolHandler) apiToolHandler {
+	if opts.DryRun || !opts.requiresConfirmation(tool) {
+		return handler
+	}
+
+	return func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		if req == nil || req.Session == nil || !supportsElicitation(req.Session) {
+			return nil, APIToolOutput{Error: fmt.Sprintf("%s needs the user's confirmation, but the client can't ask for it. Nothing was sent", tool.Name)}, nil
+		}
+
+		message, err := confirmationMessage(tool, input, opts)
+		if err != nil {
+			return nil, APIToolOutput{Error: err.Error()}, nil
+		}
+
+		result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+			Message:         message,
+			RequestedSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{}},
+		})
+		if err != nil {
+			return nil, APIToolOutput{Error: fmt.Sprintf("Failed to ask for confirmation: %v. Nothing was sent", err)}, nil
+		}
+		if result.Action != "accept" {
+			return nil, APIToolOutput{Error: fmt.Sprintf("The user did not confirm the call of %s (%s). Nothing was sent", tool.Name, result.Action)}, nil
+		}
+
+		return handler(ctx, req, input)
+	}
+}
+
+// supportsElicitation reports whether the client declared it can ask its user
+// for input
+func supportsElicitation(session *mcp.ServerSession) bool {
+	params := session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// confirmationMessage describes the call awaiting approval, with secret query
+// parameter values redacted
+func confirmationMessage(tool *EnrichedTool, input APIToolInput, opts *ToolOptions) (string, error) {
+	data, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to describe input: %w", err)
+	}
+	return opts.redact(fmt.Sprintf("Allow %s (%s %s) with input:\n%s", tool.Name, strings.ToUpper(tool.Method), tool.Path, data)), nil
+}
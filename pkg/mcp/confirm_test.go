@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestConfirmCall(t *testing.T) {
+	tests := []struct {
+		name          string
+		tool          string
+		action        string
+		noElicitation bool
+		expectSent    bool
+		expectedError string
+	}{
+		{name: "accepted", tool: "deleteUser", action: "accept", expectSent: true},
+		{name: "declined", tool: "deleteUser", action: "decline", expectedError: "did not confirm"},
+		{name: "cancelled", tool: "deleteUser", action: "cancel", expectedError: "did not confirm"},
+		{name: "client without elicitation", tool: "deleteUser", noElicitation: true, expectedError: "can't ask for it"},
+		{name: "method not confirmed", tool: "getUser", noElicitation: true, expectSent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := false
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent = true
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{}`))
+			}))
+			defer upstream.Close()
+
+			spec, err := openapi.LoadSpec([]byte(`{
+				"openapi": "3.0.0",
+				"info": {"title": "Test", "version": "1.0.0"},
+				"servers": [{"url": "` + upstream.URL + `"}],
+				"paths": {
+					"/users/{id}": {
+						"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+						"get": {"operationId": "getUser", "responses": {"200": {"description": "OK"}}},
+						"delete": {"operationId": "deleteUser", "responses": {"204": {"description": "Deleted"}}}
+					}
+				}
+			}`))
+			if err != nil {
+				t.Fatalf("Failed to load spec: %v", err)
+			}
+
+			server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+			if err := GenerateToolsFromSpec(server, spec, &ToolOptions{ConfirmMethods: []string{"DELETE"}}); err != nil {
+				t.Fatalf("Failed to generate tools: %v", err)
+			}
+
+			var message string
+			clientOptions := &mcp.ClientOptions{}
+			if !tt.noElicitation {
+				clientOptions.ElicitationHandler = func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+					message = req.Params.Message
+					return &mcp.ElicitResult{Action: tt.action}, nil
+				}
+			}
+			client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0.0.1"}, clientOptions)
+
+			ctx := context.Background()
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			serverSession, err := server.Connect(ctx, serverTransport, nil)
+			if err != nil {
+				t.Fatalf("Failed to connect server: %v", err)
+			}
+			defer serverSession.Close()
+
+			session, err := client.Connect(ctx, clientTransport, nil)
+			if err != nil {
+				t.Fatalf("Failed to connect client: %v", err)
+			}
+			defer session.Close()
+
+			result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: tt.tool, Arguments: map[string]interface{}{"id": "42"}})
+			if err != nil {
+				t.Fatalf("Failed to call tool: %v", err)
+			}
+
+			if sent != tt.expectSent {
+				t.Errorf("Expected request sent %v, got %v", tt.expectSent, sent)
+			}
+
+			output, _ := result.StructuredContent.(map[string]interface{})
+			errorMessage, _ := output["error"].(string)
+			if tt.expectedError == "" && errorMessage != "" {
+				t.Errorf("Unexpected error: %s", errorMessage)
+			}
+			if !strings.Contains(errorMessage, tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %q", tt.expectedError, errorMessage)
+			}
+
+			if tt.action != "" && !strings.Contains(message, "deleteUser (DELETE /users/{id})") {
+				t.Errorf("Expected the call to be described, got %q", message)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+// maxDescriptionLength caps tool descriptions, which clients send to the
+// model with every request
+const maxDescriptionLength = 2000
+
+// deprecationWarning starts the description of deprecated operations' tools
+const deprecationWarning = "DEPRECATED: this operation is deprecated and may be removed, prefer another tool when one does the same."
+
+// descriptionSections head the documentation appended to the text of tool
+// descriptions
+var descriptionSections = []string{"Inputs:", "Allowed values:", "Responses:", "Documentation:"}
+
+// toolDescription composes the description of an operation's tool: its text,
+// the x-mcp-description or its summary and description after a warning for
+// deprecated operations, followed by the documentation of its inputs and
+// responses and its external docs, so models can tell similar tools apart
+func toolDescription(method, path string, operation openapi.Operation, inputSchema *jsonschema.Schema) (string, error) {
+	text, err := stringExtension(operation, extensionDescription)
+	if err != nil {
+		return "", err
+	}
+
+	if text == "" {
+		summary := strings.TrimSpace(operation.GetSummary())
+		details := strings.TrimSpace(operation.GetDescription())
+		switch {
+		case summary == "":
+			text = details
+		case details == "" || details == summary:
+			text = summary
+		default:
+			text = summary + "\n\n" + details
+		}
+	}
+	if text == "" {
+		text = fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+	}
+	if operation.IsDeprecated() {
+		text = deprecationWarning + "\n\n" + text
+	}
+
+	description := text + inputDocs(inputSchema) + enumHints(inputSchema) + responseDocs(operation.GetResponses())
+	if docs := operation.GetExternalDocs(); docs != "" {
+		description += "\n\nDocumentation: " + docs
+	}
+	return truncateDescription(description), nil
+}
+
+// descriptionText returns the text of a tool description, without the
+// documentation appended to it
+func descriptionText(description string) string {
+	for _, section := range descriptionSections {
+		if i := strings.Index(description, "\n\n"+section); i >= 0 {
+			description = description[:i]
+		}
+	}
+	return description
+}
+
+// inputDocs lists the tool's inputs that are documented, marking the
+// required ones
+func inputDocs(schema *jsonschema.Schema) string {
+	if schema == nil {
+		return ""
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	var docs []string
+	for name, property := range schema.Properties {
+		if property == nil {
+			continue
+		}
+		// Parameters without a description get a generic one such as
+		// "Query parameter: view", which tells nothing more
+		description := strings.Join(strings.Fields(property.Description), " ")
+		if description == "" || strings.HasSuffix(description, " parameter: "+name) {
+			continue
+		}
+
+		if required[name] {
+			docs = append(docs, fmt.Sprintf("- %s (required): %s", name, description))
+		} else {
+			docs = append(docs, fmt.Sprintf("- %s: %s", name, description))
+		}
+	}
+
+	if len(docs) == 0 {
+		return ""
+	}
+	sort.Strings(docs)
+	return "\n\nInputs:\n" + strings.Join(docs, "\n")
+}
+
+// responseDocs lists the responses whose description says more than their
+// status text, such as when a 404 is returned
+func responseDocs(responses []openapi.Response) string {
+	var docs []string
+	for _, response := range responses {
+		description := strings.Join(strings.Fields(response.Description), " ")
+		if description == "" {
+			continue
+		}
+		if status, err := strconv.Atoi(response.Status); err == nil && strings.EqualFold(description, http.StatusText(status)) {
+			continue
+		}
+		docs = append(docs, fmt.Sprintf("- %s: %s", response.Status, description))
+	}
+
+	if len(docs) == 0 {
+		return ""
+	}
+	return "\n\nResponses:\n" + strings.Join(docs, "\n")
+}
+
+// truncateDescription cuts descriptions longer than maxDescriptionLength at
+// the last line or word that fits
+func truncateDescription(description string) string {
+	runes := []rune(description)
+	if len(runes) <= maxDescriptionLength {
+		return description
+	}
+
+	truncated := string(runes[:maxDescriptionLength-3])
+	if i := strings.LastIndexAny(truncated, "\n "); i > 0 {
+		truncated = truncated[:i]
+	}
+	return strings.TrimRight(truncated, " \n:") + "..."
+}
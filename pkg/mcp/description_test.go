@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestToolDescription(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "https://api.example.com"}],
+		"paths": {
+			"/users/{id}": {
+				"get": {
+					"operationId": "getUser",
+					"summary": "Get a user",
+					"description": "Returns the user with its profile.",
+					"externalDocs": {"url": "https://docs.example.com/users"},
+					"parameters": [
+						{"name": "id", "in": "path", "required": true, "description": "Id of the user", "schema": {"type": "string"}},
+						{"name": "view", "in": "query", "schema": {"type": "string", "enum": ["full", "brief"]}}
+					],
+					"responses": {
+						"200": {"description": "OK"},
+						"404": {"description": "No user has this id"},
+						"default": {"description": "Unexpected error"}
+					}
+				}
+			},
+			"/users": {
+				"get": {"operationId": "listUsers", "responses": {"200": {"description": "OK"}}}
+			},
+			"/v1/users": {
+				"get": {"operationId": "listUsersV1", "summary": "List users", "deprecated": true, "responses": {"200": {"description": "OK"}}}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	descriptions := make(map[string]string)
+	for _, tool := range tools {
+		descriptions[tool.Name] = tool.Description
+	}
+
+	expected := `Get a user
+
+Returns the user with its profile.
+
+Inputs:
+- id (required): Id of the user
+
+Allowed values:
+- view: full, brief
+
+Responses:
+- 404: No user has this id
+- default: Unexpected error
+
+Documentation: https://docs.example.com/users`
+	if descriptions["getUser"] != expected {
+		t.Errorf("Expected description:\n%s\ngot:\n%s", expected, descriptions["getUser"])
+	}
+
+	if descriptions["listUsers"] != "GET /users" {
+		t.Errorf("Expected the fallback description, got %q", descriptions["listUsers"])
+	}
+
+	if descriptions["listUsersV1"] != deprecationWarning+"\n\nList users" {
+		t.Errorf("Expected a deprecation warning, got %q", descriptions["listUsersV1"])
+	}
+
+	if text := descriptionText(descriptions["getUser"]); text != "Get a user\n\nReturns the user with its profile." {
+		t.Errorf("Expected the text of the description, got %q", text)
+	}
+}
+
+func TestTruncateDescription(t *testing.T) {
+	if description := truncateDescription("short"); description != "short" {
+		t.Errorf("Expected short descriptions unchanged, got %q", description)
+	}
+
+	long := strings.Repeat("word ", maxDescriptionLength)
+	truncated := truncateDescription(long)
+	if len(truncated) > maxDescriptionLength {
+		t.Errorf("Expected at most %d characters, got %d", maxDescriptionLength, len(truncated))
+	}
+	if !strings.HasSuffix(truncated, "word...") {
+		t.Errorf("Expected the description cut after a word, got %q", truncated[len(truncated)-20:])
+	}
+}
@@ -0,0 +1,232 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+// ToolDiff lists how the tools generated from two versions of a spec differ
+type ToolDiff struct {
+	Added   []string
+	Removed []string
+	Changed []ToolChange
+}
+
+// ToolChange describes how a tool changed. Name is its new name, OldName is
+// set when the tool was renamed.
+type ToolChange struct {
+	Name    string
+	OldName string
+	Changes []Change
+}
+
+// Change is a single difference of a tool. Breaking changes can fail calls
+// clients made against the old version.
+type Change struct {
+	Message  string
+	Breaking bool
+}
+
+// Empty reports whether both versions generate the same tools
+func (d *ToolDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Breaking reports whether calls made against the old tools may fail
+func (d *ToolDiff) Breaking() bool {
+	if len(d.Removed) > 0 {
+		return true
+	}
+	for _, change := range d.Changed {
+		for _, c := range change.Changes {
+			if c.Breaking {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DiffTools compares the tools generated from two versions of a spec.
+// Operations are matched by operationId, or by method and path without one,
+// like when a ToolRegistry syncs.
+func DiffTools(oldSpec, newSpec openapi.APISpec) (*ToolDiff, error) {
+	oldTools, err := toolsByOperation(oldSpec)
+	if err != nil {
+		return nil, fmt.Errorf("old spec: %w", err)
+	}
+	newTools, err := toolsByOperation(newSpec)
+	if err != nil {
+		return nil, fmt.Errorf("new spec: %w", err)
+	}
+
+	diff := &ToolDiff{}
+	for key, newTool := range newTools {
+		oldTool, ok := oldTools[key]
+		if !ok {
+			diff.Added = append(diff.Added, newTool.Name)
+			continue
+		}
+
+		if changes := diffTool(oldTool, newTool); len(changes) > 0 {
+			change := ToolChange{Name: newTool.Name, Changes: changes}
+			if oldTool.Name != newTool.Name {
+				change.OldName = oldTool.Name
+			}
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for key, oldTool := range oldTools {
+		if _, ok := newTools[key]; !ok {
+			diff.Removed = append(diff.Removed, oldTool.Name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff, nil
+}
+
+func toolsByOperation(spec openapi.APISpec) (map[string]*EnrichedTool, error) {
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	byOperation := make(map[string]*EnrichedTool, len(tools))
+	for _, tool := range tools {
+		byOperation[operationKey(tool)] = tool
+	}
+	return byOperation, nil
+}
+
+// diffTool lists the differences of two versions of a tool
+func diffTool(oldTool, newTool *EnrichedTool) []Change {
+	var changes []Change
+	add := func(breaking bool, format string, args ...interface{}) {
+		changes = append(changes, Change{Message: fmt.Sprintf(format, args...), Breaking: breaking})
+	}
+
+	if oldTool.Name != newTool.Name {
+		add(true, "renamed from %s", oldTool.Name)
+	}
+
+	oldRoute := openapi.OperationLocation(oldTool.Method, oldTool.Path)
+	newRoute := openapi.OperationLocation(newTool.Method, newTool.Path)
+	if oldRoute != newRoute {
+		add(false, "operation moved from %s to %s", oldRoute, newRoute)
+	}
+	if oldTool.BaseUrl != newTool.BaseUrl {
+		add(false, "server changed from %s to %s", oldTool.BaseUrl, newTool.BaseUrl)
+	}
+
+	if oldTool.Description != newTool.Description {
+		add(false, "description changed")
+	}
+
+	changes = append(changes, diffInputs(oldTool.InputSchema, newTool.InputSchema)...)
+
+	if !sameSchema(oldTool.OutputSchema, newTool.OutputSchema) {
+		add(false, "output schema changed")
+	}
+
+	return changes
+}
+
+// diffInputs compares the inputs of two versions of a tool. New required
+// inputs, removed inputs and changed types break existing calls.
+func diffInputs(oldSchema, newSchema *jsonschema.Schema) []Change {
+	oldProperties, oldRequired := inputProperties(oldSchema)
+	newProperties, newRequired := inputProperties(newSchema)
+
+	names := make(map[string]bool)
+	for name := range oldProperties {
+		names[name] = true
+	}
+	for name := range newProperties {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	add := func(breaking bool, format string, args ...interface{}) {
+		changes = append(changes, Change{Message: fmt.Sprintf(format, args...), Breaking: breaking})
+	}
+
+	for _, name := range sorted {
+		oldProperty, inOld := oldProperties[name]
+		newProperty, inNew := newProperties[name]
+
+		switch {
+		case !inOld && newRequired[name]:
+			add(true, "required input %s added", name)
+		case !inOld:
+			add(false, "input %s added", name)
+		case !inNew:
+			add(true, "input %s removed", name)
+		default:
+			if !oldRequired[name] && newRequired[name] {
+				add(true, "input %s is now required", name)
+			}
+			if oldRequired[name] && !newRequired[name] {
+				add(false, "input %s is no longer required", name)
+			}
+
+			oldType, newType := schemaTypeName(oldProperty), schemaTypeName(newProperty)
+			if oldType != newType {
+				add(true, "input %s type changed from %s to %s", name, oldType, newType)
+			} else if !sameSchema(oldProperty, newProperty) {
+				add(false, "input %s schema changed", name)
+			}
+		}
+	}
+	return changes
+}
+
+func inputProperties(schema *jsonschema.Schema) (map[string]*jsonschema.Schema, map[string]bool) {
+	if schema == nil {
+		return nil, nil
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	return schema.Properties, required
+}
+
+// schemaTypeName names the types a schema allows, "any" when unconstrained
+func schemaTypeName(schema *jsonschema.Schema) string {
+	if schema == nil {
+		return "any"
+	}
+	if types := schemaTypes(schema); len(types) > 0 {
+		return strings.Join(types, " or ")
+	}
+	return "any"
+}
+
+// sameSchema compares two schemas by their JSON form
+func sameSchema(a, b *jsonschema.Schema) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return string(aData) == string(bData)
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestDiffTools(t *testing.T) {
+	oldSpec, err := openapi.LoadSpec([]byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Users", "version": "1"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "summary": "Get a user",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}},
+          {"name": "expand", "in": "query", "schema": {"type": "boolean"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/search": {
+      "get": {"operationId": "search", "responses": {"200": {"description": "OK"}}}
+    },
+    "/health": {
+      "get": {"responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatalf("Failed to load old spec: %v", err)
+	}
+
+	newSpec, err := openapi.LoadSpec([]byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Users", "version": "2"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "summary": "Get a user",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "tenant", "in": "query", "required": true, "schema": {"type": "string"}},
+          {"name": "fields", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/users": {
+      "post": {"operationId": "createUser", "responses": {"201": {"description": "Created"}}}
+    },
+    "/health": {
+      "get": {"responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatalf("Failed to load new spec: %v", err)
+	}
+
+	diff, err := DiffTools(oldSpec, newSpec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(diff.Added, []string{"createUser"}) {
+		t.Errorf("Expected createUser to be added, got %v", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"search"}) {
+		t.Errorf("Expected search to be removed, got %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Name != "getUser" {
+		t.Fatalf("Expected only getUser to change, got %+v", diff.Changed)
+	}
+
+	expected := []Change{
+		{Message: "input expand removed", Breaking: true},
+		{Message: "input fields added"},
+		{Message: "input id type changed from integer to string", Breaking: true},
+		{Message: "required input tenant added", Breaking: true},
+	}
+	if !reflect.DeepEqual(diff.Changed[0].Changes, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, diff.Changed[0].Changes)
+	}
+	if !diff.Breaking() {
+		t.Error("Expected the diff to be breaking")
+	}
+
+	same, err := DiffTools(oldSpec, oldSpec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !same.Empty() {
+		t.Errorf("Expected no changes, got %+v", same)
+	}
+}
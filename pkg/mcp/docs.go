@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+// toolDoc documents a generated tool
+type toolDoc struct {
+	Name        string
+	Description string
+	Method      string
+	Path        string
+	Inputs      []inputDoc
+	Example     string
+}
+
+// inputDoc documents a single input of a tool
+type inputDoc struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+	Allowed     string
+}
+
+// documentTools describes the tools of the spec selected by opts, sorted by name
+func documentTools(spec openapi.APISpec, opts *ToolOptions) ([]toolDoc, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []toolDoc
+	for _, tool := range tools {
+		if !opts.Filter.Match(tool) {
+			continue
+		}
+		renameTool(tool, opts)
+		if err := prepareTool(tool, opts); err != nil {
+			return nil, err
+		}
+
+		// Placeholders such as <id> are kept readable
+		var example strings.Builder
+		encoder := json.NewEncoder(&example)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(exampleInput(tool.InputSchema)); err != nil {
+			return nil, fmt.Errorf("failed to build example for %s: %w", tool.Name, err)
+		}
+
+		// Inputs and allowed values are listed in the inputs table instead,
+		// and the fallback description only repeats the method and path
+		description := descriptionText(tool.Description)
+		if description == openapi.OperationLocation(tool.Method, tool.Path) {
+			description = ""
+		}
+
+		docs = append(docs, toolDoc{
+			Name:        tool.Name,
+			Description: description,
+			Method:      strings.ToUpper(tool.Method),
+			Path:        tool.Path,
+			Inputs:      documentInputs(tool.InputSchema),
+			Example:     strings.TrimSpace(example.String()),
+		})
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs, nil
+}
+
+// documentInputs describes the inputs of a tool, required ones first
+func documentInputs(schema *jsonschema.Schema) []inputDoc {
+	if schema == nil {
+		return nil
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	inputs := make([]inputDoc, 0, len(schema.Properties))
+	for name, property := range schema.Properties {
+		input := inputDoc{Name: name, Type: schemaTypeName(property), Required: required[name], Description: property.Description}
+		if len(property.Enum) > 0 {
+			values := make([]string, len(property.Enum))
+			for i, value := range property.Enum {
+				values[i] = fmt.Sprint(value)
+			}
+			input.Allowed = strings.Join(values, ", ")
+		}
+		inputs = append(inputs, input)
+	}
+
+	sort.Slice(inputs, func(i, j int) bool {
+		if inputs[i].Required != inputs[j].Required {
+			return inputs[i].Required
+		}
+		return inputs[i].Name < inputs[j].Name
+	})
+	return inputs
+}
+
+// exampleInput builds example arguments with the required inputs of a tool,
+// taken from the schema's examples, defaults and enums where it has them
+func exampleInput(schema *jsonschema.Schema) map[string]interface{} {
+	example := make(map[string]interface{})
+	if schema == nil {
+		return example
+	}
+
+	for _, name := range schema.Required {
+		example[name] = exampleValue(name, schema.Properties[name])
+	}
+	return example
+}
+
+func exampleValue(name string, schema *jsonschema.Schema) interface{} {
+	if schema == nil {
+		return "<" + name + ">"
+	}
+
+	switch {
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Default != nil:
+		var value interface{}
+		if err := json.Unmarshal(schema.Default, &value); err == nil {
+			return value
+		}
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	switch schemaTypeName(schema) {
+	case "integer":
+		return 1
+	case "number":
+		return 1.5
+	case "boolean":
+		return true
+	case "array":
+		return []interface{}{exampleValue(name, schema.Items)}
+	case "object":
+		return exampleInput(schema)
+	default:
+		return "<" + name + ">"
+	}
+}
+
+// WriteMarkdownDocs writes Markdown documentation of the tools generated from
+// the spec: one section per tool with its description, a table of its inputs
+// and example arguments
+func WriteMarkdownDocs(w io.Writer, spec openapi.APISpec, opts *ToolOptions) error {
+	docs, err := documentTools(spec, opts)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	info := spec.GetInfo()
+	fmt.Fprintf(&b, "# %s tools\n", docsTitle(info.Title))
+	if info.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(info.Description))
+	}
+
+	b.WriteString("\n## Contents\n\n")
+	for _, doc := range docs {
+		fmt.Fprintf(&b, "- [%s](#%s)\n", doc.Name, strings.ToLower(doc.Name))
+	}
+
+	for _, doc := range docs {
+		fmt.Fprintf(&b, "\n## %s\n\n`%s %s`\n", doc.Name, doc.Method, doc.Path)
+		if doc.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", doc.Description)
+		}
+
+		if len(doc.Inputs) > 0 {
+			b.WriteString("\n| Input | Type | Required | Description |\n|---|---|---|---|\n")
+			for _, input := range doc.Inputs {
+				required := "no"
+				if input.Required {
+					required = "yes"
+				}
+				description := input.Description
+				if input.Allowed != "" {
+					description = strings.TrimSpace(description + " Allowed values: " + input.Allowed)
+				}
+				fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", input.Name, input.Type, required, markdownCell(description))
+			}
+		}
+
+		fmt.Fprintf(&b, "\nExample call:\n\n```json\n%s\n```\n", doc.Example)
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell keeps text on one line of a table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// htmlDocsTemplate renders the same documentation as WriteMarkdownDocs as a
+// standalone page
+var htmlDocsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} tools</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+pre, code { background: #f5f5f5; }
+pre { padding: 0.8em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} tools</h1>
+{{with .Description}}<p>{{.}}</p>
+{{end}}<h2>Contents</h2>
+<ul>
+{{range .Tools}}<li><a href="#{{.Name}}">{{.Name}}</a></li>
+{{end}}</ul>
+{{range .Tools}}<h2 id="{{.Name}}">{{.Name}}</h2>
+<p><code>{{.Method}} {{.Path}}</code></p>
+{{with .Description}}<p>{{.}}</p>
+{{end}}{{if .Inputs}}<table>
+<tr><th>Input</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{range .Inputs}}<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{.Description}}{{with .Allowed}} Allowed values: {{.}}{{end}}</td></tr>
+{{end}}</table>
+{{end}}<p>Example call:</p>
+<pre><code>{{.Example}}</code></pre>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTMLDocs writes the documentation of WriteMarkdownDocs as a
+// standalone HTML page
+func WriteHTMLDocs(w io.Writer, spec openapi.APISpec, opts *ToolOptions) error {
+	docs, err := documentTools(spec, opts)
+	if err != nil {
+		return err
+	}
+
+	info := spec.GetInfo()
+	return htmlDocsTemplate.Execute(w, struct {
+		Title       string
+		Description string
+		Tools       []toolDoc
+	}{docsTitle(info.Title), strings.TrimSpace(info.Description), docs})
+}
+
+func docsTitle(title string) string {
+	if title != "" {
+		return title
+	}
+	return "API"
+}
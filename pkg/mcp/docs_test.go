@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestWriteDocs(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Users API", "version": "1"},
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "summary": "Get a user",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string", "description": "Id of the user"}},
+          {"name": "view", "in": "query", "schema": {"type": "string", "enum": ["full", "brief"]}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/users": {
+      "post": {
+        "operationId": "createUser",
+        "tags": ["admin"],
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "required": ["name", "age"],
+          "properties": {"name": {"type": "string", "example": "Alice"}, "age": {"type": "integer"}}
+        }}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := WriteMarkdownDocs(&out, spec, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		docs := out.String()
+		for _, expected := range []string{
+			"# Users API tools",
+			"- [createUser](#createuser)\n- [getUser](#getuser)",
+			"## getUser\n\n`GET /users/{id}`\n\nGet a user\n",
+			"| `id` | string | yes | Id of the user |",
+			"| `view` | string | no | Allowed values: full, brief |",
+			"\"id\": \"<id>\"",
+			"\"age\": 1,\n  \"name\": \"Alice\"",
+		} {
+			if !strings.Contains(docs, expected) {
+				t.Errorf("Expected docs to contain %q, got:\n%s", expected, docs)
+			}
+		}
+		if strings.Contains(docs, "\n\nAllowed values:") {
+			t.Errorf("Expected allowed values only in the inputs table, got:\n%s", docs)
+		}
+	})
+
+	t.Run("html with filter", func(t *testing.T) {
+		filter, err := NewToolFilter([]string{"admin"}, nil, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var out bytes.Buffer
+		if err := WriteHTMLDocs(&out, spec, &ToolOptions{Filter: filter}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		docs := out.String()
+		if !strings.Contains(docs, `<h2 id="createUser">createUser</h2>`) {
+			t.Errorf("Expected a createUser section, got:\n%s", docs)
+		}
+		if strings.Contains(docs, "getUser") {
+			t.Errorf("Expected getUser to be filtered out, got:\n%s", docs)
+		}
+		if !strings.Contains(docs, "&#34;Alice&#34;") {
+			t.Errorf("Expected the example to be escaped, got:\n%s", docs)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// dryRunOutput describes the request a call would send instead of sending
+// it. The values of headers that look like credentials and secret query
+// parameters are masked, like in PreviewRequest. Credentials added by the
+// authenticator or a signer when sending are not part of it.
+func dryRunOutput(req *http.Request, opts *ToolOptions) (APIToolOutput, error) {
+	described := map[string]interface{}{
+		"dry_run": true,
+		"method":  req.Method,
+		"url":     opts.redact(req.URL.String()),
+	}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		headers := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			value := req.Header.Get(name)
+			if sensitiveHeaderRegex.MatchString(name) {
+				value = redactedValue
+			}
+			headers[name] = opts.redact(value)
+		}
+		described["headers"] = headers
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return APIToolOutput{}, err
+		}
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return APIToolOutput{}, err
+		}
+
+		if len(data) > 0 {
+			var parsed interface{}
+			if err := json.Unmarshal(data, &parsed); err != nil {
+				described["body"] = opts.redact(string(data))
+			} else {
+				described["body"] = opts.redactValue(parsed)
+			}
+		}
+	}
+
+	return APIToolOutput{Body: described}, nil
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestDryRun(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request to %s", r.URL)
+	}))
+	defer upstream.Close()
+
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "` + upstream.URL + `/v1"}],
+		"paths": {
+			"/users": {
+				"post": {
+					"operationId": "createUser",
+					"parameters": [{"name": "notify", "in": "query", "schema": {"type": "boolean"}}],
+					"requestBody": {"content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	opts := &ToolOptions{
+		Headers:     http.Header{"Authorization": {"Bearer s3cr3t"}, "X-Team": {"billing"}},
+		QueryParams: url.Values{"api_key": {"k3y-s3cr3t"}},
+		DryRun:      true,
+	}
+
+	tool, err := FindTool(spec, "createUser", opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output, err := CallTool(context.Background(), tool, APIToolInput{"name": "Alice k3y-s3cr3t", "notify": true}, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output.Error != "" {
+		t.Fatalf("Unexpected tool error: %s", output.Error)
+	}
+
+	expected := map[string]interface{}{
+		"dry_run": true,
+		"method":  "POST",
+		"url":     upstream.URL + "/v1/users?api_key=[REDACTED]&notify=true",
+		"headers": map[string]interface{}{
+			"Authorization": "[REDACTED]",
+			"Content-Type":  "application/json",
+			"X-Team":        "billing",
+		},
+		"body": map[string]interface{}{"name": "Alice [REDACTED]"},
+	}
+	if !reflect.DeepEqual(output.Body, expected) {
+		t.Errorf("Expected body %v, got %v", expected, output.Body)
+	}
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Vendor extensions of operations tuning their tools
+const (
+	// extensionName replaces the tool name
+	extensionName = "x-mcp-name"
+	// extensionDescription replaces the tool description
+	extensionDescription = "x-mcp-description"
+	// extensionAnnotations overrides the behavior hints derived from the
+	// method, e.g. {"readOnlyHint": true} for a search sent with POST
+	extensionAnnotations = "x-mcp-annotations"
+	// extensionIgnore keeps operations out of the tools: true on a path or an
+	// operation, or a list of operations at the top level of the spec
+	extensionIgnore = "x-mcp-ignore"
+)
+
+// stringExtension returns the value of a string extension of the operation,
+// empty when it isn't set
+func stringExtension(operation openapi.Operation, name string) (string, error) {
+	value, ok := operation.GetExtensions()[name]
+	if !ok || value == nil {
+		return "", nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, got %v", name, value)
+	}
+	return s, nil
+}
+
+// operationToolName names the tool of an operation after its x-mcp-name,
+// falling back to its operationId or method and path, before sanitizing
+func operationToolName(method, path string, operation openapi.Operation) (string, error) {
+	name, err := stringExtension(operation, extensionName)
+	if err != nil || name != "" {
+		return name, err
+	}
+	return rawToolName(method, path, operation.GetOperationID()), nil
+}
+
+// extensionAnnotationsFor applies the x-mcp-annotations of the operation over
+// the annotations derived from its method
+func extensionAnnotationsFor(operation openapi.Operation, annotations *mcp.ToolAnnotations) (*mcp.ToolAnnotations, error) {
+	value, ok := operation.GetExtensions()[extensionAnnotations]
+	if !ok || value == nil {
+		return annotations, nil
+	}
+
+	if _, ok := value.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%s must be an object, got %v", extensionAnnotations, value)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", extensionAnnotations, err)
+	}
+
+	// Only the hints set by the extension replace the derived ones
+	merged := *annotations
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", extensionAnnotations, err)
+	}
+	return &merged, nil
+}
+
+// ignoreList holds the operations whose tools are left out through
+// x-mcp-ignore
+type ignoreList struct {
+	// entries are the operationIds, paths and "METHOD path" listed at the top
+	// level of the spec, where paths may hold * wildcards
+	entries []string
+}
+
+// newIgnoreList reads the x-mcp-ignore list at the top level of the spec
+func newIgnoreList(spec openapi.APISpec) (*ignoreList, error) {
+	value, ok := spec.GetExtensions()[extensionIgnore]
+	if !ok || value == nil {
+		return &ignoreList{}, nil
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list of operationIds and paths, got %v", extensionIgnore, value)
+	}
+
+	l := &ignoreList{}
+	for _, v := range values {
+		entry, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s entries must be strings, got %v", extensionIgnore, v)
+		}
+		entry = strings.TrimSpace(entry)
+		if _, err := path.Match(entry, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s entry %s: %w", extensionIgnore, entry, err)
+		}
+		l.entries = append(l.entries, entry)
+	}
+	return l, nil
+}
+
+// ignored reports whether the operation is left out, by the spec's list or
+// by x-mcp-ignore on its path or itself
+func (l *ignoreList) ignored(method, p string, pathItem openapi.PathItem, operation openapi.Operation) (bool, error) {
+	for _, entry := range l.entries {
+		if matchesIgnoreEntry(entry, method, p, operation.GetOperationID()) {
+			return true, nil
+		}
+	}
+
+	for _, extensions := range []map[string]interface{}{pathItem.GetExtensions(), operation.GetExtensions()} {
+		value, ok := extensions[extensionIgnore]
+		if !ok || value == nil {
+			continue
+		}
+		ignore, ok := value.(bool)
+		if !ok {
+			return false, fmt.Errorf("%s must be a boolean, got %v", extensionIgnore, value)
+		}
+		if ignore {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchesIgnoreEntry reports whether an entry of the x-mcp-ignore list names
+// the operation
+func matchesIgnoreEntry(entry, method, p, operationID string) bool {
+	if entry == operationID && operationID != "" {
+		return true
+	}
+
+	if entryMethod, entryPath, ok := strings.Cut(entry, " "); ok {
+		if !strings.EqualFold(entryMethod, method) {
+			return false
+		}
+		entry = strings.TrimSpace(entryPath)
+	}
+	matched, _ := path.Match(entry, p)
+	return matched
+}
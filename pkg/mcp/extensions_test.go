@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestVendorExtensions(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "https://api.example.com"}],
+		"paths": {
+			"/users/search": {
+				"post": {
+					"operationId": "postUsersSearch",
+					"summary": "Search",
+					"x-mcp-name": "search_users",
+					"x-mcp-description": "Find users by name or email",
+					"x-mcp-annotations": {"readOnlyHint": true, "title": "Search users"},
+					"responses": {"200": {"description": "OK"}}
+				}
+			},
+			"/users": {
+				"get": {"operationId": "listUsers", "summary": "List users", "responses": {"200": {"description": "OK"}}}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	byPath := make(map[string]*EnrichedTool, len(tools))
+	for _, tool := range tools {
+		byPath[tool.Path] = tool
+	}
+
+	search := byPath["/users/search"]
+	if search.Name != "search_users" {
+		t.Errorf("Expected the x-mcp-name, got %s", search.Name)
+	}
+	if search.Description != "Find users by name or email" {
+		t.Errorf("Expected the x-mcp-description, got %q", search.Description)
+	}
+
+	annotations := search.Annotations
+	if !annotations.ReadOnlyHint || annotations.Title != "Search users" {
+		t.Errorf("Expected the x-mcp-annotations to apply, got %+v", annotations)
+	}
+	if annotations.OpenWorldHint == nil || !*annotations.OpenWorldHint {
+		t.Errorf("Expected the hints not set by the extension to be kept, got %+v", annotations)
+	}
+
+	list := byPath["/users"]
+	if list.Name != "listUsers" || list.Description != "List users" {
+		t.Errorf("Expected the operation without extensions unchanged, got %s %q", list.Name, list.Description)
+	}
+}
+
+func TestVendorExtensionsInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		extension     string
+		expectedError string
+	}{
+		{name: "name", extension: `"x-mcp-name": 42`, expectedError: "x-mcp-name must be a string"},
+		{name: "description", extension: `"x-mcp-description": ["a"]`, expectedError: "x-mcp-description must be a string"},
+		{name: "annotations", extension: `"x-mcp-annotations": "readOnly"`, expectedError: "x-mcp-annotations must be an object"},
+		{name: "annotation type", extension: `"x-mcp-annotations": {"readOnlyHint": "yes"}`, expectedError: "invalid x-mcp-annotations"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := openapi.LoadSpec([]byte(`{
+				"openapi": "3.0.0",
+				"info": {"title": "Test", "version": "1.0.0"},
+				"servers": [{"url": "https://api.example.com"}],
+				"paths": {"/users": {"get": {` + tt.extension + `, "responses": {"200": {"description": "OK"}}}}}
+			}`))
+			if err != nil {
+				t.Fatalf("Failed to load spec: %v", err)
+			}
+
+			_, err = GetToolsFromSpec(spec)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestIgnoreExtension(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "https://api.example.com"}],
+		"x-mcp-ignore": ["resetDatabase", "DELETE /users/{id}", "/debug/*"],
+		"paths": {
+			"/users/{id}": {
+				"get": {"operationId": "getUser", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "OK"}}},
+				"delete": {"operationId": "deleteUser", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"204": {"description": "Deleted"}}},
+				"put": {"operationId": "updateUser", "x-mcp-ignore": true, "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "OK"}}}
+			},
+			"/users": {
+				"get": {"operationId": "listUsers", "x-mcp-ignore": false, "responses": {"200": {"description": "OK"}}}
+			},
+			"/admin/reset": {
+				"post": {"operationId": "resetDatabase", "responses": {"204": {"description": "Reset"}}}
+			},
+			"/debug/vars": {
+				"get": {"operationId": "debugVars", "responses": {"200": {"description": "OK"}}}
+			},
+			"/internal": {
+				"x-mcp-ignore": true,
+				"get": {"operationId": "internalStatus", "responses": {"200": {"description": "OK"}}},
+				"post": {"operationId": "internalCommand", "responses": {"200": {"description": "OK"}}}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+
+	expected := []string{"getUser", "listUsers"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected tools %v, got %v", expected, names)
+	}
+
+	for _, problem := range ValidateTools(spec) {
+		if strings.Contains(problem.Location, "/internal") || strings.Contains(problem.Location, "/debug") {
+			t.Errorf("Expected ignored operations not to be validated, got %v", problem)
+		}
+	}
+}
+
+func TestIgnoreExtensionInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		root          string
+		operation     string
+		expectedError string
+	}{
+		{name: "list", root: `"x-mcp-ignore": "listUsers",`, expectedError: "x-mcp-ignore must be a list"},
+		{name: "entry", root: `"x-mcp-ignore": [1],`, expectedError: "x-mcp-ignore entries must be strings"},
+		{name: "pattern", root: `"x-mcp-ignore": ["/users/["],`, expectedError: "invalid x-mcp-ignore entry"},
+		{name: "operation", operation: `"x-mcp-ignore": "yes",`, expectedError: "x-mcp-ignore must be a boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := openapi.LoadSpec([]byte(`{
+				"openapi": "3.0.0",
+				"info": {"title": "Test", "version": "1.0.0"},
+				"servers": [{"url": "https://api.example.com"}],
+				` + tt.root + `
+				"paths": {"/users": {"get": {` + tt.operation + ` "operationId": "listUsers", "responses": {"200": {"description": "OK"}}}}}
+			}`))
+			if err != nil {
+				t.Fatalf("Failed to load spec: %v", err)
+			}
+
+			_, err = GetToolsFromSpec(spec)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ToolFilter selects the operations exposed as tools. Each non-empty list must
+// match, an operation matches a list when any of its entries does.
+type ToolFilter struct {
+	// Tags matches operations carrying one of the tags
+	Tags []string
+	// Methods matches HTTP methods, case-insensitively
+	Methods []string
+	// Paths are glob patterns for the operation path, e.g. /users/*
+	Paths []string
+	// Toolsets matches operations in one of the toolsets, given by name or
+	// tag
+	Toolsets []string
+	// ExcludeDeprecated leaves out the operations marked deprecated
+	ExcludeDeprecated bool
+}
+
+// NewToolFilter creates a filter, returning nil when every list is empty
+func NewToolFilter(tags, methods, paths []string) (*ToolFilter, error) {
+	if len(tags) == 0 && len(methods) == 0 && len(paths) == 0 {
+		return nil, nil
+	}
+
+	for _, pattern := range paths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid path pattern %s: %w", pattern, err)
+		}
+	}
+
+	return &ToolFilter{Tags: tags, Methods: methods, Paths: paths}, nil
+}
+
+// Match reports whether a tool is selected by the filter. A nil filter
+// selects every tool.
+func (f *ToolFilter) Match(tool *EnrichedTool) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.ExcludeDeprecated && tool.Operation.IsDeprecated() {
+		return false
+	}
+
+	if len(f.Tags) > 0 && !matchesAny(f.Tags, tool.Operation.GetTags(), strings.EqualFold) {
+		return false
+	}
+
+	if len(f.Methods) > 0 && !matchesAny(f.Methods, []string{tool.Method}, strings.EqualFold) {
+		return false
+	}
+
+	if len(f.Paths) > 0 && !matchesAny(f.Paths, []string{tool.Path}, func(pattern, p string) bool {
+		matched, _ := path.Match(pattern, p)
+		return matched
+	}) {
+		return false
+	}
+
+	if len(f.Toolsets) > 0 && !matchesAny(f.Toolsets, toolsetsOf(tool), matchToolset) {
+		return false
+	}
+
+	return true
+}
+
+// matchesAny reports whether any pattern matches any of the values
+func matchesAny(patterns, values []string, match func(pattern, value string) bool) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if match(pattern, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
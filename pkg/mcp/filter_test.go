@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestToolFilterMatch(t *testing.T) {
+	tool := &EnrichedTool{
+		Method:    "get",
+		Path:      "/users/{id}",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{Tags: []string{"users", "admin"}}},
+	}
+
+	tests := []struct {
+		name     string
+		filter   *ToolFilter
+		expected bool
+	}{
+		{name: "nil filter", filter: nil, expected: true},
+		{name: "tag", filter: &ToolFilter{Tags: []string{"billing", "Admin"}}, expected: true},
+		{name: "other tag", filter: &ToolFilter{Tags: []string{"billing"}}, expected: false},
+		{name: "method", filter: &ToolFilter{Methods: []string{"GET"}}, expected: true},
+		{name: "path glob", filter: &ToolFilter{Paths: []string{"/users/*"}}, expected: true},
+		{name: "path glob does not cross segments", filter: &ToolFilter{Paths: []string{"/*"}}, expected: false},
+		{name: "every list must match", filter: &ToolFilter{Tags: []string{"users"}, Methods: []string{"post"}}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tool); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewToolFilter(t *testing.T) {
+	if filter, err := NewToolFilter(nil, nil, nil); filter != nil || err != nil {
+		t.Errorf("Expected no filter, got %v (%v)", filter, err)
+	}
+	if _, err := NewToolFilter(nil, nil, []string{"/users/["}); err == nil {
+		t.Error("Expected error for invalid path pattern")
+	}
+}
+
+func TestToolFilterDeprecated(t *testing.T) {
+	tool := &EnrichedTool{
+		Method:    "get",
+		Path:      "/v1/users",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{Deprecated: true}},
+	}
+
+	if (&ToolFilter{ExcludeDeprecated: true}).Match(tool) {
+		t.Error("Expected the deprecated operation to be left out")
+	}
+	if !(&ToolFilter{Methods: []string{"get"}}).Match(tool) {
+		t.Error("Expected the deprecated operation to be selected")
+	}
+}
@@ -0,0 +1,269 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// pageNumberParams are the query parameters recognized as page numbers
+var pageNumberParams = []string{"page", "page_number", "pageNumber"}
+
+// offsetParams are the query parameters recognized as item offsets
+var offsetParams = []string{"offset", "skip", "start"}
+
+// itemFields are the body fields recognized as the items of a page
+var itemFields = []string{"data", "items", "results", "records", "entries", "values"}
+
+// nextURLFields are the body fields recognized as the URL of the next page,
+// dotted names being nested
+var nextURLFields = []string{"next", "next_url", "nextUrl", "links.next", "_links.next.href", "paging.next"}
+
+// followPages wraps a handler to fetch the following pages of a listing and
+// return their items as a single result, up to maxPages pages. The items are
+// merged into the body of the last page, so its cursor continues the listing.
+func followPages(tool *EnrichedTool, maxPages int, handler apiToolHandler) apiToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		result, output, err := handler(ctx, req, input)
+		if err != nil || !pageSucceeded(output) {
+			return result, output, err
+		}
+
+		items, ok := pageItems(output.Body)
+		if !ok {
+			return result, output, err
+		}
+
+		pages := 1
+		for {
+			next, ok := nextPageInputs(tool, input, output, len(items))
+			if !ok {
+				break
+			}
+			if pages >= maxPages {
+				output.MorePages = true
+				break
+			}
+
+			_, nextOutput, err := handler(ctx, req, next)
+			if err != nil || !pageSucceeded(nextOutput) {
+				output.Error = fmt.Sprintf("failed to fetch page %d, returning the first %d: %s", pages+1, pages, pageError(nextOutput, err))
+				output.MorePages = true
+				return result, mergePages(output, items, pages), nil
+			}
+
+			more, ok := pageItems(nextOutput.Body)
+			if !ok || len(more) == 0 {
+				break
+			}
+
+			items = append(items, more...)
+			input, output = next, nextOutput
+			pages++
+		}
+
+		return result, mergePages(output, items, pages), nil
+	}
+}
+
+func pageSucceeded(output APIToolOutput) bool {
+	return output.Error == "" && output.StatusCode >= 200 && output.StatusCode < 300
+}
+
+func pageError(output APIToolOutput, err error) string {
+	switch {
+	case err != nil:
+		return err.Error()
+	case output.Error != "":
+		return output.Error
+	default:
+		return fmt.Sprintf("status %d", output.StatusCode)
+	}
+}
+
+// mergePages replaces the items of the last page with those of all pages
+func mergePages(output APIToolOutput, items []interface{}, pages int) APIToolOutput {
+	if pages == 1 {
+		return output
+	}
+
+	switch body := output.Body.(type) {
+	case []interface{}:
+		output.Body = items
+	case map[string]interface{}:
+		merged := make(map[string]interface{}, len(body))
+		for key, value := range body {
+			merged[key] = value
+		}
+		merged[itemsField(body)] = items
+		output.Body = merged
+	}
+	output.Pages = pages
+	return output
+}
+
+// pageItems returns the items of a page, the body itself when it is a list
+func pageItems(body interface{}) ([]interface{}, bool) {
+	switch b := body.(type) {
+	case []interface{}:
+		return b, true
+	case map[string]interface{}:
+		if field := itemsField(b); field != "" {
+			items, _ := b[field].([]interface{})
+			return items, true
+		}
+	}
+	return nil, false
+}
+
+// itemsField returns the field holding the items of a page: a well-known
+// name, or the only list in the body
+func itemsField(body map[string]interface{}) string {
+	for _, field := range itemFields {
+		if _, ok := body[field].([]interface{}); ok {
+			return field
+		}
+	}
+
+	var found string
+	for field, value := range body {
+		if _, ok := value.([]interface{}); ok {
+			if found != "" {
+				return ""
+			}
+			found = field
+		}
+	}
+	return found
+}
+
+// nextPageInputs derives the input fetching the page after output, from a
+// next page URL, a cursor, a page number or an offset. It reports false on
+// the last page.
+func nextPageInputs(tool *EnrichedTool, input APIToolInput, output APIToolOutput, count int) (APIToolInput, bool) {
+	queryParams := make(map[string]bool)
+	for _, param := range tool.Operation.GetParameters() {
+		if param.GetIn() == "query" {
+			queryParams[param.GetName()] = true
+		}
+	}
+
+	next := withoutInput(input)
+	changed := false
+
+	// The query string of the next page's URL carries whatever it is paged by
+	if nextURL := nextPageURL(output); nextURL != nil {
+		for name, values := range nextURL.Query() {
+			if queryParams[name] && len(values) > 0 {
+				next[name] = values[0]
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		if param := cursorParam(tool); param != "" {
+			cursor := nextCursor(output, param)
+			if cursor == "" {
+				return nil, false
+			}
+			next[param] = cursor
+			changed = true
+		}
+	}
+
+	if !changed && count > 0 {
+		for _, param := range pageNumberParams {
+			if queryParams[param] {
+				next[param] = float64(inputNumber(input[param], 1) + 1)
+				changed = true
+				break
+			}
+		}
+	}
+
+	if !changed && count > 0 {
+		for _, param := range offsetParams {
+			if queryParams[param] {
+				next[param] = float64(inputNumber(input[param], 0) + count)
+				changed = true
+				break
+			}
+		}
+	}
+
+	// Guard against APIs echoing the current page as the next one
+	if !changed || reflect.DeepEqual(normalizeInputs(next), normalizeInputs(input)) {
+		return nil, false
+	}
+	return next, true
+}
+
+// nextPageURL returns the URL of the next page from a Link header with
+// rel="next" or a well-known field of the body
+func nextPageURL(output APIToolOutput) *url.URL {
+	if match := linkNextRegex.FindStringSubmatch(output.Headers["Link"]); match != nil {
+		if next, err := url.Parse(match[1]); err == nil {
+			return next
+		}
+	}
+
+	body, ok := output.Body.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, field := range nextURLFields {
+		var value interface{} = body
+		for _, name := range strings.Split(field, ".") {
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = fields[name]
+		}
+
+		if s, ok := value.(string); ok && strings.Contains(s, "?") {
+			if next, err := url.Parse(s); err == nil {
+				return next
+			}
+		}
+	}
+	return nil
+}
+
+// inputNumber reads a numeric input that may have been given as a string
+func inputNumber(value interface{}, fallback int) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+// normalizeInputs renders input values as query strings, so 2 and "2" compare equal
+func normalizeInputs(input APIToolInput) map[string]string {
+	normalized := make(map[string]string, len(input))
+	for key, value := range input {
+		normalized[key] = queryValue(value)
+	}
+	return normalized
+}
+
+// followsPages reports whether a tool's listings may be followed, only reads are
+func followsPages(tool *EnrichedTool) bool {
+	return strings.EqualFold(tool.Method, http.MethodGet)
+}
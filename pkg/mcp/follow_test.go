@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestFollowPages(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        []string
+		maxPages      int
+		respond       func(w http.ResponseWriter, r *http.Request)
+		expectedBody  interface{}
+		expectedPages int
+		morePages     bool
+	}{
+		{
+			name:     "cursor in body",
+			params:   []string{"cursor"},
+			maxPages: 10,
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				pages := map[string]string{
+					"":   `{"items": [1, 2], "next_cursor": "c2"}`,
+					"c2": `{"items": [3], "next_cursor": ""}`,
+				}
+				w.Write([]byte(pages[r.URL.Query().Get("cursor")]))
+			},
+			expectedBody:  map[string]interface{}{"items": []interface{}{float64(1), float64(2), float64(3)}, "next_cursor": ""},
+			expectedPages: 2,
+		},
+		{
+			name:     "Link header",
+			params:   []string{"since"},
+			maxPages: 10,
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("since") == "" {
+					w.Header().Set("Link", `<https://api.example.com/items?since=2>; rel="next"`)
+					w.Write([]byte(`[1, 2]`))
+					return
+				}
+				w.Write([]byte(`[3]`))
+			},
+			expectedBody:  []interface{}{float64(1), float64(2), float64(3)},
+			expectedPages: 2,
+		},
+		{
+			name:     "page numbers until an empty page",
+			params:   []string{"page"},
+			maxPages: 10,
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				if page == 0 {
+					page = 1
+				}
+				if page > 2 {
+					w.Write([]byte(`{"data": []}`))
+					return
+				}
+				fmt.Fprintf(w, `{"data": [%d]}`, page)
+			},
+			expectedBody:  map[string]interface{}{"data": []interface{}{float64(1), float64(2)}},
+			expectedPages: 2,
+		},
+		{
+			name:     "page cap",
+			params:   []string{"offset"},
+			maxPages: 2,
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				fmt.Fprintf(w, `{"results": [%d, %d]}`, offset, offset+1)
+			},
+			expectedBody:  map[string]interface{}{"results": []interface{}{float64(0), float64(1), float64(2), float64(3)}},
+			expectedPages: 2,
+			morePages:     true,
+		},
+		{
+			name:     "single page",
+			params:   []string{"cursor"},
+			maxPages: 10,
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"items": [1]}`))
+			},
+			expectedBody: map[string]interface{}{"items": []interface{}{float64(1)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				tt.respond(w, r)
+			}))
+			defer mockServer.Close()
+
+			var params openapi3.Parameters
+			for _, name := range tt.params {
+				params = append(params, &openapi3.ParameterRef{Value: openapi3.NewQueryParameter(name)})
+			}
+
+			tool := &EnrichedTool{
+				Tool:      &mcp.Tool{Name: "listItems"},
+				BaseUrl:   mockServer.URL,
+				Method:    "get",
+				Path:      "/items",
+				Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{Parameters: params}},
+			}
+
+			handler := createAPIHandlerForTool(tool, &ToolOptions{FollowPages: tt.maxPages})
+			_, output, err := handler(context.Background(), nil, APIToolInput{})
+			if err != nil || output.Error != "" {
+				t.Fatalf("Unexpected error: %v %s", err, output.Error)
+			}
+
+			if !reflect.DeepEqual(output.Body, tt.expectedBody) {
+				t.Errorf("Expected body %v, got %v", tt.expectedBody, output.Body)
+			}
+			if output.Pages != tt.expectedPages || output.MorePages != tt.morePages {
+				t.Errorf("Expected pages=%d more_pages=%v, got %d %v", tt.expectedPages, tt.morePages, output.Pages, output.MorePages)
+			}
+		})
+	}
+}
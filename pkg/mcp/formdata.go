@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+// Media types of the bodies of OpenAPI 2.0 formData parameters
+const (
+	formURLEncoded = "application/x-www-form-urlencoded"
+	formMultipart  = "multipart/form-data"
+)
+
+// formDataParams returns the formData parameters of an OpenAPI 2.0 operation
+func formDataParams(operation openapi.Operation) []openapi.Parameter {
+	var params []openapi.Parameter
+	for _, param := range operation.GetParameters() {
+		if param.GetIn() == "formData" {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// formContentType picks the media type of a form body: multipart when the
+// operation consumes it or uploads a file, URL encoded otherwise
+func formContentType(operation openapi.Operation, params []openapi.Parameter) string {
+	for _, param := range params {
+		if param.GetType() == "file" {
+			return formMultipart
+		}
+	}
+	for _, consumes := range operation.GetConsumes() {
+		if strings.HasPrefix(strings.ToLower(consumes), formMultipart) {
+			return formMultipart
+		}
+	}
+	return formURLEncoded
+}
+
+// buildFormBody encodes the formData parameters of the input as the body and
+// returns its content type. Arrays follow the collectionFormat of their
+// parameter, files are sent with their content as given.
+func buildFormBody(operation openapi.Operation, input APIToolInput) ([]byte, string, error) {
+	params := formDataParams(operation)
+	contentType := formContentType(operation, params)
+
+	fields := url.Values{}
+	var files []openapi.Parameter
+	for _, param := range params {
+		// An explicit null leaves the field out
+		value := input[param.GetName()]
+		if value == nil {
+			continue
+		}
+		if param.GetType() == "file" {
+			files = append(files, param)
+			continue
+		}
+		if param.GetType() == "array" {
+			value = arrayValue(value)
+		}
+		addStyledQueryParam(fields, param.GetName(), value, param.GetStyle(), param.GetExplode())
+	}
+
+	if contentType == formURLEncoded {
+		return []byte(fields.Encode()), contentType, nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range fields[name] {
+			if err := writer.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	for _, param := range files {
+		part, err := writer.CreateFormFile(param.GetName(), param.GetName())
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write([]byte(queryValue(input[param.GetName()]))); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to encode multipart body: %w", err)
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+const formDataSpec = `{
+	"swagger": "2.0",
+	"info": {"title": "Test", "version": "1.0.0"},
+	"host": "api.example.com",
+	"consumes": ["application/x-www-form-urlencoded"],
+	"paths": {
+		"/login": {
+			"post": {
+				"operationId": "login",
+				"parameters": [
+					{"name": "username", "in": "formData", "type": "string", "required": true},
+					{"name": "password", "in": "formData", "type": "string", "required": true},
+					{"name": "scopes", "in": "formData", "type": "array", "items": {"type": "string"}, "collectionFormat": "multi"},
+					{"name": "remember", "in": "formData", "type": "boolean"}
+				],
+				"responses": {"200": {"description": "OK"}}
+			}
+		},
+		"/avatar": {
+			"post": {
+				"operationId": "uploadAvatar",
+				"consumes": ["multipart/form-data"],
+				"parameters": [
+					{"name": "file", "in": "formData", "type": "file", "required": true},
+					{"name": "caption", "in": "formData", "type": "string"}
+				],
+				"responses": {"200": {"description": "OK"}}
+			}
+		}
+	}
+}`
+
+func formDataTool(t *testing.T, name string) *EnrichedTool {
+	t.Helper()
+	spec, err := openapi.LoadSpec([]byte(formDataSpec))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool
+		}
+	}
+	t.Fatalf("Tool %s not found", name)
+	return nil
+}
+
+func TestBuildFormBodyURLEncoded(t *testing.T) {
+	tool := formDataTool(t, "login")
+	if _, ok := tool.InputSchema.Properties["username"]; !ok {
+		t.Fatalf("Expected formData parameters in the input schema, got %v", tool.InputSchema.Properties)
+	}
+
+	input := APIToolInput{"username": "ada", "password": "s3cret word", "scopes": []interface{}{"read", "write"}, "remember": nil}
+	req, err := BuildRequest(context.Background(), tool, input, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+
+	if contentType := req.Header.Get("Content-Type"); contentType != formURLEncoded {
+		t.Errorf("Expected content type %s, got %s", formURLEncoded, contentType)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if expected := "password=s3cret+word&scopes=read&scopes=write&username=ada"; string(body) != expected {
+		t.Errorf("Expected body %s, got %s", expected, body)
+	}
+}
+
+func TestBuildFormBodyMultipart(t *testing.T) {
+	tool := formDataTool(t, "uploadAvatar")
+	if file := tool.InputSchema.Properties["file"]; file == nil || file.Type != "string" || file.Format != "binary" {
+		t.Fatalf("Expected the file input to be a binary string, got %+v", file)
+	}
+
+	req, err := BuildRequest(context.Background(), tool, APIToolInput{"file": "PNG...", "caption": "me"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if contentType := req.Header.Get("Content-Type"); !strings.HasPrefix(contentType, formMultipart+"; boundary=") {
+		t.Fatalf("Expected a multipart content type, got %s", contentType)
+	}
+
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("Failed to parse multipart body: %v", err)
+	}
+	if !reflect.DeepEqual(req.MultipartForm.Value["caption"], []string{"me"}) {
+		t.Errorf("Expected the caption field, got %v", req.MultipartForm.Value)
+	}
+	files := req.MultipartForm.File["file"]
+	if len(files) != 1 {
+		t.Fatalf("Expected one file, got %v", req.MultipartForm.File)
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	if content, _ := io.ReadAll(file); string(content) != "PNG..." {
+		t.Errorf("Expected the file content, got %q", content)
+	}
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// HeaderTemplate is a header whose value is rendered from the request for
+// every call, e.g. X-Signature={{hmac_sha256 .Body "SIGNING_SECRET"}}
+type HeaderTemplate struct {
+	Name string
+	tmpl *template.Template
+}
+
+// headerTemplateData is the request context available to header templates
+type headerTemplateData struct {
+	Method string
+	URL    string
+	Path   string
+	Query  string
+	Host   string
+	Body   string
+}
+
+// headerTemplateFuncs is the complete function set of header templates. It
+// deliberately has no access to files or arbitrary environment variables,
+// secrets are only read by the signing functions.
+var headerTemplateFuncs = template.FuncMap{
+	"now":         time.Now,
+	"unix":        func(t time.Time) int64 { return t.Unix() },
+	"rfc3339":     func(t time.Time) string { return t.Format(time.RFC3339) },
+	"sha256":      sha256Hex,
+	"hmac_sha256": hmacSHA256Hex,
+	"base64":      func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"lower":       strings.ToLower,
+	"upper":       strings.ToUpper,
+}
+
+// isHeaderTemplate reports whether a header value needs rendering
+func isHeaderTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// NewHeaderTemplate parses a templated header value
+func NewHeaderTemplate(name, value string) (*HeaderTemplate, error) {
+	tmpl, err := template.New(name).Funcs(headerTemplateFuncs).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template for header %s: %w", name, err)
+	}
+	return &HeaderTemplate{Name: name, tmpl: tmpl}, nil
+}
+
+// SplitHeaderTemplates separates templated headers from static ones
+func SplitHeaderTemplates(headers http.Header) (http.Header, []*HeaderTemplate, error) {
+	static := make(http.Header)
+	var templates []*HeaderTemplate
+	for key, values := range headers {
+		for _, value := range values {
+			if !isHeaderTemplate(value) {
+				static.Add(key, value)
+				continue
+			}
+			headerTemplate, err := NewHeaderTemplate(key, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			templates = append(templates, headerTemplate)
+		}
+	}
+	return static, templates, nil
+}
+
+// applyHeaderTemplates renders the templated headers of a request whose body
+// has already been built
+func applyHeaderTemplates(req *http.Request, body []byte, templates []*HeaderTemplate) error {
+	if len(templates) == 0 {
+		return nil
+	}
+
+	data := headerTemplateData{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Path:   req.URL.EscapedPath(),
+		Query:  req.URL.RawQuery,
+		Host:   req.URL.Host,
+		Body:   string(body),
+	}
+
+	for _, headerTemplate := range templates {
+		var value strings.Builder
+		if err := headerTemplate.tmpl.Execute(&value, data); err != nil {
+			return fmt.Errorf("failed to render header %s: %w", headerTemplate.Name, err)
+		}
+		req.Header.Set(headerTemplate.Name, value.String())
+	}
+
+	return nil
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256Hex signs data with the secret held by the environment variable
+// secretEnv
+func hmacSHA256Hex(data, secretEnv string) (string, error) {
+	secret := os.Getenv(secretEnv)
+	if secret == "" {
+		return "", fmt.Errorf("signing secret environment variable %s is not set", secretEnv)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyHeaderTemplates(t *testing.T) {
+	t.Setenv("KUMOCTL_TEST_SECRET", "secret")
+
+	body := []byte(`{"amount": 10}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	expectedSignature := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name     string
+		value    string
+		expected func(string) bool
+	}{
+		{
+			name:     "HMAC of the body",
+			value:    `{{hmac_sha256 .Body "KUMOCTL_TEST_SECRET"}}`,
+			expected: func(v string) bool { return v == expectedSignature },
+		},
+		{
+			name:  "current time",
+			value: `{{now.UTC.Format "2006-01-02"}}`,
+			expected: func(v string) bool {
+				_, err := time.Parse("2006-01-02", v)
+				return err == nil
+			},
+		},
+		{
+			name:     "request context",
+			value:    `{{.Method}} {{.Path}}?{{.Query}}`,
+			expected: func(v string) bool { return v == "POST /payments?currency=eur" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headerTemplate, err := NewHeaderTemplate("X-Test", tt.value)
+			if err != nil {
+				t.Fatalf("Failed to parse template: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://api.example.com/payments?currency=eur", nil)
+			if err := applyHeaderTemplates(req, body, []*HeaderTemplate{headerTemplate}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if value := req.Header.Get("X-Test"); !tt.expected(value) {
+				t.Errorf("Unexpected header value %q", value)
+			}
+		})
+	}
+}
+
+func TestApplyHeaderTemplatesMissingSecret(t *testing.T) {
+	headerTemplate, err := NewHeaderTemplate("X-Signature", `{{hmac_sha256 .Body "KUMOCTL_TEST_UNSET_SECRET"}}`)
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "https://api.example.com/payments", nil)
+	err = applyHeaderTemplates(req, nil, []*HeaderTemplate{headerTemplate})
+	if err == nil || !strings.Contains(err.Error(), "KUMOCTL_TEST_UNSET_SECRET") {
+		t.Errorf("Expected missing secret error, got %v", err)
+	}
+}
+
+func TestSplitHeaderTemplates(t *testing.T) {
+	headers := http.Header{
+		"Authorization": {"Bearer token"},
+		"X-Date":        {"{{now.UTC}}"},
+	}
+
+	static, templates, err := SplitHeaderTemplates(headers)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if static.Get("Authorization") != "Bearer token" || static.Get("X-Date") != "" {
+		t.Errorf("Unexpected static headers: %v", static)
+	}
+	if len(templates) != 1 || templates[0].Name != "X-Date" {
+		t.Errorf("Expected X-Date template, got %v", templates)
+	}
+
+	if _, _, err := SplitHeaderTemplates(http.Header{"X-Bad": {"{{env"}}); err == nil {
+		t.Error("Expected error for an invalid template")
+	}
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// defaultHostVariablePattern accepts a single DNS label
+const defaultHostVariablePattern = `[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?`
+
+var hostVariableRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// HostVariable is a placeholder in the host portion of a base URL that is
+// filled from tool input, e.g. tenant in https://{tenant}.api.example.com
+type HostVariable struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// NewHostVariable creates a host variable whose values must fully match
+// pattern. An empty pattern only allows a single DNS label.
+func NewHostVariable(name, pattern string) (*HostVariable, error) {
+	if pattern == "" {
+		pattern = defaultHostVariablePattern
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern for host variable %s: %w", name, err)
+	}
+
+	return &HostVariable{Name: name, Pattern: re}, nil
+}
+
+// splitBaseURL splits a base URL into its scheme, host and path portions
+func splitBaseURL(baseURL string) (string, string, string) {
+	scheme, rest, found := strings.Cut(baseURL, "://")
+	if !found {
+		return "", baseURL, ""
+	}
+
+	host, path := rest, ""
+	if idx := strings.Index(rest, "/"); idx != -1 {
+		host, path = rest[:idx], rest[idx:]
+	}
+	return scheme + "://", host, path
+}
+
+// hostVariablesFor returns the declared host variables used by a base URL
+func hostVariablesFor(baseURL string, hostVariables map[string]*HostVariable) []*HostVariable {
+	_, host, _ := splitBaseURL(baseURL)
+
+	var vars []*HostVariable
+	for _, match := range hostVariableRegex.FindAllStringSubmatch(host, -1) {
+		if hostVar, ok := hostVariables[match[1]]; ok && !slices.Contains(vars, hostVar) {
+			vars = append(vars, hostVar)
+		}
+	}
+	return vars
+}
+
+// resolveBaseURL substitutes declared host variables in the base URL with
+// validated values from the tool input
+func resolveBaseURL(baseURL string, input APIToolInput, hostVariables map[string]*HostVariable) (string, error) {
+	if len(hostVariables) == 0 {
+		return baseURL, nil
+	}
+
+	scheme, host, path := splitBaseURL(baseURL)
+
+	var resolveErr error
+	resolvedHost := hostVariableRegex.ReplaceAllStringFunc(host, func(match string) string {
+		name := match[1 : len(match)-1]
+		hostVar, ok := hostVariables[name]
+		if !ok || resolveErr != nil {
+			return match
+		}
+
+		value, exists := input[name]
+		if !exists {
+			resolveErr = fmt.Errorf("missing required host variable: %s", name)
+			return match
+		}
+
+		str, ok := value.(string)
+		if !ok || !hostVar.Pattern.MatchString(str) {
+			resolveErr = fmt.Errorf("invalid value for host variable %s: %v", name, value)
+			return match
+		}
+
+		return str
+	})
+
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	return scheme + resolvedHost + path, nil
+}
+
+// addHostVariablesToSchema exposes the host variables used by a tool as
+// required string inputs. A host variable named like an input of the
+// operation is an error, as one would hide the other.
+func addHostVariablesToSchema(schema *jsonschema.Schema, vars []*HostVariable) error {
+	if schema == nil || len(vars) == 0 {
+		return nil
+	}
+
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]*jsonschema.Schema)
+	}
+
+	for _, hostVar := range vars {
+		if _, exists := schema.Properties[hostVar.Name]; exists {
+			return fmt.Errorf("host variable %s collides with an input of the same name", hostVar.Name)
+		}
+		schema.Properties[hostVar.Name] = &jsonschema.Schema{
+			Type:        "string",
+			Description: fmt.Sprintf("Host variable: %s", hostVar.Name),
+			Pattern:     hostVar.Pattern.String(),
+		}
+		if !slices.Contains(schema.Required, hostVar.Name) {
+			schema.Required = append(schema.Required, hostVar.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestResolveBaseURL(t *testing.T) {
+	tenant, err := NewHostVariable("tenant", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	region, err := NewHostVariable("region", "eu|us")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	hostVars := map[string]*HostVariable{"tenant": tenant, "region": region}
+
+	tests := []struct {
+		name     string
+		baseURL  string
+		input    APIToolInput
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "substitutes tenant",
+			baseURL:  "https://{tenant}.api.example.com/v1",
+			input:    APIToolInput{"tenant": "acme"},
+			expected: "https://acme.api.example.com/v1",
+		},
+		{
+			name:     "substitutes multiple variables",
+			baseURL:  "https://{tenant}.{region}.example.com",
+			input:    APIToolInput{"tenant": "acme", "region": "eu"},
+			expected: "https://acme.eu.example.com",
+		},
+		{
+			name:     "leaves undeclared variables and path untouched",
+			baseURL:  "https://{other}.example.com/{tenant}",
+			input:    APIToolInput{"tenant": "acme"},
+			expected: "https://{other}.example.com/{tenant}",
+		},
+		{
+			name:     "rejects host injection",
+			baseURL:  "https://{tenant}.api.example.com",
+			input:    APIToolInput{"tenant": "evil.com/x"},
+			hasError: true,
+		},
+		{
+			name:     "rejects userinfo injection",
+			baseURL:  "https://{tenant}.api.example.com",
+			input:    APIToolInput{"tenant": "evil.com@internal"},
+			hasError: true,
+		},
+		{
+			name:     "rejects values outside allowlist pattern",
+			baseURL:  "https://{region}.example.com",
+			input:    APIToolInput{"region": "ap"},
+			hasError: true,
+		},
+		{
+			name:     "rejects non-string values",
+			baseURL:  "https://{tenant}.example.com",
+			input:    APIToolInput{"tenant": 42},
+			hasError: true,
+		},
+		{
+			name:     "missing variable",
+			baseURL:  "https://{tenant}.example.com",
+			input:    APIToolInput{},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := resolveBaseURL(tt.baseURL, tt.input, hostVars)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected error but got %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("resolveBaseURL() = %s, expected %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddHostVariablesToSchema(t *testing.T) {
+	tenant, _ := NewHostVariable("tenant", "")
+	schema := &jsonschema.Schema{Type: "object"}
+
+	vars := hostVariablesFor("https://{tenant}.{tenant}.api.example.com/{version}", map[string]*HostVariable{"tenant": tenant})
+	if err := addHostVariablesToSchema(schema, vars); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if schema.Properties["tenant"] == nil || schema.Properties["tenant"].Pattern == "" {
+		t.Fatalf("Expected tenant property with pattern, got %+v", schema.Properties)
+	}
+	if len(schema.Required) != 1 || schema.Required[0] != "tenant" {
+		t.Errorf("Expected tenant to be required, got %v", schema.Required)
+	}
+}
+
+func TestAddHostVariablesToSchemaCollision(t *testing.T) {
+	tenant, _ := NewHostVariable("tenant", "")
+	schema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: map[string]*jsonschema.Schema{"tenant": {Type: "integer"}},
+		Required:   []string{"tenant"},
+	}
+
+	err := addHostVariablesToSchema(schema, []*HostVariable{tenant})
+	if err == nil || !strings.Contains(err.Error(), "collides") {
+		t.Fatalf("Expected a collision error, got %v", err)
+	}
+	if schema.Properties["tenant"].Type != "integer" {
+		t.Errorf("Expected the parameter to be kept, got %+v", schema.Properties["tenant"])
+	}
+}
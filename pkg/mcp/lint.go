@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+// pathTemplateRegex matches the parameters of a path template
+var pathTemplateRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// ValidateTools reports every problem of the spec's operations that affects
+// the generated tools, in path and method order
+func ValidateTools(spec openapi.APISpec) []openapi.Problem {
+	var problems []openapi.Problem
+	report := func(severity, location, format string, args ...interface{}) {
+		problems = append(problems, openapi.Problem{Severity: severity, Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	paths := spec.GetPaths()
+	if len(paths) == 0 {
+		report(openapi.SeverityError, "", "the spec has no operations, no tools would be generated")
+		return problems
+	}
+
+	// Relative servers are resolved against where the spec was served from,
+	// which tools don't know
+	if baseURL, err := url.Parse(spec.GetBaseURL()); err != nil || baseURL.Host == "" || len(spec.GetServers()) == 0 {
+		report(openapi.SeverityWarning, "", "the spec declares no absolute server URL, tools need --base-url to be called")
+	}
+
+	ignore, err := newIgnoreList(spec)
+	if err != nil {
+		report(openapi.SeverityError, "", "%v", err)
+		ignore = &ignoreList{}
+	}
+
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+
+	toolLocations := make(map[string][]string)
+	for _, path := range pathNames {
+		operations := paths[path].GetOperations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			operation := operations[method]
+			if operation == nil {
+				continue
+			}
+			location := openapi.OperationLocation(method, path)
+
+			// Ignored operations get no tool, so nothing of them matters
+			if ignored, err := ignore.ignored(method, path, paths[path], operation); err != nil {
+				report(openapi.SeverityError, location, "%v", err)
+				continue
+			} else if ignored {
+				continue
+			}
+
+			raw, err := operationToolName(method, path, operation)
+			if err != nil {
+				report(openapi.SeverityError, location, "%v", err)
+				continue
+			}
+			name := sanitizeToolName(raw)
+			if _, err := stringExtension(operation, extensionDescription); err != nil {
+				report(openapi.SeverityError, location, "%v", err)
+			}
+			if _, err := extensionAnnotationsFor(operation, toolAnnotations(method)); err != nil {
+				report(openapi.SeverityError, location, "%v", err)
+			}
+			toolLocations[name] = append(toolLocations[name], location)
+			if operation.GetOperationID() == "" && raw == rawToolName(method, path, "") {
+				report(openapi.SeverityWarning, location, "no operationId, the tool is named %s after the method and path; set an operationId for a stable, readable name", name)
+			}
+			if len(raw) > maxToolNameLength {
+				report(openapi.SeverityWarning, location, "tool name %s is longer than %d characters, which some MCP clients reject, it is shortened to %s", raw, maxToolNameLength, name)
+			} else if !toolNameRegex.MatchString(raw) {
+				report(openapi.SeverityWarning, location, "tool name %s has characters other than letters, digits, _ and -, which some MCP clients reject, it is renamed %s", raw, name)
+			}
+
+			problems = append(problems, validateOperation(location, path, operation)...)
+		}
+	}
+
+	names := make([]string, 0, len(toolLocations))
+	for name := range toolLocations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if locations := toolLocations[name]; len(locations) > 1 {
+			report(openapi.SeverityError, "", "tool name %s is generated by %s, give the operations distinct operationIds", name, strings.Join(locations, " and "))
+		}
+	}
+
+	return problems
+}
+
+// validateOperation checks that a tool can be generated for an operation and
+// that its calls can be built
+func validateOperation(location, path string, operation openapi.Operation) []openapi.Problem {
+	var problems []openapi.Problem
+	report := func(severity, format string, args ...interface{}) {
+		problems = append(problems, openapi.Problem{Severity: severity, Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if requestBody := operation.GetRequestBody(); requestBody != nil {
+		if _, err := requestBody.GetJSONSchema(); err != nil {
+			report(openapi.SeverityError, "request body can't be converted, only application/json bodies are supported: %v", err)
+		}
+	}
+	if _, err := openapi.GenerateInputSchema(operation); err != nil && len(problems) == 0 {
+		report(openapi.SeverityError, "input schema can't be generated, so no tools would be served: %v", err)
+	}
+
+	if _, err := openapi.GenerateOutputSchema(operation); err != nil {
+		report(openapi.SeverityWarning, "response schema can't be converted, the tool output is untyped: %v", err)
+	}
+
+	if _, err := operationBaseURL(operation, ""); err != nil {
+		report(openapi.SeverityError, "invalid servers: %v", err)
+	}
+
+	declared := make(map[string]bool)
+	for _, param := range operation.GetParameters() {
+		switch param.GetIn() {
+		case "path":
+			declared[param.GetName()] = true
+		case "cookie":
+			report(openapi.SeverityWarning, "cookie parameter %s is not supported and never sent", param.GetName())
+		}
+	}
+	for _, match := range pathTemplateRegex.FindAllStringSubmatch(path, -1) {
+		if !declared[match[1]] {
+			report(openapi.SeverityError, "path parameter %s is not declared, calls would send the literal {%s}", match[1], match[1])
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestValidateTools(t *testing.T) {
+	spec, problems := openapi.ValidateDocument([]byte(`
+openapi: 3.0.0
+info: {title: Lint, version: "1"}
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+        - {name: session, in: cookie, schema: {type: string}}
+      responses: {"200": {description: OK}}
+  /upload:
+    post:
+      operationId: upload
+      requestBody:
+        content:
+          text/csv: {schema: {type: string}}
+      responses: {"200": {description: OK}}
+  /uploads:
+    get:
+      operationId: upload
+      responses: {"200": {description: OK}}
+`))
+	if spec == nil {
+		t.Fatalf("Expected the spec to be parsed, got %v", problems)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "same operation id") {
+		t.Errorf("Expected the duplicate operationId to fail validation, got %v", problems)
+	}
+
+	var got []string
+	for _, problem := range ValidateTools(spec) {
+		got = append(got, problem.String())
+	}
+
+	expected := []string{
+		"error: POST /upload: request body can't be converted",
+		"warning: GET /users/{id}: no operationId, the tool is named get_users_id",
+		"warning: GET /users/{id}: cookie parameter session is not supported",
+		"warning: the spec declares no absolute server URL",
+		"error: tool name upload is generated by POST /upload and GET /uploads",
+	}
+	for _, e := range expected {
+		found := false
+		for _, g := range got {
+			if strings.HasPrefix(g, e) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a problem starting with %q, got %v", e, got)
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d problems, got %d: %v", len(expected), len(got), got)
+	}
+}
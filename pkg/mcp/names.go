@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DisambiguateToolNames prefixes the tool names occurring in several specs
+// with the name of their spec, e.g. users_listItems and orders_listItems. The
+// input and the result are keyed by spec name, the result holding the renames
+// of each spec keyed by generated name.
+func DisambiguateToolNames(toolNames map[string][]string) map[string]map[string]string {
+	specs := make(map[string][]string)
+	for specName, names := range toolNames {
+		for _, name := range names {
+			specs[name] = append(specs[name], specName)
+		}
+	}
+
+	renames := make(map[string]map[string]string, len(toolNames))
+	for name, specNames := range specs {
+		if len(specNames) < 2 {
+			continue
+		}
+
+		sort.Strings(specNames)
+		for _, specName := range specNames {
+			if renames[specName] == nil {
+				renames[specName] = make(map[string]string)
+			}
+			renames[specName][name] = specName + "_" + name
+		}
+	}
+	return renames
+}
+
+// Naming conventions tool names can be normalized to
+const (
+	NameCaseSnake = "snake"
+	NameCaseCamel = "camel"
+)
+
+// renameTool applies the configured rename, naming convention and prefixes to
+// a freshly generated tool
+func renameTool(tool *EnrichedTool, opts *ToolOptions) {
+	if name, ok := opts.ToolNames[tool.Name]; ok {
+		tool.Name = name
+	}
+	if opts.NameCase != "" {
+		tool.Name = convertNameCase(tool.Name, opts.NameCase)
+	}
+	if opts.PrefixToolsets {
+		tool.Name = sanitizeToolName(toolsetPrefix(tool, opts.Filter) + "_" + tool.Name)
+	}
+	if opts.ToolPrefix != "" {
+		tool.Name = sanitizeToolName(opts.ToolPrefix + tool.Name)
+	}
+}
+
+// maxToolNameLength is the longest tool name all MCP clients accept
+const maxToolNameLength = 64
+
+// toolNameRegex matches tool names all MCP clients accept
+var toolNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// invalidToolNameChars matches the runs of characters some MCP clients reject
+// in tool names, with the underscores around them
+var invalidToolNameChars = regexp.MustCompile(`_*[^A-Za-z0-9_-]+_*`)
+
+// sanitizeToolName turns a name into one all MCP clients accept. Valid names
+// are kept, other runs of characters are replaced by an underscore and names
+// too long are truncated, ending with a hash of the whole name so distinct
+// names stay distinct.
+func sanitizeToolName(name string) string {
+	if len(name) <= maxToolNameLength && toolNameRegex.MatchString(name) {
+		return name
+	}
+
+	sanitized := strings.Trim(invalidToolNameChars.ReplaceAllString(name, "_"), "_")
+	if sanitized == "" {
+		sanitized = "tool"
+	}
+	if len(sanitized) <= maxToolNameLength {
+		return sanitized
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := "_" + hex.EncodeToString(sum[:])[:8]
+	return strings.TrimRight(sanitized[:maxToolNameLength-len(suffix)], "_-") + suffix
+}
+
+// convertNameCase rewrites a name in the snake_case or camelCase convention,
+// splitting it into words at underscores, dashes and case changes, e.g.
+// getUserByID becomes get_user_by_id or getUserById
+func convertNameCase(name, nameCase string) string {
+	words := splitNameWords(name)
+	if len(words) == 0 {
+		return name
+	}
+
+	switch nameCase {
+	case NameCaseSnake:
+		return strings.Join(words, "_")
+	case NameCaseCamel:
+		var b strings.Builder
+		b.WriteString(words[0])
+		for _, word := range words[1:] {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+		return b.String()
+	default:
+		return name
+	}
+}
+
+// splitNameWords splits a name into lowercase words. Acronyms are one word,
+// e.g. HTTPServer is http and server, and digits stay with the word before
+// them.
+func splitNameWords(name string) []string {
+	var words []string
+	var word []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if r == '_' || r == '-' {
+			flush()
+			continue
+		}
+
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	return words
+}
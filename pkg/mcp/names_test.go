@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestDisambiguateToolNames(t *testing.T) {
+	renames := DisambiguateToolNames(map[string][]string{
+		"users":  {"listItems", "getUser"},
+		"orders": {"listItems", "getOrder"},
+		"search": {"query"},
+	})
+
+	expected := map[string]map[string]string{
+		"users":  {"listItems": "users_listItems"},
+		"orders": {"listItems": "orders_listItems"},
+	}
+	if !reflect.DeepEqual(renames, expected) {
+		t.Errorf("Expected %v, got %v", expected, renames)
+	}
+}
+
+func TestSanitizeToolName(t *testing.T) {
+	longPath := "get_" + strings.Repeat("organizations_orgId_projects_projectId_", 4)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "valid name kept", input: "list-users_v2", expected: "list-users_v2"},
+		{name: "runs of invalid characters", input: "users::list..all", expected: "users_list_all"},
+		{name: "leading and trailing invalid characters", input: "$users$", expected: "users"},
+		{name: "nothing valid", input: "{}/", expected: "tool"},
+		{name: "long name truncated with hash", input: longPath, expected: "get_organizations_orgId_projects_projectId_organization_6824510e"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeToolName(tt.input)
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+			if len(got) > maxToolNameLength || !toolNameRegex.MatchString(got) {
+				t.Errorf("Sanitized name %s is still rejected by some clients", got)
+			}
+		})
+	}
+
+	// Long names differing only past the limit stay distinct
+	if sanitizeToolName(longPath+"a") == sanitizeToolName(longPath+"b") {
+		t.Error("Expected distinct names for distinct long names")
+	}
+}
+
+func TestRenameTool(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     *ToolOptions
+		expected string
+	}{
+		{name: "unchanged", opts: &ToolOptions{}, expected: "listItems"},
+		{name: "renamed", opts: &ToolOptions{ToolNames: map[string]string{"listItems": "users_listItems"}}, expected: "users_listItems"},
+		{name: "prefixed", opts: &ToolOptions{ToolPrefix: "github_"}, expected: "github_listItems"},
+		{name: "renamed and prefixed", opts: &ToolOptions{ToolNames: map[string]string{"listItems": "users_listItems"}, ToolPrefix: "gh-"}, expected: "gh-users_listItems"},
+		{name: "prefix sanitized", opts: &ToolOptions{ToolPrefix: "git hub."}, expected: "git_hub_listItems"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &EnrichedTool{Tool: &mcp.Tool{Name: "listItems"}}
+			renameTool(tool, tt.opts)
+			if tool.Name != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, tool.Name)
+			}
+		})
+	}
+}
+
+func TestConvertNameCase(t *testing.T) {
+	tests := []struct {
+		name          string
+		expectedSnake string
+		expectedCamel string
+	}{
+		{name: "getUserByID", expectedSnake: "get_user_by_id", expectedCamel: "getUserById"},
+		{name: "get_users_id", expectedSnake: "get_users_id", expectedCamel: "getUsersId"},
+		{name: "list-repos", expectedSnake: "list_repos", expectedCamel: "listRepos"},
+		{name: "HTTPServerStatus", expectedSnake: "http_server_status", expectedCamel: "httpServerStatus"},
+		{name: "listS3Buckets", expectedSnake: "list_s3_buckets", expectedCamel: "listS3Buckets"},
+		{name: "Users__Create", expectedSnake: "users_create", expectedCamel: "usersCreate"},
+		{name: "get", expectedSnake: "get", expectedCamel: "get"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertNameCase(tt.name, NameCaseSnake); got != tt.expectedSnake {
+				t.Errorf("Expected snake_case %s, got %s", tt.expectedSnake, got)
+			}
+			if got := convertNameCase(tt.name, NameCaseCamel); got != tt.expectedCamel {
+				t.Errorf("Expected camelCase %s, got %s", tt.expectedCamel, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// nextPageInput is the shorthand input continuing a listing from the cursor
+// returned by the previous call with the same arguments
+const nextPageInput = "_next_page"
+
+// cursorParamNames are the query parameters recognized as page cursors
+var cursorParamNames = []string{
+	"cursor", "page_token", "pageToken", "next_token", "nextToken",
+	"continuation_token", "continuationToken", "starting_after", "after", "marker",
+}
+
+// nextCursorFields are the response fields recognized as the next cursor
+var nextCursorFields = []string{
+	"next_cursor", "nextCursor", "next_page_token", "nextPageToken",
+	"next_token", "nextToken", "continuation_token", "continuationToken", "next_marker", "nextMarker",
+}
+
+// cursorContainers are the objects next cursors are looked up in, the empty
+// name being the response body itself
+var cursorContainers = []string{"", "meta", "pagination", "paging", "response_metadata"}
+
+var linkNextRegex = regexp.MustCompile(`<([^>]*)>\s*;[^,]*rel="?next"?`)
+
+// PageCursors remembers the next page cursor per tool and arguments, so
+// agents can page through a listing with `_next_page: true`
+type PageCursors struct {
+	mu      sync.Mutex
+	cursors map[string]string
+}
+
+// NewPageCursors creates an empty cursor memory
+func NewPageCursors() *PageCursors {
+	return &PageCursors{cursors: make(map[string]string)}
+}
+
+// wrap resolves `_next_page` for a tool paginated by the cursor query
+// parameter param. Only successful calls advance the cursor, so a failed
+// page can be retried with the same input.
+func (p *PageCursors) wrap(tool *EnrichedTool, param string, handler apiToolHandler) apiToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		nextPage, _ := input[nextPageInput].(bool)
+		key := pageKey(tool.Name, input, param)
+
+		input = withoutInput(input, nextPageInput)
+		if nextPage {
+			p.mu.Lock()
+			cursor, ok := p.cursors[key]
+			p.mu.Unlock()
+
+			if !ok {
+				return nil, APIToolOutput{Error: fmt.Sprintf("%s continues a previous call with the same arguments, call %s without it first", nextPageInput, tool.Name)}, nil
+			}
+			if cursor == "" {
+				return nil, APIToolOutput{Error: "there are no more pages, the previous call returned the last one"}, nil
+			}
+			input[param] = cursor
+		}
+
+		result, output, err := handler(ctx, req, input)
+		if err == nil && output.Error == "" && output.StatusCode >= 200 && output.StatusCode < 300 {
+			p.mu.Lock()
+			p.cursors[key] = nextCursor(output, param)
+			p.mu.Unlock()
+		}
+
+		return result, output, err
+	}
+}
+
+// cursorParam returns the query parameter a tool is paginated by, or an empty
+// string when it has none
+func cursorParam(tool *EnrichedTool) string {
+	for _, name := range cursorParamNames {
+		for _, param := range tool.Operation.GetParameters() {
+			if param.GetIn() == "query" && param.GetName() == name {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// addNextPageToSchema exposes the `_next_page` shorthand as a tool input
+func addNextPageToSchema(schema *jsonschema.Schema, param string) {
+	if schema == nil {
+		return
+	}
+
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]*jsonschema.Schema)
+	}
+
+	schema.Properties[nextPageInput] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: fmt.Sprintf("Set to true to fetch the page after the previous call with the same arguments, instead of passing %s", param),
+	}
+}
+
+// pageKey fingerprints a tool call by its arguments, leaving out the cursor
+func pageKey(toolName string, input APIToolInput, param string) string {
+	data, _ := json.Marshal(withoutInput(input, nextPageInput, param))
+	hash := sha256.Sum256(data)
+	return toolName + ":" + hex.EncodeToString(hash[:])
+}
+
+// withoutInput returns a copy of input without the given keys
+func withoutInput(input APIToolInput, keys ...string) APIToolInput {
+	filtered := make(APIToolInput, len(input))
+	for key, value := range input {
+		filtered[key] = value
+	}
+	for _, key := range keys {
+		delete(filtered, key)
+	}
+	return filtered
+}
+
+// nextCursor finds the next page cursor in a response, from a Link header
+// with rel="next" or a well-known field of the body. It returns an empty
+// string on the last page.
+func nextCursor(output APIToolOutput, param string) string {
+	if match := linkNextRegex.FindStringSubmatch(output.Headers["Link"]); match != nil {
+		if next, err := url.Parse(match[1]); err == nil {
+			if cursor := next.Query().Get(param); cursor != "" {
+				return cursor
+			}
+		}
+	}
+
+	body, ok := output.Body.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, container := range cursorContainers {
+		fields := body
+		if container != "" {
+			if fields, ok = body[container].(map[string]interface{}); !ok {
+				continue
+			}
+		}
+
+		for _, field := range nextCursorFields {
+			switch cursor := fields[field].(type) {
+			case string:
+				if cursor != "" {
+					return cursor
+				}
+			case float64:
+				return strconv.FormatFloat(cursor, 'f', -1, 64)
+			}
+		}
+	}
+
+	return ""
+}
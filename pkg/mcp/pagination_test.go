@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestPageCursorsNextPage(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"items": [1, 2], "meta": {"next_cursor": "c2"}}`,
+		"c2": `{"items": [3, 4], "meta": {"next_cursor": "c3"}}`,
+		"c3": `{"items": [5]}`,
+	}
+
+	var failNext bool
+	var cursors []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursors = append(cursors, r.URL.Query().Get("cursor"))
+		if failNext {
+			failNext = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(pages[r.URL.Query().Get("cursor")]))
+	}))
+	defer mockServer.Close()
+
+	tool := &EnrichedTool{
+		Tool:    &mcp.Tool{Name: "listItems"},
+		BaseUrl: mockServer.URL,
+		Method:  "get",
+		Path:    "/items",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{
+			Parameters: openapi3.Parameters{
+				{Value: openapi3.NewQueryParameter("cursor")},
+				{Value: openapi3.NewQueryParameter("status")},
+			},
+		}},
+	}
+
+	handler := createAPIHandlerForTool(tool, &ToolOptions{PageCursors: NewPageCursors()})
+	call := func(input APIToolInput) APIToolOutput {
+		t.Helper()
+		_, output, err := handler(context.Background(), nil, input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return output
+	}
+
+	if output := call(APIToolInput{"status": "open", "_next_page": true}); output.Error == "" {
+		t.Error("Expected error for _next_page without a previous call")
+	}
+
+	call(APIToolInput{"status": "open"})
+
+	// A failed page doesn't advance the cursor, the retry fetches it again
+	failNext = true
+	call(APIToolInput{"status": "open", "_next_page": true})
+	call(APIToolInput{"status": "open", "_next_page": true})
+	call(APIToolInput{"status": "open", "_next_page": true})
+
+	if output := call(APIToolInput{"status": "open", "_next_page": true}); output.Error == "" {
+		t.Error("Expected error after the last page")
+	}
+
+	// Other arguments page independently
+	if output := call(APIToolInput{"status": "closed", "_next_page": true}); output.Error == "" {
+		t.Error("Expected error for arguments without a previous call")
+	}
+
+	expected := []string{"", "c2", "c2", "c3"}
+	if len(cursors) != len(expected) {
+		t.Fatalf("Expected cursors %v, got %v", expected, cursors)
+	}
+	for i := range expected {
+		if cursors[i] != expected[i] {
+			t.Errorf("Expected cursors %v, got %v", expected, cursors)
+			break
+		}
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   APIToolOutput
+		expected string
+	}{
+		{
+			name:     "link header",
+			output:   APIToolOutput{Headers: map[string]string{"Link": `<https://api.example.com/items?cursor=abc&limit=10>; rel="next", <https://api.example.com/items>; rel="first"`}},
+			expected: "abc",
+		},
+		{
+			name:     "top-level field",
+			output:   APIToolOutput{Body: map[string]interface{}{"nextPageToken": "token-2"}},
+			expected: "token-2",
+		},
+		{
+			name:     "nested field",
+			output:   APIToolOutput{Body: map[string]interface{}{"response_metadata": map[string]interface{}{"next_cursor": "dGVhbTpDMDYx"}}},
+			expected: "dGVhbTpDMDYx",
+		},
+		{
+			name:     "numeric cursor",
+			output:   APIToolOutput{Body: map[string]interface{}{"next_token": float64(12345678)}},
+			expected: "12345678",
+		},
+		{
+			name:     "last page",
+			output:   APIToolOutput{Body: map[string]interface{}{"next_cursor": ""}},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextCursor(tt.output, "cursor"); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ResponseParser decodes a raw response body into a JSON-compatible value
+type ResponseParser func(data []byte) (interface{}, error)
+
+var (
+	responseParsersMu sync.RWMutex
+	responseParsers   = map[string]ResponseParser{
+		"application/json":   parseJSONBody,
+		"application/xml":    parseXMLBody,
+		"text/xml":           parseXMLBody,
+		"text/csv":           parseCSVBody,
+		"application/yaml":   parseYAMLBody,
+		"application/x-yaml": parseYAMLBody,
+		"text/yaml":          parseYAMLBody,
+		"text/plain":         parseTextBody,
+		"text/*":             parseTextBody,
+	}
+)
+
+// RegisterResponseParser registers a parser for a media type, replacing any
+// parser previously registered for it. Wildcard subtypes such as "text/*" act
+// as a fallback for their whole type.
+func RegisterResponseParser(mediaType string, parser ResponseParser) {
+	responseParsersMu.Lock()
+	defer responseParsersMu.Unlock()
+	responseParsers[strings.ToLower(mediaType)] = parser
+}
+
+// lookupResponseParser finds the parser for a Content-Type header value,
+// falling back to JSON when the content type is missing or unknown
+func lookupResponseParser(contentType string) ResponseParser {
+	responseParsersMu.RLock()
+	defer responseParsersMu.RUnlock()
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		return responseParsers["application/json"]
+	}
+
+	if parser, ok := responseParsers[mediaType]; ok {
+		return parser
+	}
+
+	// Structured syntax suffixes, e.g. application/problem+json
+	if idx := strings.LastIndex(mediaType, "+"); idx != -1 {
+		switch mediaType[idx+1:] {
+		case "json":
+			return responseParsers["application/json"]
+		case "xml":
+			return responseParsers["application/xml"]
+		case "yaml":
+			return responseParsers["application/yaml"]
+		}
+	}
+
+	if idx := strings.Index(mediaType, "/"); idx != -1 {
+		if parser, ok := responseParsers[mediaType[:idx]+"/*"]; ok {
+			return parser
+		}
+	}
+
+	return responseParsers["application/json"]
+}
+
+func parseJSONBody(data []byte) (interface{}, error) {
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// parseTextBody returns the body as a string, unless it is a JSON object or
+// array served with a generic text content type
+func parseTextBody(data []byte) (interface{}, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		if body, err := parseJSONBody(trimmed); err == nil {
+			return body, nil
+		}
+	}
+	return string(data), nil
+}
+
+// parseCSVBody converts CSV into a list of rows keyed by the header row
+func parseCSVBody(data []byte) (interface{}, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []interface{}{}
+	if len(records) == 0 {
+		return rows, nil
+	}
+
+	header := records[0]
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseYAMLBody(data []byte) (interface{}, error) {
+	var body interface{}
+	if err := yaml.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	return normalizeYAMLValue(body), nil
+}
+
+// normalizeYAMLValue converts maps with non-string keys into JSON-compatible maps
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAMLValue(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized[fmt.Sprintf("%v", key)] = normalizeYAMLValue(item)
+		}
+		return normalized
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAMLValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// parseXMLBody converts an XML document into nested maps. Attributes are
+// prefixed with "@", text content is stored under "#text" and repeated child
+// elements become lists.
+func parseXMLBody(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no root element found")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := decodeXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: root}, nil
+		}
+	}
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	element := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		element["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := element[name].(type) {
+			case nil:
+				element[name] = child
+			case []interface{}:
+				element[name] = append(existing, child)
+			default:
+				element[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			// Leaf elements without attributes collapse to their text
+			if len(element) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				element["#text"] = content
+			}
+			return element, nil
+		}
+	}
+}
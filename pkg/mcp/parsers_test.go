@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseResponseContentTypes(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentType  string
+		body         string
+		expectedBody interface{}
+	}{
+		{
+			name:         "problem+json suffix",
+			contentType:  "application/problem+json",
+			body:         `{"title": "Not Found"}`,
+			expectedBody: map[string]interface{}{"title": "Not Found"},
+		},
+		{
+			name:        "XML with attributes and repeated elements",
+			contentType: "application/xml; charset=utf-8",
+			body:        `<users count="2"><user>alice</user><user>bob</user></users>`,
+			expectedBody: map[string]interface{}{
+				"users": map[string]interface{}{
+					"@count": "2",
+					"user":   []interface{}{"alice", "bob"},
+				},
+			},
+		},
+		{
+			name:        "CSV rows",
+			contentType: "text/csv",
+			body:        "id,name\n1,alice\n2,bob\n",
+			expectedBody: []interface{}{
+				map[string]interface{}{"id": "1", "name": "alice"},
+				map[string]interface{}{"id": "2", "name": "bob"},
+			},
+		},
+		{
+			name:        "YAML document",
+			contentType: "application/yaml",
+			body:        "name: alice\ntags:\n  - admin\n",
+			expectedBody: map[string]interface{}{
+				"name": "alice",
+				"tags": []interface{}{"admin"},
+			},
+		},
+		{
+			name:         "plain text",
+			contentType:  "text/plain",
+			body:         "pong",
+			expectedBody: "pong",
+		},
+		{
+			name:         "text wildcard fallback",
+			contentType:  "text/markdown",
+			body:         "# Title",
+			expectedBody: "# Title",
+		},
+		{
+			name:         "unknown content type falls back to JSON",
+			contentType:  "application/octet-stream",
+			body:         `[1, 2]`,
+			expectedBody: []interface{}{float64(1), float64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {tt.contentType}},
+				Body:       &mockReadCloser{strings.NewReader(tt.body)},
+			}
+
+			result, err := parseResponse(resp)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result.Body, tt.expectedBody) {
+				t.Errorf("Expected body %v (%T), got %v (%T)", tt.expectedBody, tt.expectedBody, result.Body, result.Body)
+			}
+		})
+	}
+}
+
+func TestRegisterResponseParser(t *testing.T) {
+	RegisterResponseParser("application/x-custom", func(data []byte) (interface{}, error) {
+		return strings.ToUpper(string(data)), nil
+	})
+	defer func() {
+		responseParsersMu.Lock()
+		delete(responseParsers, "application/x-custom")
+		responseParsersMu.Unlock()
+	}()
+
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"application/x-custom"}},
+		Body:       &mockReadCloser{strings.NewReader("custom")},
+	}
+
+	result, err := parseResponse(resp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Body != "CUSTOM" {
+		t.Errorf("Expected custom parser output, got %v", result.Body)
+	}
+}
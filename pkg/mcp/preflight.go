@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Preflight checks that the upstream API behind baseURL is reachable and
+// accepts the configured credentials, so a broken backend is reported before
+// an MCP client starts a session. Any response other than an authentication
+// failure or server error counts as reachable. Base URLs that aren't
+// absolute, such as /api/v3, are left unchecked.
+func Preflight(ctx context.Context, baseURL string, opts *ToolOptions) error {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	// Placeholders are only known once a tool is called
+	if hostVariableRegex.MatchString(baseURL) {
+		return nil
+	}
+	if u, err := url.Parse(baseURL); err != nil || !u.IsAbs() || u.Host == "" {
+		return nil
+	}
+
+	status, err := preflightRequest(ctx, http.MethodHead, baseURL, opts)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = preflightRequest(ctx, http.MethodOptions, baseURL, opts)
+	}
+
+	if err != nil {
+		err = describePreflightError(baseURL, err)
+		// Transport errors embed the request URL, including secret query parameters
+		if len(opts.QueryParams) > 0 {
+			return errors.New(opts.redact(err.Error()))
+		}
+		return err
+	}
+
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return fmt.Errorf("preflight: %s rejected the configured credentials (status %d), check the --headers flag", baseURL, status)
+	case status >= 500 && status != http.StatusNotImplemented:
+		return fmt.Errorf("preflight: %s is unhealthy (status %d)", baseURL, status)
+	}
+
+	return nil
+}
+
+func preflightRequest(ctx context.Context, method, baseURL string, opts *ToolOptions) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, baseURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	addSecretQueryParams(req.URL, opts.QueryParams)
+
+	for key := range opts.Headers {
+		req.Header.Set(key, opts.Headers.Get(key))
+	}
+
+	if err := applyHeaderTemplates(req, nil, opts.HeaderTemplates); err != nil {
+		return 0, err
+	}
+
+	if opts.Signer != nil {
+		if err := opts.Signer.Sign(req, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	resp, err := sendAuthenticated(ctx, req, opts.Auth, opts)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// describePreflightError turns transport errors into actionable messages
+func describePreflightError(baseURL string, err error) error {
+	var dnsErr *net.DNSError
+	var certErr *x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return fmt.Errorf("preflight: cannot resolve host %s, check the base URL in the spec: %w", dnsErr.Name, err)
+	case errors.As(err, &certErr), errors.As(err, &hostnameErr):
+		return fmt.Errorf("preflight: TLS verification failed for %s: %w", baseURL, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("preflight: %s did not respond in time: %w", baseURL, err)
+	case strings.Contains(err.Error(), "connection refused"):
+		return fmt.Errorf("preflight: connection to %s was refused, is the API running?: %w", baseURL, err)
+	default:
+		return fmt.Errorf("preflight: cannot reach %s: %w", baseURL, err)
+	}
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       http.HandlerFunc
+		expectedError string
+	}{
+		{
+			name: "reachable API",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		{
+			name: "falls back to OPTIONS",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			name: "credentials rejected",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			expectedError: "rejected the configured credentials",
+		},
+		{
+			name: "unhealthy API",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			expectedError: "unhealthy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			err := Preflight(context.Background(), server.URL, nil)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestPreflightSkipsRelativeBaseURL(t *testing.T) {
+	if err := Preflight(context.Background(), "/api/v3", nil); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPreflightSendsHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	opts := &ToolOptions{Headers: http.Header{"Authorization": {"Bearer token"}}}
+	if err := Preflight(context.Background(), server.URL, opts); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPreflightUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	err := Preflight(context.Background(), baseURL, nil)
+	if err == nil || !strings.Contains(err.Error(), "refused") {
+		t.Errorf("Expected connection refused error, got %v", err)
+	}
+}
+
+func TestPreflightSkipsHostVariables(t *testing.T) {
+	if err := Preflight(context.Background(), "https://{tenant}.invalid", nil); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+// sensitiveHeaderRegex matches header names whose values are masked in previews
+var sensitiveHeaderRegex = regexp.MustCompile(`(?i)auth|token|secret|key|signature|cookie|session|password`)
+
+// FindTool returns the named tool of the spec, prepared with opts exactly like
+// a served tool
+func FindTool(spec openapi.APISpec, name string, opts *ToolOptions) (*EnrichedTool, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tool := range tools {
+		renameTool(tool, opts)
+		if tool.Name == name {
+			if err := prepareTool(tool, opts); err != nil {
+				return nil, err
+			}
+			return tool, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown tool: %s", name)
+}
+
+// PrepareTools returns the tools of the spec selected by opts, prepared
+// exactly like served tools and sorted by name
+func PrepareTools(spec openapi.APISpec, opts *ToolOptions) ([]*EnrichedTool, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var prepared []*EnrichedTool
+	for _, tool := range tools {
+		if !opts.Filter.Match(tool) {
+			continue
+		}
+		renameTool(tool, opts)
+		if err := prepareTool(tool, opts); err != nil {
+			return nil, err
+		}
+		prepared = append(prepared, tool)
+	}
+
+	sort.Slice(prepared, func(i, j int) bool { return prepared[i].Name < prepared[j].Name })
+	return prepared, nil
+}
+
+// BuildRequest builds the request a call of tool with input sends upstream,
+// without sending it
+func BuildRequest(ctx context.Context, tool *EnrichedTool, input APIToolInput, opts *ToolOptions) (*http.Request, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+	return buildHTTPRequest(ctx, tool, nestDottedFields(tool.InputSchema, input), opts)
+}
+
+// CallTool runs a call of tool with input exactly like a served tool would,
+// validating the input first, and returns its output
+func CallTool(ctx context.Context, tool *EnrichedTool, input APIToolInput, opts *ToolOptions) (APIToolOutput, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+	_, output, err := createAPIHandlerForTool(tool, opts)(ctx, nil, input)
+	return output, err
+}
+
+// PreviewRequest renders a request as method and URL, sorted headers and the
+// indented JSON body. Secret query parameters and the values of headers that
+// look like credentials are masked.
+func PreviewRequest(req *http.Request, opts *ToolOptions) (string, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	var preview strings.Builder
+	fmt.Fprintf(&preview, "%s %s\n", req.Method, opts.redact(req.URL.String()))
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if sensitiveHeaderRegex.MatchString(name) {
+				value = redactedValue
+			}
+			fmt.Fprintf(&preview, "%s: %s\n", name, opts.redact(value))
+		}
+	}
+
+	if req.GetBody == nil {
+		return preview.String(), nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) > 0 {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			indented.Reset()
+			indented.Write(data)
+		}
+		fmt.Fprintf(&preview, "\n%s\n", opts.redact(indented.String()))
+	}
+
+	return preview.String(), nil
+}
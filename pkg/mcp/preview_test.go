@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestPreviewRequest(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "https://api.example.com/v1"}],
+		"paths": {
+			"/users": {
+				"post": {
+					"operationId": "createUser",
+					"parameters": [{"name": "notify", "in": "query", "schema": {"type": "boolean"}}],
+					"requestBody": {"content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	opts := &ToolOptions{
+		Headers:     http.Header{"Authorization": {"Bearer s3cr3t"}, "X-Team": {"billing"}},
+		QueryParams: url.Values{"api_key": {"k3y"}},
+	}
+
+	tool, err := FindTool(spec, "createUser", opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req, err := BuildRequest(context.Background(), tool, APIToolInput{"name": "Alice", "notify": true}, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	preview, err := PreviewRequest(req, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `POST https://api.example.com/v1/users?api_key=[REDACTED]&notify=true
+Authorization: [REDACTED]
+Content-Type: application/json
+X-Team: billing
+
+{
+  "name": "Alice"
+}
+`
+	if preview != expected {
+		t.Errorf("Expected preview:\n%s\ngot:\n%s", expected, preview)
+	}
+
+	if _, err := FindTool(spec, "deleteUser", opts); err == nil {
+		t.Error("Expected error for unknown tool")
+	}
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultProgressInterval is how often a slow call reports progress
+const defaultProgressInterval = 2 * time.Second
+
+// Call states reported in progress notifications
+const (
+	progressSending = "sending request"
+	progressWaiting = "waiting for response"
+	progressReading = "reading response"
+)
+
+type progressKey struct{}
+
+// callProgress tracks the state of a tool call for progress notifications
+type callProgress struct {
+	state atomic.Value
+}
+
+// setProgressState records the state of the call running under ctx, if its
+// progress is reported
+func setProgressState(ctx context.Context, state string) {
+	if progress, ok := ctx.Value(progressKey{}).(*callProgress); ok {
+		progress.state.Store(state)
+	}
+}
+
+// reportProgress wraps a handler to send progress notifications with the
+// elapsed seconds and state of the call while it runs, when the client asked
+// for them with a progress token
+func reportProgress(handler apiToolHandler, opts *ToolOptions) apiToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		if req == nil || req.Session == nil || req.Params == nil || req.Params.GetProgressToken() == nil {
+			return handler(ctx, req, input)
+		}
+
+		interval := opts.ProgressInterval
+		if interval <= 0 {
+			interval = defaultProgressInterval
+		}
+
+		progress := &callProgress{}
+		progress.state.Store(progressSending)
+		ctx = context.WithValue(ctx, progressKey{}, progress)
+
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+
+			start := time.Now()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					elapsed := time.Since(start)
+					err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+						ProgressToken: req.Params.GetProgressToken(),
+						Progress:      elapsed.Seconds(),
+						Message:       fmt.Sprintf("%s (%s elapsed)", progress.state.Load(), elapsed.Round(time.Second)),
+					})
+					if err != nil {
+						opts.logger().Warn("progress notification failed", "error", err)
+					}
+				}
+			}
+		}()
+
+		result, output, err := handler(ctx, req, input)
+		close(done)
+		<-stopped
+		return result, output, err
+	}
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestProgressNotifications(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(120 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer mockServer.Close()
+
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "` + mockServer.URL + `"}],
+		"paths": {"/report": {"get": {"operationId": "getReport", "responses": {"200": {"description": "OK"}}}}}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	if err := GenerateToolsFromSpec(server, spec, &ToolOptions{ProgressInterval: 20 * time.Millisecond}); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	var mu sync.Mutex
+	var notifications []*mcp.ProgressNotificationParams
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0.0.1"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			notifications = append(notifications, req.Params)
+			mu.Unlock()
+		},
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer session.Close()
+
+	params := &mcp.CallToolParams{Name: "getReport", Meta: mcp.Meta{"progressToken": "call-1"}}
+	if _, err := session.CallTool(ctx, params); err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	// Notifications are delivered asynchronously
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(notifications)
+		mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(notifications) == 0 {
+		t.Fatalf("Expected progress notifications during the slow call")
+	}
+	first := notifications[0]
+	if first.ProgressToken != "call-1" || first.Progress <= 0 {
+		t.Errorf("Unexpected notification: %+v", first)
+	}
+	if !strings.Contains(first.Message, "waiting for response") {
+		t.Errorf("Expected the call state in the message, got %q", first.Message)
+	}
+}
@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// promptNameRegex matches the characters replaced in prompt names
+var promptNameRegex = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// tagPromptName returns the name of the prompt of a tag, e.g. user_accounts
+// for "User Accounts"
+func tagPromptName(prefix, tag string) string {
+	name := strings.Trim(promptNameRegex.ReplaceAllString(strings.ToLower(tag), "_"), "_")
+	return prefix + name
+}
+
+// AddTagPrompts registers a prompt for every tag of the spec that explains
+// its tools and the sequences they are typically called in, following the
+// links between operations. Prompt names are prefixed with prefix. It returns
+// the names of the prompts, sorted.
+func AddTagPrompts(server *mcp.Server, prefix string, spec openapi.APISpec, opts *ToolOptions) ([]string, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// Links refer to operations by operationId, the model to tools by name
+	toolNames := make(map[string]string)
+	byTag := make(map[string][]*EnrichedTool)
+	for _, tool := range tools {
+		if !opts.Filter.Match(tool) {
+			continue
+		}
+		renameTool(tool, opts)
+		if id := tool.Operation.GetOperationID(); id != "" {
+			toolNames[id] = tool.Name
+		}
+		for _, tag := range tool.Operation.GetTags() {
+			byTag[tag] = append(byTag[tag], tool)
+		}
+	}
+
+	descriptions := make(map[string]string)
+	for _, tag := range spec.GetTags() {
+		descriptions[tag.Name] = tag.Description
+	}
+
+	names := make([]string, 0, len(byTag))
+	for tag, tagTools := range byTag {
+		sort.Slice(tagTools, func(i, j int) bool { return tagTools[i].Name < tagTools[j].Name })
+		text := tagPromptText(spec.GetInfo().Title, tag, descriptions[tag], tagTools, toolNames)
+
+		description := descriptions[tag]
+		if description == "" {
+			description = fmt.Sprintf("Work with the %s operations of the API", tag)
+		}
+
+		name := tagPromptName(prefix, tag)
+		names = append(names, name)
+		server.AddPrompt(&mcp.Prompt{
+			Name:        name,
+			Title:       tag,
+			Description: description,
+			Arguments: []*mcp.PromptArgument{
+				{Name: "goal", Description: "What you want to achieve with these operations"},
+			},
+		}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			message := text
+			if goal := req.Params.Arguments["goal"]; goal != "" {
+				message += "\nGoal: " + goal + "\n"
+			}
+			return &mcp.GetPromptResult{
+				Description: description,
+				Messages: []*mcp.PromptMessage{
+					{Role: "user", Content: &mcp.TextContent{Text: message}},
+				},
+			}, nil
+		})
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// tagPromptText explains the tools of a tag and the calls that can follow them
+func tagPromptText(title, tag, description string, tools []*EnrichedTool, toolNames map[string]string) string {
+	var b strings.Builder
+	if title != "" {
+		fmt.Fprintf(&b, "You are working with the %q operations of the %s API.\n", tag, title)
+	} else {
+		fmt.Fprintf(&b, "You are working with the %q operations of the API.\n", tag)
+	}
+	if description != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(description))
+	}
+
+	b.WriteString("\nAvailable tools:\n")
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "- %s (%s %s)", tool.Name, strings.ToUpper(tool.Method), tool.Path)
+		if summary := tool.Operation.GetSummary(); summary != "" {
+			fmt.Fprintf(&b, ": %s", summary)
+		}
+		if details := tool.Operation.GetDescription(); details != "" {
+			fmt.Fprintf(&b, ". %s", strings.TrimSpace(details))
+		}
+		b.WriteString("\n")
+	}
+
+	var sequences []string
+	for _, tool := range tools {
+		for _, link := range tool.Operation.GetLinks() {
+			next, ok := toolNames[link.OperationID]
+			if !ok {
+				continue
+			}
+
+			sequence := fmt.Sprintf("- After %s, call %s", tool.Name, next)
+			if len(link.Parameters) > 0 {
+				names := make([]string, 0, len(link.Parameters))
+				for name := range link.Parameters {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				mappings := make([]string, len(names))
+				for i, name := range names {
+					mappings[i] = fmt.Sprintf("%s from %v", name, link.Parameters[name])
+				}
+				sequence += " with " + strings.Join(mappings, ", ")
+			}
+			if link.Description != "" {
+				sequence += ": " + link.Description
+			}
+			sequences = append(sequences, sequence)
+		}
+	}
+
+	if len(sequences) > 0 {
+		b.WriteString("\nTypical call sequences:\n")
+		b.WriteString(strings.Join(sequences, "\n"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTagPrompts(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Pets", "version": "1.0.0"},
+		"tags": [{"name": "Pet Store", "description": "Adopt and manage pets"}],
+		"paths": {
+			"/pets": {
+				"post": {
+					"operationId": "createPet",
+					"summary": "Create a pet",
+					"tags": ["Pet Store"],
+					"responses": {"201": {
+						"description": "Created",
+						"links": {"GetPet": {"operationId": "getPet", "parameters": {"id": "$response.body#/id"}, "description": "Fetch the new pet"}}
+					}}
+				}
+			},
+			"/pets/{id}": {
+				"get": {
+					"operationId": "getPet",
+					"summary": "Get a pet",
+					"description": "Includes the adoption status.",
+					"tags": ["Pet Store"],
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			},
+			"/health": {
+				"get": {"operationId": "health", "responses": {"200": {"description": "OK"}}}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	names, err := AddTagPrompts(server, "", spec, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(names) != 1 || names[0] != "pet_store" {
+		t.Errorf("Expected the pet_store prompt, got %v", names)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0.0.1"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer session.Close()
+
+	prompts, err := session.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to list prompts: %v", err)
+	}
+	if len(prompts.Prompts) != 1 || prompts.Prompts[0].Name != "pet_store" {
+		t.Fatalf("Expected a single pet_store prompt, got %+v", prompts.Prompts)
+	}
+	if prompts.Prompts[0].Description != "Adopt and manage pets" {
+		t.Errorf("Expected the tag description, got %q", prompts.Prompts[0].Description)
+	}
+
+	result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: "pet_store", Arguments: map[string]string{"goal": "adopt a cat"}})
+	if err != nil {
+		t.Fatalf("Failed to get prompt: %v", err)
+	}
+
+	text := result.Messages[0].Content.(*mcp.TextContent).Text
+	for _, expected := range []string{
+		"Adopt and manage pets",
+		"- createPet (POST /pets): Create a pet",
+		"- getPet (GET /pets/{id}): Get a pet. Includes the adoption status.",
+		"- After createPet, call getPet with id from $response.body#/id: Fetch the new pet",
+		"Goal: adopt a cat",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in prompt:\n%s", expected, text)
+		}
+	}
+}
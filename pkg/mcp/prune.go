@@ -0,0 +1,59 @@
+package mcp
+
+import "github.com/google/jsonschema-go/jsonschema"
+
+// SchemaPruning trims generated input schemas, trading schema richness for a
+// smaller tools/list payload on large APIs
+type SchemaPruning struct {
+	// StripDescriptions removes the descriptions of inputs, the tool keeps its own
+	StripDescriptions bool
+	// StripExamples removes input examples
+	StripExamples bool
+	// MaxDepth drops the nested fields of inputs deeper than this, where the
+	// tool's inputs are at depth 1. Zero keeps every level.
+	MaxDepth int
+}
+
+// prune trims the input schema of a tool in place
+func (p *SchemaPruning) prune(schema *jsonschema.Schema) {
+	if p == nil || schema == nil {
+		return
+	}
+	p.pruneAt(schema, 0)
+}
+
+func (p *SchemaPruning) pruneAt(schema *jsonschema.Schema, depth int) {
+	if schema == nil {
+		return
+	}
+
+	if p.StripDescriptions {
+		schema.Description = ""
+		schema.Title = ""
+	}
+	if p.StripExamples {
+		schema.Examples = nil
+	}
+
+	// Inputs at the maximum depth are kept, but only as free-form values
+	if p.MaxDepth > 0 && depth >= p.MaxDepth {
+		schema.Properties = nil
+		schema.Required = nil
+		schema.Items = nil
+		schema.AdditionalProperties = nil
+		schema.AnyOf, schema.OneOf, schema.AllOf = nil, nil, nil
+		return
+	}
+
+	for _, property := range schema.Properties {
+		p.pruneAt(property, depth+1)
+	}
+	p.pruneAt(schema.Items, depth+1)
+	p.pruneAt(schema.AdditionalProperties, depth+1)
+	for _, subschemas := range [][]*jsonschema.Schema{schema.AnyOf, schema.OneOf, schema.AllOf} {
+		// Alternatives describe the same value, so they don't add a level
+		for _, subschema := range subschemas {
+			p.pruneAt(subschema, depth)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestSchemaPruning(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/users": {
+				"post": {
+					"operationId": "createUser",
+					"requestBody": {
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "object",
+									"properties": {
+										"name": {"type": "string", "description": "Full name", "example": "Alice"},
+										"address": {
+											"type": "object",
+											"description": "Postal address",
+											"properties": {
+												"city": {"type": "string", "description": "City"}
+											}
+										}
+									}
+								}
+							}
+						}
+					},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	generate := func(pruning *SchemaPruning) *EnrichedTool {
+		tools, err := GetToolsFromSpec(spec)
+		if err != nil {
+			t.Fatalf("Failed to generate tools: %v", err)
+		}
+		prepareTool(tools[0], &ToolOptions{Pruning: pruning})
+		return tools[0]
+	}
+
+	tool := generate(nil)
+	name := tool.InputSchema.Properties["name"]
+	if name.Description != "Full name" || len(name.Examples) != 1 {
+		t.Fatalf("Expected description and example without pruning, got %+v", name)
+	}
+	if _, ok := tool.InputSchema.Properties["address"].Properties["city"]; !ok {
+		t.Fatal("Expected nested fields without pruning")
+	}
+
+	tool = generate(&SchemaPruning{StripDescriptions: true, StripExamples: true, MaxDepth: 1})
+	name = tool.InputSchema.Properties["name"]
+	if name.Description != "" || name.Examples != nil {
+		t.Errorf("Expected description and example to be stripped, got %+v", name)
+	}
+	address := tool.InputSchema.Properties["address"]
+	if address == nil || address.Type != "object" {
+		t.Fatalf("Expected inputs at the maximum depth to be kept, got %+v", address)
+	}
+	if address.Properties != nil {
+		t.Errorf("Expected nested fields past the maximum depth to be dropped, got %v", address.Properties)
+	}
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// styleDelimiters joins the values of delimited query styles
+var styleDelimiters = map[string]string{
+	"form":           ",",
+	"spaceDelimited": " ",
+	"pipeDelimited":  "|",
+	"tabDelimited":   "\t",
+}
+
+// addStyledQueryParam adds a query parameter to query, serializing arrays and
+// objects according to the OpenAPI style and explode of the parameter
+func addStyledQueryParam(query url.Values, name string, value interface{}, style string, explode bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, queryValue(item))
+		}
+		if explode {
+			query[name] = append(query[name], values...)
+			return
+		}
+		query.Set(name, strings.Join(values, delimiterFor(style)))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		switch {
+		case style == "deepObject":
+			for _, key := range keys {
+				addDeepObjectParam(query, name+"["+key+"]", v[key])
+			}
+		case explode:
+			for _, key := range keys {
+				query.Add(key, queryValue(v[key]))
+			}
+		default:
+			pairs := make([]string, 0, 2*len(keys))
+			for _, key := range keys {
+				pairs = append(pairs, key, queryValue(v[key]))
+			}
+			query.Set(name, strings.Join(pairs, delimiterFor(style)))
+		}
+	default:
+		query.Set(name, queryValue(value))
+	}
+}
+
+// arrayValue coerces the input of an array parameter into a list. Clients
+// sometimes send the list JSON encoded in a string, or a single element.
+func arrayValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "[") {
+			var list []interface{}
+			if err := json.Unmarshal([]byte(v), &list); err == nil {
+				return list
+			}
+		}
+	}
+	return []interface{}{value}
+}
+
+// addDeepObjectParam adds the nested fields of a deepObject value as
+// name[key][nested]=value
+func addDeepObjectParam(query url.Values, name string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			addDeepObjectParam(query, name+"["+key+"]", item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			query.Add(name, queryValue(item))
+		}
+	default:
+		query.Add(name, queryValue(value))
+	}
+}
+
+// delimiterFor returns the delimiter of a style, falling back to a comma
+func delimiterFor(style string) string {
+	if delimiter, ok := styleDelimiters[style]; ok {
+		return delimiter
+	}
+	return ","
+}
+
+// queryValue formats a scalar for the query string. Numbers never use
+// exponents and nested structures are sent as JSON.
+func queryValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestAddStyledQueryParam(t *testing.T) {
+	tags := []interface{}{"a", "b"}
+	color := map[string]interface{}{"R": float64(100), "G": float64(200)}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		style    string
+		explode  bool
+		expected string
+	}{
+		{name: "scalar number", value: float64(1000000), style: "form", explode: true, expected: "id=1000000"},
+		{name: "form exploded array", value: tags, style: "form", explode: true, expected: "id=a&id=b"},
+		{name: "form array", value: tags, style: "form", expected: "id=a%2Cb"},
+		{name: "space delimited array", value: tags, style: "spaceDelimited", expected: "id=a+b"},
+		{name: "pipe delimited array", value: tags, style: "pipeDelimited", expected: "id=a%7Cb"},
+		{name: "form exploded object", value: color, style: "form", explode: true, expected: "G=200&R=100"},
+		{name: "form object", value: color, style: "form", expected: "id=G%2C200%2CR%2C100"},
+		{name: "deep object", value: color, style: "deepObject", explode: true, expected: "id%5BG%5D=200&id%5BR%5D=100"},
+		{
+			name:     "nested deep object",
+			value:    map[string]interface{}{"filter": map[string]interface{}{"status": "open"}},
+			style:    "deepObject",
+			explode:  true,
+			expected: "id%5Bfilter%5D%5Bstatus%5D=open",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{}
+			addStyledQueryParam(query, "id", tt.value, tt.style, tt.explode)
+			if got := query.Encode(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestArrayQueryParams(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"swagger": "2.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"host": "api.example.com",
+		"paths": {
+			"/items": {
+				"get": {
+					"operationId": "listItems",
+					"parameters": [
+						{"name": "id", "in": "query", "type": "array", "items": {"type": "integer"}, "collectionFormat": "multi"},
+						{"name": "tag", "in": "query", "type": "array", "items": {"type": "string"}}
+					],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tool, err := FindTool(spec, "listItems", nil)
+	if err != nil {
+		t.Fatalf("Failed to find tool: %v", err)
+	}
+
+	if items := tool.InputSchema.Properties["id"].Items; items == nil || items.Type != "integer" {
+		t.Errorf("Expected integer items in the input schema, got %+v", items)
+	}
+
+	tests := []struct {
+		name     string
+		input    APIToolInput
+		expected string
+	}{
+		{name: "repeated", input: APIToolInput{"id": []interface{}{float64(1), float64(2)}}, expected: "id=1&id=2"},
+		{name: "comma joined", input: APIToolInput{"tag": []interface{}{"a", "b"}}, expected: "tag=a%2Cb"},
+		{name: "JSON encoded list", input: APIToolInput{"id": "[1, 2]"}, expected: "id=1&id=2"},
+		{name: "single element", input: APIToolInput{"tag": "a"}, expected: "tag=a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := BuildRequest(context.Background(), tool, tt.input, nil)
+			if err != nil {
+				t.Fatalf("Failed to build request: %v", err)
+			}
+			if req.URL.RawQuery != tt.expected {
+				t.Errorf("Expected query %s, got %s", tt.expected, req.URL.RawQuery)
+			}
+		})
+	}
+}
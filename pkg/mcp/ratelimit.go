@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that refills at a fixed rate and allows bursts
+// of up to one second worth of requests
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rate requests per second
+func NewRateLimiter(rate float64) *RateLimiter {
+	burst := math.Max(1, math.Floor(rate))
+	return &RateLimiter{
+		rate:   rate,
+		burst:  burst,
+		now:    time.Now,
+		tokens: burst,
+	}
+}
+
+// Wait blocks until a request may proceed or the context is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise it returns how long to
+// wait until the next token is due
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// HostRateLimiter keeps a separate token bucket for every upstream host
+type HostRateLimiter struct {
+	rate float64
+
+	mu       sync.Mutex
+	limiters map[string]*RateLimiter
+}
+
+// NewHostRateLimiter creates a limiter allowing rate requests per second to each host
+func NewHostRateLimiter(rate float64) *HostRateLimiter {
+	return &HostRateLimiter{
+		rate:     rate,
+		limiters: make(map[string]*RateLimiter),
+	}
+}
+
+// Wait blocks until a request to host may proceed or the context is done
+func (h *HostRateLimiter) Wait(ctx context.Context, host string) error {
+	h.mu.Lock()
+	limiter, ok := h.limiters[host]
+	if !ok {
+		limiter = NewRateLimiter(h.rate)
+		h.limiters[host] = limiter
+	}
+	h.mu.Unlock()
+
+	return limiter.Wait(ctx)
+}
+
+// ParseRate parses a rate such as "10/s", "100/m" or "1000/h" into requests
+// per second. A bare number is interpreted as requests per second.
+func ParseRate(value string) (float64, error) {
+	count, unit, found := strings.Cut(strings.TrimSpace(value), "/")
+	n, err := strconv.ParseFloat(strings.TrimSpace(count), 64)
+	if err != nil || n <= 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid rate: %s (expected e.g. 10/s)", value)
+	}
+
+	if !found {
+		return n, nil
+	}
+
+	switch strings.TrimSpace(unit) {
+	case "s", "sec", "second":
+		return n, nil
+	case "m", "min", "minute":
+		return n / 60, nil
+	case "h", "hour":
+		return n / 3600, nil
+	default:
+		return 0, fmt.Errorf("invalid rate unit in %s (expected s, m or h)", value)
+	}
+}
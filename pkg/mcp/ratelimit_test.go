@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		hasError bool
+	}{
+		{input: "10/s", expected: 10},
+		{input: "10", expected: 10},
+		{input: "120/m", expected: 2},
+		{input: "3600/h", expected: 1},
+		{input: "0/s", hasError: true},
+		{input: "ten/s", hasError: true},
+		{input: "NaN/s", hasError: true},
+		{input: "Inf/s", hasError: true},
+		{input: "10/d", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rate, err := ParseRate(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if rate != tt.expected {
+				t.Errorf("ParseRate(%q) = %v, expected %v", tt.input, rate, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewRateLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	// The burst allows two immediate requests
+	for i := 0; i < 2; i++ {
+		if delay := limiter.reserve(); delay != 0 {
+			t.Fatalf("Request %d should not wait, got %v", i, delay)
+		}
+	}
+
+	if delay := limiter.reserve(); delay != 500*time.Millisecond {
+		t.Errorf("Expected 500ms delay, got %v", delay)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if delay := limiter.reserve(); delay != 0 {
+		t.Errorf("Expected token after refill, got delay %v", delay)
+	}
+}
+
+func TestRateLimiterWaitHonorsContext(t *testing.T) {
+	limiter := NewRateLimiter(0.001)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("First request should pass, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Expected context error while waiting")
+	}
+}
+
+func TestHostRateLimiterSeparatesHosts(t *testing.T) {
+	limiter := NewHostRateLimiter(0.001)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, "a.example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := limiter.Wait(ctx, "b.example.com"); err != nil {
+		t.Errorf("Other hosts should have their own bucket, got %v", err)
+	}
+	if err := limiter.Wait(ctx, "a.example.com"); err == nil {
+		t.Error("Expected second request to the same host to be throttled")
+	}
+}
@@ -0,0 +1,292 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolState is the runtime state kept for each registered tool
+type ToolState struct {
+	Calls      int64
+	Errors     int64
+	LastCalled time.Time
+	Disabled   bool
+}
+
+// SyncSummary lists the tools affected by a ToolRegistry.Sync, by tool name
+type SyncSummary struct {
+	Added     []string
+	Changed   []string
+	Removed   []string
+	Unchanged []string
+}
+
+// registeredTool is a tool known to the registry together with its state
+type registeredTool struct {
+	tool        *EnrichedTool
+	fingerprint string
+
+	mu    sync.Mutex
+	state ToolState
+}
+
+// ToolRegistry owns the tools registered on a server. Syncing it with a new
+// version of the spec only replaces the operations that changed, so unchanged
+// tools keep their usage stats, disable toggle and prepared input schema.
+type ToolRegistry struct {
+	server *mcp.Server
+	opts   *ToolOptions
+
+	mu     sync.Mutex
+	synced bool
+	tools  map[string]*registeredTool
+}
+
+// NewToolRegistry creates an empty registry adding tools to server
+func NewToolRegistry(server *mcp.Server, opts *ToolOptions) *ToolRegistry {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	return &ToolRegistry{
+		server: server,
+		opts:   opts,
+		tools:  make(map[string]*registeredTool),
+	}
+}
+
+// Sync registers the tools of spec, replacing changed operations, removing
+// the ones that no longer exist and leaving unchanged ones untouched
+func (r *ToolRegistry) Sync(spec openapi.APISpec) (SyncSummary, error) {
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		return SyncSummary{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var summary SyncSummary
+	next := make(map[string]*registeredTool, len(tools))
+
+	for _, tool := range tools {
+		if !r.opts.Filter.Match(tool) {
+			continue
+		}
+		renameTool(tool, r.opts)
+
+		key := operationKey(tool)
+		fingerprint, err := toolFingerprint(tool)
+		if err != nil {
+			return SyncSummary{}, err
+		}
+		if err := prepareTool(tool, r.opts); err != nil {
+			return SyncSummary{}, err
+		}
+
+		existing, ok := r.tools[key]
+		if ok && existing.fingerprint == fingerprint {
+			next[key] = existing
+			summary.Unchanged = append(summary.Unchanged, existing.tool.Name)
+			continue
+		}
+
+		if ok {
+			// The old version may have been registered under another name
+			r.server.RemoveTools(existing.tool.Name)
+			summary.Changed = append(summary.Changed, tool.Name)
+		} else {
+			summary.Added = append(summary.Added, tool.Name)
+		}
+
+		if tool.CollidingName != "" {
+			r.opts.logger().Warn("tool name shared by several operations, renamed the tool",
+				"name", tool.CollidingName, "tool", tool.Name, "operation", openapi.OperationLocation(tool.Method, tool.Path))
+		}
+
+		registered := &registeredTool{tool: tool, fingerprint: fingerprint}
+		r.addTool(registered)
+		next[key] = registered
+	}
+
+	for key, existing := range r.tools {
+		if _, ok := next[key]; !ok {
+			r.server.RemoveTools(existing.tool.Name)
+			summary.Removed = append(summary.Removed, existing.tool.Name)
+		}
+	}
+
+	r.tools = next
+
+	for _, names := range [][]string{summary.Added, summary.Changed, summary.Removed, summary.Unchanged} {
+		sort.Strings(names)
+	}
+
+	if !r.synced {
+		r.synced = true
+		r.opts.logger().Info("registered tools", "count", len(next))
+	} else {
+		r.opts.logger().Info("refreshed tools", "added", len(summary.Added), "changed", len(summary.Changed),
+			"removed", len(summary.Removed), "unchanged", len(summary.Unchanged))
+	}
+
+	return summary, nil
+}
+
+// State returns the runtime state of the named tool
+func (r *ToolRegistry) State(name string) (ToolState, bool) {
+	registered := r.lookup(name)
+	if registered == nil {
+		return ToolState{}, false
+	}
+
+	registered.mu.Lock()
+	defer registered.mu.Unlock()
+	return registered.state, true
+}
+
+// SetDisabled hides the named tool from clients, or registers it again
+func (r *ToolRegistry) SetDisabled(name string, disabled bool) error {
+	registered := r.lookup(name)
+	if registered == nil {
+		return fmt.Errorf("unknown tool: %s", name)
+	}
+
+	registered.mu.Lock()
+	changed := registered.state.Disabled != disabled
+	registered.state.Disabled = disabled
+	registered.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	if disabled {
+		r.server.RemoveTools(name)
+	} else {
+		r.addTool(registered)
+	}
+	return nil
+}
+
+func (r *ToolRegistry) lookup(name string) *registeredTool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, registered := range r.tools {
+		if registered.tool.Name == name {
+			return registered
+		}
+	}
+	return nil
+}
+
+// prepareTool adjusts a freshly generated tool to the options before it is
+// first used
+func prepareTool(tool *EnrichedTool, opts *ToolOptions) error {
+	// Servers of the operation or its path take precedence over the spec's
+	if opts.BaseURL != "" && (tool.Operation == nil || len(tool.Operation.GetServers()) == 0) {
+		tool.BaseUrl = opts.BaseURL
+	}
+
+	// Let the client choose the declared host variables of the base URL
+	if err := addHostVariablesToSchema(tool.InputSchema, hostVariablesFor(tool.BaseUrl, opts.HostVariables)); err != nil {
+		return fmt.Errorf("tool %s: %w", tool.Name, err)
+	}
+	removeSecretInputs(tool.InputSchema, opts.QueryParams)
+	if opts.FlattenBody {
+		flattenBodyInputs(tool.InputSchema)
+	}
+
+	if param := cursorParam(tool); opts.PageCursors != nil && param != "" {
+		addNextPageToSchema(tool.InputSchema, param)
+	}
+
+	opts.Pruning.prune(tool.InputSchema)
+	return nil
+}
+
+// addTool registers the handler of a tool on the server unless it is disabled
+func (r *ToolRegistry) addTool(registered *registeredTool) {
+	registered.mu.Lock()
+	disabled := registered.state.Disabled
+	registered.mu.Unlock()
+
+	if disabled {
+		return
+	}
+
+	// Create the handler function for this specific operation
+	tool := registered.tool
+	handler := createAPIHandlerForTool(tool, r.opts)
+	if r.opts.Transcript != nil {
+		handler = recordTranscript(tool.Name, handler, r.opts.Transcript, r.opts)
+	}
+	addValidatedTool(r.server, tool.Tool, registered.recordUsage(handler))
+	r.opts.logger().Debug("registered tool", "tool", tool.Name, "method", strings.ToUpper(tool.Method), "path", tool.Path)
+}
+
+// recordUsage wraps a handler to keep the tool's usage stats
+func (t *registeredTool) recordUsage(handler apiToolHandler) apiToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		result, output, err := handler(ctx, req, input)
+
+		t.mu.Lock()
+		t.state.Calls++
+		t.state.LastCalled = time.Now()
+		if err != nil || output.Error != "" || output.StatusCode >= 400 {
+			t.state.Errors++
+		}
+		t.mu.Unlock()
+
+		return result, output, err
+	}
+}
+
+// operationKey identifies an operation across versions of a spec
+func operationKey(tool *EnrichedTool) string {
+	if operationID := tool.Operation.GetOperationID(); operationID != "" {
+		return operationID
+	}
+	return strings.ToUpper(tool.Method) + " " + tool.Path
+}
+
+// toolFingerprint hashes everything that affects how a tool is presented or
+// called, so any change to the operation replaces the tool
+func toolFingerprint(tool *EnrichedTool) (string, error) {
+	type parameter struct {
+		Name string
+		In   string
+	}
+
+	var parameters []parameter
+	for _, param := range tool.Operation.GetParameters() {
+		parameters = append(parameters, parameter{Name: param.GetName(), In: param.GetIn()})
+	}
+
+	data, err := json.Marshal(struct {
+		Name        string
+		Description string
+		BaseURL     string
+		Method      string
+		Path        string
+		Parameters  []parameter
+		InputSchema interface{}
+	}{tool.Name, tool.Description, tool.BaseUrl, tool.Method, tool.Path, parameters, tool.InputSchema})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint tool %s: %w", tool.Name, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolRegistrySyncPreservesState(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer mockServer.Close()
+
+	loadSpec := func(paths string) openapi.APISpec {
+		spec, err := openapi.LoadSpec([]byte(`{
+			"openapi": "3.0.0",
+			"info": {"title": "Test", "version": "1.0.0"},
+			"servers": [{"url": "` + mockServer.URL + `"}],
+			"paths": {` + paths + `}
+		}`))
+		if err != nil {
+			t.Fatalf("Failed to load spec: %v", err)
+		}
+		return spec
+	}
+
+	listUsers := `"/users": {"get": {"operationId": "listUsers", "responses": {"200": {"description": "OK"}}}}`
+	getUser := `"/users/{id}": {"get": {"operationId": "getUser", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "OK"}}}}`
+	getUserChanged := `"/users/{id}": {"get": {"operationId": "getUser", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}], "responses": {"200": {"description": "OK"}}}}`
+	listOrders := `"/orders": {"get": {"operationId": "listOrders", "responses": {"200": {"description": "OK"}}}}`
+	deleteUser := `"/users/{id}/delete": {"post": {"operationId": "deleteUser", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "OK"}}}}`
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	registry := NewToolRegistry(server, nil)
+
+	summary, err := registry.Sync(loadSpec(listUsers + "," + getUser + "," + deleteUser))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(summary.Added, []string{"deleteUser", "getUser", "listUsers"}) {
+		t.Errorf("Unexpected initial summary: %+v", summary)
+	}
+
+	// Call listUsers through a client session to record usage
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0.0.1"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer session.Close()
+
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "listUsers"}); err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	if err := registry.SetDisabled("getUser", true); err != nil {
+		t.Fatalf("Failed to disable tool: %v", err)
+	}
+
+	summary, err = registry.Sync(loadSpec(listUsers + "," + getUserChanged + "," + listOrders))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := SyncSummary{
+		Added:     []string{"listOrders"},
+		Changed:   []string{"getUser"},
+		Removed:   []string{"deleteUser"},
+		Unchanged: []string{"listUsers"},
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Expected summary %+v, got %+v", expected, summary)
+	}
+
+	if state, _ := registry.State("listUsers"); state.Calls != 1 {
+		t.Errorf("Expected usage of unchanged tool to be kept, got %+v", state)
+	}
+	if state, _ := registry.State("getUser"); state.Disabled {
+		t.Error("Expected changed tool to start with fresh state")
+	}
+	if _, ok := registry.State("deleteUser"); ok {
+		t.Error("Expected removed tool to be forgotten")
+	}
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"getUser", "listOrders", "listUsers"}) {
+		t.Errorf("Unexpected tools on server: %v", names)
+	}
+}
+
+func TestToolRegistrySetDisabled(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {"/users": {"get": {"operationId": "listUsers", "responses": {"200": {"description": "OK"}}}}}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	registry := NewToolRegistry(mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil), nil)
+	if _, err := registry.Sync(spec); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := registry.SetDisabled("listUsers", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state, _ := registry.State("listUsers"); !state.Disabled {
+		t.Error("Expected tool to be disabled")
+	}
+
+	// Unchanged tools stay disabled across a sync
+	if _, err := registry.Sync(spec); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state, _ := registry.State("listUsers"); !state.Disabled {
+		t.Error("Expected disable toggle to survive a sync")
+	}
+
+	if err := registry.SetDisabled("missing", true); err == nil {
+		t.Error("Expected error for unknown tool")
+	}
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// specResourceURI returns the URI of one of the resources of a spec
+func specResourceURI(name, resource string) string {
+	return fmt.Sprintf("openapi://%s/%s", name, resource)
+}
+
+// AddSpecResources exposes the spec as MCP resources, so the model can read
+// endpoint documentation on demand: the full document as JSON, and a Markdown
+// summary of the operations behind the tools
+func AddSpecResources(server *mcp.Server, name string, spec openapi.APISpec, opts *ToolOptions) error {
+	document, err := openapi.MarshalJSON(spec)
+	if err != nil {
+		return err
+	}
+
+	summary, err := SummarizeSpec(spec, opts)
+	if err != nil {
+		return err
+	}
+
+	title := spec.GetInfo().Title
+	if title == "" {
+		title = name
+	}
+
+	addTextResource(server, &mcp.Resource{
+		URI:         specResourceURI(name, "spec.json"),
+		Name:        name + "-spec",
+		Title:       title + " OpenAPI document",
+		Description: "The full OpenAPI document the tools are generated from",
+		MIMEType:    "application/json",
+	}, string(document))
+
+	addTextResource(server, &mcp.Resource{
+		URI:         specResourceURI(name, "summary.md"),
+		Name:        name + "-summary",
+		Title:       title + " API summary",
+		Description: "The operations behind the tools with their parameters, shorter than the full document",
+		MIMEType:    "text/markdown",
+	}, summary)
+
+	return nil
+}
+
+// addTextResource registers a resource with fixed text content
+func addTextResource(server *mcp.Server, resource *mcp.Resource, text string) {
+	server.AddResource(resource, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: resource.URI, MIMEType: resource.MIMEType, Text: text}},
+		}, nil
+	})
+}
+
+// SummarizeSpec describes the API and the operations exposed as tools in
+// Markdown, listing every tool with its method, path and parameters
+func SummarizeSpec(spec openapi.APISpec, opts *ToolOptions) (string, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	info := spec.GetInfo()
+	fmt.Fprintf(&b, "# %s", info.Title)
+	if info.Version != "" {
+		fmt.Fprintf(&b, " (%s)", info.Version)
+	}
+	b.WriteString("\n")
+	if info.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(info.Description))
+	}
+	if baseURL := spec.GetBaseURL(); baseURL != "" {
+		fmt.Fprintf(&b, "\nBase URL: %s\n", baseURL)
+	}
+
+	var exposed []*EnrichedTool
+	for _, tool := range tools {
+		if opts.Filter.Match(tool) {
+			renameTool(tool, opts)
+			exposed = append(exposed, tool)
+		}
+	}
+	sort.Slice(exposed, func(i, j int) bool { return exposed[i].Name < exposed[j].Name })
+
+	b.WriteString("\n## Tools\n")
+	for _, tool := range exposed {
+		fmt.Fprintf(&b, "\n### %s\n\n`%s %s`\n\n%s\n", tool.Name, strings.ToUpper(tool.Method), tool.Path, tool.Description)
+		writeParameterSummary(&b, tool)
+	}
+
+	return b.String(), nil
+}
+
+// writeParameterSummary lists the inputs of a tool, one line each
+func writeParameterSummary(b *strings.Builder, tool *EnrichedTool) {
+	var lines []string
+	for _, param := range tool.Operation.GetParameters() {
+		if param.GetIn() == "body" {
+			continue
+		}
+		line := fmt.Sprintf("- `%s` (%s, %s", param.GetName(), param.GetIn(), param.GetType())
+		if param.IsRequired() {
+			line += ", required"
+		}
+		line += ")"
+		if description := param.GetDescription(); description != "" {
+			line += ": " + description
+		}
+		lines = append(lines, line)
+	}
+
+	if requestBody := tool.Operation.GetRequestBody(); requestBody != nil {
+		if schema, err := requestBody.GetJSONSchema(); err == nil && schema != nil {
+			required := make(map[string]bool)
+			for _, name := range schema.GetRequired() {
+				required[name] = true
+			}
+
+			properties := schema.GetProperties()
+			names := make([]string, 0, len(properties))
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				line := fmt.Sprintf("- `%s` (body, %s", name, properties[name].GetType())
+				if required[name] {
+					line += ", required"
+				}
+				line += ")"
+				if description := properties[name].GetDescription(); description != "" {
+					line += ": " + description
+				}
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\nParameters:\n%s\n", strings.Join(lines, "\n"))
+}
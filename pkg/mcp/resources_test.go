@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSpecResources(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Pets", "version": "1.0.0", "description": "Manage pets"},
+		"servers": [{"url": "https://api.example.com"}],
+		"paths": {
+			"/pets/{id}": {
+				"get": {
+					"operationId": "getPet",
+					"summary": "Get a pet",
+					"parameters": [{"name": "id", "in": "path", "required": true, "description": "Pet ID", "schema": {"type": "string"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			},
+			"/pets": {
+				"post": {
+					"operationId": "createPet",
+					"requestBody": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"required": ["name"],
+						"properties": {"name": {"type": "string", "description": "Pet name"}}
+					}}}},
+					"responses": {"201": {"description": "Created"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	opts := &ToolOptions{ToolNames: map[string]string{"createPet": "pets_createPet"}}
+	if err := AddSpecResources(server, "pets", spec, opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0.0.1"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer session.Close()
+
+	resources, err := session.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to list resources: %v", err)
+	}
+	if len(resources.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(resources.Resources))
+	}
+
+	document, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "openapi://pets/spec.json"})
+	if err != nil {
+		t.Fatalf("Failed to read spec: %v", err)
+	}
+	if text := document.Contents[0].Text; !strings.Contains(text, `"operationId":"createPet"`) {
+		t.Errorf("Expected the full document, got %s", text)
+	}
+
+	summary, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "openapi://pets/summary.md"})
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+
+	text := summary.Contents[0].Text
+	for _, expected := range []string{
+		"# Pets (1.0.0)",
+		"Base URL: https://api.example.com",
+		"### getPet\n\n`GET /pets/{id}`\n\nGet a pet",
+		"- `id` (path, string, required): Pet ID",
+		"### pets_createPet\n\n`POST /pets`",
+		"- `name` (body, string, required): Pet name",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in summary:\n%s", expected, text)
+		}
+	}
+}
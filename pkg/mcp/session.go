@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SessionConfig configures a SessionAuthenticator
+type SessionConfig struct {
+	// Login is the operation establishing a session
+	Login *EnrichedTool
+	// Credentials is the input the login operation is called with
+	Credentials APIToolInput
+	// CookieName is the session cookie, empty keeps every cookie the login sets
+	CookieName string
+}
+
+// SessionAuthenticator maintains a cookie based session. It logs in through
+// an operation of the spec, sends the session cookie with every request and
+// logs in again once the cookie expires or the API rejects it.
+type SessionAuthenticator struct {
+	cfg  SessionConfig
+	opts *ToolOptions
+	now  func() time.Time
+
+	mu      sync.Mutex
+	cookies []*http.Cookie
+}
+
+// NewSessionAuthenticator creates an authenticator sending its login request
+// with the headers, base URL and HTTP client of opts
+func NewSessionAuthenticator(cfg SessionConfig, opts *ToolOptions) (*SessionAuthenticator, error) {
+	if cfg.Login == nil {
+		return nil, fmt.Errorf("session login operation must not be empty")
+	}
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	return &SessionAuthenticator{cfg: cfg, opts: opts, now: time.Now}, nil
+}
+
+// Login establishes a new session
+func (a *SessionAuthenticator) Login(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.login(ctx)
+}
+
+// Authenticate adds the session cookies, logging in when there is no valid
+// session
+func (a *SessionAuthenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.valid() {
+		if err := a.login(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, cookie := range a.cookies {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return nil
+}
+
+// Invalidate discards the session
+func (a *SessionAuthenticator) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cookies = nil
+}
+
+// valid reports whether a session exists and none of its cookies expired,
+// the caller must hold a.mu
+func (a *SessionAuthenticator) valid() bool {
+	if len(a.cookies) == 0 {
+		return false
+	}
+	for _, cookie := range a.cookies {
+		if !cookie.Expires.IsZero() && !a.now().Before(cookie.Expires) {
+			return false
+		}
+	}
+	return true
+}
+
+// login calls the login operation and keeps the session cookies it sets, the
+// caller must hold a.mu
+func (a *SessionAuthenticator) login(ctx context.Context) error {
+	req, err := buildHTTPRequest(ctx, a.cfg.Login, a.cfg.Credentials, a.opts)
+	if err != nil {
+		return fmt.Errorf("session login: %w", err)
+	}
+
+	resp, err := a.opts.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("session login: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("session login: %s rejected the credentials (status %d)", a.cfg.Login.Name, resp.StatusCode)
+	}
+
+	var cookies []*http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if a.cfg.CookieName != "" && cookie.Name != a.cfg.CookieName {
+			continue
+		}
+		// Max-Age takes precedence over Expires
+		if cookie.MaxAge > 0 {
+			cookie.Expires = a.now().Add(time.Duration(cookie.MaxAge) * time.Second)
+		}
+		cookies = append(cookies, cookie)
+	}
+
+	if len(cookies) == 0 {
+		if a.cfg.CookieName != "" {
+			return fmt.Errorf("session login: %s did not set the session cookie %s", a.cfg.Login.Name, a.cfg.CookieName)
+		}
+		return fmt.Errorf("session login: %s did not set a session cookie", a.cfg.Login.Name)
+	}
+
+	a.cookies = cookies
+	a.opts.logger().Info("logged in", "tool", a.cfg.Login.Name)
+	return nil
+}
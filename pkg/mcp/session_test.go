@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSessionAuthenticatorLogsInAgainAfterUnauthorized(t *testing.T) {
+	var logins, calls atomic.Int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			var credentials map[string]string
+			json.NewDecoder(r.Body).Decode(&credentials)
+			if credentials["username"] != "alice" || credentials["password"] != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			n := logins.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "tracking", Value: "ignored"})
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: fmt.Sprintf("session-%d", n)})
+		case "/users":
+			calls.Add(1)
+			// The first session expires on the server before its first use
+			cookie, err := r.Cookie("sid")
+			if err != nil || cookie.Value != "session-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if _, err := r.Cookie("tracking"); err == nil {
+				t.Error("Expected only the session cookie to be sent")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok": true}`))
+		}
+	}))
+	defer apiServer.Close()
+
+	bodySchema := openapi3.NewObjectSchema().
+		WithProperty("username", openapi3.NewStringSchema()).
+		WithProperty("password", openapi3.NewStringSchema())
+	login := &EnrichedTool{
+		Tool:    &mcp.Tool{Name: "login"},
+		BaseUrl: apiServer.URL,
+		Method:  "post",
+		Path:    "/login",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(bodySchema)},
+		}},
+	}
+
+	opts := &ToolOptions{}
+	session, err := NewSessionAuthenticator(SessionConfig{
+		Login:       login,
+		Credentials: APIToolInput{"username": "alice", "password": "secret"},
+		CookieName:  "sid",
+	}, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	opts.Auth = session
+
+	if err := session.Login(context.Background()); err != nil {
+		t.Fatalf("Unexpected login error: %v", err)
+	}
+
+	tool := &EnrichedTool{
+		Tool:      &mcp.Tool{Name: "listUsers"},
+		BaseUrl:   apiServer.URL,
+		Method:    "get",
+		Path:      "/users",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{}},
+	}
+
+	handler := createAPIHandlerForTool(tool, opts)
+	_, output, err := handler(context.Background(), nil, APIToolInput{})
+	if err != nil || output.StatusCode != http.StatusOK {
+		t.Fatalf("Expected retry with a new session to succeed, got %v %+v", err, output)
+	}
+
+	if logins.Load() != 2 || calls.Load() != 2 {
+		t.Errorf("Expected 2 logins and 2 calls, got %d and %d", logins.Load(), calls.Load())
+	}
+}
+
+func TestSessionAuthenticatorLoginErrors(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("user") != "alice" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer apiServer.Close()
+
+	login := &EnrichedTool{
+		Tool:    &mcp.Tool{Name: "login"},
+		BaseUrl: apiServer.URL,
+		Method:  "post",
+		Path:    "/login",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{
+			Parameters: openapi3.Parameters{{Value: openapi3.NewQueryParameter("user")}},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		credentials APIToolInput
+		expected    string
+	}{
+		{
+			name:        "rejected credentials",
+			credentials: APIToolInput{"user": "mallory"},
+			expected:    "session login: login rejected the credentials (status 403)",
+		},
+		{
+			name:        "missing session cookie",
+			credentials: APIToolInput{"user": "alice"},
+			expected:    "session login: login did not set the session cookie sid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := NewSessionAuthenticator(SessionConfig{Login: login, Credentials: tt.credentials, CookieName: "sid"}, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			err = session.Login(context.Background())
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
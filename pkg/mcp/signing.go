@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CanonicalTimestampBody signs "<timestamp>.<body>"
+	CanonicalTimestampBody = "timestamp-body"
+	// CanonicalRequest signs the method, path, sorted query string, timestamp
+	// and body, each on its own line
+	CanonicalRequest = "request"
+)
+
+// RequestSigner signs a request once its URL, headers and body are final
+type RequestSigner interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// HMACSignerConfig configures an HMACSigner
+type HMACSignerConfig struct {
+	// Secret is the shared HMAC key
+	Secret []byte
+	// Algorithm is sha1, sha256 or sha512, sha256 when empty
+	Algorithm string
+	// SignatureHeader carries the signature, X-Signature when empty
+	SignatureHeader string
+	// TimestampHeader carries the signed Unix timestamp, X-Timestamp when empty
+	TimestampHeader string
+	// Canonicalization selects what is signed, CanonicalTimestampBody when empty
+	Canonicalization string
+	// Encoding of the signature, hex or base64, hex when empty
+	Encoding string
+}
+
+// HMACSigner signs requests with a shared secret, as required by many internal
+// and partner APIs
+type HMACSigner struct {
+	secret           []byte
+	hash             func() hash.Hash
+	signatureHeader  string
+	timestampHeader  string
+	canonicalization string
+	encode           func([]byte) string
+	now              func() time.Time
+}
+
+// hmacAlgorithms are the hash functions an HMACSigner supports
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// hmacEncodings are the signature encodings an HMACSigner supports
+var hmacEncodings = map[string]func([]byte) string{
+	"hex":    hex.EncodeToString,
+	"base64": base64.StdEncoding.EncodeToString,
+}
+
+// NewHMACSigner creates a signer from the config, applying its defaults
+func NewHMACSigner(cfg HMACSignerConfig) (*HMACSigner, error) {
+	if len(cfg.Secret) == 0 {
+		return nil, fmt.Errorf("HMAC signing secret must not be empty")
+	}
+
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	hashFunc, ok := hmacAlgorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported HMAC algorithm: %s (expected sha1, sha256 or sha512)", algorithm)
+	}
+
+	encoding := cfg.Encoding
+	if encoding == "" {
+		encoding = "hex"
+	}
+	encode, ok := hmacEncodings[strings.ToLower(encoding)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported HMAC encoding: %s (expected hex or base64)", encoding)
+	}
+
+	canonicalization := cfg.Canonicalization
+	switch canonicalization {
+	case "":
+		canonicalization = CanonicalTimestampBody
+	case CanonicalTimestampBody, CanonicalRequest:
+	default:
+		return nil, fmt.Errorf("unsupported HMAC canonicalization: %s (expected %s or %s)", canonicalization, CanonicalTimestampBody, CanonicalRequest)
+	}
+
+	signer := &HMACSigner{
+		secret:           cfg.Secret,
+		hash:             hashFunc,
+		signatureHeader:  cfg.SignatureHeader,
+		timestampHeader:  cfg.TimestampHeader,
+		canonicalization: canonicalization,
+		encode:           encode,
+		now:              time.Now,
+	}
+	if signer.signatureHeader == "" {
+		signer.signatureHeader = "X-Signature"
+	}
+	if signer.timestampHeader == "" {
+		signer.timestampHeader = "X-Timestamp"
+	}
+
+	return signer, nil
+}
+
+// Sign sets the timestamp and signature headers of the request
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+
+	mac := hmac.New(s.hash, s.secret)
+	mac.Write(s.canonicalize(req, timestamp, body))
+
+	req.Header.Set(s.timestampHeader, timestamp)
+	req.Header.Set(s.signatureHeader, s.encode(mac.Sum(nil)))
+	return nil
+}
+
+// canonicalize returns the bytes covered by the signature
+func (s *HMACSigner) canonicalize(req *http.Request, timestamp string, body []byte) []byte {
+	if s.canonicalization == CanonicalRequest {
+		// Encode sorts the query by key, so parameter order doesn't matter
+		lines := []string{
+			strings.ToUpper(req.Method),
+			req.URL.EscapedPath(),
+			req.URL.Query().Encode(),
+			timestamp,
+			string(body),
+		}
+		return []byte(strings.Join(lines, "\n"))
+	}
+
+	return []byte(timestamp + "." + string(body))
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHMACSignerSignsBody(t *testing.T) {
+	var verified bool
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(r.Header.Get("X-Timestamp") + "." + string(body)))
+		verified = r.Header.Get("X-Timestamp") == "1700000000" &&
+			hmac.Equal([]byte(r.Header.Get("X-Signature")), []byte(hex.EncodeToString(mac.Sum(nil))))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer mockServer.Close()
+
+	signer, err := NewHMACSigner(HMACSignerConfig{Secret: []byte("secret")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	bodySchema := openapi3.NewObjectSchema().WithProperty("amount", openapi3.NewIntegerSchema())
+	tool := &EnrichedTool{
+		Tool:    &mcp.Tool{Name: "createPayment"},
+		BaseUrl: mockServer.URL,
+		Method:  "post",
+		Path:    "/payments",
+		Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(bodySchema)},
+		}},
+	}
+
+	handler := createAPIHandlerForTool(tool, &ToolOptions{Signer: signer})
+	_, output, err := handler(context.Background(), nil, APIToolInput{"amount": 10})
+	if err != nil || output.Error != "" {
+		t.Fatalf("Unexpected error: %v %s", err, output.Error)
+	}
+
+	if !verified {
+		t.Error("Expected the upstream to verify the HMAC signature")
+	}
+}
+
+func TestHMACSignerCanonicalRequest(t *testing.T) {
+	signer, err := NewHMACSigner(HMACSignerConfig{
+		Secret:           []byte("secret"),
+		Algorithm:        "sha512",
+		SignatureHeader:  "X-Partner-Signature",
+		Canonicalization: CanonicalRequest,
+		Encoding:         "base64",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	sign := func(target string) string {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		if err := signer.Sign(req, []byte(`{"amount":10}`)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return req.Header.Get("X-Partner-Signature")
+	}
+
+	mac := hmac.New(sha512.New, []byte("secret"))
+	mac.Write([]byte("POST\n/v1/payments\na=1&b=2\n1700000000\n{\"amount\":10}"))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if signature := sign("https://api.example.com/v1/payments?b=2&a=1"); signature != expected {
+		t.Errorf("Expected signature %s, got %s", expected, signature)
+	}
+
+	if sign("https://api.example.com/v1/refunds?a=1&b=2") == expected {
+		t.Error("Expected the path to be covered by the signature")
+	}
+}
+
+func TestNewHMACSignerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  HMACSignerConfig
+	}{
+		{name: "empty secret", cfg: HMACSignerConfig{}},
+		{name: "unknown algorithm", cfg: HMACSignerConfig{Secret: []byte("secret"), Algorithm: "md5"}},
+		{name: "unknown encoding", cfg: HMACSignerConfig{Secret: []byte("secret"), Encoding: "base32"}},
+		{name: "unknown canonicalization", cfg: HMACSignerConfig{Secret: []byte("secret"), Canonicalization: "body"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewHMACSigner(tt.cfg); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
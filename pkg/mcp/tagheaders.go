@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"net/http"
+)
+
+// tagHeadersFor returns the headers configured for the tags of a tool. When
+// several tags set the same header the tag listed first by the operation wins.
+func tagHeadersFor(tool *EnrichedTool, tagHeaders map[string]http.Header) http.Header {
+	if len(tagHeaders) == 0 {
+		return nil
+	}
+
+	headers := make(http.Header)
+	for _, tag := range tool.Operation.GetTags() {
+		for key, values := range tagHeaders[tag] {
+			if _, ok := headers[key]; !ok {
+				headers[key] = values
+			}
+		}
+	}
+	return headers
+}
+
+// applyTagHeaders replaces the headers of a request with the ones configured
+// for the tags of its tool
+func applyTagHeaders(req *http.Request, tool *EnrichedTool, tagHeaders map[string]http.Header) {
+	for key, values := range tagHeadersFor(tool, tagHeaders) {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+}
+
+// authenticatorFor returns the authenticator used for a tool. Operations
+// needing no credentials and tags carrying their own Authorization header opt
+// out of the session-wide credentials.
+func (o *ToolOptions) authenticatorFor(tool *EnrichedTool) Authenticator {
+	if tool.anonymous() || tagHeadersFor(tool, o.TagHeaders).Get("Authorization") != "" {
+		return nil
+	}
+	return o.Auth
+}
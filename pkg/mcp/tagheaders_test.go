@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTagHeaders(t *testing.T) {
+	newTool := func(tags ...string) *EnrichedTool {
+		return &EnrichedTool{
+			Tool:      &mcp.Tool{Name: "tool"},
+			BaseUrl:   "https://api.example.com",
+			Method:    "get",
+			Path:      "/users",
+			Operation: &openapi.OpenAPI3Operation{Op: &openapi3.Operation{Tags: tags}},
+		}
+	}
+
+	auth := &BearerAuthenticator{}
+	opts := &ToolOptions{
+		Headers: http.Header{"Authorization": {"Bearer user-token"}, "X-Team": {"default"}},
+		Auth:    auth,
+		TagHeaders: map[string]http.Header{
+			"admin":   {"Authorization": {"Bearer admin-token"}},
+			"reports": {"X-Team": {"analytics"}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		tags          []string
+		authorization string
+		team          string
+		usesAuth      bool
+	}{
+		{name: "untagged", authorization: "Bearer user-token", team: "default", usesAuth: true},
+		{name: "tag header", tags: []string{"reports"}, authorization: "Bearer user-token", team: "analytics", usesAuth: true},
+		{name: "tag authorization", tags: []string{"admin", "reports"}, authorization: "Bearer admin-token", team: "analytics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := newTool(tt.tags...)
+			req, err := buildHTTPRequest(context.Background(), tool, APIToolInput{}, opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := req.Header.Values("Authorization"); len(got) != 1 || got[0] != tt.authorization {
+				t.Errorf("Expected Authorization %q, got %v", tt.authorization, got)
+			}
+			if got := req.Header.Get("X-Team"); got != tt.team {
+				t.Errorf("Expected X-Team %q, got %q", tt.team, got)
+			}
+			if usesAuth := opts.authenticatorFor(tool) != nil; usesAuth != tt.usesAuth {
+				t.Errorf("Expected authenticator use %v, got %v", tt.usesAuth, usesAuth)
+			}
+		})
+	}
+}
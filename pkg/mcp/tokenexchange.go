@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenExchangeGrantType is the grant type of RFC 8693 token exchange
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// AccessTokenType identifies an OAuth2 access token in a token exchange
+	AccessTokenType = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// TokenExchangeConfig configures a TokenExchangeAuthenticator
+type TokenExchangeConfig struct {
+	// TokenURL is the token endpoint of the security token service
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	// SubjectToken is the credential exchanged for audience-scoped tokens. It
+	// is configured rather than taken from the MCP client: over stdio the
+	// client's requests carry no credential to exchange.
+	SubjectToken string
+	// SubjectTokenFile holds the subject token instead of SubjectToken. It is
+	// read for every exchange, so rotated tokens are picked up.
+	SubjectTokenFile string
+	// SubjectTokenType defaults to AccessTokenType
+	SubjectTokenType string
+	// Audiences maps upstream hosts to the audience requested for them. Other
+	// hosts request their origin, e.g. https://api.example.com.
+	Audiences map[string]string
+}
+
+// exchangedToken is a token issued for one audience
+type exchangedToken struct {
+	accessToken string
+	expiry      time.Time
+}
+
+// TokenExchangeAuthenticator exchanges a subject token at a security token
+// service for a token scoped to the audience of each upstream host, following
+// RFC 8693. Tokens are cached per audience until they expire.
+type TokenExchangeAuthenticator struct {
+	cfg    TokenExchangeConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]exchangedToken
+}
+
+// NewTokenExchangeAuthenticator creates an authenticator exchanging tokens
+// with client, nil uses http.DefaultClient
+func NewTokenExchangeAuthenticator(cfg TokenExchangeConfig, client *http.Client) (*TokenExchangeAuthenticator, error) {
+	if cfg.TokenURL == "" {
+		return nil, fmt.Errorf("token exchange URL must not be empty")
+	}
+	if cfg.SubjectToken == "" && cfg.SubjectTokenFile == "" {
+		return nil, fmt.Errorf("token exchange requires a subject token")
+	}
+	if cfg.SubjectTokenType == "" {
+		cfg.SubjectTokenType = AccessTokenType
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &TokenExchangeAuthenticator{
+		cfg:    cfg,
+		client: client,
+		now:    time.Now,
+		tokens: make(map[string]exchangedToken),
+	}, nil
+}
+
+// Authenticate sets the Authorization header to a token for the audience of
+// the request's host
+func (a *TokenExchangeAuthenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.token(ctx, a.audienceFor(req.URL))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate discards the tokens of every audience
+func (a *TokenExchangeAuthenticator) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tokens = make(map[string]exchangedToken)
+}
+
+// InvalidateRequest discards the token of the audience of the request's host,
+// keeping those of other audiences
+func (a *TokenExchangeAuthenticator) InvalidateRequest(req *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.tokens, a.audienceFor(req.URL))
+}
+
+// audienceFor returns the audience requested for an upstream URL
+func (a *TokenExchangeAuthenticator) audienceFor(u *url.URL) string {
+	if audience, ok := a.cfg.Audiences[u.Host]; ok {
+		return audience
+	}
+	if audience, ok := a.cfg.Audiences[u.Hostname()]; ok {
+		return audience
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// token returns the cached token of an audience, exchanging the subject token
+// for a new one when there is none or it is about to expire
+func (a *TokenExchangeAuthenticator) token(ctx context.Context, audience string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if token, ok := a.tokens[audience]; ok && (token.expiry.IsZero() || a.now().Add(tokenExpiryLeeway).Before(token.expiry)) {
+		return token.accessToken, nil
+	}
+
+	subjectToken, err := a.subjectToken()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", tokenExchangeGrantType)
+	form.Set("subject_token", subjectToken)
+	form.Set("subject_token_type", a.cfg.SubjectTokenType)
+	form.Set("requested_token_type", AccessTokenType)
+	form.Set("audience", audience)
+
+	response, err := requestToken(ctx, a.client, a.cfg.TokenURL, a.cfg.ClientID, a.cfg.ClientSecret, form)
+	if err != nil {
+		return "", fmt.Errorf("token exchange for %s: %w", audience, err)
+	}
+
+	token := exchangedToken{accessToken: response.AccessToken}
+	if response.ExpiresIn > 0 {
+		token.expiry = a.now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	a.tokens[audience] = token
+
+	return token.accessToken, nil
+}
+
+// subjectToken returns the credential to exchange
+func (a *TokenExchangeAuthenticator) subjectToken() (string, error) {
+	if a.cfg.SubjectTokenFile == "" {
+		return a.cfg.SubjectToken, nil
+	}
+
+	data, err := os.ReadFile(a.cfg.SubjectTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read subject token: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("subject token file %s is empty", a.cfg.SubjectTokenFile)
+	}
+	return token, nil
+}
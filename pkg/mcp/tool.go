@@ -11,4 +11,24 @@ type EnrichedTool struct {
 	Method    string
 	Path      string
 	Operation openapi.Operation
+	// Security is the operation's security requirements, inherited from the
+	// spec unless the operation declares its own
+	Security []openapi.SecurityRequirement
+	// CollidingName is the name the tool shared with other operations before
+	// it was disambiguated, empty when its generated name is unique
+	CollidingName string
+}
+
+// anonymous reports whether the operation declares that it needs no
+// credentials, in which case none are sent
+func (t *EnrichedTool) anonymous() bool {
+	if t.Security == nil {
+		return false
+	}
+	for _, requirement := range t.Security {
+		if len(requirement) > 0 {
+			return false
+		}
+	}
+	return true
 }
@@ -4,57 +4,198 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/kumolabai/kumoctl/pkg/openapi"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // APIToolInput represents the input for dynamically generated API tools
 type APIToolInput map[string]interface{}
 
-// APIToolOutput represents the output from API calls
-// TODO: Look into changing this to the actual response schema from the OpenAPI Spec
+// apiToolHandler is the typed handler registered for every generated tool
+type apiToolHandler = mcp.ToolHandlerFor[APIToolInput, APIToolOutput]
+
+// APIToolOutput represents the output from API calls. The output schema of a
+// tool types Body by the response schema of its operation.
 type APIToolOutput struct {
 	StatusCode int               `json:"status_code"`
 	Body       interface{}       `json:"body,omitempty"`
 	Headers    map[string]string `json:"headers,omitempty"`
 	Error      string            `json:"error,omitempty"`
+	Snippet    string            `json:"snippet,omitempty"`
+	FromCache  bool              `json:"from_cache,omitempty"`
+	// Partial is set when the body was cut off, Body then holds what arrived
+	Partial bool `json:"partial,omitempty"`
+	// Pages is the number of pages merged into Body when following pages
+	Pages int `json:"pages,omitempty"`
+	// MorePages is set when pages were left unfetched at the page cap
+	MorePages bool `json:"more_pages,omitempty"`
+	// ElapsedMS is how long a timed out call ran, in milliseconds
+	ElapsedMS int64 `json:"elapsed_ms,omitempty"`
 }
 
-func GenerateToolsFromSpec(server *mcp.Server, spec openapi.APISpec, additionalHeaders http.Header) error {
-	tools, err := GetToolsFromSpec(spec)
-	if err != nil {
-		return err
+// htmlSnippetLength is the maximum number of characters of an unexpected HTML
+// body echoed back to the client
+const htmlSnippetLength = 256
+
+// ToolOptions configures how generated tools execute their HTTP requests
+type ToolOptions struct {
+	// BaseURL replaces the base URL declared in the spec when set. Operations
+	// declaring their own servers keep them.
+	BaseURL string
+	// Headers are added to every request
+	Headers http.Header
+	// HeaderTemplates are rendered from each request and added to it
+	HeaderTemplates []*HeaderTemplate
+	// Auth adds credentials to every request and renews them on 401, nil
+	// disables it
+	Auth Authenticator
+	// Signer signs every request after its headers and body are final, nil
+	// disables signing
+	Signer RequestSigner
+	// Timeout bounds every tool call, zero disables the timeout
+	Timeout time.Duration
+	// OperationTimeouts overrides Timeout for individual tools, keyed by tool name
+	OperationTimeouts map[string]time.Duration
+	// Budget caps the number of requests made per day, nil means unlimited
+	Budget *RequestBudget
+	// RateLimit throttles requests across all tools, nil means unlimited
+	RateLimit *RateLimiter
+	// HostRateLimit throttles requests to each upstream host, nil means unlimited
+	HostRateLimit *HostRateLimiter
+	// HostVariables are base URL host placeholders filled from tool input, keyed by name
+	HostVariables map[string]*HostVariable
+	// HTTPClient is shared by all tools so connections are reused, nil uses http.DefaultClient
+	HTTPClient *http.Client
+	// Cache serves repeated GET calls from memory, nil disables caching
+	Cache *ResponseCache
+	// Logger receives diagnostics and a record of every HTTP call, it must
+	// never write to stdout when serving over stdio. Nil discards them.
+	Logger *slog.Logger
+	// Transcript records every tool call, nil disables recording
+	Transcript *Transcript
+	// QueryParams are added to the query string of every request. They carry
+	// secrets such as API keys, so they are hidden from tool input schemas and
+	// redacted from tool results.
+	QueryParams url.Values
+	// Filter selects the operations exposed as tools, nil exposes all
+	Filter *ToolFilter
+	// TagHeaders are sent with the operations of a tag, replacing Headers of
+	// the same name. An Authorization header replaces Auth as well.
+	TagHeaders map[string]http.Header
+	// PageCursors remembers next page cursors for the `_next_page` shorthand,
+	// nil disables it
+	PageCursors *PageCursors
+	// ToolNames renames generated tools, keyed by their generated name
+	ToolNames map[string]string
+	// NameCase normalizes tool names to NameCaseSnake or NameCaseCamel, empty
+	// keeps them as generated
+	NameCase string
+	// ToolPrefix is prepended to every tool name, after ToolNames, so the
+	// tools of several servers in one client don't collide
+	ToolPrefix string
+	// PrefixToolsets prepends the toolset of every tool to its name, before
+	// ToolPrefix, e.g. users_listUsers
+	PrefixToolsets bool
+	// FlattenBody gives body fields named like a parameter the parameter's
+	// input instead of their own, e.g. body.id, as before they were
+	// namespaced. The one input fills both.
+	FlattenBody bool
+	// Pruning trims generated input schemas, nil keeps them whole
+	Pruning *SchemaPruning
+	// FollowPages fetches up to this many pages of a paginated GET listing
+	// and merges their items into one result, zero disables it
+	FollowPages int
+	// ProgressInterval is how often calls report progress to clients that
+	// ask for it, zero uses defaultProgressInterval
+	ProgressInterval time.Duration
+	// DryRun makes every call return the request it would send, with
+	// credentials masked, instead of sending it
+	DryRun bool
+	// ConfirmMethods lists the HTTP methods, e.g. DELETE, whose calls the user
+	// must approve through MCP elicitation before they are sent
+	ConfirmMethods []string
+	// TracerProvider records a span for every tool call and propagates its
+	// trace context upstream, nil disables tracing
+	TracerProvider trace.TracerProvider
+}
+
+// timeoutFor returns the timeout that applies to the named tool
+func (o *ToolOptions) timeoutFor(toolName string) time.Duration {
+	if timeout, ok := o.OperationTimeouts[toolName]; ok {
+		return timeout
 	}
+	return o.Timeout
+}
 
-	for _, tool := range tools {
-		// Create the handler function for this specific operation
-		handler := createAPIHandlerForTool(tool, additionalHeaders)
-		mcp.AddTool(server, tool.Tool, handler)
+// logger returns the configured logger, or one discarding everything
+func (o *ToolOptions) logger() *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return discardLogger
+}
+
+// discardLogger is used when no logger is configured
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// httpClient returns the client used to call the upstream API
+func (o *ToolOptions) httpClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
 	}
+	return http.DefaultClient
+}
 
-	return nil
+// GenerateToolsFromSpec registers a tool for every operation of the spec
+func GenerateToolsFromSpec(server *mcp.Server, spec openapi.APISpec, opts *ToolOptions) error {
+	_, err := NewToolRegistry(server, opts).Sync(spec)
+	return err
 }
 
 func GetToolsFromSpec(spec openapi.APISpec) ([]*EnrichedTool, error) {
 	tools := []*EnrichedTool{}
 	baseURL := spec.GetBaseURL()
 
+	ignore, err := newIgnoreList(spec)
+	if err != nil {
+		return nil, err
+	}
+
 	for path, pathItem := range spec.GetPaths() {
 		for method, operation := range pathItem.GetOperations() {
 			if operation == nil {
 				continue
 			}
 
-			toolName := generateToolName(method, path, operation.GetOperationID())
-			description := operation.GetSummary()
-			if description == "" {
-				description = fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+			location := openapi.OperationLocation(method, path)
+			if ignored, err := ignore.ignored(method, path, pathItem, operation); err != nil {
+				return nil, fmt.Errorf("%s: %w", location, err)
+			} else if ignored {
+				continue
+			}
+			rawName, err := operationToolName(method, path, operation)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", location, err)
+			}
+			toolName := sanitizeToolName(rawName)
+
+			annotations, err := extensionAnnotationsFor(operation, toolAnnotations(method))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", location, err)
 			}
 
 			// Generate input schema for this tool
@@ -62,26 +203,188 @@ func GetToolsFromSpec(spec openapi.APISpec) ([]*EnrichedTool, error) {
 			if err != nil {
 				return nil, fmt.Errorf("failed to generate input schema for %s %s: %w", method, path, err)
 			}
+			description, err := toolDescription(method, path, operation, inputSchema)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", location, err)
+			}
+
+			// Tools without a usable response schema keep the generic output
+			var outputSchema *jsonschema.Schema
+			if bodySchema, err := openapi.GenerateOutputSchema(operation); err == nil && bodySchema != nil {
+				outputSchema = outputSchemaFor(bodySchema)
+			}
+
+			toolBaseURL, err := operationBaseURL(operation, baseURL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid servers for %s %s: %w", method, path, err)
+			}
 
 			tools = append(tools, &EnrichedTool{
 				Tool: &mcp.Tool{
-					Name:        toolName,
-					Description: description,
-					InputSchema: inputSchema,
+					Name:         toolName,
+					Description:  description,
+					InputSchema:  inputSchema,
+					OutputSchema: outputSchema,
+					Annotations:  annotations,
 				},
-				BaseUrl:   baseURL,
+				BaseUrl:   toolBaseURL,
 				Method:    method,
 				Path:      path,
 				Operation: operation,
+				Security:  openapi.EffectiveSecurity(spec, operation),
 			})
 
 		}
 	}
 
+	disambiguateToolNames(tools)
 	return tools, nil
 }
 
+// disambiguateToolNames renames the tools of operations sharing a name, from
+// duplicated operationIds or paths generating the same name, by suffixing
+// their method and path, e.g. getUser_get_v2_users_id. Tools are sorted by
+// path and method first, so the names are the same on every run.
+func disambiguateToolNames(tools []*EnrichedTool) {
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].Path != tools[j].Path {
+			return tools[i].Path < tools[j].Path
+		}
+		return tools[i].Method < tools[j].Method
+	})
+
+	counts := make(map[string]int, len(tools))
+	for _, tool := range tools {
+		counts[tool.Name]++
+	}
+
+	taken := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		if counts[tool.Name] == 1 {
+			taken[tool.Name] = true
+		}
+	}
+
+	for _, tool := range tools {
+		if counts[tool.Name] == 1 {
+			continue
+		}
+
+		name := rawToolName(tool.Method, tool.Path, "")
+		if tool.Name != sanitizeToolName(name) {
+			name = tool.Name + "_" + name
+		}
+		unique := sanitizeToolName(name)
+		for i := 2; taken[unique]; i++ {
+			unique = sanitizeToolName(fmt.Sprintf("%s_%d", name, i))
+		}
+		taken[unique] = true
+
+		tool.CollidingName = tool.Name
+		tool.Name = unique
+	}
+}
+
+// outputSchemaFor describes the tool output with its body typed by the
+// schema of the successful response. Error responses can have any body, so
+// the typed schema is offered as the first alternative rather than enforced.
+func outputSchemaFor(body *jsonschema.Schema) *jsonschema.Schema {
+	schema, err := jsonschema.For[APIToolOutput](&jsonschema.ForOptions{})
+	if err != nil {
+		return nil
+	}
+
+	schema.Properties["body"] = &jsonschema.Schema{
+		Description: "Response body, matching the first schema for successful responses",
+		AnyOf:       []*jsonschema.Schema{body, {}},
+	}
+	return schema
+}
+
+// toolAnnotations derives the behavior hints of a tool from the semantics of
+// its HTTP method, which clients use to decide when to ask for confirmation
+func toolAnnotations(method string) *mcp.ToolAnnotations {
+	openWorld := true
+	annotations := &mcp.ToolAnnotations{OpenWorldHint: &openWorld}
+
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		annotations.ReadOnlyHint = true
+	default:
+		destructive := strings.EqualFold(method, http.MethodDelete)
+		annotations.DestructiveHint = &destructive
+		annotations.IdempotentHint = strings.EqualFold(method, http.MethodPut) || destructive
+	}
+
+	return annotations
+}
+
+// enumHints lists the allowed values of the tool's enum inputs, so the model
+// sees them without digging through the input schema
+func enumHints(schema *jsonschema.Schema) string {
+	if schema == nil {
+		return ""
+	}
+
+	var hints []string
+	for name, property := range schema.Properties {
+		if property == nil {
+			continue
+		}
+		enum := property.Enum
+		if len(enum) == 0 && property.Items != nil {
+			enum = property.Items.Enum
+		}
+		if len(enum) == 0 {
+			continue
+		}
+
+		values := make([]string, len(enum))
+		for i, value := range enum {
+			values[i] = fmt.Sprint(value)
+			if value == nil {
+				values[i] = "null"
+			}
+		}
+		hints = append(hints, fmt.Sprintf("- %s: %s", name, strings.Join(values, ", ")))
+	}
+
+	if len(hints) == 0 {
+		return ""
+	}
+	sort.Strings(hints)
+	return "\n\nAllowed values:\n" + strings.Join(hints, "\n")
+}
+
+// operationBaseURL returns the first server declared by the operation or its
+// path with the variable defaults filled in, or the spec's base URL. Relative
+// server URLs are resolved against the spec's base URL.
+func operationBaseURL(operation openapi.Operation, specBaseURL string) (string, error) {
+	servers := operation.GetServers()
+	if len(servers) == 0 {
+		return specBaseURL, nil
+	}
+
+	baseURL, err := servers[0].ResolveURL(nil)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(baseURL, "/") {
+		scheme, host, _ := splitBaseURL(specBaseURL)
+		return scheme + host + baseURL, nil
+	}
+	return baseURL, nil
+}
+
+// generateToolName names the tool of an operation after its operationId, or
+// its method and path, in a form all MCP clients accept
 func generateToolName(method, path string, operationID string) string {
+	return sanitizeToolName(rawToolName(method, path, operationID))
+}
+
+// rawToolName names the tool of an operation before sanitizing
+func rawToolName(method, path string, operationID string) string {
 	if operationID != "" {
 		return operationID
 	}
@@ -107,8 +410,8 @@ func buildURL(baseURL, path string, input APIToolInput) (*url.URL, error) {
 
 	finalPath := pathParamRegex.ReplaceAllStringFunc(path, func(match string) string {
 		paramName := match[1 : len(match)-1] // Remove { and }
-		if value, exists := input[paramName]; exists {
-			return fmt.Sprintf("%v", value)
+		if value := input[paramName]; value != nil {
+			return escapePathValue(queryValue(value))
 		}
 		missingParams = append(missingParams, paramName)
 		return match // Keep original for error reporting
@@ -123,13 +426,27 @@ func buildURL(baseURL, path string, input APIToolInput) (*url.URL, error) {
 	return url.Parse(fullURLStr)
 }
 
+// escapePathValue percent-encodes a path parameter value so it stays within
+// its segment. Dot segments are encoded too, as they would otherwise walk up
+// the path.
+func escapePathValue(value string) string {
+	if value == "." || value == ".." {
+		return strings.ReplaceAll(value, ".", "%2E")
+	}
+	return url.PathEscape(value)
+}
+
 // addQueryParams adds query parameters to the URL
 func addQueryParams(fullURL *url.URL, operation openapi.Operation, input APIToolInput) error {
 	query := fullURL.Query()
 	for _, param := range operation.GetParameters() {
 		if param.GetIn() == "query" {
-			if value, exists := input[param.GetName()]; exists {
-				query.Set(param.GetName(), fmt.Sprintf("%v", value))
+			// An explicit null leaves the parameter out
+			if value := input[param.GetName()]; value != nil {
+				if param.GetType() == "array" {
+					value = arrayValue(value)
+				}
+				addStyledQueryParam(query, param.GetName(), value, param.GetStyle(), param.GetExplode())
 			}
 		}
 	}
@@ -142,8 +459,9 @@ func hasRequestBody(operation openapi.Operation) bool {
 	return operation.GetRequestBody() != nil
 }
 
-// buildRequestBody constructs the JSON request body
-func buildRequestBody(operation openapi.Operation, input APIToolInput) ([]byte, error) {
+// buildRequestBody constructs the JSON request body. Free-form bodies also
+// take the inputs the tool's input schema doesn't declare.
+func buildRequestBody(operation openapi.Operation, input APIToolInput, inputSchema *jsonschema.Schema) ([]byte, error) {
 	requestBody := operation.GetRequestBody()
 	if requestBody == nil {
 		return nil, nil
@@ -160,11 +478,74 @@ func buildRequestBody(operation openapi.Operation, input APIToolInput) ([]byte,
 
 	// Build request body from input based on schema
 	body := make(map[string]interface{})
-	extractFieldsFromSchema(body, schema, input)
+	extractFieldsFromSchema(body, schema, bodyInput(inputSchema, input))
+	extractAdditionalFields(body, schema, input, func(key string) bool {
+		if inputSchema == nil {
+			return false
+		}
+		_, declared := inputSchema.Properties[key]
+		return declared
+	})
 
 	return json.Marshal(body)
 }
 
+// bodyInput returns the input of the body fields, taking the namespaced inputs
+// of fields named like a parameter, e.g. body.id, in place of the parameter's
+func bodyInput(inputSchema *jsonschema.Schema, input APIToolInput) APIToolInput {
+	if inputSchema == nil {
+		return input
+	}
+
+	var fields APIToolInput
+	for key := range inputSchema.Properties {
+		name, ok := strings.CutPrefix(key, openapi.BodyFieldPrefix)
+		if !ok {
+			continue
+		}
+
+		if fields == nil {
+			fields = make(APIToolInput, len(input))
+			for k, v := range input {
+				fields[k] = v
+			}
+		}
+		delete(fields, name)
+		if value, exists := input[key]; exists {
+			fields[name] = value
+		}
+	}
+
+	if fields == nil {
+		return input
+	}
+	return fields
+}
+
+// flattenBodyInputs folds the namespaced inputs of body fields back into the
+// inputs of the parameters they are named like, whose schema they replace
+func flattenBodyInputs(schema *jsonschema.Schema) {
+	if schema == nil {
+		return
+	}
+
+	for key, property := range schema.Properties {
+		name, ok := strings.CutPrefix(key, openapi.BodyFieldPrefix)
+		if !ok {
+			continue
+		}
+		delete(schema.Properties, key)
+		schema.Properties[name] = property
+
+		if i := slices.Index(schema.Required, key); i >= 0 {
+			schema.Required = slices.Delete(schema.Required, i, i+1)
+			if !slices.Contains(schema.Required, name) {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+}
+
 // setHeaders sets HTTP headers based on operation parameters and defaults
 func setHeaders(req *http.Request, operation openapi.Operation, input APIToolInput, additionalHeaders http.Header) error {
 	// Set default content type for requests with body
@@ -175,7 +556,7 @@ func setHeaders(req *http.Request, operation openapi.Operation, input APIToolInp
 	// Add header parameters
 	for _, param := range operation.GetParameters() {
 		if param.GetIn() == "header" {
-			if value, exists := input[param.GetName()]; exists {
+			if value := input[param.GetName()]; value != nil {
 				req.Header.Set(param.GetName(), fmt.Sprintf("%v", value))
 			}
 		}
@@ -204,27 +585,71 @@ func parseResponse(resp *http.Response) (APIToolOutput, error) {
 
 	// Parse response body if present
 	if resp.Body != nil {
-		var body interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
-			// Accept any valid JSON: objects, arrays, or primitives
-			output.Body = body
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			// Keep what arrived, a truncated body is often still useful
+			if len(data) > 0 {
+				output.Partial = true
+				output.Body = string(data)
+				if body, parseErr := lookupResponseParser(resp.Header.Get("Content-Type"))(data); parseErr == nil {
+					output.Body = body
+				}
+			}
+			return output, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		// Login redirects and WAF blocks typically answer with an HTML page
+		// instead of JSON, surface that explicitly rather than an empty body
+		if isHTMLResponse(resp.Header.Get("Content-Type"), data) {
+			output.Error = fmt.Sprintf("received HTML instead of JSON (status %d), likely authentication required or the request was blocked", resp.StatusCode)
+			output.Snippet = htmlSnippet(data)
+			return output, nil
+		}
+
+		if len(data) > 0 {
+			parser := lookupResponseParser(resp.Header.Get("Content-Type"))
+			if body, err := parser(data); err == nil {
+				output.Body = body
+			}
 		}
 	}
 
 	return output, nil
 }
 
-// extractFieldsFromSchema recursively extracts fields from schema and input
+// isHTMLResponse reports whether a response is an HTML document, either by its
+// declared content type or by sniffing the start of the body
+func isHTMLResponse(contentType string, data []byte) bool {
+	if strings.Contains(strings.ToLower(contentType), "text/html") {
+		return true
+	}
+
+	trimmed := strings.ToLower(strings.TrimSpace(string(data[:min(len(data), 64)])))
+	return strings.HasPrefix(trimmed, "<!doctype html") || strings.HasPrefix(trimmed, "<html")
+}
+
+// htmlSnippet returns a whitespace-collapsed prefix of an HTML body
+func htmlSnippet(data []byte) string {
+	snippet := []rune(strings.Join(strings.Fields(string(data)), " "))
+	if len(snippet) > htmlSnippetLength {
+		return string(snippet[:htmlSnippetLength]) + "..."
+	}
+	return string(snippet)
+}
+
+// extractFieldsFromSchema recursively extracts fields from schema and input.
+// Nested objects of the input are built the same way, keeping the fields
+// their schema doesn't declare, so their defaults are filled too.
 func extractFieldsFromSchema(target map[string]interface{}, schema openapi.Schema, input APIToolInput) {
 	if schema == nil {
 		return
 	}
 
 	// Handle object properties
-	if schema.GetType() == "object" {
+	if isObjectSchema(schema) {
 		for propName, propSchema := range schema.GetProperties() {
 			if value, exists := input[propName]; exists {
-				target[propName] = value
+				target[propName] = bodyValue(propSchema, value)
 			} else if defaultVal := propSchema.GetDefault(); defaultVal != nil {
 				target[propName] = defaultVal
 			}
@@ -232,54 +657,387 @@ func extractFieldsFromSchema(target map[string]interface{}, schema openapi.Schem
 	}
 }
 
+// bodyValue builds the body value of a field from its input. Objects are
+// built like the body, and arrays item by item, also when they are given as a
+// JSON string or a single item.
+func bodyValue(schema openapi.Schema, value interface{}) interface{} {
+	if schema == nil || value == nil {
+		return value
+	}
+
+	if schema.GetType() == "array" {
+		items := arrayValue(value).([]interface{})
+		built := make([]interface{}, len(items))
+		for i, item := range items {
+			built[i] = bodyValue(schema.GetItems(), item)
+		}
+		return built
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok || !isObjectSchema(schema) {
+		return value
+	}
+	nested := make(map[string]interface{}, len(object))
+	for key, field := range object {
+		nested[key] = field
+	}
+	extractFieldsFromSchema(nested, schema, object)
+	extractAdditionalFields(nested, schema, object, nil)
+	return nested
+}
+
+// extractAdditionalFields copies the fields of input the schema doesn't
+// declare, except the skipped ones, when the schema allows them as in free-form
+// or typed maps. Values of typed maps are built by their schema.
+func extractAdditionalFields(target map[string]interface{}, schema openapi.Schema, input map[string]interface{}, skip func(key string) bool) {
+	additional, allowed := schema.GetAdditionalProperties()
+	if additional == nil && (allowed == nil || !*allowed) {
+		return
+	}
+
+	properties := schema.GetProperties()
+	for key, value := range input {
+		if _, declared := properties[key]; declared || (skip != nil && skip(key)) {
+			continue
+		}
+		target[key] = bodyValue(additional, value)
+	}
+}
+
+// isObjectSchema reports whether a schema describes an object, which item
+// schemas often only tell by their properties
+func isObjectSchema(schema openapi.Schema) bool {
+	return schema.GetType() == "object" || (schema.GetType() == "" && len(schema.GetProperties()) > 0)
+}
+
+// nestDottedFields folds inputs naming the fields of nested objects with dots,
+// such as address.city, into those objects, so models can give nested body
+// fields either way. Inputs the schema declares as they are, and fields also
+// given in their object, are left alone. The input itself isn't modified.
+func nestDottedFields(schema *jsonschema.Schema, input APIToolInput) APIToolInput {
+	if schema == nil {
+		return input
+	}
+
+	var nested APIToolInput
+	for key, value := range input {
+		if _, declared := schema.Properties[key]; declared || !strings.Contains(key, ".") {
+			continue
+		}
+
+		segments := strings.Split(key, ".")
+		if !isObjectPath(schema, segments[:len(segments)-1]) {
+			continue
+		}
+
+		if nested == nil {
+			nested = make(APIToolInput, len(input))
+			for k, v := range input {
+				nested[k] = v
+			}
+		}
+		if setNestedField(nested, segments, value) {
+			delete(nested, key)
+		}
+	}
+
+	if nested == nil {
+		return input
+	}
+	return nested
+}
+
+// isObjectPath reports whether the path names nested object properties of the
+// schema
+func isObjectPath(schema *jsonschema.Schema, path []string) bool {
+	for _, name := range path {
+		property, ok := schema.Properties[name]
+		if !ok || property == nil || !slices.Contains(schemaTypes(property), "object") {
+			return false
+		}
+		schema = property
+	}
+	return true
+}
+
+// setNestedField sets the field at the path in target, creating or copying
+// the objects on the way. It reports false when the field is already set or
+// the path crosses a value that isn't an object.
+func setNestedField(target map[string]interface{}, path []string, value interface{}) bool {
+	for _, name := range path[:len(path)-1] {
+		child, exists := target[name]
+		if !exists {
+			object := make(map[string]interface{})
+			target[name] = object
+			target = object
+			continue
+		}
+
+		object, ok := child.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		// Objects of the input belong to the caller
+		copied := make(map[string]interface{}, len(object)+1)
+		for k, v := range object {
+			copied[k] = v
+		}
+		target[name] = copied
+		target = copied
+	}
+
+	last := path[len(path)-1]
+	if _, exists := target[last]; exists {
+		return false
+	}
+	target[last] = value
+	return true
+}
+
+// buildHTTPRequest builds the upstream request a tool call would send
+func buildHTTPRequest(ctx context.Context, tool *EnrichedTool, input APIToolInput, opts *ToolOptions) (*http.Request, error) {
+	httpReq, body, err := newHTTPRequest(ctx, tool, input, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := signHTTPRequest(httpReq, body, opts); err != nil {
+		return nil, err
+	}
+	return httpReq, nil
+}
+
+// newHTTPRequest builds the upstream request of a tool call and returns its
+// body, without the templated headers and signature computed per call
+func newHTTPRequest(ctx context.Context, tool *EnrichedTool, input APIToolInput, opts *ToolOptions) (*http.Request, []byte, error) {
+	baseURL, err := resolveBaseURL(tool.BaseUrl, input, opts.HostVariables)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to build URL: %w", err)
+	}
+
+	// Build the full URL with path parameters
+	fullURL, err := buildURL(baseURL, tool.Path, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to build URL: %w", err)
+	}
+
+	// Add query parameters
+	if err := addQueryParams(fullURL, tool.Operation, input); err != nil {
+		return nil, nil, fmt.Errorf("Failed to add query params: %w", err)
+	}
+	if !tool.anonymous() {
+		addSecretQueryParams(fullURL, opts.QueryParams)
+	}
+
+	// Create HTTP request
+	var body []byte
+	var contentType string
+	if hasRequestBody(tool.Operation) {
+		body, err = buildRequestBody(tool.Operation, input, tool.InputSchema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to build request body: %w", err)
+		}
+	} else if len(formDataParams(tool.Operation)) > 0 {
+		body, contentType, err = buildFormBody(tool.Operation, input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to build request body: %w", err)
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, strings.ToUpper(tool.Method), fullURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create request: %w", err)
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	// Set headers
+	if err := setHeaders(httpReq, tool.Operation, input, opts.Headers); err != nil {
+		return nil, nil, fmt.Errorf("Failed to set headers: %w", err)
+	}
+	applyTagHeaders(httpReq, tool, opts.TagHeaders)
+
+	return httpReq, body, nil
+}
+
+// signHTTPRequest renders the templated headers and signs the request
+func signHTTPRequest(httpReq *http.Request, body []byte, opts *ToolOptions) error {
+	if err := applyHeaderTemplates(httpReq, body, opts.HeaderTemplates); err != nil {
+		return fmt.Errorf("Failed to set headers: %w", err)
+	}
+
+	if opts.Signer != nil {
+		if err := opts.Signer.Sign(httpReq, body); err != nil {
+			return fmt.Errorf("Failed to sign request: %w", err)
+		}
+	}
+	return nil
+}
+
+// throttle enforces the request budget of the credentials the request is
+// sent with and rate limits before it is sent upstream
+func throttle(ctx context.Context, httpReq *http.Request, tool *EnrichedTool, auth Authenticator, opts *ToolOptions) error {
+	if opts.Budget != nil {
+		key, err := budgetKey(ctx, httpReq, tool, auth, opts)
+		if err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+		if err := opts.Budget.Spend(key, budgetClass(httpReq.Method)); err != nil {
+			return err
+		}
+	}
+
+	if opts.RateLimit != nil {
+		if err := opts.RateLimit.Wait(ctx); err != nil {
+			return fmt.Errorf("Rate limit wait aborted: %w", err)
+		}
+	}
+
+	if opts.HostRateLimit != nil {
+		if err := opts.HostRateLimit.Wait(ctx, httpReq.URL.Host); err != nil {
+			return fmt.Errorf("Rate limit wait aborted: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // createAPIHandler creates a handler function for a specific API operation
-func createAPIHandlerForTool(tool *EnrichedTool, additionalHeaders http.Header) func(context.Context, *mcp.CallToolRequest, APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+func createAPIHandlerForTool(tool *EnrichedTool, opts *ToolOptions) apiToolHandler {
+	call := callAPI(tool, opts)
+	if opts.FollowPages > 1 && followsPages(tool) {
+		call = followPages(tool, opts.FollowPages, call)
+	}
+	if param := cursorParam(tool); opts.PageCursors != nil && param != "" {
+		call = opts.PageCursors.wrap(tool, param, call)
+	}
+	call = confirmCall(tool, opts, call)
+	return traceToolCall(tool, opts, reportProgress(func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		// Reject invalid input before anything is sent upstream
+		input = nestDottedFields(tool.InputSchema, input)
+		if err := validateInput(tool.InputSchema, input); err != nil {
+			return nil, APIToolOutput{Error: err.Error()}, nil
+		}
+
+		result, output, err := call(ctx, req, input)
+		return result, opts.redactOutput(output), err
+	}, opts))
+}
+
+// logHTTPCall records the method, URL, status and duration of an upstream
+// request, with secret query parameters redacted
+func logHTTPCall(ctx context.Context, opts *ToolOptions, tool *EnrichedTool, req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	attrs := []slog.Attr{
+		slog.String("tool", tool.Name),
+		slog.String("method", req.Method),
+		slog.String("url", opts.redact(req.URL.String())),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Duration("duration", duration), slog.String("error", opts.redact(err.Error())))
+		opts.logger().LogAttrs(ctx, slog.LevelWarn, "http call failed", attrs...)
+		return
+	}
+
+	attrs = append(attrs, slog.Int("status", resp.StatusCode), slog.Duration("duration", duration))
+	opts.logger().LogAttrs(ctx, slog.LevelInfo, "http call", attrs...)
+}
+
+// callAPI performs the upstream request of a tool call
+func callAPI(tool *EnrichedTool, opts *ToolOptions) apiToolHandler {
 	return func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
-		// Build the full URL with path parameters
-		fullURL, err := buildURL(tool.BaseUrl, tool.Path, input)
+		// Bound the whole call so a hung upstream can't stall the tool forever
+		timeout := opts.timeoutFor(tool.Name)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		httpReq, body, err := newHTTPRequest(ctx, tool, input, opts)
 		if err != nil {
-			return nil, APIToolOutput{Error: fmt.Sprintf("Failed to build URL: %v", err)}, nil
+			return nil, APIToolOutput{Error: err.Error()}, nil
+		}
+
+		// Key the cache before the templated headers and the signature, which
+		// change with every call
+		var key string
+		if opts.Cache != nil && isCacheable(httpReq) {
+			key = cacheKey(httpReq)
 		}
 
-		// Add query parameters
-		if err := addQueryParams(fullURL, tool.Operation, input); err != nil {
-			return nil, APIToolOutput{Error: fmt.Sprintf("Failed to add query params: %v", err)}, nil
+		if err := signHTTPRequest(httpReq, body, opts); err != nil {
+			return nil, APIToolOutput{Error: err.Error()}, nil
 		}
 
-		// Create HTTP request
-		bodyReader := &bytes.Reader{}
-		if hasRequestBody(tool.Operation) {
-			body, err := buildRequestBody(tool.Operation, input)
+		if opts.DryRun {
+			output, err := dryRunOutput(httpReq, opts)
 			if err != nil {
-				return nil, APIToolOutput{Error: fmt.Sprintf("Failed to build request body: %v", err)}, nil
+				return nil, APIToolOutput{Error: fmt.Sprintf("Failed to describe request: %v", err)}, nil
 			}
-			bodyReader = bytes.NewReader(body)
+			return nil, output, nil
 		}
 
-		httpReq, err := http.NewRequestWithContext(ctx, strings.ToUpper(tool.Method), fullURL.String(), bodyReader)
-		if err != nil {
-			return nil, APIToolOutput{Error: fmt.Sprintf("Failed to create request: %v", err)}, nil
+		// Serve repeated reads from the cache, and revalidate expired entries
+		if key != "" {
+			if output, ok := opts.Cache.Get(key); ok {
+				output.FromCache = true
+				return nil, output, nil
+			}
+			if output, ok := opts.Cache.Stale(key); ok {
+				setConditionalHeaders(httpReq, output)
+			}
 		}
 
-		// Set headers
-		if err := setHeaders(httpReq, tool.Operation, input, additionalHeaders); err != nil {
-			return nil, APIToolOutput{Error: fmt.Sprintf("Failed to set headers: %v", err)}, nil
+		auth := opts.authenticatorFor(tool)
+		if err := throttle(ctx, httpReq, tool, auth, opts); err != nil {
+			return nil, APIToolOutput{Error: err.Error()}, nil
 		}
 
 		// Make the HTTP request
-		client := &http.Client{}
-		resp, err := client.Do(httpReq)
+		injectTraceContext(ctx, httpReq)
+		setProgressState(ctx, progressWaiting)
+		start := time.Now()
+		resp, err := sendAuthenticated(ctx, httpReq, auth, opts)
+		logHTTPCall(ctx, opts, tool, httpReq, resp, err, time.Since(start))
 		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, APIToolOutput{
+					Error:     fmt.Sprintf("HTTP request timed out after %s", timeout),
+					ElapsedMS: time.Since(start).Milliseconds(),
+				}, nil
+			}
 			return nil, APIToolOutput{Error: fmt.Sprintf("HTTP request failed: %v", err)}, nil
 		}
 		defer resp.Body.Close()
 
 		// Parse response
+		setProgressState(ctx, progressReading)
 		output, err := parseResponse(resp)
 		if err != nil {
+			// Return the part of the body received before the deadline
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				output.Error = fmt.Sprintf("HTTP response timed out after %s, the body is incomplete", timeout)
+				output.ElapsedMS = time.Since(start).Milliseconds()
+				return nil, output, nil
+			}
 			return nil, APIToolOutput{Error: fmt.Sprintf("Failed to parse response: %v", err)}, nil
 		}
 
+		// An empty 304 is useless to the model, replay the body it confirms
+		if key != "" && output.StatusCode == http.StatusNotModified {
+			if cached, ok := opts.Cache.Stale(key); ok {
+				opts.Cache.Refresh(key)
+				cached.FromCache = true
+				return nil, cached, nil
+			}
+		}
+
+		if key != "" && output.Error == "" && output.StatusCode >= 200 && output.StatusCode < 300 {
+			opts.Cache.Set(key, output)
+		}
+
 		return nil, output, nil
 	}
 }
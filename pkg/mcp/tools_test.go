@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,8 +12,10 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/kumolabai/kumoctl/pkg/openapi"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -60,6 +63,38 @@ func TestGenerateToolName(t *testing.T) {
 			operationID: "",
 			expected:    "delete",
 		},
+		{
+			name:     "without operation ID - path with extension",
+			method:   "get",
+			path:     "/reports/{id}.json",
+			expected: "get_reports_id_json",
+		},
+		{
+			name:     "without operation ID - non-ASCII path",
+			method:   "get",
+			path:     "/café/menü items",
+			expected: "get_caf_men_items",
+		},
+		{
+			name:     "without operation ID - wildcard path parameter",
+			method:   "get",
+			path:     "/files/{path*}",
+			expected: "get_files_path",
+		},
+		{
+			name:        "operation ID with dots and spaces",
+			method:      "get",
+			path:        "/users",
+			operationID: "users.list all",
+			expected:    "users_list_all",
+		},
+		{
+			name:        "operation ID without valid characters",
+			method:      "get",
+			path:        "/users",
+			operationID: "???",
+			expected:    "tool",
+		},
 	}
 
 	for _, tt := range tests {
@@ -134,6 +169,41 @@ func TestBuildURL(t *testing.T) {
 			expected: "https://api.example.com/users",
 			hasError: false,
 		},
+		{
+			name:     "slash stays within the segment",
+			baseURL:  "https://api.example.com",
+			path:     "/files/{name}",
+			input:    APIToolInput{"name": "a/b"},
+			expected: "https://api.example.com/files/a%2Fb",
+		},
+		{
+			name:     "space and hash are encoded",
+			baseURL:  "https://api.example.com",
+			path:     "/files/{name}/meta",
+			input:    APIToolInput{"name": "my file#1"},
+			expected: "https://api.example.com/files/my%20file%231/meta",
+		},
+		{
+			name:     "query string can't be injected",
+			baseURL:  "https://api.example.com",
+			path:     "/users/{id}",
+			input:    APIToolInput{"id": "1?admin=true"},
+			expected: "https://api.example.com/users/1%3Fadmin=true",
+		},
+		{
+			name:     "dot segments are encoded",
+			baseURL:  "https://api.example.com",
+			path:     "/users/{id}/posts",
+			input:    APIToolInput{"id": ".."},
+			expected: "https://api.example.com/users/%2E%2E/posts",
+		},
+		{
+			name:     "large numbers without exponent",
+			baseURL:  "https://api.example.com",
+			path:     "/users/{id}",
+			input:    APIToolInput{"id": float64(12345678)},
+			expected: "https://api.example.com/users/12345678",
+		},
 	}
 
 	for _, tt := range tests {
@@ -241,6 +311,22 @@ func TestAddQueryParams(t *testing.T) {
 			},
 			expected: "status=active",
 		},
+		{
+			name: "explicit null left out",
+			operation: &openapi.OpenAPI3Operation{
+				Op: &openapi3.Operation{
+					Parameters: []*openapi3.ParameterRef{
+						{Value: &openapi3.Parameter{Name: "status", In: "query"}},
+						{Value: &openapi3.Parameter{Name: "cursor", In: "query"}},
+					},
+				},
+			},
+			input: APIToolInput{
+				"status": "active",
+				"cursor": nil,
+			},
+			expected: "status=active",
+		},
 	}
 
 	for _, tt := range tests {
@@ -362,6 +448,79 @@ func TestParseResponse(t *testing.T) {
 	}
 }
 
+func TestParseResponseHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string][]string
+		body        string
+		expectError bool
+	}{
+		{
+			name:        "HTML content type",
+			headers:     map[string][]string{"Content-Type": {"text/html; charset=utf-8"}},
+			body:        "<html><head><title>Sign in</title></head><body>Please log in</body></html>",
+			expectError: true,
+		},
+		{
+			name:        "HTML body without content type",
+			headers:     map[string][]string{},
+			body:        "\n  <!DOCTYPE html>\n<html><body>Access denied</body></html>",
+			expectError: true,
+		},
+		{
+			name:        "JSON body",
+			headers:     map[string][]string{"Content-Type": {"application/json"}},
+			body:        `{"html": "<html>"}`,
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: 200,
+				Header:     tt.headers,
+				Body:       &mockReadCloser{strings.NewReader(tt.body)},
+			}
+
+			result, err := parseResponse(resp)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.expectError {
+				if !strings.Contains(result.Error, "received HTML") {
+					t.Errorf("Expected HTML error, got %q", result.Error)
+				}
+				if result.Snippet == "" || strings.Contains(result.Snippet, "\n") {
+					t.Errorf("Expected collapsed snippet, got %q", result.Snippet)
+				}
+				if result.Body != nil {
+					t.Errorf("Expected nil body, got %v", result.Body)
+				}
+			} else if result.Error != "" {
+				t.Errorf("Unexpected error: %s", result.Error)
+			}
+		})
+	}
+
+	t.Run("long HTML body is truncated", func(t *testing.T) {
+		resp := &http.Response{
+			StatusCode: 403,
+			Header:     map[string][]string{"Content-Type": {"text/html"}},
+			Body:       &mockReadCloser{strings.NewReader("<html>" + strings.Repeat("blocked ", 200) + "</html>")},
+		}
+
+		result, _ := parseResponse(resp)
+		if !strings.HasSuffix(result.Snippet, "...") || len([]rune(result.Snippet)) != htmlSnippetLength+3 {
+			t.Errorf("Expected truncated snippet, got %d runes", len([]rune(result.Snippet)))
+		}
+		if !strings.Contains(result.Error, "status 403") {
+			t.Errorf("Expected status in error, got %q", result.Error)
+		}
+	})
+}
+
 func TestExtractFieldsFromSchema(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -452,6 +611,106 @@ func TestExtractFieldsFromSchema(t *testing.T) {
 				"name": "ProvidedName",
 			},
 		},
+		{
+			name: "nested objects",
+			schema: &openapi.OpenAPI3Schema{
+				Schema: &openapi3.Schema{
+					Type: &openapi3.Types{"object"},
+					Properties: map[string]*openapi3.SchemaRef{
+						"address": {
+							Value: &openapi3.Schema{
+								Type: &openapi3.Types{"object"},
+								Properties: map[string]*openapi3.SchemaRef{
+									"city":    {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+									"country": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Default: "NL"}},
+									"geo": {
+										Value: &openapi3.Schema{
+											Type: &openapi3.Types{"object"},
+											Properties: map[string]*openapi3.SchemaRef{
+												"datum": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Default: "WGS84"}},
+											},
+										},
+									},
+								},
+							},
+						},
+						"billing": {
+							Value: &openapi3.Schema{
+								Type: &openapi3.Types{"object"},
+								Properties: map[string]*openapi3.SchemaRef{
+									"currency": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Default: "EUR"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			input: APIToolInput{
+				"address": map[string]interface{}{
+					"city": "Utrecht",
+					"geo":  map[string]interface{}{"lat": 52.09},
+					"note": "undeclared",
+				},
+			},
+			expected: map[string]interface{}{
+				"address": map[string]interface{}{
+					"city":    "Utrecht",
+					"country": "NL",
+					"geo":     map[string]interface{}{"lat": 52.09, "datum": "WGS84"},
+					"note":    "undeclared",
+				},
+			},
+		}, {
+			name: "arrays of objects",
+			schema: &openapi.OpenAPI3Schema{
+				Schema: &openapi3.Schema{
+					Type: &openapi3.Types{"object"},
+					Properties: map[string]*openapi3.SchemaRef{
+						"lines": {
+							Value: &openapi3.Schema{
+								Type: &openapi3.Types{"array"},
+								Items: &openapi3.SchemaRef{
+									Value: &openapi3.Schema{
+										Properties: map[string]*openapi3.SchemaRef{
+											"sku":      {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+											"quantity": {Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}, Default: 1}},
+										},
+									},
+								},
+							},
+						},
+						"tags": {
+							Value: &openapi3.Schema{
+								Type:  &openapi3.Types{"array"},
+								Items: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+							},
+						},
+						"notes": {
+							Value: &openapi3.Schema{
+								Type:  &openapi3.Types{"array"},
+								Items: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+							},
+						},
+					},
+				},
+			},
+			input: APIToolInput{
+				"lines": []interface{}{
+					map[string]interface{}{"sku": "A-1", "quantity": float64(3)},
+					map[string]interface{}{"sku": "B-2"},
+				},
+				"tags":  `["new", "gift"]`,
+				"notes": "single",
+			},
+			expected: map[string]interface{}{
+				"lines": []interface{}{
+					map[string]interface{}{"sku": "A-1", "quantity": float64(3)},
+					map[string]interface{}{"sku": "B-2", "quantity": 1},
+				},
+				"tags":  []interface{}{"new", "gift"},
+				"notes": []interface{}{"single"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -475,6 +734,224 @@ func (m *mockReadCloser) Close() error {
 	return nil
 }
 
+func TestNestDottedFields(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"filter.name": {Type: "string"},
+			"name":        {Type: "string"},
+			"address": {
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"city": {Type: "string"},
+					"geo":  {Type: "object", Properties: map[string]*jsonschema.Schema{"lat": {Type: "number"}}},
+				},
+			},
+		},
+	}
+
+	address := map[string]interface{}{"city": "Utrecht"}
+	input := APIToolInput{
+		"filter.name":     "kept",
+		"name.first":      "not an object",
+		"address":         address,
+		"address.city":    "ignored, given in the object",
+		"address.geo.lat": 52.09,
+		"address.zip":     "3511",
+	}
+
+	expected := APIToolInput{
+		"filter.name":  "kept",
+		"name.first":   "not an object",
+		"address.city": "ignored, given in the object",
+		"address": map[string]interface{}{
+			"city": "Utrecht",
+			"zip":  "3511",
+			"geo":  map[string]interface{}{"lat": 52.09},
+		},
+	}
+
+	got := nestDottedFields(schema, input)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if len(address) != 1 || len(input) != 6 {
+		t.Errorf("Expected the input to be left unchanged, got %v", input)
+	}
+}
+
+func TestBuildRequestBodyAdditionalProperties(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/things/{id}/labels": {
+				"put": {
+					"operationId": "putLabels",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"requestBody": {"content": {"application/json": {"schema": {"type": "object", "additionalProperties": {"type": "string"}}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			},
+			"/things": {
+				"post": {
+					"operationId": "createThing",
+					"requestBody": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string"},
+							"limits": {
+								"type": "object",
+								"additionalProperties": {
+									"type": "object",
+									"properties": {"value": {"type": "integer"}, "unit": {"type": "string", "default": "req"}}
+								}
+							}
+						}
+					}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		method   string
+		input    APIToolInput
+		expected string
+	}{
+		{
+			name:     "free-form body takes the undeclared inputs",
+			path:     "/things/{id}/labels",
+			method:   "put",
+			input:    APIToolInput{"id": "t1", "env": "prod", "team": "ops"},
+			expected: `{"env":"prod","team":"ops"}`,
+		},
+		{
+			name:     "typed map values are built from their schema",
+			path:     "/things",
+			method:   "post",
+			input:    APIToolInput{"name": "api", "limits": map[string]interface{}{"rate": map[string]interface{}{"value": float64(10)}}},
+			expected: `{"limits":{"rate":{"unit":"req","value":10}},"name":"api"}`,
+		},
+		{
+			name:     "undeclared inputs stay out of bodies without additionalProperties",
+			path:     "/things",
+			method:   "post",
+			input:    APIToolInput{"name": "api", "extra": "x"},
+			expected: `{"name":"api"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := spec.GetPaths()[tt.path].GetOperations()[tt.method]
+			inputSchema, err := openapi.GenerateInputSchema(operation)
+			if err != nil {
+				t.Fatalf("Failed to generate schema: %v", err)
+			}
+
+			body, err := buildRequestBody(operation, tt.input, inputSchema)
+			if err != nil {
+				t.Fatalf("Failed to build body: %v", err)
+			}
+			if string(body) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestBodyFieldNamespacing(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/users/{id}": {
+				"put": {
+					"operationId": "updateUser",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"requestBody": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"required": ["id", "name"],
+						"properties": {"id": {"type": "string", "description": "Public ID"}, "name": {"type": "string"}}
+					}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	operation := spec.GetPaths()["/users/{id}"].GetOperations()["put"]
+
+	tests := []struct {
+		name         string
+		flatten      bool
+		input        APIToolInput
+		expectedPath string
+		expectedBody string
+	}{
+		{
+			name:         "namespaced",
+			input:        APIToolInput{"id": "42", "body.id": "u-42", "name": "Ada"},
+			expectedPath: "/users/42",
+			expectedBody: `{"id":"u-42","name":"Ada"}`,
+		},
+		{
+			name:         "path parameter not sent in the body",
+			input:        APIToolInput{"id": "42", "name": "Ada"},
+			expectedPath: "/users/42",
+			expectedBody: `{"name":"Ada"}`,
+		},
+		{
+			name:         "flattened",
+			flatten:      true,
+			input:        APIToolInput{"id": "42", "name": "Ada"},
+			expectedPath: "/users/42",
+			expectedBody: `{"id":"42","name":"Ada"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputSchema, err := openapi.GenerateInputSchema(operation)
+			if err != nil {
+				t.Fatalf("Failed to generate schema: %v", err)
+			}
+			tool := &EnrichedTool{
+				Tool:      &mcp.Tool{Name: "updateUser", InputSchema: inputSchema},
+				BaseUrl:   "https://api.example.com",
+				Method:    "put",
+				Path:      "/users/{id}",
+				Operation: operation,
+			}
+			prepareTool(tool, &ToolOptions{FlattenBody: tt.flatten})
+
+			if _, namespaced := inputSchema.Properties["body.id"]; namespaced == tt.flatten {
+				t.Errorf("Expected body.id input only without flattening, got %v", inputSchema.Properties)
+			}
+			req, err := BuildRequest(context.Background(), tool, tt.input, &ToolOptions{FlattenBody: tt.flatten})
+			if err != nil {
+				t.Fatalf("Failed to build request: %v", err)
+			}
+			if req.URL.Path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, req.URL.Path)
+			}
+			body, _ := io.ReadAll(req.Body)
+			if string(body) != tt.expectedBody {
+				t.Errorf("Expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
 func TestPathParametersSpecific(t *testing.T) {
 	// Create a mock server that logs exactly what it receives
 	requestLog := make([]string, 0)
@@ -892,7 +1369,7 @@ func TestCreateAPIHandlerForTool_AdditionalHeaders(t *testing.T) {
 			receivedHeaders = nil
 
 			// Create handler with additional headers
-			handler := createAPIHandlerForTool(tool, tc.additionalHeaders)
+			handler := createAPIHandlerForTool(tool, &ToolOptions{Headers: tc.additionalHeaders})
 
 			// Execute the handler
 			_, output, err := handler(context.Background(), nil, tc.input)
@@ -994,7 +1471,7 @@ func TestCreateAPIHandlerForTool_AdditionalHeadersWithRequestBody(t *testing.T)
 		"X-Request-Id":  []string{"req-12345"},
 	}
 
-	handler := createAPIHandlerForTool(tool, additionalHeaders)
+	handler := createAPIHandlerForTool(tool, &ToolOptions{Headers: additionalHeaders})
 
 	input := APIToolInput{
 		"name": "Test Resource",
@@ -1295,3 +1772,408 @@ func TestPathParametersOpenAPI2(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateAPIHandlerForTool_Timeout(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockServer.Close()
+
+	tool := &EnrichedTool{
+		Tool: &mcp.Tool{
+			Name: "slowTool",
+		},
+		BaseUrl: mockServer.URL,
+		Method:  "get",
+		Path:    "/slow",
+		Operation: &openapi.OpenAPI3Operation{
+			Op: &openapi3.Operation{OperationID: "slowTool"},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		opts        *ToolOptions
+		expectError bool
+	}{
+		{
+			name:        "global timeout exceeded",
+			opts:        &ToolOptions{Timeout: 50 * time.Millisecond},
+			expectError: true,
+		},
+		{
+			name: "operation override exceeded",
+			opts: &ToolOptions{
+				Timeout:           time.Minute,
+				OperationTimeouts: map[string]time.Duration{"slowTool": 50 * time.Millisecond},
+			},
+			expectError: true,
+		},
+		{
+			name: "operation override extends global timeout",
+			opts: &ToolOptions{
+				Timeout:           50 * time.Millisecond,
+				OperationTimeouts: map[string]time.Duration{"slowTool": 5 * time.Second},
+			},
+			expectError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := createAPIHandlerForTool(tool, tc.opts)
+
+			_, output, err := handler(context.Background(), nil, APIToolInput{})
+			if err != nil {
+				t.Fatalf("Handler execution failed: %v", err)
+			}
+
+			if tc.expectError && !strings.Contains(output.Error, "timed out") {
+				t.Errorf("Expected timeout error, got %q", output.Error)
+			}
+			if !tc.expectError && output.Error != "" {
+				t.Errorf("Unexpected error: %s", output.Error)
+			}
+		})
+	}
+}
+
+func TestOperationServers(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "https://api.example.com/v1"}],
+		"paths": {
+			"/users": {
+				"get": {"operationId": "listUsers", "responses": {"200": {"description": "OK"}}}
+			},
+			"/files": {
+				"servers": [{"url": "https://{region}.files.example.com", "variables": {"region": {"default": "us"}}}],
+				"get": {"operationId": "listFiles", "responses": {"200": {"description": "OK"}}},
+				"post": {
+					"operationId": "uploadFile",
+					"servers": [{"url": "/upload"}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	expected := map[string]string{
+		"listUsers":  "https://proxy.example.com",
+		"listFiles":  "https://us.files.example.com",
+		"uploadFile": "https://api.example.com/upload",
+	}
+	for _, tool := range tools {
+		prepareTool(tool, &ToolOptions{BaseURL: "https://proxy.example.com"})
+		if tool.BaseUrl != expected[tool.Name] {
+			t.Errorf("Expected base URL %s for %s, got %s", expected[tool.Name], tool.Name, tool.BaseUrl)
+		}
+	}
+}
+
+func TestOperationSecurity(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "https://api.example.com"}],
+		"security": [{"apiKey": []}],
+		"components": {"securitySchemes": {"apiKey": {"type": "apiKey", "in": "query", "name": "key"}}},
+		"paths": {
+			"/users": {
+				"get": {"operationId": "listUsers", "responses": {"200": {"description": "OK"}}}
+			},
+			"/health": {
+				"get": {"operationId": "health", "security": [], "responses": {"200": {"description": "OK"}}}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	opts := &ToolOptions{Auth: &BearerAuthenticator{}, QueryParams: url.Values{"key": {"secret"}}}
+
+	tests := []struct {
+		tool        string
+		credentials bool
+	}{
+		{tool: "listUsers", credentials: true},
+		{tool: "health", credentials: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			tool, err := FindTool(spec, tt.tool, opts)
+			if err != nil {
+				t.Fatalf("Failed to find tool: %v", err)
+			}
+
+			req, err := BuildRequest(context.Background(), tool, APIToolInput{}, opts)
+			if err != nil {
+				t.Fatalf("Failed to build request: %v", err)
+			}
+
+			if got := req.URL.Query().Has("key"); got != tt.credentials {
+				t.Errorf("Expected API key sent: %v, got %v", tt.credentials, got)
+			}
+			if got := opts.authenticatorFor(tool) != nil; got != tt.credentials {
+				t.Errorf("Expected authenticator used: %v, got %v", tt.credentials, got)
+			}
+		})
+	}
+}
+
+func TestCreateAPIHandlerForTool_PartialBody(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"id": 1}, {"id": 2`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer mockServer.Close()
+
+	tool := &EnrichedTool{
+		Tool:    &mcp.Tool{Name: "slowBody"},
+		BaseUrl: mockServer.URL,
+		Method:  "get",
+		Path:    "/items",
+		Operation: &openapi.OpenAPI3Operation{
+			Op: &openapi3.Operation{OperationID: "slowBody"},
+		},
+	}
+
+	handler := createAPIHandlerForTool(tool, &ToolOptions{Timeout: 100 * time.Millisecond})
+	_, output, err := handler(context.Background(), nil, APIToolInput{})
+	if err != nil {
+		t.Fatalf("Handler execution failed: %v", err)
+	}
+
+	if !output.Partial || output.Body != `[{"id": 1}, {"id": 2` {
+		t.Errorf("Expected the partial body, got partial=%v body=%v", output.Partial, output.Body)
+	}
+	if output.StatusCode != http.StatusOK || !strings.Contains(output.Error, "timed out") {
+		t.Errorf("Expected status and timeout error, got %d %q", output.StatusCode, output.Error)
+	}
+	if output.ElapsedMS < 100 {
+		t.Errorf("Expected the elapsed time, got %dms", output.ElapsedMS)
+	}
+}
+
+func TestEnumHints(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"swagger": "2.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"host": "api.example.com",
+		"paths": {
+			"/pets": {
+				"get": {
+					"operationId": "listPets",
+					"summary": "List pets",
+					"parameters": [
+						{"name": "status", "in": "query", "type": "string", "enum": ["available", "sold"]},
+						{"name": "tags", "in": "query", "type": "array", "items": {"type": "string", "enum": ["cat", "dog"]}},
+						{"name": "limit", "in": "query", "type": "integer"}
+					],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	expected := "List pets\n\nAllowed values:\n- status: available, sold\n- tags: cat, dog"
+	if tools[0].Description != expected {
+		t.Errorf("Expected description %q, got %q", expected, tools[0].Description)
+	}
+
+	err = validateInput(tools[0].InputSchema, APIToolInput{"status": "pending"})
+	if err == nil || !strings.Contains(err.Error(), `status: must be one of ["available","sold"]`) {
+		t.Errorf("Expected the allowed values in the error, got %v", err)
+	}
+}
+
+func TestToolAnnotations(t *testing.T) {
+	tests := []struct {
+		method      string
+		readOnly    bool
+		destructive bool
+		idempotent  bool
+	}{
+		{method: "get", readOnly: true},
+		{method: "head", readOnly: true},
+		{method: "post"},
+		{method: "patch"},
+		{method: "put", idempotent: true},
+		{method: "delete", destructive: true, idempotent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			annotations := toolAnnotations(tt.method)
+
+			if annotations.OpenWorldHint == nil || !*annotations.OpenWorldHint {
+				t.Errorf("Expected openWorldHint")
+			}
+			if annotations.ReadOnlyHint != tt.readOnly {
+				t.Errorf("Expected readOnlyHint %v, got %v", tt.readOnly, annotations.ReadOnlyHint)
+			}
+			if annotations.IdempotentHint != tt.idempotent {
+				t.Errorf("Expected idempotentHint %v, got %v", tt.idempotent, annotations.IdempotentHint)
+			}
+			if tt.readOnly {
+				if annotations.DestructiveHint != nil {
+					t.Errorf("Expected no destructiveHint on a read-only tool")
+				}
+			} else if annotations.DestructiveHint == nil || *annotations.DestructiveHint != tt.destructive {
+				t.Errorf("Expected destructiveHint %v, got %v", tt.destructive, annotations.DestructiveHint)
+			}
+		})
+	}
+}
+
+func TestToolOutputSchema(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+		"swagger": "2.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"host": "api.example.com",
+		"definitions": {
+			"Pet": {"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}
+		},
+		"paths": {
+			"/pets/{id}": {
+				"get": {
+					"operationId": "getPet",
+					"parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}],
+					"responses": {
+						"200": {"description": "OK", "schema": {"$ref": "#/definitions/Pet"}},
+						"404": {"description": "Not found", "schema": {"type": "object", "properties": {"message": {"type": "string"}}}}
+					}
+				},
+				"delete": {
+					"operationId": "deletePet",
+					"parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}],
+					"responses": {"204": {"description": "Deleted"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	for _, tool := range tools {
+		switch tool.Name {
+		case "getPet":
+			if tool.OutputSchema == nil {
+				t.Fatalf("Expected an output schema for getPet")
+			}
+			body := tool.OutputSchema.Properties["body"]
+			if body == nil || len(body.AnyOf) != 2 || body.AnyOf[0].Properties["name"] == nil {
+				t.Errorf("Expected the body typed by the 200 response, got %+v", body)
+			}
+			if tool.OutputSchema.Properties["status_code"] == nil {
+				t.Errorf("Expected the generic output fields to be kept")
+			}
+		case "deletePet":
+			if tool.OutputSchema != nil {
+				t.Errorf("Expected no output schema without a response schema, got %+v", tool.OutputSchema)
+			}
+		}
+	}
+}
+
+func TestGetToolsFromSpecDisambiguatesNames(t *testing.T) {
+	// Unlike OpenAPI 3 documents, Swagger 2 specs load with duplicate
+	// operationIds
+	spec, err := openapi.LoadSpec([]byte(`{
+		"swagger": "2.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"host": "api.example.com",
+		"paths": {
+			"/users/{id}": {
+				"get": {"operationId": "getUser", "responses": {"200": {"description": "OK"}}}
+			},
+			"/v2/users/{id}": {
+				"get": {"operationId": "getUser", "responses": {"200": {"description": "OK"}}}
+			},
+			"/a_b": {
+				"get": {"responses": {"200": {"description": "OK"}}}
+			},
+			"/a/b": {
+				"get": {"responses": {"200": {"description": "OK"}}}
+			},
+			"/orders": {
+				"get": {"operationId": "listOrders", "responses": {"200": {"description": "OK"}}}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	// Names must not depend on the iteration order of the paths
+	for i := 0; i < 5; i++ {
+		tools, err := GetToolsFromSpec(spec)
+		if err != nil {
+			t.Fatalf("Failed to generate tools: %v", err)
+		}
+
+		names := make(map[string]string, len(tools))
+		colliding := make(map[string]string, len(tools))
+		for _, tool := range tools {
+			names[tool.Method+" "+tool.Path] = tool.Name
+			colliding[tool.Name] = tool.CollidingName
+		}
+
+		expected := map[string]string{
+			"get /users/{id}":    "getUser_get_users_id",
+			"get /v2/users/{id}": "getUser_get_v2_users_id",
+			"get /a/b":           "get_a_b",
+			"get /a_b":           "get_a_b_2",
+			"get /orders":        "listOrders",
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Expected names %v, got %v", expected, names)
+		}
+
+		expectedColliding := map[string]string{
+			"getUser_get_users_id":    "getUser",
+			"getUser_get_v2_users_id": "getUser",
+			"get_a_b":                 "get_a_b",
+			"get_a_b_2":               "get_a_b",
+			"listOrders":              "",
+		}
+		if !reflect.DeepEqual(colliding, expectedColliding) {
+			t.Errorf("Expected colliding names %v, got %v", expectedColliding, colliding)
+		}
+	}
+}
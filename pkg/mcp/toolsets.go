@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"sort"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+// DefaultToolset holds the tools of operations without tags
+const DefaultToolset = "default"
+
+// Toolset groups the tools of an OpenAPI tag, so clients can enable only the
+// parts of a large API they need
+type Toolset struct {
+	// Name is derived from the tag like prompt names, e.g. user_accounts for
+	// "User Accounts"
+	Name string
+	// Tag is the tag of the toolset, empty for DefaultToolset
+	Tag         string
+	Description string
+	// Tools are the names of the toolset's tools, sorted
+	Tools []string
+}
+
+// toolsetName returns the name of a toolset, given as is or as its tag
+func toolsetName(tag string) string {
+	return tagPromptName("", tag)
+}
+
+// toolsetsOf returns the toolsets of a tool, one for each of its tags
+func toolsetsOf(tool *EnrichedTool) []string {
+	tags := tool.Operation.GetTags()
+	if len(tags) == 0 {
+		return []string{DefaultToolset}
+	}
+
+	toolsets := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		toolsets = append(toolsets, toolsetName(tag))
+	}
+	return toolsets
+}
+
+// toolsetPrefix returns the toolset prefixing a tool's name: its first
+// toolset selected by the filter
+func toolsetPrefix(tool *EnrichedTool, filter *ToolFilter) string {
+	toolsets := toolsetsOf(tool)
+	if filter != nil && len(filter.Toolsets) > 0 {
+		for _, toolset := range toolsets {
+			if matchesAny(filter.Toolsets, []string{toolset}, matchToolset) {
+				return toolset
+			}
+		}
+	}
+	return toolsets[0]
+}
+
+// matchToolset reports whether a toolset, given by name or tag, is the named
+// one
+func matchToolset(toolset, name string) bool {
+	return toolsetName(toolset) == name
+}
+
+// GetToolsets groups the tools of the spec selected by opts by toolset,
+// sorted by name
+func GetToolsets(spec openapi.APISpec, opts *ToolOptions) ([]Toolset, error) {
+	if opts == nil {
+		opts = &ToolOptions{}
+	}
+
+	tools, err := GetToolsFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*Toolset)
+	for _, tag := range spec.GetTags() {
+		byName[toolsetName(tag.Name)] = &Toolset{Name: toolsetName(tag.Name), Tag: tag.Name, Description: tag.Description}
+	}
+
+	for _, tool := range tools {
+		if !opts.Filter.Match(tool) {
+			continue
+		}
+
+		tags := tool.Operation.GetTags()
+		toolsets := toolsetsOf(tool)
+		renameTool(tool, opts)
+		for i, name := range toolsets {
+			toolset, ok := byName[name]
+			if !ok {
+				toolset = &Toolset{Name: name}
+				if len(tags) > 0 {
+					toolset.Tag = tags[i]
+				}
+				byName[name] = toolset
+			}
+			toolset.Tools = append(toolset.Tools, tool.Name)
+		}
+	}
+
+	toolsets := make([]Toolset, 0, len(byName))
+	for _, toolset := range byName {
+		// Tags declared without operations, or whose operations are all
+		// filtered out, offer nothing to enable
+		if len(toolset.Tools) == 0 {
+			continue
+		}
+		sort.Strings(toolset.Tools)
+		toolsets = append(toolsets, *toolset)
+	}
+	sort.Slice(toolsets, func(i, j int) bool { return toolsets[i].Name < toolsets[j].Name })
+	return toolsets, nil
+}
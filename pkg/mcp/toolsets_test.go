@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+const toolsetsSpec = `{
+	"openapi": "3.0.0",
+	"info": {"title": "Test", "version": "1.0.0"},
+	"servers": [{"url": "https://api.example.com"}],
+	"tags": [
+		{"name": "User Accounts", "description": "Manage users"},
+		{"name": "Unused"}
+	],
+	"paths": {
+		"/users": {
+			"get": {"operationId": "listUsers", "tags": ["User Accounts"], "responses": {"200": {"description": "OK"}}}
+		},
+		"/users/{id}/invoices": {
+			"get": {"operationId": "listUserInvoices", "tags": ["User Accounts", "billing"], "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "OK"}}}
+		},
+		"/health": {
+			"get": {"operationId": "health", "responses": {"200": {"description": "OK"}}}
+		}
+	}
+}`
+
+func TestGetToolsets(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(toolsetsSpec))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	toolsets, err := GetToolsets(spec, nil)
+	if err != nil {
+		t.Fatalf("Failed to group tools: %v", err)
+	}
+
+	expected := []Toolset{
+		{Name: "billing", Tag: "billing", Tools: []string{"listUserInvoices"}},
+		{Name: "default", Tools: []string{"health"}},
+		{Name: "user_accounts", Tag: "User Accounts", Description: "Manage users", Tools: []string{"listUserInvoices", "listUsers"}},
+	}
+	if !reflect.DeepEqual(toolsets, expected) {
+		t.Errorf("Expected toolsets %+v, got %+v", expected, toolsets)
+	}
+}
+
+func TestToolsetFilterAndPrefix(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(toolsetsSpec))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     *ToolOptions
+		expected []string
+	}{
+		{
+			name:     "filter by name",
+			opts:     &ToolOptions{Filter: &ToolFilter{Toolsets: []string{"billing", "default"}}},
+			expected: []string{"health", "listUserInvoices"},
+		},
+		{
+			name:     "filter by tag",
+			opts:     &ToolOptions{Filter: &ToolFilter{Toolsets: []string{"User Accounts"}}},
+			expected: []string{"listUserInvoices", "listUsers"},
+		},
+		{
+			name:     "prefix with the first toolset",
+			opts:     &ToolOptions{PrefixToolsets: true, ToolPrefix: "api_"},
+			expected: []string{"api_default_health", "api_user_accounts_listUserInvoices", "api_user_accounts_listUsers"},
+		},
+		{
+			name:     "prefix with the selected toolset",
+			opts:     &ToolOptions{PrefixToolsets: true, Filter: &ToolFilter{Toolsets: []string{"billing"}}},
+			expected: []string{"billing_listUserInvoices"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolsets, err := GetToolsets(spec, tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to group tools: %v", err)
+			}
+
+			seen := make(map[string]bool)
+			var names []string
+			for _, toolset := range toolsets {
+				for _, name := range toolset.Tools {
+					if !seen[name] {
+						seen[name] = true
+						names = append(names, name)
+					}
+				}
+			}
+			sort.Strings(names)
+
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected tools %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
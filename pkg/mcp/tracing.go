@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of tool calls
+const tracerName = "github.com/kumolabai/kumoctl/pkg/mcp"
+
+// traceToolCall records a span for every call of the tool, with its route and
+// the status of the upstream response. Calls failing before or after the
+// request is sent mark the span as failed.
+func traceToolCall(tool *EnrichedTool, opts *ToolOptions, handler apiToolHandler) apiToolHandler {
+	if opts.TracerProvider == nil {
+		return handler
+	}
+
+	tracer := opts.TracerProvider.Tracer(tracerName)
+	return func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		ctx, span := tracer.Start(ctx, "tools/call "+tool.Name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("mcp.method.name", "tools/call"),
+				attribute.String("gen_ai.tool.name", tool.Name),
+				attribute.String("http.request.method", strings.ToUpper(tool.Method)),
+				attribute.String("url.template", tool.Path),
+			),
+		)
+		defer span.End()
+
+		result, output, err := handler(ctx, req, input)
+		if output.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", output.StatusCode))
+		}
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case output.Error != "":
+			span.SetStatus(codes.Error, output.Error)
+		case output.StatusCode >= 400:
+			span.SetStatus(codes.Error, http.StatusText(output.StatusCode))
+		}
+		return result, output, err
+	}
+}
+
+// injectTraceContext adds the traceparent header of the call's span to the
+// upstream request, so the API's traces join the tool call's. Nothing is added
+// when the call isn't traced.
+func injectTraceContext(ctx context.Context, req *http.Request) {
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
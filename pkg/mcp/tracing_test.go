@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceToolCall(t *testing.T) {
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		if r.URL.Path == "/v1/users/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "42"}`))
+	}))
+	defer upstream.Close()
+
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "` + upstream.URL + `/v1"}],
+		"paths": {
+			"/users/{id}": {
+				"get": {
+					"operationId": "getUser",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	opts := &ToolOptions{TracerProvider: provider}
+
+	tool, err := FindTool(spec, "getUser", opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		id             string
+		expectedStatus int
+		expectedCode   codes.Code
+	}{
+		{id: "42", expectedStatus: http.StatusOK, expectedCode: codes.Unset},
+		{id: "missing", expectedStatus: http.StatusNotFound, expectedCode: codes.Error},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if _, err := CallTool(context.Background(), tool, APIToolInput{"id": tt.id}, opts); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != i+1 {
+				t.Fatalf("Expected %d spans, got %d", i+1, len(spans))
+			}
+			span := spans[i]
+
+			if span.Name() != "tools/call getUser" {
+				t.Errorf("Expected span tools/call getUser, got %s", span.Name())
+			}
+			if span.Status().Code != tt.expectedCode {
+				t.Errorf("Expected status %v, got %v", tt.expectedCode, span.Status().Code)
+			}
+
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, attr := range span.Attributes() {
+				attrs[attr.Key] = attr.Value
+			}
+			expected := map[attribute.Key]string{
+				"gen_ai.tool.name":    "getUser",
+				"http.request.method": "GET",
+				"url.template":        "/users/{id}",
+			}
+			for key, value := range expected {
+				if attrs[key].AsString() != value {
+					t.Errorf("Expected %s %q, got %q", key, value, attrs[key].AsString())
+				}
+			}
+			if status := attrs["http.response.status_code"].AsInt64(); status != int64(tt.expectedStatus) {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, status)
+			}
+
+			expectedParent := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+			if traceparent != expectedParent {
+				t.Errorf("Expected traceparent %s, got %s", expectedParent, traceparent)
+			}
+		})
+	}
+}
+
+func TestTraceToolCallDisabled(t *testing.T) {
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+	}))
+	defer upstream.Close()
+
+	spec, err := openapi.LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "` + upstream.URL + `"}],
+		"paths": {"/ping": {"get": {"operationId": "ping", "responses": {"200": {"description": "OK"}}}}}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	tool, err := FindTool(spec, "ping", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := CallTool(context.Background(), tool, APIToolInput{}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if traceparent != "" {
+		t.Errorf("Expected no traceparent without tracing, got %s", traceparent)
+	}
+}
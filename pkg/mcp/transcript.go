@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TranscriptVersion is the version of the transcript file format
+const TranscriptVersion = 1
+
+// Transcript records every tool call of a serve session to a JSON Lines file
+type Transcript struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// TranscriptHeader is the first line of a transcript file
+type TranscriptHeader struct {
+	Version   int       `json:"version"`
+	Source    string    `json:"source"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// TranscriptEntry is a single recorded tool call, one per line after the header
+type TranscriptEntry struct {
+	Tool       string        `json:"tool"`
+	Input      APIToolInput  `json:"input"`
+	Output     APIToolOutput `json:"output"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+}
+
+// NewTranscript creates a transcript for the spec at source, writing its
+// header to path right away so unwritable paths fail at startup
+func NewTranscript(path, source string) (*Transcript, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcript: %w", err)
+	}
+
+	t := &Transcript{file: file}
+	header := TranscriptHeader{Version: TranscriptVersion, Source: source, StartedAt: time.Now().UTC()}
+	if err := t.writeLine(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Record appends a call to the transcript file, so the file is complete up
+// to the last call even if the session ends abruptly
+func (t *Transcript) Record(entry TranscriptEntry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.writeLine(entry)
+}
+
+// Close closes the transcript file
+func (t *Transcript) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.file.Close()
+}
+
+// writeLine writes value as a single line, in one write so a line is never
+// interleaved with another
+func (t *Transcript) writeLine(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+
+	if _, err := t.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// recordTranscript wraps a tool handler so each call is added to the transcript
+func recordTranscript(toolName string, handler apiToolHandler, transcript *Transcript, opts *ToolOptions) apiToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		started := time.Now()
+		result, output, err := handler(ctx, req, input)
+
+		entry := TranscriptEntry{
+			Tool:       toolName,
+			Input:      input,
+			Output:     output,
+			StartedAt:  started.UTC(),
+			DurationMs: time.Since(started).Milliseconds(),
+		}
+		if recordErr := transcript.Record(entry); recordErr != nil {
+			opts.logger().Warn("failed to record transcript", "error", recordErr)
+		}
+
+		return result, output, err
+	}
+}
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTranscriptRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+
+	transcript, err := NewTranscript(path, "spec.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer transcript.Close()
+
+	// The header is written at the start of the session
+	lines := readTranscriptLines(t, path)
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the header, got %d lines", len(lines))
+	}
+	var header TranscriptHeader
+	if err := json.Unmarshal(lines[0], &header); err != nil {
+		t.Fatalf("Failed to parse header: %v", err)
+	}
+	if header.Version != TranscriptVersion || header.Source != "spec.json" || header.StartedAt.IsZero() {
+		t.Errorf("Unexpected header: %+v", header)
+	}
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input APIToolInput) (*mcp.CallToolResult, APIToolOutput, error) {
+		return nil, APIToolOutput{StatusCode: 200, Body: map[string]interface{}{"id": input["id"]}}, nil
+	}
+	wrapped := recordTranscript("getUser", handler, transcript, &ToolOptions{})
+
+	for _, id := range []string{"1", "2"} {
+		if _, _, err := wrapped(context.Background(), nil, APIToolInput{"id": id}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	lines = readTranscriptLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("Expected the header and 2 calls, got %d lines", len(lines))
+	}
+
+	var call TranscriptEntry
+	if err := json.Unmarshal(lines[2], &call); err != nil {
+		t.Fatalf("Failed to parse call: %v", err)
+	}
+	if call.Tool != "getUser" || call.Input["id"] != "2" || call.Output.StatusCode != 200 {
+		t.Errorf("Unexpected recorded call: %+v", call)
+	}
+	if call.StartedAt.IsZero() {
+		t.Error("Expected start time to be recorded")
+	}
+}
+
+func TestNewTranscriptTruncates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte("{\"tool\": \"old\"}\n{\"tool\": \"old\"}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	transcript, err := NewTranscript(path, "spec.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer transcript.Close()
+
+	if lines := readTranscriptLines(t, path); len(lines) != 1 {
+		t.Errorf("Expected the previous session to be replaced, got %d lines", len(lines))
+	}
+}
+
+func TestNewTranscriptUnwritablePath(t *testing.T) {
+	if _, err := NewTranscript(filepath.Join(t.TempDir(), "missing", "session.json"), "spec.json"); err == nil {
+		t.Error("Expected error for unwritable path")
+	}
+}
+
+func readTranscriptLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read transcript: %v", err)
+	}
+	return bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+}
@@ -0,0 +1,301 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// InputValidationError lists every field of a tool input that doesn't match
+// the tool's input schema
+type InputValidationError struct {
+	Fields []FieldError
+}
+
+// FieldError is a single invalid field, Path is empty for the input itself
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e *InputValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		if field.Path == "" {
+			problems[i] = field.Message
+		} else {
+			problems[i] = field.Path + ": " + field.Message
+		}
+	}
+	return "Invalid input: " + strings.Join(problems, "; ")
+}
+
+// validateInput checks a tool input against the tool's input schema, so the
+// caller gets field-level errors instead of an opaque rejection from the API
+func validateInput(schema *jsonschema.Schema, input APIToolInput) error {
+	if schema == nil {
+		return nil
+	}
+
+	v := &inputValidator{}
+	v.validateObject("", schema, input, true)
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &InputValidationError{Fields: v.errors}
+}
+
+type inputValidator struct {
+	errors []FieldError
+}
+
+func (v *inputValidator) fail(path, format string, args ...interface{}) {
+	v.errors = append(v.errors, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks a value against a schema, recording errors under path
+func (v *inputValidator) validate(path string, schema *jsonschema.Schema, value interface{}) {
+	if schema == nil {
+		return
+	}
+
+	types := schemaTypes(schema)
+	if len(types) > 0 && !matchesAnyType(value, types) {
+		v.fail(path, "expected %s, got %s", strings.Join(types, " or "), jsonType(value))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !containsValue(schema.Enum, value) {
+		v.fail(path, "must be one of %s", formatEnum(schema.Enum))
+	}
+
+	if schema.Pattern != "" {
+		if str, ok := value.(string); ok {
+			re, err := regexp.Compile(schema.Pattern)
+			if err == nil && !re.MatchString(str) {
+				v.fail(path, "%q does not match pattern %s", str, schema.Pattern)
+			}
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(path, schema, val, false)
+	case []interface{}:
+		for i, item := range val {
+			v.validate(fmt.Sprintf("%s[%d]", path, i), schema.Items, item)
+		}
+	}
+
+	for _, subschema := range schema.AllOf {
+		v.validate(path, subschema, value)
+	}
+	for _, alternatives := range [][]*jsonschema.Schema{schema.AnyOf, schema.OneOf} {
+		if len(alternatives) > 0 && !v.matchesAny(path, alternatives, value) {
+			v.fail(path, "does not match any of the allowed schemas")
+		}
+	}
+}
+
+// validateObject checks the required and declared fields of an object. The
+// tool input's array fields may also be given as a JSON string or a single
+// value, they are converted when the query string is built.
+func (v *inputValidator) validateObject(path string, schema *jsonschema.Schema, object map[string]interface{}, topLevel bool) {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			v.fail(joinPath(path, name), "is required")
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := object[name]
+		property, ok := schema.Properties[name]
+		if !ok {
+			property = schema.AdditionalProperties
+			// additionalProperties: false forbids the fields not declared
+			if property != nil && property.Not != nil && reflect.DeepEqual(property.Not, &jsonschema.Schema{}) {
+				v.fail(joinPath(path, name), "is not a known field")
+				continue
+			}
+		}
+		if topLevel && property != nil && value != nil && slices.Contains(schemaTypes(property), "array") {
+			value = arrayValue(value)
+		}
+		v.validate(joinPath(path, name), property, value)
+	}
+}
+
+// matchesAny reports whether value matches at least one of the alternatives
+func (v *inputValidator) matchesAny(path string, alternatives []*jsonschema.Schema, value interface{}) bool {
+	for _, alternative := range alternatives {
+		branch := &inputValidator{}
+		branch.validate(path, alternative, value)
+		if len(branch.errors) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// schemaTypes returns the types a schema allows
+func schemaTypes(schema *jsonschema.Schema) []string {
+	if schema.Type != "" {
+		return []string{schema.Type}
+	}
+	return schema.Types
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	for _, t := range types {
+		if matchesType(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(value interface{}, t string) bool {
+	switch t {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+		// Enums from YAML specs may hold ints where the input holds float64
+		if fmt.Sprint(v) == fmt.Sprint(value) && jsonType(value) == "number" {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(values []interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	return string(data)
+}
+
+// addValidatedTool registers a tool like mcp.AddTool, but leaves checking the
+// input to the handler. The SDK rejects invalid input with a protocol error
+// the model can't act on.
+func addValidatedTool(server *mcp.Server, tool *mcp.Tool, handler apiToolHandler) {
+	tt := *tool
+	if tt.OutputSchema == nil {
+		schema, err := jsonschema.For[APIToolOutput](&jsonschema.ForOptions{})
+		if err != nil {
+			panic(fmt.Errorf("AddTool %q: output schema: %w", tt.Name, err))
+		}
+		tt.OutputSchema = schema
+	}
+
+	resolved, err := tt.InputSchema.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
+	if err != nil {
+		panic(fmt.Errorf("AddTool %q: input schema: %w", tt.Name, err))
+	}
+
+	server.AddTool(&tt, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		input := APIToolInput{}
+		if len(req.Params.Arguments) > 0 {
+			if err := json.Unmarshal(req.Params.Arguments, &input); err != nil {
+				return nil, fmt.Errorf("unmarshaling: %w", err)
+			}
+			if input == nil {
+				input = APIToolInput{}
+			}
+		}
+
+		if err := resolved.ApplyDefaults(&input); err != nil {
+			return nil, fmt.Errorf("applying defaults: %w", err)
+		}
+
+		result, output, err := handler(ctx, req, input)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				IsError: true,
+			}, nil
+		}
+
+		if result == nil {
+			result = &mcp.CallToolResult{}
+		}
+		data, err := json.Marshal(output)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling output: %w", err)
+		}
+		result.StructuredContent = json.RawMessage(data)
+		if result.Content == nil {
+			result.Content = []mcp.Content{&mcp.TextContent{Text: string(data)}}
+		}
+		return result, nil
+	})
+}
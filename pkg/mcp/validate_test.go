@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestValidateInput(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"id":     {Type: "integer"},
+			"status": {Type: "string", Enum: []interface{}{"active", "archived"}},
+			"sku":    {Type: "string", Pattern: "^[A-Z]{3}-[0-9]+$"},
+			"tags":   {Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+			"note":   {Types: []string{"null", "string"}},
+			"labels": {Types: []string{"array", "null"}, Items: &jsonschema.Schema{Type: "string"}},
+			"lines": {
+				Type: "array",
+				Items: &jsonschema.Schema{
+					Type:       "object",
+					Required:   []string{"sku"},
+					Properties: map[string]*jsonschema.Schema{"sku": {Type: "string"}, "quantity": {Type: "integer"}},
+				},
+			},
+			"body": {
+				Type:     "object",
+				Required: []string{"name"},
+				Properties: map[string]*jsonschema.Schema{
+					"name":  {Type: "string"},
+					"sizes": {Type: "array", Items: &jsonschema.Schema{Type: "number"}},
+				},
+			},
+			"limits": {Type: "object", AdditionalProperties: &jsonschema.Schema{Type: "integer"}},
+			"strict": {
+				Type:                 "object",
+				Properties:           map[string]*jsonschema.Schema{"a": {Type: "string"}},
+				AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+			},
+		},
+		Required: []string{"id"},
+	}
+
+	tests := []struct {
+		name     string
+		input    APIToolInput
+		expected []string
+	}{
+		{
+			name:  "valid input",
+			input: APIToolInput{"id": float64(1), "status": "active", "sku": "ABC-12", "note": nil, "body": map[string]interface{}{"name": "x"}},
+		},
+		{
+			name:  "explicit null of a nullable array",
+			input: APIToolInput{"id": float64(1), "labels": nil},
+		},
+		{
+			name:  "nullable array given as a JSON string",
+			input: APIToolInput{"id": float64(1), "labels": `["a"]`},
+		},
+		{
+			name:  "array given as a JSON string",
+			input: APIToolInput{"id": float64(1), "tags": `["a", "b"]`},
+		},
+		{
+			name:  "array of objects given as a JSON string",
+			input: APIToolInput{"id": float64(1), "lines": `[{"sku": "A-1", "quantity": 2}]`},
+		},
+		{
+			name:  "invalid items of an array of objects",
+			input: APIToolInput{"id": float64(1), "lines": []interface{}{map[string]interface{}{"sku": "A-1"}, map[string]interface{}{"quantity": "two"}}},
+			expected: []string{
+				"lines[1].sku: is required",
+				"lines[1].quantity: expected integer, got string",
+			},
+		},
+		{
+			name:     "missing required field",
+			input:    APIToolInput{},
+			expected: []string{"id: is required"},
+		},
+		{
+			name:     "wrong type",
+			input:    APIToolInput{"id": "one"},
+			expected: []string{"id: expected integer, got string"},
+		},
+		{
+			name:     "fractional integer",
+			input:    APIToolInput{"id": 1.5},
+			expected: []string{"id: expected integer, got number"},
+		},
+		{
+			name:     "value outside enum",
+			input:    APIToolInput{"id": float64(1), "status": "deleted"},
+			expected: []string{`status: must be one of ["active","archived"]`},
+		},
+		{
+			name:     "pattern mismatch",
+			input:    APIToolInput{"id": float64(1), "sku": "abc"},
+			expected: []string{`sku: "abc" does not match pattern ^[A-Z]{3}-[0-9]+$`},
+		},
+		{
+			name:  "nested fields",
+			input: APIToolInput{"id": float64(1), "body": map[string]interface{}{"sizes": []interface{}{float64(1), "large"}}},
+			expected: []string{
+				"body.name: is required",
+				"body.sizes[1]: expected number, got string",
+			},
+		},
+		{
+			name:     "typed map values",
+			input:    APIToolInput{"id": float64(1), "limits": map[string]interface{}{"cpu": float64(2), "memory": "1Gi"}},
+			expected: []string{"limits.memory: expected integer, got string"},
+		},
+		{
+			name:     "field not allowed by additionalProperties",
+			input:    APIToolInput{"id": float64(1), "strict": map[string]interface{}{"a": "x", "b": "y"}},
+			expected: []string{"strict.b: is not a known field"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInput(schema, tt.input)
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Expected validation error")
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected %q in %q", expected, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidInputIsNotSentUpstream(t *testing.T) {
+	var calls int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer mockServer.Close()
+
+	tool := &EnrichedTool{
+		Tool: &mcp.Tool{
+			Name: "getUser",
+			InputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: map[string]*jsonschema.Schema{"id": {Type: "integer"}},
+				Required:   []string{"id"},
+			},
+		},
+		BaseUrl: mockServer.URL,
+		Method:  "get",
+		Path:    "/users/{id}",
+		Operation: &openapi.OpenAPI3Operation{
+			Op: &openapi3.Operation{OperationID: "getUser"},
+		},
+	}
+
+	handler := createAPIHandlerForTool(tool, &ToolOptions{})
+	_, output, err := handler(context.Background(), nil, APIToolInput{"id": "abc"})
+	if err != nil {
+		t.Fatalf("Handler execution failed: %v", err)
+	}
+
+	if output.Error != "Invalid input: id: expected integer, got string" {
+		t.Errorf("Expected a field-level error, got %q", output.Error)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no upstream request, got %d", calls)
+	}
+}
@@ -0,0 +1,243 @@
+// Package mock serves fake responses for the operations of a spec, so the
+// tools generated from it can be called without the real API
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+// maxSampleDepth bounds how deep nested and recursive schemas are sampled
+const maxSampleDepth = 8
+
+// pathParamRegex matches the parameters of a path template, e.g. {userId}
+var pathParamRegex = regexp.MustCompile(`\{[^}/]+\}`)
+
+// Route is an operation served by the mock
+type Route struct {
+	Method string
+	// Path is the path template of the operation, prefixed with the path of
+	// the spec's base URL
+	Path   string
+	Status int
+	// Body is the JSON response, nil when the operation declares none
+	Body interface{}
+
+	pattern *regexp.Regexp
+}
+
+// Handler answers the requests matching an operation of the spec with the
+// example of its successful response, or a value synthesized from the
+// response schema when there is no example
+type Handler struct {
+	basePath string
+	routes   []*Route
+}
+
+// NewHandler builds the routes of every operation of the spec
+func NewHandler(spec openapi.APISpec) (*Handler, error) {
+	basePath := ""
+	if base, err := url.Parse(spec.GetBaseURL()); err == nil {
+		basePath = strings.TrimRight(base.Path, "/")
+	}
+
+	h := &Handler{basePath: basePath}
+	for path, pathItem := range spec.GetPaths() {
+		for method, operation := range pathItem.GetOperations() {
+			if operation == nil {
+				continue
+			}
+
+			route, err := newRoute(method, basePath+path, operation)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", openapi.OperationLocation(method, path), err)
+			}
+			h.routes = append(h.routes, route)
+		}
+	}
+
+	// Literal segments take precedence over parameters, e.g. /users/me over
+	// /users/{id}
+	sort.Slice(h.routes, func(i, j int) bool {
+		a, b := h.routes[i], h.routes[j]
+		if countParams(a.Path) != countParams(b.Path) {
+			return countParams(a.Path) < countParams(b.Path)
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	return h, nil
+}
+
+func newRoute(method, path string, operation openapi.Operation) (*Route, error) {
+	segments := pathParamRegex.Split(path, -1)
+	for i, segment := range segments {
+		segments[i] = regexp.QuoteMeta(segment)
+	}
+
+	route := &Route{
+		Method:  strings.ToUpper(method),
+		Path:    path,
+		pattern: regexp.MustCompile("^" + strings.Join(segments, "[^/]+") + "/?$"),
+	}
+
+	status, example := operation.GetSuccessResponse()
+	route.Status = status
+	if route.Status == 0 {
+		route.Status = http.StatusOK
+	}
+
+	switch {
+	case example != nil:
+		route.Body = example
+	default:
+		schema, err := openapi.GenerateOutputSchema(operation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert response schema: %w", err)
+		}
+		if schema != nil {
+			route.Body = Sample(schema)
+		}
+	}
+	return route, nil
+}
+
+func countParams(path string) int {
+	return len(pathParamRegex.FindAllString(path, -1))
+}
+
+// BasePath returns the path of the spec's base URL the operations are served
+// under, e.g. /v1
+func (h *Handler) BasePath() string {
+	return h.basePath
+}
+
+// Routes returns the served operations, most specific paths first
+func (h *Handler) Routes() []*Route {
+	return h.routes
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pathMatched := false
+	for _, route := range h.routes {
+		if !route.pattern.MatchString(r.URL.Path) {
+			continue
+		}
+		pathMatched = true
+		if route.Method != r.Method {
+			continue
+		}
+
+		if route.Body == nil {
+			w.WriteHeader(route.Status)
+			return
+		}
+		writeJSON(w, route.Status, route.Body)
+		return
+	}
+
+	if pathMatched {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": fmt.Sprintf("no operation for %s %s", r.Method, r.URL.Path)})
+		return
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("no operation matches %s", r.URL.Path)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Sample synthesizes a value valid against a schema, preferring its
+// examples, default and enum values
+func Sample(schema *jsonschema.Schema) interface{} {
+	return sample(schema, 0)
+}
+
+func sample(schema *jsonschema.Schema, depth int) interface{} {
+	if schema == nil || depth > maxSampleDepth {
+		return nil
+	}
+
+	switch {
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Default != nil:
+		var value interface{}
+		if err := json.Unmarshal(schema.Default, &value); err == nil {
+			return value
+		}
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	typ := schema.Type
+	if typ == "" && len(schema.Types) > 0 {
+		typ = schema.Types[0]
+	}
+	if typ == "" && len(schema.Properties) > 0 {
+		typ = "object"
+	}
+
+	switch typ {
+	case "string":
+		return sampleString(schema.Format)
+	case "integer":
+		return 1
+	case "number":
+		return 1.5
+	case "boolean":
+		return true
+	case "array":
+		if item := sample(schema.Items, depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "object":
+		object := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			if value := sample(property, depth+1); value != nil {
+				object[name] = value
+			}
+		}
+		return object
+	default:
+		return nil
+	}
+}
+
+func sampleString(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "uuid":
+		return "00000000-0000-4000-8000-000000000000"
+	case "ipv4":
+		return "192.0.2.1"
+	case "ipv6":
+		return "2001:db8::1"
+	case "byte":
+		return "c3RyaW5n"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,147 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/kumolabai/kumoctl/pkg/openapi"
+)
+
+func TestHandler(t *testing.T) {
+	spec, err := openapi.LoadSpec([]byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Users API", "version": "1"},
+  "servers": [{"url": "https://api.example.com/v1"}],
+  "paths": {
+    "/users": {
+      "post": {
+        "operationId": "createUser",
+        "responses": {"201": {"description": "Created", "content": {"application/json": {
+          "schema": {"type": "object", "properties": {"id": {"type": "string"}}},
+          "example": {"id": "42"}
+        }}}}
+      }
+    },
+    "/users/me": {
+      "get": {
+        "operationId": "getMe",
+        "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {
+            "name": {"type": "string", "example": "Alice"},
+            "email": {"type": "string", "format": "email"},
+            "age": {"type": "integer"},
+            "role": {"type": "string", "enum": ["admin", "user"]},
+            "tags": {"type": "array", "items": {"type": "string"}}
+          }
+        }}}}}
+      }
+    },
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "string"}}}}}}}
+      },
+      "delete": {
+        "operationId": "deleteUser",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	handler, err := NewHandler(spec)
+	if err != nil {
+		t.Fatalf("Failed to build handler: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		expectStatus int
+		expectBody   interface{}
+	}{
+		{
+			name:         "response example",
+			method:       http.MethodPost,
+			path:         "/v1/users",
+			expectStatus: http.StatusCreated,
+			expectBody:   map[string]interface{}{"id": "42"},
+		},
+		{
+			name:         "literal path before parameter",
+			method:       http.MethodGet,
+			path:         "/v1/users/me",
+			expectStatus: http.StatusOK,
+			expectBody: map[string]interface{}{
+				"name":  "Alice",
+				"email": "user@example.com",
+				"age":   float64(1),
+				"role":  "admin",
+				"tags":  []interface{}{"string"},
+			},
+		},
+		{
+			name:         "path parameter",
+			method:       http.MethodGet,
+			path:         "/v1/users/7",
+			expectStatus: http.StatusOK,
+			expectBody:   map[string]interface{}{"id": "string"},
+		},
+		{
+			name:         "no content",
+			method:       http.MethodDelete,
+			path:         "/v1/users/7",
+			expectStatus: http.StatusNoContent,
+		},
+		{
+			name:         "method not allowed",
+			method:       http.MethodPut,
+			path:         "/v1/users/7",
+			expectStatus: http.StatusMethodNotAllowed,
+			expectBody:   map[string]interface{}{"error": "no operation for PUT /v1/users/7"},
+		},
+		{
+			name:         "outside the base path",
+			method:       http.MethodGet,
+			path:         "/users/7",
+			expectStatus: http.StatusNotFound,
+			expectBody:   map[string]interface{}{"error": "no operation matches /users/7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.expectStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+
+			if tt.expectBody == nil {
+				if rec.Body.Len() != 0 {
+					t.Errorf("Expected no body, got %s", rec.Body.String())
+				}
+				return
+			}
+
+			var body interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode body %q: %v", rec.Body.String(), err)
+			}
+			if !reflect.DeepEqual(body, tt.expectBody) {
+				t.Errorf("Expected body %v, got %v", tt.expectBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,507 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// parseAsyncAPI decodes a JSON or YAML AsyncAPI document. It returns false
+// when data isn't one.
+func parseAsyncAPI(data []byte) (map[string]interface{}, bool) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, false
+	}
+
+	// Round-trip through JSON so numbers and nested maps have JSON types
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(normalized, &doc); err != nil {
+		return nil, false
+	}
+
+	if _, ok := doc["asyncapi"].(string); !ok {
+		return nil, false
+	}
+	return doc, true
+}
+
+// IsAsyncAPI reports whether data holds an AsyncAPI document rather than an
+// OpenAPI spec
+func IsAsyncAPI(data []byte) bool {
+	_, ok := parseAsyncAPI(data)
+	return ok
+}
+
+// LoadAsyncAPI converts the operations of an AsyncAPI 2 or 3 document that
+// clients send messages to into an OpenAPI 3 spec: publish operations in
+// AsyncAPI 2, receive operations in AsyncAPI 3. Only operations reachable
+// over HTTP are kept, through an http or https server or an HTTP binding.
+// They are sent to the channel address with the binding's method, POST by
+// default, and the message payload as JSON body.
+func LoadAsyncAPI(data []byte) (APISpec, error) {
+	doc, ok := parseAsyncAPI(data)
+	if !ok {
+		return nil, fmt.Errorf("unsupported or invalid AsyncAPI document")
+	}
+
+	version := doc["asyncapi"].(string)
+	r := &asyncAPIResolver{root: doc, resolving: make(map[string]bool)}
+
+	info := asyncMap(doc["info"])
+	spec := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       asyncString(info["title"]),
+			Version:     asyncString(info["version"]),
+			Description: asyncString(info["description"]),
+		},
+		Paths: openapi3.NewPaths(),
+	}
+
+	serverURL := asyncHTTPServer(asyncMap(r.resolve(doc["servers"])), strings.HasPrefix(version, "2."))
+	if serverURL != "" {
+		spec.Servers = openapi3.Servers{{URL: serverURL}}
+	}
+
+	var operations []asyncOperation
+	switch {
+	case strings.HasPrefix(version, "2."):
+		operations = asyncOperations2(r, asyncMap(doc["channels"]))
+	case strings.HasPrefix(version, "3."):
+		operations = asyncOperations3(r, asyncMap(doc["operations"]))
+	default:
+		return nil, fmt.Errorf("unsupported AsyncAPI version %s, expected 2.x or 3.x", version)
+	}
+
+	tags := make(map[string]bool)
+	for _, op := range operations {
+		if serverURL == "" && op.binding == nil {
+			continue
+		}
+
+		method := http.MethodPost
+		if m := asyncString(op.binding["method"]); m != "" {
+			method = strings.ToUpper(m)
+		}
+
+		operation, err := op.openAPIOperation(method)
+		if err != nil {
+			return nil, fmt.Errorf("operation %s: %w", op.name(), err)
+		}
+		for _, tag := range operation.Tags {
+			tags[tag] = true
+		}
+
+		pathItem := spec.Paths.Value(op.path)
+		if pathItem == nil {
+			pathItem = &openapi3.PathItem{}
+			spec.Paths.Set(op.path, pathItem)
+		}
+		if pathItem.GetOperation(method) != nil {
+			return nil, fmt.Errorf("operation %s: %s %s is already used by another operation", op.name(), method, op.path)
+		}
+		pathItem.SetOperation(method, operation)
+	}
+
+	if spec.Paths.Len() == 0 {
+		return nil, fmt.Errorf("AsyncAPI document has no operations reachable over HTTP, expected an http or https server or HTTP bindings")
+	}
+
+	descriptions := make(map[string]string)
+	for _, tag := range asyncList(r.resolve(doc["tags"])) {
+		t := asyncMap(tag)
+		descriptions[asyncString(t["name"])] = asyncString(t["description"])
+	}
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec.Tags = append(spec.Tags, &openapi3.Tag{Name: name, Description: descriptions[name]})
+	}
+
+	return &OpenAPI3Spec{spec: spec}, nil
+}
+
+// asyncOperation is an operation clients send messages to
+type asyncOperation struct {
+	id          string
+	path        string
+	summary     string
+	description string
+	tags        []string
+	parameters  map[string]interface{}
+	messages    []map[string]interface{}
+	binding     map[string]interface{}
+}
+
+func (op *asyncOperation) name() string {
+	if op.id != "" {
+		return op.id
+	}
+	return op.path
+}
+
+// asyncOperations2 returns the publish operations of AsyncAPI 2 channels
+func asyncOperations2(r *asyncAPIResolver, channels map[string]interface{}) []asyncOperation {
+	var operations []asyncOperation
+	for _, address := range sortedKeys(channels) {
+		channel := asyncMap(r.resolve(channels[address]))
+		publish := asyncMap(channel["publish"])
+		if publish == nil {
+			continue
+		}
+
+		message := asyncMap(r.resolve(publish["message"]))
+		messages := []map[string]interface{}{message}
+		if oneOf := asyncList(message["oneOf"]); oneOf != nil {
+			messages = nil
+			for _, m := range oneOf {
+				messages = append(messages, asyncMap(m))
+			}
+		}
+
+		// AsyncAPI 2 channel parameters hold a schema, AsyncAPI 3 ones are
+		// the schema
+		parameters := make(map[string]interface{})
+		for name, param := range asyncMap(r.resolve(channel["parameters"])) {
+			parameters[name] = asyncMap(param)["schema"]
+		}
+
+		description := asyncString(publish["description"])
+		if description == "" {
+			description = asyncString(channel["description"])
+		}
+
+		operations = append(operations, asyncOperation{
+			id:          asyncString(publish["operationId"]),
+			path:        channelPath(address),
+			summary:     asyncString(publish["summary"]),
+			description: description,
+			tags:        asyncTags(r.resolve(publish["tags"])),
+			parameters:  parameters,
+			messages:    messages,
+			binding:     asyncMap(asyncMap(r.resolve(publish["bindings"]))["http"]),
+		})
+	}
+	return operations
+}
+
+// asyncOperations3 returns the receive operations of an AsyncAPI 3 document
+func asyncOperations3(r *asyncAPIResolver, ops map[string]interface{}) []asyncOperation {
+	var operations []asyncOperation
+	for _, id := range sortedKeys(ops) {
+		op := asyncMap(r.resolve(ops[id]))
+		if asyncString(op["action"]) != "receive" {
+			continue
+		}
+
+		channel := asyncMap(op["channel"])
+		address := asyncString(channel["address"])
+		if address == "" {
+			continue
+		}
+
+		var messages []map[string]interface{}
+		for _, m := range asyncList(op["messages"]) {
+			messages = append(messages, asyncMap(m))
+		}
+		if len(messages) == 0 {
+			channelMessages := asyncMap(channel["messages"])
+			for _, name := range sortedKeys(channelMessages) {
+				messages = append(messages, asyncMap(channelMessages[name]))
+			}
+		}
+
+		parameters := make(map[string]interface{})
+		for name, param := range asyncMap(channel["parameters"]) {
+			schema := map[string]interface{}{"type": "string"}
+			for _, key := range []string{"description", "enum", "default"} {
+				if value, ok := asyncMap(param)[key]; ok {
+					schema[key] = value
+				}
+			}
+			parameters[name] = schema
+		}
+
+		description := asyncString(op["description"])
+		if description == "" {
+			description = asyncString(channel["description"])
+		}
+
+		operations = append(operations, asyncOperation{
+			id:          id,
+			path:        channelPath(address),
+			summary:     asyncString(op["summary"]),
+			description: description,
+			tags:        asyncTags(op["tags"]),
+			parameters:  parameters,
+			messages:    messages,
+			binding:     asyncMap(asyncMap(op["bindings"])["http"]),
+		})
+	}
+	return operations
+}
+
+// openAPIOperation builds the HTTP operation sending the messages of op. The
+// body accepts the properties of every message, requiring those all of them
+// require.
+func (op *asyncOperation) openAPIOperation(method string) (*openapi3.Operation, error) {
+	operation := &openapi3.Operation{
+		OperationID: op.id,
+		Summary:     op.summary,
+		Description: op.description,
+		Tags:        op.tags,
+		Responses:   openapi3.NewResponses(),
+	}
+
+	for _, match := range routeParamRegex.FindAllStringSubmatch(op.path, -1) {
+		schema, err := asyncSchema(op.parameters[match[1]])
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", match[1], err)
+		}
+		param := openapi3.NewPathParameter(match[1]).WithSchema(schema)
+		param.Description = schema.Description
+		operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{Value: param})
+	}
+
+	query, err := asyncSchema(op.binding["query"])
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	operation.Parameters = append(operation.Parameters, objectParameters(query, openapi3.ParameterInQuery)...)
+
+	body := openapi3.NewObjectSchema()
+	required := make(map[string]int)
+	for _, message := range op.messages {
+		headers, err := asyncSchema(message["headers"])
+		if err != nil {
+			return nil, fmt.Errorf("message headers: %w", err)
+		}
+		for _, param := range objectParameters(headers, openapi3.ParameterInHeader) {
+			if !hasParameter(operation.Parameters, param.Value.Name, openapi3.ParameterInHeader) {
+				param.Value.Required = param.Value.Required && len(op.messages) == 1
+				operation.Parameters = append(operation.Parameters, param)
+			}
+		}
+
+		payload, err := asyncSchema(message["payload"])
+		if err != nil {
+			return nil, fmt.Errorf("message payload: %w", err)
+		}
+		for name, property := range payload.Properties {
+			body.WithPropertyRef(name, property)
+		}
+		for _, name := range payload.Required {
+			required[name]++
+		}
+	}
+
+	if len(body.Properties) == 0 || method == http.MethodGet || method == http.MethodDelete {
+		return operation, nil
+	}
+
+	for name, count := range required {
+		if count == len(op.messages) {
+			body.Required = append(body.Required, name)
+		}
+	}
+	sort.Strings(body.Required)
+	operation.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(len(body.Required) > 0).WithJSONSchema(body)}
+
+	return operation, nil
+}
+
+// objectParameters turns the properties of an object schema into parameters
+func objectParameters(schema *openapi3.Schema, in string) openapi3.Parameters {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	var params openapi3.Parameters
+	for _, name := range sortedKeys(schema.Properties) {
+		property := propertySchema(schema, name)
+		param := &openapi3.Parameter{Name: name, In: in, Required: required[name], Description: property.Description}
+		param.WithSchema(property)
+		params = append(params, &openapi3.ParameterRef{Value: param})
+	}
+	return params
+}
+
+func hasParameter(params openapi3.Parameters, name, in string) bool {
+	for _, param := range params {
+		if param.Value.Name == name && param.Value.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+// asyncSchema converts a resolved AsyncAPI schema, unwrapping multi-format
+// schemas, into an OpenAPI schema. A missing schema is a string.
+func asyncSchema(value interface{}) (*openapi3.Schema, error) {
+	raw := asyncMap(value)
+	if raw == nil {
+		return openapi3.NewStringSchema(), nil
+	}
+	if _, ok := raw["schemaFormat"]; ok {
+		raw = asyncMap(raw["schema"])
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	schema := &openapi3.Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return schema, nil
+}
+
+// asyncHTTPServer returns the URL of the first server, by name, reachable
+// over HTTP. AsyncAPI 2 servers have a url, AsyncAPI 3 ones a host and
+// pathname. Server variables are replaced with their default.
+func asyncHTTPServer(servers map[string]interface{}, v2 bool) string {
+	for _, name := range sortedKeys(servers) {
+		server := asyncMap(servers[name])
+		protocol := strings.ToLower(asyncString(server["protocol"]))
+		if protocol != "http" && protocol != "https" {
+			continue
+		}
+
+		var url string
+		if v2 {
+			url = asyncString(server["url"])
+		} else {
+			url = asyncString(server["host"]) + asyncString(server["pathname"])
+		}
+		if !strings.Contains(url, "://") {
+			url = protocol + "://" + url
+		}
+
+		for variable, definition := range asyncMap(server["variables"]) {
+			url = strings.ReplaceAll(url, "{"+variable+"}", asyncString(asyncMap(definition)["default"]))
+		}
+		return strings.TrimSuffix(url, "/")
+	}
+	return ""
+}
+
+// channelPath turns a channel address into an HTTP path
+func channelPath(address string) string {
+	return "/" + strings.TrimPrefix(address, "/")
+}
+
+func asyncTags(value interface{}) []string {
+	var tags []string
+	for _, tag := range asyncList(value) {
+		if name := asyncString(asyncMap(tag)["name"]); name != "" {
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+func asyncMap(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
+
+func asyncList(value interface{}) []interface{} {
+	l, _ := value.([]interface{})
+	return l
+}
+
+func asyncString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// asyncAPIResolver inlines the local $refs of an AsyncAPI document.
+// Recursive references are replaced with an empty, free-form schema.
+type asyncAPIResolver struct {
+	root      map[string]interface{}
+	resolving map[string]bool
+}
+
+func (r *asyncAPIResolver) resolve(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if r.resolving[ref] {
+				return map[string]interface{}{}
+			}
+			target := r.lookup(ref)
+			if target == nil {
+				return map[string]interface{}{}
+			}
+			r.resolving[ref] = true
+			resolved := r.resolve(target)
+			delete(r.resolving, ref)
+			return resolved
+		}
+
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved[key] = r.resolve(item)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = r.resolve(item)
+		}
+		return resolved
+	default:
+		return value
+	}
+}
+
+// lookup follows a local JSON pointer such as #/components/messages/UserSignedUp
+func (r *asyncAPIResolver) lookup(ref string) interface{} {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil
+	}
+
+	var value interface{} = r.root
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"sort"
+	"testing"
+)
+
+const asyncAPI2 = `
+asyncapi: '2.6.0'
+info:
+  title: Signup Events
+  version: '1.0.0'
+servers:
+  broker:
+    url: kafka.example.com:9092
+    protocol: kafka
+  webhooks:
+    url: '{env}.example.com/hooks'
+    protocol: https
+    variables:
+      env:
+        default: api
+tags:
+  - name: users
+    description: User lifecycle events
+channels:
+  users/{userId}/signedup:
+    parameters:
+      userId:
+        description: Id of the user
+        schema:
+          type: string
+    publish:
+      operationId: userSignedUp
+      summary: Report a signup
+      tags:
+        - name: users
+      message:
+        $ref: '#/components/messages/UserSignedUp'
+  users/deleted:
+    subscribe:
+      operationId: onUserDeleted
+      message:
+        payload:
+          type: object
+components:
+  messages:
+    UserSignedUp:
+      headers:
+        type: object
+        properties:
+          X-Correlation-Id:
+            type: string
+      payload:
+        $ref: '#/components/schemas/Signup'
+  schemas:
+    Signup:
+      type: object
+      required: [email]
+      properties:
+        email:
+          type: string
+        referrer:
+          $ref: '#/components/schemas/Signup'
+`
+
+const asyncAPI3 = `{
+  "asyncapi": "3.0.0",
+  "info": {"title": "Orders", "version": "2.0.0"},
+  "channels": {
+    "orders": {
+      "address": "orders",
+      "messages": {
+        "created": {"payload": {"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "total": {"type": "number"}}}},
+        "cancelled": {"payload": {"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "reason": {"type": "string"}}}}
+      }
+    }
+  },
+  "operations": {
+    "submitOrder": {
+      "action": "receive",
+      "channel": {"$ref": "#/channels/orders"},
+      "bindings": {"http": {"method": "PUT", "query": {"type": "object", "properties": {"dryRun": {"type": "boolean"}}}}}
+    },
+    "notifyOrder": {
+      "action": "send",
+      "channel": {"$ref": "#/channels/orders"}
+    }
+  }
+}`
+
+func TestLoadAsyncAPI(t *testing.T) {
+	t.Run("AsyncAPI 2 publish operations", func(t *testing.T) {
+		if !IsAsyncAPI([]byte(asyncAPI2)) {
+			t.Fatal("Expected an AsyncAPI document")
+		}
+
+		spec, err := LoadAsyncAPI([]byte(asyncAPI2))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if spec.GetBaseURL() != "https://api.example.com/hooks" {
+			t.Errorf("Expected the https server, got %s", spec.GetBaseURL())
+		}
+		if len(spec.GetPaths()) != 1 {
+			t.Fatalf("Expected only the publish operation, got %v", spec.GetPaths())
+		}
+
+		op := spec.GetPaths()["/users/{userId}/signedup"].GetOperations()["post"]
+		if op == nil || op.GetOperationID() != "userSignedUp" || op.GetSummary() != "Report a signup" {
+			t.Fatalf("Expected userSignedUp at POST /users/{userId}/signedup, got %v", spec.GetPaths())
+		}
+
+		params := map[string]string{}
+		for _, param := range op.GetParameters() {
+			params[param.GetName()] = param.GetIn()
+		}
+		if params["userId"] != "path" || params["X-Correlation-Id"] != "header" {
+			t.Errorf("Expected path and header parameters, got %v", params)
+		}
+
+		schema, err := op.GetRequestBody().GetJSONSchema()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := schema.GetProperties()["referrer"]; !ok || len(schema.GetRequired()) != 1 {
+			t.Errorf("Expected the recursive payload schema, got %v", schema.GetProperties())
+		}
+
+		tags := spec.GetTags()
+		if len(tags) != 1 || tags[0].Description != "User lifecycle events" {
+			t.Errorf("Expected the users tag, got %v", tags)
+		}
+	})
+
+	t.Run("AsyncAPI 3 receive operations", func(t *testing.T) {
+		spec, err := LoadAsyncAPI([]byte(asyncAPI3))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		op := spec.GetPaths()["/orders"].GetOperations()["put"]
+		if op == nil || op.GetOperationID() != "submitOrder" {
+			t.Fatalf("Expected submitOrder at PUT /orders, got %v", spec.GetPaths())
+		}
+		if len(spec.GetPaths()["/orders"].GetOperations()) != 1 {
+			t.Errorf("Expected send operations to be skipped")
+		}
+
+		params := op.GetParameters()
+		if len(params) != 1 || params[0].GetName() != "dryRun" || params[0].GetIn() != "query" {
+			t.Errorf("Expected the dryRun query parameter, got %v", params)
+		}
+
+		schema, err := op.GetRequestBody().GetJSONSchema()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var properties []string
+		for name := range schema.GetProperties() {
+			properties = append(properties, name)
+		}
+		sort.Strings(properties)
+		if len(properties) != 3 || len(schema.GetRequired()) != 1 || schema.GetRequired()[0] != "id" {
+			t.Errorf("Expected the merged payloads requiring id, got %v required %v", properties, schema.GetRequired())
+		}
+	})
+
+	t.Run("no HTTP server or binding", func(t *testing.T) {
+		doc := `{"asyncapi": "2.6.0", "info": {"title": "Kafka", "version": "1"}, "servers": {"broker": {"url": "kafka:9092", "protocol": "kafka"}}, "channels": {"events": {"publish": {"message": {"payload": {"type": "object"}}}}}}`
+		if _, err := LoadAsyncAPI([]byte(doc)); err == nil {
+			t.Error("Expected an error without HTTP operations")
+		}
+	})
+
+	t.Run("OpenAPI is not AsyncAPI", func(t *testing.T) {
+		if IsAsyncAPI([]byte(`{"openapi": "3.0.0", "info": {"title": "API", "version": "1"}, "paths": {}}`)) {
+			t.Error("Expected an OpenAPI spec not to be detected as AsyncAPI")
+		}
+	})
+}
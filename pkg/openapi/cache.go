@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheDir returns ~/.cache/kumoctl/specs
+func DefaultCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "kumoctl", "specs"), nil
+}
+
+// cachedSpec is the last downloaded copy of a remote spec, with the
+// validators used to revalidate it
+type cachedSpec struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+
+	data []byte
+}
+
+// specCachePaths returns the metadata and document files caching url
+func specCachePaths(dir, url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(dir, key+".json"), filepath.Join(dir, key+".spec")
+}
+
+// readCachedSpec returns the cached copy of url, nil when there is none
+func readCachedSpec(dir, url string) *cachedSpec {
+	if dir == "" {
+		return nil
+	}
+
+	metaPath, dataPath := specCachePaths(dir, url)
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil
+	}
+
+	var cached cachedSpec
+	if err := json.Unmarshal(meta, &cached); err != nil || cached.URL != url {
+		return nil
+	}
+
+	cached.data, err = os.ReadFile(dataPath)
+	if err != nil {
+		return nil
+	}
+	return &cached
+}
+
+// writeCachedSpec stores a downloaded spec with the validators of the response
+func writeCachedSpec(dir, url string, data []byte, header http.Header) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(cachedSpec{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		FetchedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// The document is written first so the metadata never points at a
+	// partial copy
+	metaPath, dataPath := specCachePaths(dir, url)
+	if err := writeFileAtomic(dataPath, data); err != nil {
+		return err
+	}
+	return writeFileAtomic(metaPath, meta)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// isTemporaryStatus reports whether a status means the spec may be available
+// again later
+func isTemporaryStatus(status int) bool {
+	return status >= 500 || status == http.StatusTooManyRequests
+}
@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSpecCache(t *testing.T) {
+	const document = `{"openapi": "3.0.0", "info": {"title": "Cached API", "version": "1.0.0"}, "paths": {}}`
+
+	var status int
+	var conditional string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = r.Header.Get("If-None-Match")
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		if conditional == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(document))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	opts := &LoadOptions{
+		CacheDir: t.TempDir(),
+		Logger:   slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn})),
+	}
+
+	load := func() (APISpec, error) {
+		return LoadSpecFromSourceWithOptions(server.URL, opts)
+	}
+
+	if _, err := load(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if conditional != "" {
+		t.Errorf("Expected an unconditional first request, got If-None-Match %s", conditional)
+	}
+
+	// The cached copy is revalidated with its ETag
+	spec, err := load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if conditional != `"v1"` {
+		t.Errorf("Expected If-None-Match \"v1\", got %q", conditional)
+	}
+	if spec.GetInfo().Title != "Cached API" {
+		t.Errorf("Expected the cached spec, got %q", spec.GetInfo().Title)
+	}
+
+	// Server errors fall back to the cached copy
+	status = http.StatusServiceUnavailable
+	spec, err = load()
+	if err != nil {
+		t.Fatalf("Expected the cached copy, got %v", err)
+	}
+	if spec.GetInfo().Title != "Cached API" {
+		t.Errorf("Expected the cached spec, got %q", spec.GetInfo().Title)
+	}
+	if strings.Count(logs.String(), "\n") != 1 || !strings.Contains(logs.String(), "cached") {
+		t.Errorf("Expected the fallback to be logged, got %q", logs.String())
+	}
+
+	// Client errors such as bad credentials are not hidden by the cache
+	status = http.StatusUnauthorized
+	if _, err := load(); err == nil {
+		t.Error("Expected an error for status 401")
+	}
+
+	// The server being down entirely also falls back
+	server.Close()
+	status = 0
+	if _, err := load(); err != nil {
+		t.Errorf("Expected the cached copy while offline, got %v", err)
+	}
+
+	// Without a cached copy the download error is returned
+	opts.CacheDir = t.TempDir()
+	if _, err := load(); err == nil {
+		t.Error("Expected an error without a cached copy")
+	}
+}
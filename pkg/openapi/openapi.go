@@ -1,12 +1,19 @@
 package openapi
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi2"
 	"github.com/getkin/kin-openapi/openapi3"
@@ -18,21 +25,175 @@ import (
 type APISpec interface {
 	GetVersion() string
 	GetBaseURL() string
+	GetServers() []Server
 	GetPaths() map[string]PathItem
 	GetInfo() openapi3.Info
+	GetSecuritySchemes() map[string]SecurityScheme
+	GetSecurity() []SecurityRequirement
+	GetTags() []Tag
+	// GetExtensions returns the vendor extensions at the top level of the spec
+	GetExtensions() map[string]interface{}
+}
+
+// Tag groups operations, as declared at the top level of the spec
+type Tag struct {
+	Name        string
+	Description string
+}
+
+// Response describes a response an operation may return
+type Response struct {
+	// Status is a status code such as 404, a range such as 4XX, or default
+	Status      string
+	Description string
+}
+
+// Link describes an operation that can follow another one, using values
+// from its response
+type Link struct {
+	Name        string
+	OperationID string
+	Description string
+	// Parameters maps the parameters of the linked operation to runtime
+	// expressions such as $response.body#/id
+	Parameters map[string]interface{}
+}
+
+// Server is a base URL the API is served from
+type Server struct {
+	URL         string
+	Description string
+	// Variables are the placeholders of the URL, keyed by name
+	Variables map[string]ServerVariable
+}
+
+// ServerVariable is a placeholder in a server URL such as {region}
+type ServerVariable struct {
+	Default     string
+	Enum        []string
+	Description string
+}
+
+var serverVariableRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// ResolveURL substitutes the declared variables of the server URL with values,
+// falling back to their defaults. Placeholders without a declared variable are
+// left untouched.
+func (s Server) ResolveURL(values map[string]string) (string, error) {
+	for name := range values {
+		if _, ok := s.Variables[name]; !ok {
+			return "", fmt.Errorf("server variable %s is not declared by %s", name, s.URL)
+		}
+	}
+
+	var resolveErr error
+	resolved := serverVariableRegex.ReplaceAllStringFunc(s.URL, func(match string) string {
+		name := match[1 : len(match)-1]
+		variable, ok := s.Variables[name]
+		if !ok || resolveErr != nil {
+			return match
+		}
+
+		value, ok := values[name]
+		if !ok {
+			return variable.Default
+		}
+
+		if len(variable.Enum) > 0 && !slices.Contains(variable.Enum, value) {
+			resolveErr = fmt.Errorf("invalid value for server variable %s: %s (expected one of %s)", name, value, strings.Join(variable.Enum, ", "))
+			return match
+		}
+		return value
+	})
+
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// SecurityScheme describes how an API expects to receive credentials
+type SecurityScheme struct {
+	// Type is apiKey, http, oauth2, openIdConnect or basic
+	Type string
+	// In is where an apiKey is sent: query, header or cookie
+	In string
+	// Name is the query parameter, header or cookie carrying an apiKey
+	Name string
+	// TokenURL is the token endpoint of an oauth2 scheme, preferring the
+	// client credentials flow
+	TokenURL string
+}
+
+// SecurityRequirement names the security schemes that together authorize a
+// request, with the scopes required of each. An empty requirement makes
+// authorization optional.
+type SecurityRequirement map[string][]string
+
+// EffectiveSecurity returns the security requirements of an operation, which
+// replace the spec's when declared. An empty list means the operation needs no
+// credentials, nil that neither the operation nor the spec declares any.
+func EffectiveSecurity(spec APISpec, operation Operation) []SecurityRequirement {
+	if security := operation.GetSecurity(); security != nil {
+		return security
+	}
+	return spec.GetSecurity()
+}
+
+// convertSecurity converts security requirements, keeping nil apart from an
+// empty list
+func convertSecurity(requirements []map[string][]string) []SecurityRequirement {
+	if requirements == nil {
+		return nil
+	}
+
+	converted := make([]SecurityRequirement, 0, len(requirements))
+	for _, requirement := range requirements {
+		converted = append(converted, SecurityRequirement(requirement))
+	}
+	return converted
 }
 
 // PathItem represents a path item that can contain operations
 type PathItem interface {
 	GetOperations() map[string]Operation
+	// GetExtensions returns the vendor extensions of the path, shared by all
+	// its operations
+	GetExtensions() map[string]interface{}
 }
 
 // Operation represents an API operation
 type Operation interface {
 	GetOperationID() string
 	GetSummary() string
+	GetDescription() string
+	GetTags() []string
+	// IsDeprecated reports whether the operation is marked deprecated
+	IsDeprecated() bool
+	// GetLinks returns the operations that can follow this one, sorted by name
+	GetLinks() []Link
+	GetServers() []Server
+	GetSecurity() []SecurityRequirement
 	GetParameters() []Parameter
 	GetRequestBody() RequestBody
+	// GetResponseSchema returns the JSON schema of the first successful
+	// response that declares one, nil if none does
+	GetResponseSchema() (Schema, error)
+	// GetSuccessResponse returns the status code of the first successful
+	// response, 0 when none is declared, and its JSON example if it has one
+	GetSuccessResponse() (int, interface{})
+	// GetResponses returns the declared responses sorted by status, with the
+	// default response last
+	GetResponses() []Response
+	// GetExternalDocs returns the URL of the operation's external
+	// documentation, empty when it has none
+	GetExternalDocs() string
+	// GetConsumes returns the media types the operation accepts, from consumes
+	// in OpenAPI 2.0 or the content of the request body in OpenAPI 3
+	GetConsumes() []string
+	// GetExtensions returns the vendor extensions of the operation, keyed by
+	// name such as x-mcp-name
+	GetExtensions() map[string]interface{}
 }
 
 // Parameter represents an API parameter
@@ -44,6 +205,14 @@ type Parameter interface {
 	GetType() string
 	GetFormat() string
 	GetSchema() Schema
+	// GetItems returns the schema of the elements of an array parameter
+	GetItems() Schema
+	// GetStyle returns how array and object values are serialized, e.g. form
+	// or deepObject, defaulting by location
+	GetStyle() string
+	GetExplode() bool
+	// GetEnum returns the values the parameter is restricted to, if any
+	GetEnum() []interface{}
 }
 
 // RequestBody represents a request body
@@ -61,41 +230,162 @@ type Schema interface {
 	GetRequired() []string
 	GetEnum() []interface{}
 	GetDefault() interface{}
+	GetExample() interface{}
+	// GetAdditionalProperties returns the schema of the properties an object
+	// doesn't declare, and whether they are allowed when the schema says so
+	GetAdditionalProperties() (Schema, *bool)
+	// IsNullable reports whether null is allowed besides the type, through
+	// nullable or x-nullable in OpenAPI 2.0
+	IsNullable() bool
+	// GetAnyOf returns the alternatives of anyOf or oneOf, if any
+	GetAnyOf() []Schema
+}
+
+// LoadOptions configures how specs are loaded from remote sources
+type LoadOptions struct {
+	// HTTPClient fetches remote specs, nil uses http.DefaultClient
+	HTTPClient *http.Client
+	// Headers are sent when fetching remote specs, e.g. for authentication
+	Headers http.Header
+	// CacheDir keeps the last downloaded copy of remote specs, revalidated
+	// with ETag and Last-Modified and used when the URL is unreachable. Empty
+	// disables caching.
+	CacheDir string
+	// Logger reports loaded specs and stale cached copies being used, nil
+	// discards them
+	Logger *slog.Logger
+	// ToolDefinitions maps function-calling tool definitions loaded instead
+	// of a spec to HTTP operations
+	ToolDefinitions ToolDefinitionOptions
 }
 
 // LoadSpecFromSource loads an OpenAPI spec from either a file path or URL
 func LoadSpecFromSource(source string) (APISpec, error) {
-	var data []byte
-	var err error
+	return LoadSpecFromSourceWithOptions(source, nil)
+}
+
+// LoadSpecFromSourceWithOptions loads an OpenAPI spec from either a file path
+// or URL using the given options. AsyncAPI documents and OpenAI or Anthropic
+// tool definitions are converted into a spec.
+func LoadSpecFromSourceWithOptions(source string, opts *LoadOptions) (APISpec, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+
+	start := time.Now()
+	data, err := ReadSource(source, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec APISpec
+	switch {
+	case IsAsyncAPI(data):
+		spec, err = LoadAsyncAPI(data)
+	case IsToolDefinitions(data):
+		spec, err = LoadToolDefinitions(data, opts.ToolDefinitions)
+	default:
+		spec, err = LoadSpec(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opts.logger().Info("loaded spec", "source", source, "title", spec.GetInfo().Title,
+		"paths", len(spec.GetPaths()), "duration", time.Since(start))
+	return spec, nil
+}
+
+// ReadSource reads the document at a file path or URL without parsing it
+func ReadSource(source string, opts *LoadOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
 
 	// Check if source is a URL
 	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
-		data, err = fetchFromURL(source)
+		data, err := fetchFromURL(source, opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch from URL: %w", err)
 		}
-	} else {
-		data, err = os.ReadFile(source)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read file: %w", err)
-		}
+		return data, nil
 	}
 
-	return LoadSpec(data)
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, nil
 }
 
-func fetchFromURL(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+func fetchFromURL(url string, opts *LoadOptions) ([]byte, error) {
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	for key, values := range opts.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	cached := readCachedSpec(opts.CacheDir, url)
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return cachedFallback(cached, err, opts)
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.data, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
+		err := fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
+		if isTemporaryStatus(resp.StatusCode) {
+			return cachedFallback(cached, err, opts)
+		}
+		return nil, err
 	}
 
-	return io.ReadAll(resp.Body)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return cachedFallback(cached, err, opts)
+	}
+
+	if err := writeCachedSpec(opts.CacheDir, url, data, resp.Header); err != nil {
+		opts.logger().Warn("failed to cache spec", "url", url, "error", err)
+	}
+	return data, nil
+}
+
+// cachedFallback returns the cached copy of a spec that couldn't be downloaded
+func cachedFallback(cached *cachedSpec, err error, opts *LoadOptions) ([]byte, error) {
+	if cached == nil {
+		return nil, err
+	}
+	opts.logger().Warn("using cached copy of spec", "url", cached.URL, "cached_at", cached.FetchedAt.Local().Format(time.RFC3339), "error", err)
+	return cached.data, nil
+}
+
+func (opts *LoadOptions) logger() *slog.Logger {
+	if opts.Logger != nil {
+		return opts.Logger
+	}
+	return slog.New(slog.DiscardHandler)
 }
 
 func LoadSpec(data []byte) (APISpec, error) {
@@ -108,6 +398,16 @@ func LoadSpec(data []byte) (APISpec, error) {
 		if err := spec.Validate(loader.Context); err == nil {
 			return &OpenAPI3Spec{spec: spec}, nil
 		}
+
+		// The null type, as in anyOf: [X, {type: null}], is read as nullable,
+		// which kin-openapi validates
+		if normalized, ok := nullTypesAsNullable(data); ok {
+			loader := openapi3.NewLoader()
+			loader.IsExternalRefsAllowed = true
+			if spec, err := loader.LoadFromData(normalized); err == nil && spec.Validate(loader.Context) == nil {
+				return &OpenAPI3Spec{spec: spec}, nil
+			}
+		}
 	}
 
 	// Fallback to OpenAPI 2
@@ -155,6 +455,10 @@ func GenerateInputSchema(operation interface{}) (*jsonschema.Schema, error) {
 	}
 }
 
+// BodyFieldPrefix namespaces the inputs of body fields named like a parameter
+// of the operation, e.g. body.id next to the id path parameter
+const BodyFieldPrefix = "body."
+
 func generateInputSchemaFromInterface(operation Operation) (*jsonschema.Schema, error) {
 	schema := &jsonschema.Schema{
 		Type:       "object",
@@ -189,13 +493,32 @@ func generateInputSchemaFromInterface(operation Operation) (*jsonschema.Schema,
 		if bodySchema != nil {
 			bodyJSONSchema := convertSchemaToJSONSchema(bodySchema)
 			if bodyJSONSchema != nil && bodyJSONSchema.Properties != nil {
+				// Parameters are all added by now, so a body field taking an
+				// input name already used is namespaced
+				inputNames := make(map[string]string, len(bodyJSONSchema.Properties))
+				for propName := range bodyJSONSchema.Properties {
+					inputNames[propName] = propName
+					if _, taken := schema.Properties[propName]; taken {
+						inputNames[propName] = BodyFieldPrefix + propName
+					}
+				}
 				for propName, propSchema := range bodyJSONSchema.Properties {
-					schema.Properties[propName] = propSchema
+					schema.Properties[inputNames[propName]] = propSchema
 				}
 
 				// Add required properties from body schema
-				if bodyJSONSchema.Required != nil {
-					schema.Required = append(schema.Required, bodyJSONSchema.Required...)
+				for _, propName := range bodyJSONSchema.Required {
+					if inputName, ok := inputNames[propName]; ok {
+						propName = inputName
+					}
+					schema.Required = append(schema.Required, propName)
+				}
+
+				// Inputs other than the parameters are fields of a free-form
+				// body. Forbidding them is left to the API, as the tool has
+				// parameters too.
+				if additional := bodyJSONSchema.AdditionalProperties; additional != nil && additional.Not == nil {
+					schema.AdditionalProperties = additional
 				}
 			}
 		}
@@ -227,6 +550,72 @@ func GetRequestBodyJSONContent(requestBodyRef *openapi3.RequestBodyRef) (*openap
 	return contentType, nil
 }
 
+// GenerateOutputSchema converts the schema of an operation's successful
+// response, returning nil when it declares none
+func GenerateOutputSchema(operation Operation) (*jsonschema.Schema, error) {
+	schema, err := operation.GetResponseSchema()
+	if err != nil || schema == nil {
+		return nil, err
+	}
+	return convertSchemaToJSONSchema(schema), nil
+}
+
+// GenerateRequestBodySchema converts the JSON schema of an operation's
+// request body, returning nil when it has none
+func GenerateRequestBodySchema(operation Operation) (*jsonschema.Schema, error) {
+	requestBody := operation.GetRequestBody()
+	if requestBody == nil {
+		return nil, nil
+	}
+
+	schema, err := requestBody.GetJSONSchema()
+	if err != nil || schema == nil {
+		return nil, err
+	}
+	return convertSchemaToJSONSchema(schema), nil
+}
+
+// successStatusCodes returns the 2xx status codes of a response map in
+// order, e.g. 200, 201, 2XX
+func successStatusCodes[T any](responses map[string]T) []string {
+	var codes []string
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// sortedResponses converts response descriptions keyed by status, sorting
+// them by status with the default response last
+func sortedResponses(descriptions map[string]string) []Response {
+	responses := make([]Response, 0, len(descriptions))
+	for status, description := range descriptions {
+		responses = append(responses, Response{Status: status, Description: description})
+	}
+	slices.SortFunc(responses, func(a, b Response) int {
+		if (a.Status == "default") != (b.Status == "default") {
+			if a.Status == "default" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(strings.ToUpper(a.Status), strings.ToUpper(b.Status))
+	})
+	return responses
+}
+
+// successStatus converts a success status code such as 201 or 2XX
+func successStatus(code string) int {
+	status, err := strconv.Atoi(code)
+	if err != nil {
+		return http.StatusOK
+	}
+	return status
+}
+
 // Helper functions for converting to jsonschema
 func convertParameterToJSONSchemaFromInterface(param Parameter) *jsonschema.Schema {
 	if param == nil {
@@ -245,7 +634,12 @@ func convertParameterToJSONSchemaFromInterface(param Parameter) *jsonschema.Sche
 
 	// Handle parameter schema if available
 	if paramSchema := param.GetSchema(); paramSchema != nil {
-		return convertSchemaToJSONSchema(paramSchema)
+		converted := convertSchemaToJSONSchema(paramSchema)
+		// The parameter's description documents it better than its schema's
+		if converted != nil && param.GetDescription() != "" {
+			converted.Description = param.GetDescription()
+		}
+		return converted
 	}
 
 	// Use type and format directly
@@ -256,6 +650,16 @@ func convertParameterToJSONSchemaFromInterface(param Parameter) *jsonschema.Sche
 	if param.GetFormat() != "" {
 		schema.Format = param.GetFormat()
 	}
+	// Files of formData parameters are given as their content
+	if schema.Type == "file" {
+		schema.Type, schema.Format = "string", "binary"
+	}
+	if schema.Type == "array" {
+		schema.Items = convertSchemaToJSONSchema(param.GetItems())
+	}
+	if enum := param.GetEnum(); len(enum) > 0 {
+		schema.Enum = enum
+	}
 
 	return schema
 }
@@ -265,6 +669,21 @@ func convertSchemaToJSONSchema(schema Schema) *jsonschema.Schema {
 		return nil
 	}
 
+	// anyOf: [X, {type: null}] is X allowing null, as nullable X is
+	alternatives := schema.GetAnyOf()
+	if len(alternatives) == 2 && (isNullSchema(alternatives[0]) != isNullSchema(alternatives[1])) {
+		alternative := alternatives[0]
+		if isNullSchema(alternative) {
+			alternative = alternatives[1]
+		}
+		jsonSchema := convertSchemaToJSONSchema(alternative)
+		if description := schema.GetDescription(); description != "" {
+			jsonSchema.Description = description
+		}
+		allowNull(jsonSchema)
+		return jsonSchema
+	}
+
 	jsonSchema := &jsonschema.Schema{
 		Type:        schema.GetType(),
 		Format:      schema.GetFormat(),
@@ -279,6 +698,10 @@ func convertSchemaToJSONSchema(schema Schema) *jsonschema.Schema {
 		}
 	}
 
+	if example := schema.GetExample(); example != nil {
+		jsonSchema.Examples = []interface{}{example}
+	}
+
 	// Convert properties
 	if properties := schema.GetProperties(); properties != nil {
 		for propName, propSchema := range properties {
@@ -301,5 +724,137 @@ func convertSchemaToJSONSchema(schema Schema) *jsonschema.Schema {
 		jsonSchema.Enum = enum
 	}
 
+	// Free-form and typed maps, or objects forbidding other properties
+	if additional, allowed := schema.GetAdditionalProperties(); additional != nil {
+		jsonSchema.AdditionalProperties = convertSchemaToJSONSchema(additional)
+	} else if allowed != nil && *allowed {
+		jsonSchema.AdditionalProperties = &jsonschema.Schema{}
+	} else if allowed != nil {
+		jsonSchema.AdditionalProperties = &jsonschema.Schema{Not: &jsonschema.Schema{}}
+	}
+
+	for _, alternative := range alternatives {
+		jsonSchema.AnyOf = append(jsonSchema.AnyOf, convertSchemaToJSONSchema(alternative))
+	}
+
+	if schema.IsNullable() {
+		allowNull(jsonSchema)
+	}
+
 	return jsonSchema
 }
+
+// isNullSchema reports whether a schema only allows null, as the null type
+// read by nullTypesAsNullable
+func isNullSchema(schema Schema) bool {
+	return schema != nil && schema.IsNullable() && schema.GetType() == "" &&
+		len(schema.GetProperties()) == 0 && len(schema.GetAnyOf()) == 0
+}
+
+// nullTypesAsNullable rewrites the null type of schemas, alone or in a list of
+// types, as nullable: true, reporting whether the document held any
+func nullTypesAsNullable(data []byte) ([]byte, bool) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, false
+	}
+	if !replaceNullTypes(document) {
+		return nil, false
+	}
+
+	normalized, err := json.Marshal(document)
+	if err != nil {
+		return nil, false
+	}
+	return normalized, true
+}
+
+// replaceNullTypes rewrites the null types found under node
+func replaceNullTypes(node interface{}) bool {
+	replaced := false
+	switch n := node.(type) {
+	case map[string]interface{}:
+		switch t := n["type"].(type) {
+		case string:
+			if t == "null" {
+				delete(n, "type")
+				n["nullable"] = true
+				replaced = true
+			}
+		case []interface{}:
+			if i := slices.Index(t, interface{}("null")); i >= 0 {
+				t = slices.Delete(t, i, i+1)
+				switch len(t) {
+				case 0:
+					delete(n, "type")
+				case 1:
+					n["type"] = t[0]
+				default:
+					n["type"] = t
+				}
+				n["nullable"] = true
+				replaced = true
+			}
+		}
+		for _, value := range n {
+			if replaceNullTypes(value) {
+				replaced = true
+			}
+		}
+	case []interface{}:
+		for _, value := range n {
+			if replaceNullTypes(value) {
+				replaced = true
+			}
+		}
+	}
+	return replaced
+}
+
+// allowNull makes a converted schema accept null too, adding it to its types
+// and enum. Schemas without a type already accept it, unless they are
+// alternatives.
+func allowNull(schema *jsonschema.Schema) {
+	switch {
+	case schema.Type == "null":
+		return
+	case schema.Type != "":
+		schema.Types = []string{schema.Type, "null"}
+		schema.Type = ""
+	case len(schema.Types) > 0:
+		if !slices.Contains(schema.Types, "null") {
+			schema.Types = append(schema.Types, "null")
+		}
+	case len(schema.AnyOf) > 0:
+		schema.AnyOf = append(schema.AnyOf, &jsonschema.Schema{Type: "null"})
+	}
+
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, nil) {
+		schema.Enum = append(schema.Enum, nil)
+	}
+}
+
+// MarshalJSON returns a spec's canonical JSON form, whether it was loaded
+// from JSON or YAML
+func MarshalJSON(spec APISpec) ([]byte, error) {
+	switch s := spec.(type) {
+	case *OpenAPI3Spec:
+		return json.Marshal(s.spec)
+	case *OpenAPI2Spec:
+		return json.Marshal(s.spec)
+	default:
+		return nil, fmt.Errorf("unsupported spec type %T", spec)
+	}
+}
+
+// Hash returns the SHA-256 of a spec's canonical JSON form, so the same API
+// hashes alike whether it was loaded from JSON or YAML
+func Hash(spec APISpec) (string, error) {
+	data, err := MarshalJSON(spec)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -1,7 +1,10 @@
 package openapi
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi2"
@@ -29,6 +32,11 @@ type OpenAPI2RequestBody struct {
 
 type OpenAPI2Schema struct {
 	schema *openapi2.Schema
+	// spec resolves the references to definitions of nested schemas
+	spec *openapi2.T
+	// refs are the references followed to reach the schema, a reference back
+	// to one of them is recursive
+	refs []string
 }
 
 func (s *OpenAPI2Spec) GetVersion() string {
@@ -52,6 +60,53 @@ func (s *OpenAPI2Spec) GetBaseURL() string {
 	return fmt.Sprintf("%s://%s%s", scheme, host, basePath)
 }
 
+// GetServers returns the base URL for every scheme the spec declares
+func (s *OpenAPI2Spec) GetServers() []Server {
+	if len(s.spec.Schemes) == 0 {
+		return []Server{{URL: s.GetBaseURL()}}
+	}
+
+	host := s.spec.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+
+	servers := make([]Server, 0, len(s.spec.Schemes))
+	for _, scheme := range s.spec.Schemes {
+		servers = append(servers, Server{URL: fmt.Sprintf("%s://%s%s", scheme, host, s.spec.BasePath)})
+	}
+	return servers
+}
+
+func (s *OpenAPI2Spec) GetExtensions() map[string]interface{} {
+	return s.spec.Extensions
+}
+
+func (s *OpenAPI2Spec) GetTags() []Tag {
+	var tags []Tag
+	for _, tag := range s.spec.Tags {
+		if tag != nil {
+			tags = append(tags, Tag{Name: tag.Name, Description: tag.Description})
+		}
+	}
+	return tags
+}
+
+func (s *OpenAPI2Spec) GetSecurity() []SecurityRequirement {
+	return convertSecurity(s.spec.Security)
+}
+
+func (s *OpenAPI2Spec) GetSecuritySchemes() map[string]SecurityScheme {
+	schemes := make(map[string]SecurityScheme)
+	for name, scheme := range s.spec.SecurityDefinitions {
+		if scheme == nil {
+			continue
+		}
+		schemes[name] = SecurityScheme{Type: scheme.Type, In: scheme.In, Name: scheme.Name, TokenURL: scheme.TokenURL}
+	}
+	return schemes
+}
+
 func (s *OpenAPI2Spec) GetPaths() map[string]PathItem {
 	paths := make(map[string]PathItem)
 	if s.spec.Paths != nil {
@@ -62,6 +117,10 @@ func (s *OpenAPI2Spec) GetPaths() map[string]PathItem {
 	return paths
 }
 
+func (p *OpenAPI2PathItem) GetExtensions() map[string]interface{} {
+	return p.item.Extensions
+}
+
 func (p *OpenAPI2PathItem) GetOperations() map[string]Operation {
 	operations := make(map[string]Operation)
 
@@ -104,6 +163,62 @@ func (o *OpenAPI2Operation) GetSummary() string {
 	return o.op.Summary
 }
 
+func (o *OpenAPI2Operation) GetTags() []string {
+	return o.op.Tags
+}
+
+func (o *OpenAPI2Operation) GetDescription() string {
+	return o.op.Description
+}
+
+// GetLinks returns nil, links were introduced in OpenAPI 3.0
+func (o *OpenAPI2Operation) GetLinks() []Link {
+	return nil
+}
+
+func (o *OpenAPI2Operation) GetResponseSchema() (Schema, error) {
+	return responseSchema2(o.op, nil)
+}
+
+func (o *OpenAPI2Operation) GetSuccessResponse() (int, interface{}) {
+	return successResponse2(o.op)
+}
+
+func (o *OpenAPI2Operation) GetResponses() []Response {
+	return responses2(o.op)
+}
+
+func (o *OpenAPI2Operation) GetExternalDocs() string {
+	if o.op.ExternalDocs == nil {
+		return ""
+	}
+	return o.op.ExternalDocs.URL
+}
+
+func (o *OpenAPI2Operation) GetConsumes() []string {
+	return o.op.Consumes
+}
+
+func (o *OpenAPI2Operation) IsDeprecated() bool {
+	return o.op.Deprecated
+}
+
+func (o *OpenAPI2Operation) GetExtensions() map[string]interface{} {
+	return o.op.Extensions
+}
+
+// GetServers returns nil, OpenAPI 2.0 only declares servers for the whole spec
+func (o *OpenAPI2Operation) GetServers() []Server {
+	return nil
+}
+
+func (o *OpenAPI2Operation) GetSecurity() []SecurityRequirement {
+	if o.op.Security == nil {
+		return nil
+	}
+	return convertSecurity(*o.op.Security)
+}
+
 func (o *OpenAPI2Operation) GetParameters() []Parameter {
 	var params []Parameter
 	for _, param := range o.op.Parameters {
@@ -131,6 +246,111 @@ func (o *OpenAPI2OperationWithPath) GetSummary() string {
 	return o.op.Summary
 }
 
+func (o *OpenAPI2OperationWithPath) GetTags() []string {
+	return o.op.Tags
+}
+
+func (o *OpenAPI2OperationWithPath) GetDescription() string {
+	return o.op.Description
+}
+
+// GetLinks returns nil, links were introduced in OpenAPI 3.0
+func (o *OpenAPI2OperationWithPath) GetLinks() []Link {
+	return nil
+}
+
+func (o *OpenAPI2OperationWithPath) GetResponseSchema() (Schema, error) {
+	return responseSchema2(o.op, o.spec)
+}
+
+// responseSchema2 returns the schema of the first successful response that
+// declares one
+func responseSchema2(op *openapi2.Operation, spec *openapi2.T) (Schema, error) {
+	for _, code := range successStatusCodes(op.Responses) {
+		if response := op.Responses[code]; response != nil && response.Schema != nil {
+			return resolveSchemaRef2(response.Schema, spec)
+		}
+	}
+	return nil, nil
+}
+
+func (o *OpenAPI2OperationWithPath) GetSuccessResponse() (int, interface{}) {
+	return successResponse2(o.op)
+}
+
+func (o *OpenAPI2OperationWithPath) GetResponses() []Response {
+	return responses2(o.op)
+}
+
+func (o *OpenAPI2OperationWithPath) GetExternalDocs() string {
+	if o.op.ExternalDocs == nil {
+		return ""
+	}
+	return o.op.ExternalDocs.URL
+}
+
+// GetConsumes returns the consumes of the operation, or of the spec when the
+// operation doesn't declare its own
+func (o *OpenAPI2OperationWithPath) GetConsumes() []string {
+	if len(o.op.Consumes) > 0 || o.spec == nil {
+		return o.op.Consumes
+	}
+	return o.spec.Consumes
+}
+
+func (o *OpenAPI2OperationWithPath) IsDeprecated() bool {
+	return o.op.Deprecated
+}
+
+func (o *OpenAPI2OperationWithPath) GetExtensions() map[string]interface{} {
+	return o.op.Extensions
+}
+
+// responses2 returns the declared responses with their descriptions
+func responses2(op *openapi2.Operation) []Response {
+	descriptions := make(map[string]string)
+	for status, response := range op.Responses {
+		description := ""
+		if response != nil {
+			description = response.Description
+		}
+		descriptions[status] = description
+	}
+	return sortedResponses(descriptions)
+}
+
+// successResponse2 returns the status code and JSON example of the first
+// successful response
+func successResponse2(op *openapi2.Operation) (int, interface{}) {
+	codes := successStatusCodes(op.Responses)
+	if len(codes) == 0 {
+		return 0, nil
+	}
+
+	status := successStatus(codes[0])
+	response := op.Responses[codes[0]]
+	if response == nil {
+		return status, nil
+	}
+	for mimeType, example := range response.Examples {
+		if strings.HasSuffix(strings.SplitN(mimeType, ";", 2)[0], "json") {
+			return status, example
+		}
+	}
+	return status, nil
+}
+
+func (o *OpenAPI2OperationWithPath) GetServers() []Server {
+	return nil
+}
+
+func (o *OpenAPI2OperationWithPath) GetSecurity() []SecurityRequirement {
+	if o.op.Security == nil {
+		return nil
+	}
+	return convertSecurity(*o.op.Security)
+}
+
 func (o *OpenAPI2OperationWithPath) GetParameters() []Parameter {
 	var params []Parameter
 
@@ -196,6 +416,38 @@ func (p *OpenAPI2Parameter) GetFormat() string {
 	return p.param.Format
 }
 
+func (p *OpenAPI2Parameter) GetItems() Schema {
+	if p.param.Items != nil && p.param.Items.Value != nil {
+		return &OpenAPI2Schema{schema: p.param.Items.Value}
+	}
+	return nil
+}
+
+// GetStyle maps the collectionFormat of an array parameter onto its OpenAPI 3
+// style. Tab separated values have no OpenAPI 3 equivalent.
+func (p *OpenAPI2Parameter) GetStyle() string {
+	switch p.param.CollectionFormat {
+	case "ssv":
+		return "spaceDelimited"
+	case "pipes":
+		return "pipeDelimited"
+	case "tsv":
+		return "tabDelimited"
+	}
+	if p.param.In == "path" || p.param.In == "header" {
+		return "simple"
+	}
+	return "form"
+}
+
+func (p *OpenAPI2Parameter) GetExplode() bool {
+	return p.param.CollectionFormat == "multi"
+}
+
+func (p *OpenAPI2Parameter) GetEnum() []interface{} {
+	return p.param.Enum
+}
+
 func (p *OpenAPI2Parameter) GetSchema() Schema {
 	if p.param.Schema != nil && p.param.Schema.Value != nil {
 		return &OpenAPI2Schema{schema: p.param.Schema.Value}
@@ -208,23 +460,101 @@ func (r *OpenAPI2RequestBody) GetJSONSchema() (Schema, error) {
 		return nil, nil
 	}
 
-	// Handle schema references in OpenAPI 2.0
-	if r.param.Schema.Ref != "" && r.param.Schema.Value == nil {
-		// Resolve the reference manually
-		refPath := strings.TrimPrefix(r.param.Schema.Ref, "#/definitions/")
-		if r.spec != nil && r.spec.Definitions != nil {
-			if refSchemaRef, exists := r.spec.Definitions[refPath]; exists && refSchemaRef.Value != nil {
-				return &OpenAPI2Schema{schema: refSchemaRef.Value}, nil
+	return resolveSchemaRef2(r.param.Schema, r.spec)
+}
+
+// resolveSchemaRef2 returns the schema of a schema reference, looking up
+// references to definitions that weren't resolved when loading
+func resolveSchemaRef2(ref *openapi2.SchemaRef, spec *openapi2.T) (Schema, error) {
+	schema, err := schemaRef2(ref, spec, nil)
+	if err != nil || schema == nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// schemaRef2 returns the schema of a reference reached by following refs,
+// looking up the definition it references. Recursive references are replaced
+// with an empty, free-form schema.
+func schemaRef2(ref *openapi2.SchemaRef, spec *openapi2.T, refs []string) (*OpenAPI2Schema, error) {
+	if ref == nil {
+		return nil, nil
+	}
+
+	if ref.Ref != "" {
+		if slices.Contains(refs, ref.Ref) {
+			return &OpenAPI2Schema{schema: &openapi2.Schema{}, spec: spec, refs: refs}, nil
+		}
+		refs = append(slices.Clip(refs), ref.Ref)
+
+		if ref.Value == nil {
+			name, ok := strings.CutPrefix(ref.Ref, "#/definitions/")
+			if !ok || spec == nil || spec.Definitions[name] == nil {
+				return nil, fmt.Errorf("could not resolve schema reference: %s", ref.Ref)
 			}
+			return schemaRef2(spec.Definitions[name], spec, refs)
 		}
-		return nil, fmt.Errorf("could not resolve schema reference: %s", r.param.Schema.Ref)
 	}
 
-	if r.param.Schema.Value == nil {
+	if ref.Value == nil {
 		return nil, nil
 	}
 
-	return &OpenAPI2Schema{schema: r.param.Schema.Value}, nil
+	schema := ref.Value
+	if len(schema.AllOf) > 0 {
+		schema = mergeAllOf2(schema, spec, refs)
+	}
+	return &OpenAPI2Schema{schema: schema, spec: spec, refs: refs}, nil
+}
+
+// mergeAllOf2 folds the schemas of allOf into a copy of schema, as models
+// extending a base definition declare their fields
+func mergeAllOf2(schema *openapi2.Schema, spec *openapi2.T, refs []string) *openapi2.Schema {
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = maps.Clone(schema.Properties)
+	if merged.Properties == nil {
+		merged.Properties = make(openapi2.Schemas)
+	}
+	merged.Required = slices.Clone(schema.Required)
+
+	for _, ref := range schema.AllOf {
+		part, err := schemaRef2(ref, spec, refs)
+		if err != nil || part == nil {
+			continue
+		}
+
+		if merged.Type == nil {
+			merged.Type = part.schema.Type
+		}
+		if merged.Description == "" {
+			merged.Description = part.schema.Description
+		}
+		if merged.AdditionalProperties.Has == nil && merged.AdditionalProperties.Schema == nil {
+			merged.AdditionalProperties = part.schema.AdditionalProperties
+		}
+		for name, property := range part.schema.Properties {
+			if _, exists := merged.Properties[name]; !exists {
+				merged.Properties[name] = property
+			}
+		}
+		for _, name := range part.schema.Required {
+			if !slices.Contains(merged.Required, name) {
+				merged.Required = append(merged.Required, name)
+			}
+		}
+	}
+	return &merged
+}
+
+// child returns the schema of a reference nested in s, nil when it can't be
+// resolved
+func (s *OpenAPI2Schema) child(ref *openapi2.SchemaRef) Schema {
+	schema, err := schemaRef2(ref, s.spec, s.refs)
+	if err != nil || schema == nil {
+		return nil
+	}
+	return schema
 }
 
 func (s *OpenAPI2Schema) GetType() string {
@@ -244,21 +574,16 @@ func (s *OpenAPI2Schema) GetDescription() string {
 
 func (s *OpenAPI2Schema) GetProperties() map[string]Schema {
 	properties := make(map[string]Schema)
-	if s.schema.Properties != nil {
-		for name, prop := range s.schema.Properties {
-			if prop.Value != nil {
-				properties[name] = &OpenAPI2Schema{schema: prop.Value}
-			}
+	for name, prop := range s.schema.Properties {
+		if property := s.child(prop); property != nil {
+			properties[name] = property
 		}
 	}
 	return properties
 }
 
 func (s *OpenAPI2Schema) GetItems() Schema {
-	if s.schema.Items != nil && s.schema.Items.Value != nil {
-		return &OpenAPI2Schema{schema: s.schema.Items.Value}
-	}
-	return nil
+	return s.child(s.schema.Items)
 }
 
 func (s *OpenAPI2Schema) GetRequired() []string {
@@ -272,3 +597,41 @@ func (s *OpenAPI2Schema) GetEnum() []interface{} {
 func (s *OpenAPI2Schema) GetDefault() interface{} {
 	return s.schema.Default
 }
+
+func (s *OpenAPI2Schema) GetExample() interface{} {
+	return s.schema.Example
+}
+
+// IsNullable reports the x-nullable extension, as OpenAPI 2.0 has no null
+func (s *OpenAPI2Schema) IsNullable() bool {
+	nullable, _ := s.schema.Extensions["x-nullable"].(bool)
+	return nullable
+}
+
+// GetAnyOf returns nothing, OpenAPI 2.0 has no anyOf or oneOf
+func (s *OpenAPI2Schema) GetAnyOf() []Schema {
+	return nil
+}
+
+// GetAdditionalProperties returns the schema of the map values. kin-openapi
+// holds it as an OpenAPI 3 schema, which is read back as an OpenAPI 2.0 one so
+// its references to definitions resolve.
+func (s *OpenAPI2Schema) GetAdditionalProperties() (Schema, *bool) {
+	additional := s.schema.AdditionalProperties
+	if additional.Schema == nil {
+		return nil, additional.Has
+	}
+
+	data, err := json.Marshal(additional.Schema)
+	if err != nil {
+		return nil, additional.Has
+	}
+	var ref openapi2.SchemaRef
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return nil, additional.Has
+	}
+	if schema := s.child(&ref); schema != nil {
+		return schema, additional.Has
+	}
+	return nil, additional.Has
+}
@@ -2,6 +2,10 @@ package openapi
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -57,8 +61,8 @@ func (p *OpenAPI3Parameter) IsRequired() bool {
 }
 
 func (p *OpenAPI3Parameter) GetType() string {
-	if p.param.Schema != nil && p.param.Schema.Value != nil && len(p.param.Schema.Value.Type.Slice()) > 0 {
-		return p.param.Schema.Value.Type.Slice()[0]
+	if schema := p.GetSchema(); schema != nil && schema.GetType() != "" {
+		return schema.GetType()
 	}
 	return "string"
 }
@@ -70,6 +74,13 @@ func (p *OpenAPI3Parameter) GetFormat() string {
 	return ""
 }
 
+func (p *OpenAPI3Parameter) GetEnum() []interface{} {
+	if schema := p.GetSchema(); schema != nil {
+		return schema.GetEnum()
+	}
+	return nil
+}
+
 func (p *OpenAPI3Parameter) GetSchema() Schema {
 	if p.param.Schema != nil && p.param.Schema.Value != nil {
 		return &OpenAPI3Schema{Schema: p.param.Schema.Value}
@@ -77,6 +88,27 @@ func (p *OpenAPI3Parameter) GetSchema() Schema {
 	return nil
 }
 
+func (p *OpenAPI3Parameter) GetItems() Schema {
+	if schema := p.GetSchema(); schema != nil {
+		return schema.GetItems()
+	}
+	return nil
+}
+
+func (p *OpenAPI3Parameter) GetStyle() string {
+	if method, err := p.param.SerializationMethod(); err == nil {
+		return method.Style
+	}
+	return p.param.Style
+}
+
+func (p *OpenAPI3Parameter) GetExplode() bool {
+	if method, err := p.param.SerializationMethod(); err == nil {
+		return method.Explode
+	}
+	return p.param.Explode != nil && *p.param.Explode
+}
+
 func (s *OpenAPI3Spec) GetVersion() string {
 	return s.spec.OpenAPI
 }
@@ -85,13 +117,235 @@ func (s *OpenAPI3Spec) GetInfo() openapi3.Info {
 	return *s.spec.Info
 }
 
+// GetBaseURL returns the URL of the first server with its variables set to
+// their defaults
 func (s *OpenAPI3Spec) GetBaseURL() string {
 	if len(s.spec.Servers) > 0 && s.spec.Servers[0].URL != "" {
-		return s.spec.Servers[0].URL
+		server := convertServers(s.spec.Servers[:1])[0]
+		if resolved, err := server.ResolveURL(nil); err == nil {
+			return resolved
+		}
+		return server.URL
 	}
 	return "http://localhost:8080"
 }
 
+func (s *OpenAPI3Spec) GetServers() []Server {
+	return convertServers(s.spec.Servers)
+}
+
+// convertServers converts the servers with a URL
+func convertServers(servers openapi3.Servers) []Server {
+	var converted []Server
+	for _, server := range servers {
+		if server == nil || server.URL == "" {
+			continue
+		}
+
+		var variables map[string]ServerVariable
+		if len(server.Variables) > 0 {
+			variables = make(map[string]ServerVariable, len(server.Variables))
+			for name, variable := range server.Variables {
+				if variable == nil {
+					continue
+				}
+				variables[name] = ServerVariable{Default: variable.Default, Enum: variable.Enum, Description: variable.Description}
+			}
+		}
+
+		converted = append(converted, Server{URL: server.URL, Description: server.Description, Variables: variables})
+	}
+	return converted
+}
+
+func (s *OpenAPI3Spec) GetExtensions() map[string]interface{} {
+	return s.spec.Extensions
+}
+
+func (s *OpenAPI3Spec) GetTags() []Tag {
+	var tags []Tag
+	for _, tag := range s.spec.Tags {
+		if tag != nil {
+			tags = append(tags, Tag{Name: tag.Name, Description: tag.Description})
+		}
+	}
+	return tags
+}
+
+// responseSchema3 returns the JSON schema of the first successful response
+// that declares one
+func responseSchema3(responses *openapi3.Responses) Schema {
+	if responses == nil {
+		return nil
+	}
+
+	for _, code := range successStatusCodes(responses.Map()) {
+		response := responses.Value(code)
+		if response == nil || response.Value == nil {
+			continue
+		}
+
+		mediaType := response.Value.Content.Get("application/json")
+		if mediaType == nil {
+			for name, candidate := range response.Value.Content {
+				if strings.HasSuffix(strings.SplitN(name, ";", 2)[0], "json") {
+					mediaType = candidate
+					break
+				}
+			}
+		}
+
+		if mediaType != nil && mediaType.Schema != nil && mediaType.Schema.Value != nil {
+			return &OpenAPI3Schema{Schema: mediaType.Schema.Value}
+		}
+	}
+	return nil
+}
+
+// responses3 returns the declared responses with their descriptions
+func responses3(responses *openapi3.Responses) []Response {
+	if responses == nil {
+		return nil
+	}
+
+	descriptions := make(map[string]string)
+	for status, response := range responses.Map() {
+		description := ""
+		if response != nil && response.Value != nil && response.Value.Description != nil {
+			description = *response.Value.Description
+		}
+		descriptions[status] = description
+	}
+	return sortedResponses(descriptions)
+}
+
+// successResponse3 returns the status code and JSON example of the first
+// successful response
+func successResponse3(responses *openapi3.Responses) (int, interface{}) {
+	if responses == nil {
+		return 0, nil
+	}
+
+	codes := successStatusCodes(responses.Map())
+	if len(codes) == 0 {
+		return 0, nil
+	}
+
+	status := successStatus(codes[0])
+	response := responses.Value(codes[0])
+	if response == nil || response.Value == nil {
+		return status, nil
+	}
+
+	for name, mediaType := range response.Value.Content {
+		if !strings.HasSuffix(strings.SplitN(name, ";", 2)[0], "json") || mediaType == nil {
+			continue
+		}
+		if mediaType.Example != nil {
+			return status, mediaType.Example
+		}
+		for _, example := range sortedExamples(mediaType.Examples) {
+			return status, example
+		}
+	}
+	return status, nil
+}
+
+// sortedExamples returns the values of named examples ordered by name
+func sortedExamples(examples openapi3.Examples) []interface{} {
+	names := make([]string, 0, len(examples))
+	for name, example := range examples {
+		if example != nil && example.Value != nil && example.Value.Value != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	values := make([]interface{}, len(names))
+	for i, name := range names {
+		values[i] = examples[name].Value.Value
+	}
+	return values
+}
+
+// convertLinks collects the links of all responses, keyed by operationId.
+// Links by operationRef aren't supported.
+func convertLinks(responses *openapi3.Responses) []Link {
+	if responses == nil {
+		return nil
+	}
+
+	var links []Link
+	for _, response := range responses.Map() {
+		if response == nil || response.Value == nil {
+			continue
+		}
+		for name, link := range response.Value.Links {
+			if link == nil || link.Value == nil || link.Value.OperationID == "" {
+				continue
+			}
+			links = append(links, Link{
+				Name:        name,
+				OperationID: link.Value.OperationID,
+				Description: link.Value.Description,
+				Parameters:  link.Value.Parameters,
+			})
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool { return links[i].Name < links[j].Name })
+	return links
+}
+
+func (s *OpenAPI3Spec) GetSecurity() []SecurityRequirement {
+	return convertSecurity3(&s.spec.Security)
+}
+
+// convertSecurity3 converts optional OpenAPI 3 security requirements
+func convertSecurity3(requirements *openapi3.SecurityRequirements) []SecurityRequirement {
+	if requirements == nil || *requirements == nil {
+		return nil
+	}
+
+	maps := make([]map[string][]string, 0, len(*requirements))
+	for _, requirement := range *requirements {
+		maps = append(maps, requirement)
+	}
+	return convertSecurity(maps)
+}
+
+func (s *OpenAPI3Spec) GetSecuritySchemes() map[string]SecurityScheme {
+	schemes := make(map[string]SecurityScheme)
+	if s.spec.Components == nil {
+		return schemes
+	}
+	for name, ref := range s.spec.Components.SecuritySchemes {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		schemes[name] = SecurityScheme{
+			Type:     ref.Value.Type,
+			In:       ref.Value.In,
+			Name:     ref.Value.Name,
+			TokenURL: oauthTokenURL(ref.Value.Flows),
+		}
+	}
+	return schemes
+}
+
+// oauthTokenURL returns the token endpoint of the first flow that has one
+func oauthTokenURL(flows *openapi3.OAuthFlows) string {
+	if flows == nil {
+		return ""
+	}
+	for _, flow := range []*openapi3.OAuthFlow{flows.ClientCredentials, flows.AuthorizationCode, flows.Password} {
+		if flow != nil && flow.TokenURL != "" {
+			return flow.TokenURL
+		}
+	}
+	return ""
+}
+
 func (s *OpenAPI3Spec) GetPaths() map[string]PathItem {
 	paths := make(map[string]PathItem)
 	if s.spec.Paths != nil {
@@ -110,6 +364,68 @@ func (o *OpenAPI3Operation) GetSummary() string {
 	return o.Op.Summary
 }
 
+func (o *OpenAPI3Operation) GetTags() []string {
+	return o.Op.Tags
+}
+
+func (o *OpenAPI3Operation) GetDescription() string {
+	return o.Op.Description
+}
+
+func (o *OpenAPI3Operation) GetLinks() []Link {
+	return convertLinks(o.Op.Responses)
+}
+
+func (o *OpenAPI3Operation) GetResponseSchema() (Schema, error) {
+	return responseSchema3(o.Op.Responses), nil
+}
+
+func (o *OpenAPI3Operation) GetSuccessResponse() (int, interface{}) {
+	return successResponse3(o.Op.Responses)
+}
+
+func (o *OpenAPI3Operation) GetResponses() []Response {
+	return responses3(o.Op.Responses)
+}
+
+func (o *OpenAPI3Operation) GetExternalDocs() string {
+	if o.Op.ExternalDocs == nil {
+		return ""
+	}
+	return o.Op.ExternalDocs.URL
+}
+
+func (o *OpenAPI3Operation) GetConsumes() []string {
+	return requestContentTypes3(o.Op.RequestBody)
+}
+
+// requestContentTypes3 returns the media types of a request body, sorted
+func requestContentTypes3(body *openapi3.RequestBodyRef) []string {
+	if body == nil || body.Value == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(body.Value.Content))
+}
+
+func (o *OpenAPI3Operation) IsDeprecated() bool {
+	return o.Op.Deprecated
+}
+
+func (o *OpenAPI3Operation) GetExtensions() map[string]interface{} {
+	return o.Op.Extensions
+}
+
+func (o *OpenAPI3Operation) GetServers() []Server {
+	if o.Op.Servers == nil {
+		return nil
+	}
+	return convertServers(*o.Op.Servers)
+}
+
+func (o *OpenAPI3Operation) GetSecurity() []SecurityRequirement {
+	return convertSecurity3(o.Op.Security)
+}
+
 func (o *OpenAPI3Operation) GetParameters() []Parameter {
 	var params []Parameter
 	for _, param := range o.Op.Parameters {
@@ -136,6 +452,62 @@ func (o *OpenAPI3OperationWithPath) GetSummary() string {
 	return o.Op.Summary
 }
 
+func (o *OpenAPI3OperationWithPath) GetTags() []string {
+	return o.Op.Tags
+}
+
+func (o *OpenAPI3OperationWithPath) GetDescription() string {
+	return o.Op.Description
+}
+
+func (o *OpenAPI3OperationWithPath) GetLinks() []Link {
+	return convertLinks(o.Op.Responses)
+}
+
+func (o *OpenAPI3OperationWithPath) GetResponseSchema() (Schema, error) {
+	return responseSchema3(o.Op.Responses), nil
+}
+
+func (o *OpenAPI3OperationWithPath) GetSuccessResponse() (int, interface{}) {
+	return successResponse3(o.Op.Responses)
+}
+
+func (o *OpenAPI3OperationWithPath) GetResponses() []Response {
+	return responses3(o.Op.Responses)
+}
+
+func (o *OpenAPI3OperationWithPath) GetExternalDocs() string {
+	if o.Op.ExternalDocs == nil {
+		return ""
+	}
+	return o.Op.ExternalDocs.URL
+}
+
+func (o *OpenAPI3OperationWithPath) GetConsumes() []string {
+	return requestContentTypes3(o.Op.RequestBody)
+}
+
+func (o *OpenAPI3OperationWithPath) IsDeprecated() bool {
+	return o.Op.Deprecated
+}
+
+func (o *OpenAPI3OperationWithPath) GetExtensions() map[string]interface{} {
+	return o.Op.Extensions
+}
+
+// GetServers returns the servers of the operation, falling back to those of
+// its path
+func (o *OpenAPI3OperationWithPath) GetServers() []Server {
+	if o.Op.Servers != nil && len(*o.Op.Servers) > 0 {
+		return convertServers(*o.Op.Servers)
+	}
+	return convertServers(o.pathItem.Servers)
+}
+
+func (o *OpenAPI3OperationWithPath) GetSecurity() []SecurityRequirement {
+	return convertSecurity3(o.Op.Security)
+}
+
 func (o *OpenAPI3OperationWithPath) GetParameters() []Parameter {
 	var params []Parameter
 
@@ -165,6 +537,10 @@ func (o *OpenAPI3OperationWithPath) GetRequestBody() RequestBody {
 	return nil
 }
 
+func (p *OpenAPI3PathItem) GetExtensions() map[string]interface{} {
+	return p.item.Extensions
+}
+
 func (p *OpenAPI3PathItem) GetOperations() map[string]Operation {
 	operations := make(map[string]Operation)
 
@@ -256,3 +632,31 @@ func (s *OpenAPI3Schema) GetEnum() []interface{} {
 func (s *OpenAPI3Schema) GetDefault() interface{} {
 	return s.Schema.Default
 }
+
+func (s *OpenAPI3Schema) GetExample() interface{} {
+	return s.Schema.Example
+}
+
+func (s *OpenAPI3Schema) IsNullable() bool {
+	return s.Schema.Nullable
+}
+
+func (s *OpenAPI3Schema) GetAnyOf() []Schema {
+	var alternatives []Schema
+	for _, refs := range []openapi3.SchemaRefs{s.Schema.AnyOf, s.Schema.OneOf} {
+		for _, ref := range refs {
+			if ref != nil && ref.Value != nil {
+				alternatives = append(alternatives, &OpenAPI3Schema{Schema: ref.Value})
+			}
+		}
+	}
+	return alternatives
+}
+
+func (s *OpenAPI3Schema) GetAdditionalProperties() (Schema, *bool) {
+	additional := s.Schema.AdditionalProperties
+	if additional.Schema != nil && additional.Schema.Value != nil {
+		return &OpenAPI3Schema{Schema: additional.Schema.Value}, additional.Has
+	}
+	return nil, additional.Has
+}
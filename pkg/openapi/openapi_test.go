@@ -2,10 +2,14 @@ package openapi
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
 )
 
 func TestGetRequestBodyJSONContent(t *testing.T) {
@@ -147,6 +151,133 @@ func TestLoadSpecV2AndV3(t *testing.T) {
 	}
 }
 
+func TestGetServers(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []Server
+	}{
+		{
+			name: "OpenAPI 3.0 servers",
+			content: `{
+				"openapi": "3.0.0",
+				"info": {"title": "Test", "version": "1.0.0"},
+				"servers": [
+					{"url": "https://api.example.com", "description": "Production"},
+					{"url": "https://sandbox.example.com"}
+				],
+				"paths": {}
+			}`,
+			expected: []Server{
+				{URL: "https://api.example.com", Description: "Production"},
+				{URL: "https://sandbox.example.com"},
+			},
+		},
+		{
+			name: "OpenAPI 2.0 schemes",
+			content: `{
+				"swagger": "2.0",
+				"info": {"title": "Test", "version": "1.0.0"},
+				"host": "api.example.com",
+				"basePath": "/v2",
+				"schemes": ["https", "http"],
+				"paths": {}
+			}`,
+			expected: []Server{
+				{URL: "https://api.example.com/v2"},
+				{URL: "http://api.example.com/v2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := t.TempDir() + "/test.json"
+			if err := os.WriteFile(tmpFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			spec, err := LoadSpecFromSource(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to load spec: %v", err)
+			}
+
+			if servers := spec.GetServers(); !reflect.DeepEqual(servers, tt.expected) {
+				t.Errorf("Expected servers %v, got %v", tt.expected, servers)
+			}
+		})
+	}
+}
+
+func TestServerResolveURL(t *testing.T) {
+	server := Server{
+		URL: "https://{region}.api.example.com/{version}/{tenant}",
+		Variables: map[string]ServerVariable{
+			"region":  {Default: "us", Enum: []string{"us", "eu"}},
+			"version": {Default: "v1"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		values      map[string]string
+		expected    string
+		expectError bool
+	}{
+		{name: "defaults", expected: "https://us.api.example.com/v1/{tenant}"},
+		{name: "overrides", values: map[string]string{"region": "eu", "version": "v2"}, expected: "https://eu.api.example.com/v2/{tenant}"},
+		{name: "value outside enum", values: map[string]string{"region": "ap"}, expectError: true},
+		{name: "undeclared variable", values: map[string]string{"tenant": "acme"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.ResolveURL(tt.values)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEffectiveSecurity(t *testing.T) {
+	spec, err := LoadSpec([]byte(`{
+		"swagger": "2.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"security": [{"apiKey": []}],
+		"paths": {
+			"/users": {"get": {"responses": {"200": {"description": "OK"}}}},
+			"/health": {"get": {"security": [], "responses": {"200": {"description": "OK"}}}},
+			"/admin": {"get": {"security": [{"oauth": ["admin"]}], "responses": {"200": {"description": "OK"}}}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	expected := map[string][]SecurityRequirement{
+		"/users":  {{"apiKey": {}}},
+		"/health": {},
+		"/admin":  {{"oauth": {"admin"}}},
+	}
+
+	for path, item := range spec.GetPaths() {
+		security := EffectiveSecurity(spec, item.GetOperations()["get"])
+		if security == nil || !reflect.DeepEqual(security, expected[path]) {
+			t.Errorf("Expected security %v for %s, got %v", expected[path], path, security)
+		}
+	}
+}
+
 func TestGetBaseURLVersions(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -163,6 +294,22 @@ func TestGetBaseURLVersions(t *testing.T) {
 			}`,
 			expected: "https://api.v3.example.com",
 		},
+		{
+			name: "OpenAPI 3.0 with server variables",
+			content: `{
+				"openapi": "3.0.0",
+				"info": {"title": "Test", "version": "1.0.0"},
+				"servers": [{
+					"url": "https://{region}.api.example.com/{version}",
+					"variables": {
+						"region": {"default": "eu", "enum": ["eu", "us"]},
+						"version": {"default": "v1"}
+					}
+				}],
+				"paths": {}
+			}`,
+			expected: "https://eu.api.example.com/v1",
+		},
 		{
 			name: "OpenAPI 2.0 with host and basePath",
 			content: `{
@@ -198,6 +345,59 @@ func TestGetBaseURLVersions(t *testing.T) {
 	}
 }
 
+func TestGetSecuritySchemesVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "OpenAPI 3.0 components",
+			content: `{
+				"openapi": "3.0.0",
+				"info": {"title": "Test", "version": "1.0.0"},
+				"components": {
+					"securitySchemes": {
+						"apiKey": {"type": "apiKey", "in": "query", "name": "api_key"},
+						"oauth": {"type": "oauth2", "flows": {"clientCredentials": {"tokenUrl": "https://auth.example.com/token", "scopes": {}}}}
+					}
+				},
+				"paths": {}
+			}`,
+		},
+		{
+			name: "OpenAPI 2.0 securityDefinitions",
+			content: `{
+				"swagger": "2.0",
+				"info": {"title": "Test", "version": "1.0.0"},
+				"securityDefinitions": {
+					"apiKey": {"type": "apiKey", "in": "query", "name": "api_key"},
+					"oauth": {"type": "oauth2", "flow": "application", "tokenUrl": "https://auth.example.com/token", "scopes": {}}
+				},
+				"paths": {}
+			}`,
+		},
+	}
+
+	expected := SecurityScheme{Type: "apiKey", In: "query", Name: "api_key"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := LoadSpec([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Failed to load spec: %v", err)
+			}
+
+			schemes := spec.GetSecuritySchemes()
+			if schemes["apiKey"] != expected {
+				t.Errorf("Expected scheme %+v, got %+v", expected, schemes["apiKey"])
+			}
+			if schemes["oauth"].TokenURL != "https://auth.example.com/token" {
+				t.Errorf("Expected OAuth2 token URL, got %+v", schemes["oauth"])
+			}
+		})
+	}
+}
+
 func TestPathLevelParameterSchemaGeneration(t *testing.T) {
 	// Test OpenAPI 3.0 with path-level parameters
 	t.Run("OpenAPI3_PathLevelParameters", func(t *testing.T) {
@@ -742,3 +942,320 @@ func TestSchemaJSONMarshaling(t *testing.T) {
 		t.Logf("Generated schema JSON:\n%s", string(schemaJSON))
 	})
 }
+
+func TestAdditionalPropertiesSchema(t *testing.T) {
+	spec, err := LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/labels": {
+				"put": {
+					"operationId": "putLabels",
+					"requestBody": {"content": {"application/json": {"schema": {"type": "object", "additionalProperties": {"type": "string"}}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			},
+			"/things": {
+				"post": {
+					"operationId": "createThing",
+					"requestBody": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"additionalProperties": false,
+						"properties": {
+							"meta": {"type": "object", "additionalProperties": true},
+							"strict": {"type": "object", "additionalProperties": false, "properties": {"a": {"type": "string"}}}
+						}
+					}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	schemaFor := func(path, method string) *jsonschema.Schema {
+		schema, err := GenerateInputSchema(spec.GetPaths()[path].GetOperations()[method])
+		if err != nil {
+			t.Fatalf("Failed to generate schema: %v", err)
+		}
+		return schema
+	}
+
+	labels := schemaFor("/labels", "put")
+	if labels.AdditionalProperties == nil || labels.AdditionalProperties.Type != "string" {
+		t.Errorf("Expected the free-form body to allow string inputs, got %+v", labels.AdditionalProperties)
+	}
+
+	things := schemaFor("/things", "post")
+	if things.AdditionalProperties != nil {
+		t.Errorf("Expected the tool's own inputs to be left open, got %+v", things.AdditionalProperties)
+	}
+	if meta := things.Properties["meta"].AdditionalProperties; meta == nil || meta.Not != nil {
+		t.Errorf("Expected meta to allow any field, got %+v", meta)
+	}
+	if strict := things.Properties["strict"].AdditionalProperties; strict == nil || strict.Not == nil {
+		t.Errorf("Expected strict to forbid other fields, got %+v", strict)
+	}
+}
+
+func TestBodyFieldNamespacing(t *testing.T) {
+	spec, err := LoadSpec([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/users/{id}": {
+				"put": {
+					"operationId": "updateUser",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"requestBody": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"required": ["id", "name"],
+						"properties": {"id": {"type": "string", "description": "Public ID"}, "name": {"type": "string"}}
+					}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	schema, err := GenerateInputSchema(spec.GetPaths()["/users/{id}"].GetOperations()["put"])
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	if id := schema.Properties["id"]; id == nil || id.Description == "Public ID" {
+		t.Errorf("Expected id to be the path parameter, got %+v", id)
+	}
+	if bodyID := schema.Properties["body.id"]; bodyID == nil || bodyID.Description != "Public ID" {
+		t.Errorf("Expected body.id to be the body field, got %+v", bodyID)
+	}
+	if _, ok := schema.Properties["name"]; !ok {
+		t.Errorf("Expected name to keep its input name")
+	}
+	if expected := []string{"id", "body.id", "name"}; !reflect.DeepEqual(schema.Required, expected) {
+		t.Errorf("Expected required %v, got %v", expected, schema.Required)
+	}
+}
+
+func TestNullableSchema(t *testing.T) {
+	spec, err := LoadSpec([]byte(`{
+		"openapi": "3.0.3",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/items": {
+				"post": {
+					"operationId": "createItem",
+					"parameters": [
+						{"name": "cursor", "in": "query", "schema": {"type": "string", "nullable": true}},
+						{"name": "after", "in": "query", "description": "Only later items", "schema": {"anyOf": [{"type": "string", "format": "date"}, {"type": "null"}]}},
+						{"name": "status", "in": "query", "schema": {"type": "string", "enum": ["open", "closed"], "nullable": true}},
+						{"name": "size", "in": "query", "schema": {"oneOf": [{"type": "integer"}, {"type": "string"}]}},
+						{"name": "limit", "in": "query", "schema": {"type": ["integer", "null"]}}
+					],
+					"requestBody": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"properties": {"owner": {"anyOf": [{"$ref": "#/components/schemas/Owner"}, {"type": "null"}]}}
+					}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		},
+		"components": {"schemas": {"Owner": {"type": "object", "properties": {"name": {"type": "string"}}}}}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	schema, err := GenerateInputSchema(spec.GetPaths()["/items"].GetOperations()["post"])
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		types    []string
+		expected func(*jsonschema.Schema) bool
+	}{
+		{name: "cursor", types: []string{"string", "null"}},
+		{name: "after", types: []string{"string", "null"}, expected: func(s *jsonschema.Schema) bool {
+			return s.Format == "date" && s.Description == "Only later items"
+		}},
+		{name: "status", types: []string{"string", "null"}, expected: func(s *jsonschema.Schema) bool {
+			return reflect.DeepEqual(s.Enum, []interface{}{"open", "closed", nil})
+		}},
+		{name: "size", expected: func(s *jsonschema.Schema) bool {
+			return len(s.AnyOf) == 2 && s.AnyOf[0].Type == "integer" && s.AnyOf[1].Type == "string"
+		}},
+		{name: "limit", types: []string{"integer", "null"}},
+		{name: "owner", types: []string{"object", "null"}, expected: func(s *jsonschema.Schema) bool {
+			return s.Properties["name"] != nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			property := schema.Properties[tt.name]
+			if property == nil {
+				t.Fatalf("Expected an input schema for %s", tt.name)
+			}
+			if !reflect.DeepEqual(property.Types, tt.types) {
+				t.Errorf("Expected types %v, got %v (type %q)", tt.types, property.Types, property.Type)
+			}
+			if tt.expected != nil && !tt.expected(property) {
+				t.Errorf("Unexpected schema %+v", property)
+			}
+		})
+	}
+}
+
+func TestNullableSchemaOpenAPI2(t *testing.T) {
+	spec, err := LoadSpec([]byte(`{
+		"swagger": "2.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/items": {
+				"post": {
+					"operationId": "createItem",
+					"parameters": [{"name": "body", "in": "body", "schema": {
+						"type": "object",
+						"properties": {"note": {"type": "string", "x-nullable": true}}
+					}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	schema, err := GenerateInputSchema(spec.GetPaths()["/items"].GetOperations()["post"])
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+	if note := schema.Properties["note"]; note == nil || !reflect.DeepEqual(note.Types, []string{"string", "null"}) {
+		t.Errorf("Expected note to allow null, got %+v", note)
+	}
+}
+
+func TestNestedRefsOpenAPI2(t *testing.T) {
+	spec, err := LoadSpec([]byte(`{
+		"swagger": "2.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/orders": {
+				"post": {
+					"operationId": "createOrder",
+					"parameters": [{"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Order"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		},
+		"definitions": {
+			"Entity": {"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}}},
+			"Address": {"type": "object", "properties": {"city": {"type": "string"}}},
+			"Line": {"type": "object", "properties": {"sku": {"type": "string"}, "quantity": {"type": "integer"}}},
+			"Category": {"type": "object", "properties": {"name": {"type": "string"}, "parent": {"$ref": "#/definitions/Category"}}},
+			"Order": {
+				"allOf": [
+					{"$ref": "#/definitions/Entity"},
+					{
+						"type": "object",
+						"properties": {
+							"shipping": {"$ref": "#/definitions/Address"},
+							"lines": {"type": "array", "items": {"$ref": "#/definitions/Line"}},
+							"category": {"$ref": "#/definitions/Category"},
+							"addresses": {"type": "object", "additionalProperties": {"$ref": "#/definitions/Address"}}
+						}
+					}
+				]
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	schema, err := GenerateInputSchema(spec.GetPaths()["/orders"].GetOperations()["post"])
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	if id := schema.Properties["id"]; id == nil || id.Type != "string" {
+		t.Errorf("Expected id from the allOf base, got %+v", id)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"id"}) {
+		t.Errorf("Expected id to be required, got %v", schema.Required)
+	}
+	if city := schema.Properties["shipping"].Properties["city"]; city == nil || city.Type != "string" {
+		t.Errorf("Expected shipping.city from the Address definition, got %+v", schema.Properties["shipping"])
+	}
+	if items := schema.Properties["lines"].Items; items == nil || items.Properties["quantity"] == nil {
+		t.Errorf("Expected lines items from the Line definition, got %+v", items)
+	}
+	if address := schema.Properties["addresses"].AdditionalProperties; address == nil || address.Properties["city"] == nil {
+		t.Errorf("Expected addresses values from the Address definition, got %+v", address)
+	}
+
+	// The recursive parent ends in a free-form schema
+	parent := schema.Properties["category"].Properties["parent"]
+	if parent == nil || parent.Type != "" || len(parent.Properties) != 0 {
+		t.Errorf("Expected the recursive reference to be free-form, got %+v", parent)
+	}
+}
+
+func TestHashIgnoresFormat(t *testing.T) {
+	jsonSpec, err := LoadSpec([]byte(`{"openapi": "3.0.0", "info": {"title": "Test", "version": "1.0.0"}, "paths": {}}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	yamlSpec, err := LoadSpec([]byte("openapi: 3.0.0\ninfo:\n  title: Test\n  version: 1.0.0\npaths: {}\n"))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	otherSpec, err := LoadSpec([]byte(`{"openapi": "3.0.0", "info": {"title": "Test", "version": "2.0.0"}, "paths": {}}`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	jsonHash, err := Hash(jsonSpec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if yamlHash, _ := Hash(yamlSpec); yamlHash != jsonHash {
+		t.Errorf("Expected JSON and YAML to hash alike, got %s and %s", jsonHash, yamlHash)
+	}
+	if otherHash, _ := Hash(otherSpec); otherHash == jsonHash {
+		t.Error("Expected different specs to hash differently")
+	}
+}
+
+func TestLoadSpecFromURLWithHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Private API", "version": "1.0.0"}, "paths": {}}`))
+	}))
+	defer server.Close()
+
+	if _, err := LoadSpecFromSource(server.URL); err == nil {
+		t.Error("Expected an error without the spec headers")
+	}
+
+	spec, err := LoadSpecFromSourceWithOptions(server.URL, &LoadOptions{
+		Headers: http.Header{"Authorization": {"token secret"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if spec.GetInfo().Title != "Private API" {
+		t.Errorf("Expected the private spec, got %q", spec.GetInfo().Title)
+	}
+}
@@ -0,0 +1,231 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var routeParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// ToolRoute is the HTTP operation an imported tool definition is served with
+type ToolRoute struct {
+	Method string
+	Path   string
+}
+
+// ParseToolRoute parses a route such as "GET /users/{id}"
+func ParseToolRoute(route string) (ToolRoute, error) {
+	method, path, found := strings.Cut(strings.TrimSpace(route), " ")
+	path = strings.TrimSpace(path)
+	if !found || !strings.HasPrefix(path, "/") {
+		return ToolRoute{}, fmt.Errorf("invalid route %q (expected e.g. 'GET /users/{id}')", route)
+	}
+	return ToolRoute{Method: strings.ToUpper(method), Path: path}, nil
+}
+
+// ToolDefinitionOptions configures how imported tool definitions map to HTTP
+type ToolDefinitionOptions struct {
+	// BaseURL is the server the tools are sent to
+	BaseURL string
+	// Routes maps tool names to operations, unmapped tools are sent as
+	// POST /<name>
+	Routes map[string]ToolRoute
+}
+
+// toolDefinition is a function-calling tool in the OpenAI or Anthropic format
+type toolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+	Function    *toolDefinition        `json:"function"`
+}
+
+// schema returns the JSON schema of the tool's arguments
+func (d *toolDefinition) schema() map[string]interface{} {
+	if d.InputSchema != nil {
+		return d.InputSchema
+	}
+	return d.Parameters
+}
+
+// parseToolDefinitions parses a list of tool definitions, a {"tools": [...]}
+// object or a single definition. It returns false when data isn't tool
+// definitions at all.
+func parseToolDefinitions(data []byte) ([]toolDefinition, bool) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false
+	}
+
+	if object, ok := doc.(map[string]interface{}); ok {
+		if _, ok := object["openapi"]; ok {
+			return nil, false
+		}
+		if _, ok := object["swagger"]; ok {
+			return nil, false
+		}
+		if tools, ok := object["tools"]; ok {
+			data, _ = json.Marshal(tools)
+		} else {
+			data, _ = json.Marshal([]interface{}{object})
+		}
+	}
+
+	var definitions []toolDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil || len(definitions) == 0 {
+		return nil, false
+	}
+
+	for i, definition := range definitions {
+		if definition.Function != nil {
+			definitions[i] = *definition.Function
+		}
+		if definitions[i].Name == "" {
+			return nil, false
+		}
+	}
+	return definitions, true
+}
+
+// IsToolDefinitions reports whether data holds OpenAI or Anthropic
+// function-calling tool definitions rather than an OpenAPI spec
+func IsToolDefinitions(data []byte) bool {
+	_, ok := parseToolDefinitions(data)
+	return ok
+}
+
+// LoadToolDefinitions converts function-calling tool definitions into an
+// OpenAPI 3 spec. Arguments named in the route's path become path
+// parameters, the others query parameters for GET and DELETE or properties of
+// a JSON body otherwise.
+func LoadToolDefinitions(data []byte, opts ToolDefinitionOptions) (APISpec, error) {
+	definitions, ok := parseToolDefinitions(data)
+	if !ok {
+		return nil, fmt.Errorf("unsupported tool definitions, expected OpenAI or Anthropic function-calling tools")
+	}
+
+	names := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		names[definition.Name] = true
+	}
+	for name := range opts.Routes {
+		if !names[name] {
+			return nil, fmt.Errorf("route for unknown tool %s", name)
+		}
+	}
+
+	spec := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "Imported tools", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+	}
+	if opts.BaseURL != "" {
+		spec.Servers = openapi3.Servers{{URL: opts.BaseURL}}
+	}
+
+	for _, definition := range definitions {
+		route, ok := opts.Routes[definition.Name]
+		if !ok {
+			route = ToolRoute{Method: http.MethodPost, Path: "/" + definition.Name}
+		}
+
+		operation, err := toolOperation(definition, route)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", definition.Name, err)
+		}
+
+		pathItem := spec.Paths.Value(route.Path)
+		if pathItem == nil {
+			pathItem = &openapi3.PathItem{}
+			spec.Paths.Set(route.Path, pathItem)
+		}
+		if pathItem.GetOperation(route.Method) != nil {
+			return nil, fmt.Errorf("tool %s: %s %s is already routed to another tool", definition.Name, route.Method, route.Path)
+		}
+		pathItem.SetOperation(route.Method, operation)
+	}
+
+	return &OpenAPI3Spec{spec: spec}, nil
+}
+
+// toolOperation builds the operation serving a tool definition
+func toolOperation(definition toolDefinition, route ToolRoute) (*openapi3.Operation, error) {
+	schema := &openapi3.Schema{}
+	if raw := definition.schema(); raw != nil {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, schema); err != nil {
+			return nil, fmt.Errorf("invalid input schema: %w", err)
+		}
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	operation := &openapi3.Operation{
+		OperationID: definition.Name,
+		Summary:     definition.Description,
+		Responses:   openapi3.NewResponses(),
+	}
+
+	inPath := make(map[string]bool)
+	for _, match := range routeParamRegex.FindAllStringSubmatch(route.Path, -1) {
+		name := match[1]
+		param := openapi3.NewPathParameter(name).WithSchema(propertySchema(schema, name))
+		operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{Value: param})
+		inPath[name] = true
+	}
+
+	var rest []string
+	for name := range schema.Properties {
+		if !inPath[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+
+	if route.Method == http.MethodGet || route.Method == http.MethodDelete {
+		for _, name := range rest {
+			param := openapi3.NewQueryParameter(name).WithSchema(propertySchema(schema, name))
+			param.Required = required[name]
+			param.Description = param.Schema.Value.Description
+			operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{Value: param})
+		}
+		return operation, nil
+	}
+
+	if len(rest) == 0 {
+		return operation, nil
+	}
+
+	body := openapi3.NewObjectSchema()
+	for _, name := range rest {
+		body.WithPropertyRef(name, schema.Properties[name])
+		if required[name] {
+			body.Required = append(body.Required, name)
+		}
+	}
+	operation.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(len(body.Required) > 0).WithJSONSchema(body)}
+
+	return operation, nil
+}
+
+// propertySchema returns the schema of a tool argument, a string when the
+// tool doesn't declare it
+func propertySchema(schema *openapi3.Schema, name string) *openapi3.Schema {
+	if ref, ok := schema.Properties[name]; ok && ref != nil && ref.Value != nil {
+		return ref.Value
+	}
+	return openapi3.NewStringSchema()
+}
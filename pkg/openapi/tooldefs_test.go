@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const openAITools = `[
+  {
+    "type": "function",
+    "function": {
+      "name": "get_user",
+      "description": "Get a user",
+      "parameters": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "expand": {"type": "boolean", "description": "Include related objects"}
+        },
+        "required": ["id"]
+      }
+    }
+  },
+  {
+    "type": "function",
+    "function": {
+      "name": "create_user",
+      "description": "Create a user",
+      "parameters": {
+        "type": "object",
+        "properties": {"name": {"type": "string"}, "email": {"type": "string"}},
+        "required": ["name"]
+      }
+    }
+  }
+]`
+
+const anthropicTools = `{"tools": [{"name": "search", "description": "Search", "input_schema": {"type": "object", "properties": {"q": {"type": "string"}}}}]}`
+
+func TestLoadToolDefinitions(t *testing.T) {
+	spec, err := LoadToolDefinitions([]byte(openAITools), ToolDefinitionOptions{
+		BaseURL: "https://api.example.com",
+		Routes:  map[string]ToolRoute{"get_user": {Method: "GET", Path: "/users/{id}"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if spec.GetBaseURL() != "https://api.example.com" {
+		t.Errorf("Expected base URL, got %s", spec.GetBaseURL())
+	}
+
+	getUser := spec.GetPaths()["/users/{id}"].GetOperations()["get"]
+	if getUser == nil || getUser.GetOperationID() != "get_user" || getUser.GetSummary() != "Get a user" {
+		t.Fatalf("Expected get_user at GET /users/{id}, got %v", spec.GetPaths())
+	}
+
+	params := map[string]string{}
+	for _, param := range getUser.GetParameters() {
+		params[param.GetName()] = param.GetIn()
+	}
+	if !reflect.DeepEqual(params, map[string]string{"id": "path", "expand": "query"}) {
+		t.Errorf("Expected id in path and expand in query, got %v", params)
+	}
+
+	// Unrouted tools are posted to /<name> with their arguments as JSON body
+	createUser := spec.GetPaths()["/create_user"].GetOperations()["post"]
+	if createUser == nil || createUser.GetRequestBody() == nil {
+		t.Fatalf("Expected create_user at POST /create_user with a body, got %v", spec.GetPaths())
+	}
+
+	body, err := createUser.GetRequestBody().GetJSONSchema()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var properties []string
+	for name := range body.GetProperties() {
+		properties = append(properties, name)
+	}
+	sort.Strings(properties)
+	if !reflect.DeepEqual(properties, []string{"email", "name"}) || !reflect.DeepEqual(body.GetRequired(), []string{"name"}) {
+		t.Errorf("Unexpected body schema: %v required %v", properties, body.GetRequired())
+	}
+}
+
+func TestIsToolDefinitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected bool
+	}{
+		{name: "OpenAI tools", data: openAITools, expected: true},
+		{name: "Anthropic tools", data: anthropicTools, expected: true},
+		{name: "single function", data: `{"name": "ping", "parameters": {"type": "object"}}`, expected: true},
+		{name: "OpenAPI spec", data: `{"openapi": "3.0.0", "info": {"title": "Test", "version": "1.0.0"}, "paths": {}}`, expected: false},
+		{name: "YAML", data: "openapi: 3.0.0\n", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsToolDefinitions([]byte(tt.data)); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadToolDefinitionsErrors(t *testing.T) {
+	if _, err := LoadToolDefinitions([]byte(anthropicTools), ToolDefinitionOptions{Routes: map[string]ToolRoute{"missing": {Method: "GET", Path: "/"}}}); err == nil {
+		t.Error("Expected error for a route of an unknown tool")
+	}
+
+	routes := map[string]ToolRoute{
+		"get_user":    {Method: "GET", Path: "/users"},
+		"create_user": {Method: "GET", Path: "/users"},
+	}
+	if _, err := LoadToolDefinitions([]byte(openAITools), ToolDefinitionOptions{Routes: routes}); err == nil {
+		t.Error("Expected error for two tools routed to the same operation")
+	}
+
+	if _, err := ParseToolRoute("users/{id}"); err == nil {
+		t.Error("Expected error for a route without method")
+	}
+}
@@ -0,0 +1,93 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is an issue found in a spec. Location names the operation, such as
+// "GET /users/{id}", and is empty for the document itself.
+type Problem struct {
+	Severity string
+	Location string
+	Message  string
+}
+
+func (p Problem) String() string {
+	if p.Location == "" {
+		return fmt.Sprintf("%s: %s", p.Severity, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Location, p.Message)
+}
+
+// ValidateDocument parses a spec strictly. Unlike LoadSpec, an OpenAPI 3
+// document failing validation is reported and still returned, so its
+// operations can be checked too. The spec is nil when it can't be parsed.
+func ValidateDocument(data []byte) (APISpec, []Problem) {
+	fail := func(format string, args ...interface{}) (APISpec, []Problem) {
+		return nil, []Problem{{Severity: SeverityError, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if IsAsyncAPI(data) {
+		spec, err := LoadAsyncAPI(data)
+		if err != nil {
+			return fail("%v", err)
+		}
+		return spec, nil
+	}
+
+	if IsToolDefinitions(data) {
+		spec, err := LoadToolDefinitions(data, ToolDefinitionOptions{})
+		if err != nil {
+			return fail("%v", err)
+		}
+		return spec, nil
+	}
+
+	var header struct {
+		OpenAPI string `yaml:"openapi"`
+		Swagger string `yaml:"swagger"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return fail("not a JSON or YAML document: %v", err)
+	}
+
+	switch {
+	case header.OpenAPI != "":
+		loader := openapi3.NewLoader()
+		loader.IsExternalRefsAllowed = true
+
+		spec, err := loader.LoadFromData(data)
+		if err != nil {
+			return fail("failed to parse OpenAPI %s document, check that every $ref resolves: %v", header.OpenAPI, err)
+		}
+
+		var problems []Problem
+		if err := spec.Validate(loader.Context); err != nil {
+			problems = append(problems, Problem{Severity: SeverityError, Message: fmt.Sprintf("invalid OpenAPI %s document: %v", header.OpenAPI, err)})
+		}
+		return &OpenAPI3Spec{spec: spec}, problems
+	case header.Swagger != "":
+		spec, err := LoadSpec(data)
+		if err != nil {
+			return fail("failed to parse Swagger %s document: %v", header.Swagger, err)
+		}
+		return spec, nil
+	default:
+		return fail("not an OpenAPI document, expected an openapi or swagger version field")
+	}
+}
+
+// OperationLocation names an operation in problems, e.g. "GET /users/{id}"
+func OperationLocation(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}